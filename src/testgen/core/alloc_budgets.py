@@ -0,0 +1,333 @@
+"""
+Allocation Budgets for Go Functions.
+
+Hot paths that must not allocate (or allocate at most N times per call)
+get a budget, either in testgen.toml:
+
+    [[alloc_budgets]]
+    package = "internal/codec"
+    function = "Encode"
+    max_allocs = 0
+
+or as a directive above the function (testgen.toml wins when both are set):
+
+    //testgen:allocs 0
+    func Encode(dst []byte, v int64) []byte {
+
+The generated `testgen_alloc_test.go` checks every budget with
+`testing.AllocsPerRun` across input sizes, built like the generated
+benchmarks' inputs. For functions over budget, the escape analysis of
+`go build -gcflags=-m` shows what moved to the heap.
+"""
+
+import os
+import re
+import subprocess
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Dict, List, Optional
+
+from .base_runner import TestResults
+from .go_benchmarks import (
+    BenchmarkTarget, _input_helpers, _inputs, _sinks, _doc_comment, _test_file, _write_test_file, find_go_functions,
+)
+
+
+ALLOC_TEST_FILE = "testgen_alloc_test.go"
+ALLOC_DIRECTIVE = re.compile(r'^//\s*testgen:allocs\s+(\d+(?:\.\d+)?)\s*$', re.MULTILINE)
+
+# Calls averaged by testing.AllocsPerRun
+ALLOC_RUNS = 100
+
+# ./codec.go:14:13: make([]byte, n) escapes to heap  |  ./codec.go:15:2: moved to heap: buf
+ESCAPE_LINE = re.compile(r'^(?:\./)?([^\s:]+\.go):(\d+):(\d+): (.*\b(?:escapes to heap|moved to heap)\b.*)$')
+
+@dataclass
+class AllocBudget:
+    """Allocations per call a Go function may make."""
+    
+    target: BenchmarkTarget
+    max_allocs: float
+    file: str                # source file name
+    source: str = "directive"   # "directive" or "config"
+    
+    @property
+    def test_name(self) -> str:
+        return "TestAllocs" + self.target.benchmark_name[len("Benchmark"):]
+    
+    @property
+    def budget(self) -> str:
+        return f"{self.max_allocs:g}"
+
+
+@dataclass
+class EscapeHint:
+    """A value the compiler moved to the heap (`-gcflags=-m`)."""
+    
+    file: str
+    line: int
+    message: str
+    
+    def __str__(self) -> str:
+        return f"{self.file}:{self.line}: {self.message}"
+
+
+def configured_budgets(project, package_dir: str) -> Dict[str, float]:
+    """
+    Budgets declared in testgen.toml for one package.
+    
+    Args:
+        project: ProjectConfig
+        package_dir: Go package directory
+        
+    Returns:
+        Qualified function name -> allocations per call
+    """
+    directory = Path(package_dir).resolve()
+    return {
+        budget.function: budget.max_allocs
+        for budget in project.alloc_budgets
+        if project.resolve(budget.package).resolve() == directory
+    }
+
+
+def find_alloc_budgets(package_dir: str, configured: Optional[Dict[str, float]] = None) -> List[AllocBudget]:
+    """
+    Functions of a package with an allocation budget.
+    
+    Args:
+        package_dir: Go package directory
+        configured: Budgets from testgen.toml (see `configured_budgets`)
+        
+    Returns:
+        Budgets in source order
+    """
+    configured = configured or {}
+    budgets = []
+    for source in sorted(Path(package_dir).glob("*.go")):
+        if source.name.endswith("_test.go"):
+            continue
+        code = source.read_text(encoding='utf-8', errors='ignore')
+        for function in find_go_functions(code):
+            if function.qualified_name in configured:
+                budgets.append(AllocBudget(function, configured[function.qualified_name], source.name, "config"))
+                continue
+            comment = _doc_comment(code, _line_offset(code, function.line))
+            directive = ALLOC_DIRECTIVE.search(comment)
+            if directive:
+                budgets.append(AllocBudget(function, float(directive.group(1)), source.name))
+    return budgets
+
+
+def describe_alloc_budgets(budgets: List[AllocBudget]) -> str:
+    """
+    Describe budgets without generated tests for the allocation prompt block.
+    
+    Args:
+        budgets: Budgets of the package
+        
+    Returns:
+        One line per budget
+    """
+    lines = []
+    for budget in budgets:
+        target = budget.target
+        if target.generatable:
+            continue  # Covered by the generated tests
+        params = ", ".join(f"{p.name} {p.type}" for p in target.params)
+        lines.append(
+            f"- {budget.test_name}: {target.qualified_name}({params}) "
+            f"(line {target.line}): at most {budget.budget} allocations per call"
+        )
+    return "\n".join(lines)
+
+
+def generate_go_alloc_tests(
+    package: str,
+    budgets: List[AllocBudget],
+    imports: Optional[Dict[str, str]] = None
+) -> str:
+    """
+    Generate the Go allocation budget tests for a package.
+    
+    Args:
+        package: Go package name
+        budgets: Budgets (tests for those whose function is generatable)
+        imports: Package qualifier -> import path of the package's own imports
+            (for types of the sinks)
+            
+    Returns:
+        Go source
+    """
+    generatable = [b for b in budgets if b.target.generatable]
+    tests = "".join(_alloc_test(budget) for budget in generatable)
+    helpers = _input_helpers(tests, "testgenAlloc")
+    if any(p.type == "error" for b in generatable for p in b.target.results):
+        helpers = "\nvar testgenAllocSinkErr error\n" + helpers
+    return _test_file(GO_ALLOC_TEST, package, tests, helpers, imports)
+
+
+def write_go_alloc_tests(package_dir: str, budgets: List[AllocBudget]) -> Optional[Path]:
+    """
+    Write `testgen_alloc_test.go` for the budgets of a package.
+    
+    Args:
+        package_dir: Go package directory
+        budgets: Budgets (see `find_alloc_budgets`)
+        
+    Returns:
+        Path of the written file, or None when no budgeted function is generatable
+    """
+    if not any(b.target.generatable for b in budgets):
+        return None
+    return _write_test_file(
+        package_dir, ALLOC_TEST_FILE,
+        lambda package, imports: generate_go_alloc_tests(package, budgets, imports)
+    )
+
+
+def over_budget(results: TestResults, budgets: List[AllocBudget]) -> Dict[str, List[str]]:
+    """
+    Budgets the generated tests found exceeded.
+    
+    Args:
+        results: Results of the `^TestAllocs` run
+        budgets: Budgets the tests were generated for
+        
+    Returns:
+        Qualified function name -> failure messages (one per input size)
+    """
+    return _subtests(results, budgets, ("failed", "error"), "allocations")
+
+
+def unverified_budgets(results: TestResults, budgets: List[AllocBudget]) -> Dict[str, List[str]]:
+    """
+    Budgets the generated tests couldn't check at some input sizes.
+    
+    Subtests skip when the function returns an error for the sample input,
+    so nothing was measured for that size.
+    
+    Args:
+        results: Results of the `^TestAllocs` run
+        budgets: Budgets the tests were generated for
+        
+    Returns:
+        Qualified function name -> skip messages (one per input size)
+    """
+    return _subtests(results, budgets, ("skipped",), "sample input rejected")
+
+
+def escape_hints(package_dir: str, budgets: List[AllocBudget], timeout: int = 300) -> Dict[str, List[EscapeHint]]:
+    """
+    What the compiler moves to the heap inside budgeted functions.
+    
+    Args:
+        package_dir: Go package directory
+        budgets: Budgets to explain (usually the exceeded ones)
+        timeout: Timeout of the build in seconds
+        
+    Returns:
+        Qualified function name -> hints in source order (empty if the build fails)
+    """
+    try:
+        completed = subprocess.run(
+            ["go", "build", "-gcflags=-m", "-o", os.devnull, "."],
+            cwd=package_dir, capture_output=True, text=True, timeout=timeout
+        )
+    except (OSError, subprocess.TimeoutExpired):
+        return {}
+    
+    hints: Dict[str, List[EscapeHint]] = {}
+    seen = set()
+    for line in completed.stderr.splitlines():
+        match = ESCAPE_LINE.match(line.strip())
+        if not match or line in seen:
+            continue
+        seen.add(line)
+        file, number = Path(match.group(1)).name, int(match.group(2))
+        for budget in budgets:
+            if budget.file == file and budget.target.line <= number <= budget.target.end_line:
+                hints.setdefault(budget.target.qualified_name, []).append(EscapeHint(file, number, match.group(4)))
+    return hints
+
+
+GO_ALLOC_TEST = """// Code generated by testgen allocs. Allocation budgets, checked with
+// testing.AllocsPerRun:
+//
+//	go test -run '^TestAllocs' -v .
+//
+// Budgets come from //testgen:allocs directives and [[alloc_budgets]] in
+// testgen.toml; results go to package-level sinks so the calls aren't
+// eliminated.
+
+package {package}
+
+import (
+{imports}
+)
+
+var testgenAllocSizes = []int{{sizes}}
+{body}"""
+
+
+def _alloc_test(budget: AllocBudget) -> str:
+    """Subtests across input sizes checking one function's budget."""
+    target = budget.target
+    setup, call, readers = _inputs(target, "testgenAlloc")
+    declarations, assigned = _sinks(target, "testgenAllocSink")
+    
+    lines = list(setup)
+    if target.results and target.results[-1].type == "error":
+        # Count the allocations of the work, not of the error path
+        blanks = "_, " * (len(target.results) - 1)
+        lines += [
+            f"if {blanks}err := {call}; err != nil {{",
+            '\tt.Skipf("sample input rejected: %v", err)',
+            "}",
+        ]
+    lines.append(f"allocs := testing.AllocsPerRun({ALLOC_RUNS}, func() {{")
+    lines += [f"\t{reader}.Reset(data)" for reader in readers]
+    lines.append(f"\t{assigned} = {call}" if assigned else f"\t{call}")
+    lines += [
+        "})",
+        f"if allocs > {budget.budget} {{",
+        f'\tt.Errorf("%v allocations per call, budget {budget.budget}", allocs)',
+        "}",
+    ]
+    
+    body = "".join(f"\t\t\t{line}\n" for line in lines)
+    origin = "testgen.toml" if budget.source == "config" else "//testgen:allocs"
+    return (
+        declarations
+        + f"\n// {budget.test_name} checks {target.qualified_name}'s budget of {budget.budget} "
+        f"allocations per call ({origin}).\n"
+        f"func {budget.test_name}(t *testing.T) {{\n"
+        f"\tfor _, n := range testgenAllocSizes {{\n"
+        f'\t\tt.Run(fmt.Sprintf("n=%d", n), func(t *testing.T) {{\n'
+        f"{body}"
+        f"\t\t}})\n"
+        f"\t}}\n"
+        f"}}\n"
+    )
+
+
+def _subtests(results: TestResults, budgets: List[AllocBudget], statuses: tuple, keyword: str) -> Dict[str, List[str]]:
+    """"n=SIZE: message" of the sized subtests with one of the statuses, by qualified function name."""
+    by_test = {b.test_name: b for b in budgets}
+    found: Dict[str, List[str]] = {}
+    for test in results.tests:
+        name, _, size = test.name.partition("/")
+        if test.status not in statuses or not size or name not in by_test:
+            continue
+        message = next((line.strip() for line in (test.message or "").splitlines() if keyword in line), "")
+        message = re.sub(r'^\S+_test\.go:\d+: ', "", message)
+        found.setdefault(by_test[name].target.qualified_name, []).append(f"{size}: {message}" if message else size)
+    return found
+
+
+def _line_offset(code: str, line: int) -> int:
+    """Offset of the start of a 1-based line."""
+    offset = 0
+    for _ in range(line - 1):
+        offset = code.index("\n", offset) + 1
+    return offset
@@ -0,0 +1,566 @@
+"""
+Allure Results Export for TestGen AI.
+
+Writes a stored run (see `run_store.RunStore`) in Allure's results format,
+so `allure generate` / `allure serve` dashboards can show it:
+
+    <output>/<uuid>-result.json       One per test (and per failed retry attempt)
+    <output>/<uuid>-container.json    One per package/module, holding profiles,
+                                      and one holding the run's artifacts
+    <output>/<uuid>-attachment.txt    Logs, golden diffs and artifacts
+    <output>/environment.properties   Language, framework, run id
+    <output>/executor.json
+
+Go subtests (`t.Run`, recorded as "TestX/case/inner") become nested steps
+of their top-level test. Every result carries `language`, `framework`,
+`package`, `owner` (from CODEOWNERS) and `testType` labels, and a
+`historyId` derived from the test's stable key, so Allure's trends and
+retries line up across runs. Trends also need the previous report's
+`history/` directory copied into the results (see `copy_history`).
+
+The run's artifacts (see `artifacts`) are attached too: per-test ones to
+their test, the rest (runner output, reports, snapshots) to a container
+of the whole run.
+"""
+
+import hashlib
+import json
+import re
+import shutil
+import uuid
+from dataclasses import dataclass, field
+from datetime import datetime
+from pathlib import Path
+from typing import Any, Dict, List, Optional, Tuple
+
+from .base_runner import TestResult
+from .benchmark import go_profile_name
+from .fault_injection import FAULT_TEST_FILE
+from .load_test import LOAD_TEST_FILE
+from .run_store import test_key
+from .security_tests import find_security_tests, security_category
+
+
+# Runner status -> Allure status
+STATUSES = {
+    "passed": "passed",
+    "passed_on_retry": "passed",
+    "failed": "failed",
+    "error": "broken",
+    "skipped": "skipped",
+}
+
+# Test type label, by test name or file name (first match wins; security tests are marked, see `security_tests`)
+TEST_TYPES = [
+    ("load", re.compile(r'^TestLoad'), re.compile(rf'^{re.escape(LOAD_TEST_FILE)}$')),
+    ("contract", re.compile(r'(?i)^(?:TestContract|test_contract_)'), re.compile(r'^(?:contract_\w+_test\.go|test_contract_\w+\.py)$')),
+    ("fault-injection", re.compile(r'^TestFault'), re.compile(rf'^{re.escape(FAULT_TEST_FILE)}$')),
+    ("port", None, re.compile(r'(?:_port_test\.go|^test_\w+_port\.py)$')),
+    ("benchmark", re.compile(r'^Benchmark'), None),
+    ("fuzz", re.compile(r'^Fuzz'), None),
+    ("example", re.compile(r'^Example'), None),
+]
+
+# Artifact file suffix -> attachment type (Allure shows text inline)
+ATTACHMENT_TYPES = {
+    ".txt": "text/plain",
+    ".diff": "text/plain",
+    ".out": "text/plain",
+    ".json": "application/json",
+}
+
+CODEOWNERS_LOCATIONS = ["CODEOWNERS", ".github/CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"]
+
+# Start of a golden/expected-vs-actual diff in a failure message
+DIFF_START = re.compile(
+    r'^.*(?:\(-\s*(?:want|expected|golden)\s+\+\s*(?:got|actual)\)'
+    r'|\(-\s*(?:got|actual)\s+\+\s*(?:want|expected|golden)\)).*$'
+    r'|^--- \S.*\n\+\+\+ \S.*$'
+    r'|^@@ -\d+(?:,\d+)? \+\d+(?:,\d+)? @@',
+    re.MULTILINE
+)
+
+GO_TEST_FUNC = r'^func\s+{name}\s*\('
+
+
+@dataclass
+class CodeOwners:
+    """CODEOWNERS rules: the last matching pattern wins, as on GitHub and GitLab."""
+    
+    rules: List[Tuple[str, List[str]]] = field(default_factory=list)
+    
+    @classmethod
+    def load(cls, project_root: Path) -> "CodeOwners":
+        """Read the first CODEOWNERS file found in the usual locations."""
+        for location in CODEOWNERS_LOCATIONS:
+            path = project_root / location
+            if path.is_file():
+                return cls.parse(path.read_text(encoding='utf-8', errors='replace'))
+        return cls()
+    
+    @classmethod
+    def parse(cls, text: str) -> "CodeOwners":
+        rules = []
+        for line in text.splitlines():
+            line = line.split("#", 1)[0].strip()
+            # GitLab [Section] headers
+            if not line or line.startswith("["):
+                continue
+            pattern, *owners = line.split()
+            rules.append((pattern, owners))
+        return cls(rules)
+    
+    def owners(self, path: str) -> List[str]:
+        """Owners of a path relative to the project root."""
+        path = path.replace("\\", "/").removeprefix("./")
+        found: List[str] = []
+        for pattern, owners in self.rules:
+            if _codeowners_match(pattern, path):
+                found = owners
+        return found
+
+
+def export_run(
+    run: Dict[str, Any],
+    output_dir: Path,
+    project_root: Path = Path("."),
+    profile_dir: Optional[Path] = None
+) -> List[Path]:
+    """
+    Write a stored run as Allure results.
+    
+    Args:
+        run: Full run data (see `RunStore.load_run`)
+        output_dir: Allure results directory (created if missing)
+        project_root: Root the tests' files and CODEOWNERS are relative to
+        profile_dir: Directory of profiles to attach to their package
+            (named by `benchmark.go_profile_name`, as `BenchmarkHarness` writes them)
+            
+    Returns:
+        The result files written
+    """
+    output_dir.mkdir(parents=True, exist_ok=True)
+    language = run.get("language") or "unknown"
+    framework = run.get("framework") or "unknown"
+    start = _millis(run.get("timestamp"))
+    owners = CodeOwners.load(project_root)
+    locator = _TestLocator(project_root, language)
+    security = find_security_tests(str(project_root))
+    
+    tests = [_test_result(data) for data in run.get("tests", [])]
+    per_test, run_files = _run_artifacts(run)
+    written: List[Path] = []
+    children: Dict[str, List[str]] = {}
+    
+    for test, subtests in _group_subtests(tests, language):
+        file_path = locator.file(test)
+        history_id = _digest(f"{language}:{test_key(test)}")
+        labels = _labels(test, file_path, language, framework, owners, security)
+        
+        # Failed attempts before the final one are Allure retries (same historyId)
+        offset = 0
+        for attempt in test.attempts[:-1]:
+            retry = {
+                "status": STATUSES.get(attempt.get("status"), "unknown"),
+                "statusDetails": {"message": attempt.get("message")} if attempt.get("message") else {},
+                "start": start + offset,
+                "stop": start + offset + _duration_ms(attempt.get("duration")),
+            }
+            offset = retry["stop"] - start
+            written.append(_write_result(output_dir, test, history_id, labels, retry, []))
+        
+        result = {
+            "status": STATUSES.get(test.status, "unknown"),
+            "statusDetails": _status_details(test),
+            "start": start + offset,
+            "stop": start + offset + _duration_ms(test.duration),
+            "steps": [_step(output_dir, sub, subtests, start + offset) for sub in _children(test.name, subtests)],
+        }
+        attachments = _attachments(output_dir, test, per_test.get(test_key(test), []))
+        path = _write_result(output_dir, test, history_id, labels, result, attachments)
+        written.append(path)
+        children.setdefault(_package(test, file_path), []).append(path.name[:-len("-result.json")])
+    
+    for package, uuids in children.items():
+        _write_container(output_dir, package, uuids, "Profiles", _profiles(profile_dir, package), start)
+    if run_files:
+        every = [child for uuids in children.values() for child in uuids]
+        _write_container(output_dir, f"run {run.get('id', '')}", every, "Run artifacts", run_files, start)
+    
+    (output_dir / "environment.properties").write_text(
+        "".join(f"{k}={v}\n" for k, v in [
+            ("language", language), ("framework", framework),
+            ("test_dir", run.get("test_dir") or ""), ("run", run.get("id", "")),
+        ]),
+        encoding='utf-8'
+    )
+    (output_dir / "executor.json").write_text(json.dumps({
+        "name": "TestGen AI", "type": "testgen", "buildName": run.get("id", ""),
+    }, indent=2), encoding='utf-8')
+    
+    return written
+
+
+def copy_history(report_dir: Path, output_dir: Path) -> bool:
+    """
+    Copy a previous Allure report's `history/` into the results for trends.
+    
+    Returns:
+        Whether there was a history to copy
+    """
+    history = report_dir / "history"
+    if not history.is_dir():
+        return False
+    shutil.copytree(history, output_dir / "history", dirs_exist_ok=True)
+    return True
+
+
+def classify_test(name: str, file_path: Optional[str], security: Optional[Dict[str, str]] = None) -> str:
+    """Kind of test, from the security markers and the naming conventions of the generators ("unit" otherwise)."""
+    if security_category(name, security or {}):
+        return "security"
+    base = name.split("::")[-1].split("/")[0]
+    file_name = Path(file_path).name if file_path else ""
+    for kind, by_name, by_file in TEST_TYPES:
+        if (by_name and by_name.search(base)) or (by_file and file_name and by_file.search(file_name)):
+            return kind
+    return "unit"
+
+
+def golden_diff(text: Optional[str]) -> Optional[str]:
+    """The expected-vs-actual diff in a failure message, if it has one."""
+    if not text:
+        return None
+    match = DIFF_START.search(text)
+    if match is None:
+        return None
+    diff = text[match.start():]
+    lines = diff.splitlines()
+    if not any(l.lstrip().startswith("-") for l in lines[1:]) or not any(l.lstrip().startswith("+") for l in lines[1:]):
+        return None
+    return diff.strip("\n") + "\n"
+
+
+class _TestLocator:
+    """Finds the file of a test; Go results only carry the package import path."""
+    
+    def __init__(self, project_root: Path, language: str):
+        self.root = project_root
+        self.language = language
+        self._modules: Optional[List[Tuple[str, Path]]] = None
+        self._packages: Dict[str, List[Path]] = {}
+    
+    def file(self, test: TestResult) -> Optional[str]:
+        """File of a test relative to the project root."""
+        if test.file_path:
+            path = Path(test.file_path)
+            if path.is_absolute() and path.is_relative_to(self.root.resolve()):
+                path = path.relative_to(self.root.resolve())
+            return str(path)
+        if "::" in test.name:  # pytest node id
+            return test.name.split("::", 1)[0]
+        if self.language == "go" and test.suite:
+            return self._go_file(test.suite, test.name.split("/")[0])
+        return None
+    
+    def _go_file(self, package: str, name: str) -> Optional[str]:
+        files = self._packages.get(package)
+        if files is None:
+            directory = self._go_package_dir(package)
+            files = sorted(directory.glob("*_test.go")) if directory else []
+            self._packages[package] = files
+        definition = re.compile(GO_TEST_FUNC.format(name=re.escape(name)), re.MULTILINE)
+        for path in files:
+            if definition.search(path.read_text(encoding='utf-8', errors='replace')):
+                return str(path.resolve().relative_to(self.root.resolve()))
+        return None
+    
+    def _go_package_dir(self, package: str) -> Optional[Path]:
+        if self._modules is None:
+            self._modules = []
+            for go_mod in self.root.rglob("go.mod"):
+                if any(p in ("vendor", "testdata") or p.startswith(".") for p in go_mod.relative_to(self.root).parts):
+                    continue
+                match = re.search(r'^module\s+(\S+)', go_mod.read_text(encoding='utf-8', errors='replace'), re.MULTILINE)
+                if match:
+                    self._modules.append((match.group(1), go_mod.parent))
+            # Longest module path first: nested modules win
+            self._modules.sort(key=lambda m: -len(m[0]))
+        for module, directory in self._modules:
+            if package == module:
+                return directory
+            if package.startswith(module + "/"):
+                return directory / package[len(module) + 1:]
+        return None
+
+
+def _test_result(data: Dict[str, Any]) -> TestResult:
+    test = TestResult(
+        name=data.get("name", "unknown"),
+        status=data.get("status", "unknown"),
+        duration=data.get("duration") or 0.0,
+        message=data.get("message"),
+        traceback=data.get("traceback"),
+        file_path=data.get("file_path"),
+        line_number=data.get("line_number"),
+        suite=data.get("suite"),
+    )
+    # Kept as dicts: that's how the run store has them
+    test.attempts = list(data.get("attempts") or [])
+    return test
+
+
+def _group_subtests(tests: List[TestResult], language: str) -> List[Tuple[TestResult, List[TestResult]]]:
+    """Top-level tests with their Go subtests; a subtest whose parent wasn't recorded gets one."""
+    if language != "go":
+        return [(t, []) for t in tests]
+    
+    groups: Dict[Tuple[Optional[str], str], Tuple[TestResult, List[TestResult]]] = {}
+    for test in tests:
+        top = test.name.split("/")[0]
+        key = (test.suite, top)
+        if "/" not in test.name:
+            subtests = groups[key][1] if key in groups else []
+            groups[key] = (test, subtests)
+        else:
+            if key not in groups:
+                groups[key] = (TestResult(name=top, status="unknown", suite=test.suite), [])
+            groups[key][1].append(test)
+    
+    for parent, subtests in groups.values():
+        if parent.status == "unknown" and subtests:
+            statuses = {s.status for s in subtests}
+            parent.status = next((s for s in ("error", "failed", "passed") if s in statuses), "skipped")
+            parent.duration = sum(s.duration for s in _children(parent.name, subtests))
+    return list(groups.values())
+
+
+def _children(name: str, subtests: List[TestResult]) -> List[TestResult]:
+    depth = name.count("/") + 1
+    return [s for s in subtests if s.name.startswith(name + "/") and s.name.count("/") == depth]
+
+
+def _step(output_dir: Path, test: TestResult, subtests: List[TestResult], start: int) -> Dict[str, Any]:
+    """A Go subtest as an Allure step, with its own subtests nested."""
+    return {
+        "name": test.name.rsplit("/", 1)[1],
+        "status": STATUSES.get(test.status, "unknown"),
+        "statusDetails": _status_details(test),
+        "stage": "finished",
+        "start": start,
+        "stop": start + _duration_ms(test.duration),
+        "steps": [_step(output_dir, sub, subtests, start) for sub in _children(test.name, subtests)],
+        "attachments": _attachments(output_dir, test),
+        "parameters": [],
+    }
+
+
+def _status_details(test: TestResult) -> Dict[str, Any]:
+    details: Dict[str, Any] = {}
+    if test.message and test.status != "passed":
+        details["message"] = test.message.strip().splitlines()[0]
+    if test.traceback:
+        details["trace"] = test.traceback
+    elif test.message and "\n" in test.message.strip():
+        details["trace"] = test.message
+    if test.status == "passed_on_retry":
+        details["flaky"] = True
+    return details
+
+
+def _attachments(output_dir: Path, test: TestResult, files: Optional[List[Path]] = None) -> List[Dict[str, str]]:
+    """Test output as a log, the golden diff in it separately, and the test's artifacts."""
+    attachments = []
+    log = "\n".join(part for part in (test.message, test.traceback) if part)
+    if log:
+        attachments.append(_attach(output_dir, "Log", log, "text/plain", "txt"))
+    diff = golden_diff(log)
+    # A kept golden diff artifact is the same diff
+    if diff and not any(f.parent.name == "golden-diff" for f in files or []):
+        attachments.append(_attach(output_dir, "Golden diff", diff, "text/plain", "diff"))
+    attachments.extend(_attach_file(output_dir, f) for f in files or [])
+    return attachments
+
+
+def _attach(output_dir: Path, name: str, content: str, mime: str, extension: str) -> Dict[str, str]:
+    source = f"{uuid.uuid4()}-attachment.{extension}"
+    (output_dir / source).write_text(content, encoding='utf-8')
+    return {"name": name, "source": source, "type": mime}
+
+
+def _labels(
+    test: TestResult,
+    file_path: Optional[str],
+    language: str,
+    framework: str,
+    owners: CodeOwners,
+    security: Dict[str, str]
+) -> List[Dict[str, str]]:
+    kind = classify_test(test.name, file_path, security)
+    package = _package(test, file_path)
+    method = test.name.split("::")[-1]
+    labels = [
+        ("language", language),
+        ("framework", framework),
+        ("package", package),
+        ("suite", package),
+        ("testMethod", method),
+        ("testType", kind),
+        ("tag", kind),
+    ]
+    if "::" in test.name and test.name.count("::") > 1:  # file::Class::test
+        labels.append(("subSuite", test.name.split("::")[-2]))
+    found = owners.owners(file_path) if file_path else []
+    if found:
+        labels.append(("owner", ", ".join(found)))
+    return [{"name": n, "value": v} for n, v in labels]
+
+
+def _package(test: TestResult, file_path: Optional[str]) -> str:
+    """Go import path, or the module/directory of the test file."""
+    if test.suite:
+        return test.suite
+    if file_path:
+        path = Path(file_path)
+        return ".".join(path.with_suffix("").parts) if path.suffix == ".py" else str(path.parent)
+    return "tests"
+
+
+def _write_result(
+    output_dir: Path,
+    test: TestResult,
+    history_id: str,
+    labels: List[Dict[str, str]],
+    outcome: Dict[str, Any],
+    attachments: List[Dict[str, str]]
+) -> Path:
+    result_id = str(uuid.uuid4())
+    name = test.name.split("::")[-1]
+    full_name = f"{test.suite}.{test.name}" if test.suite else test.name.replace("::", ".")
+    data = {
+        "uuid": result_id,
+        "historyId": history_id,
+        # Parametrized pytest cases share the test case, not the history
+        "testCaseId": _digest(re.sub(r'\[.*\]$', "", full_name)),
+        "name": name,
+        "fullName": full_name,
+        "stage": "finished",
+        "labels": labels,
+        "links": [],
+        "parameters": [],
+        "steps": [],
+        "attachments": attachments,
+        **outcome,
+    }
+    path = output_dir / f"{result_id}-result.json"
+    path.write_text(json.dumps(data, indent=2), encoding='utf-8')
+    return path
+
+
+def _write_container(
+    output_dir: Path,
+    name: str,
+    children: List[str],
+    fixture: str,
+    files: List[Path],
+    start: int
+) -> None:
+    """Group results; files (profiles, run artifacts) go on a `befores` fixture."""
+    container_id = str(uuid.uuid4())
+    befores = []
+    if files:
+        befores.append({
+            "name": fixture,
+            "status": "passed",
+            "stage": "finished",
+            "start": start,
+            "stop": start,
+            "steps": [],
+            "parameters": [],
+            "attachments": [_attach_file(output_dir, f) for f in files],
+        })
+    data = {
+        "uuid": container_id,
+        "name": name,
+        "children": children,
+        "befores": befores,
+        "afters": [],
+        "start": start,
+        "stop": start,
+    }
+    (output_dir / f"{container_id}-container.json").write_text(json.dumps(data, indent=2), encoding='utf-8')
+
+
+def _attach_file(output_dir: Path, path: Path) -> Dict[str, str]:
+    source = f"{uuid.uuid4()}-attachment{path.suffix}"
+    shutil.copyfile(path, output_dir / source)
+    return {"name": path.name, "source": source, "type": ATTACHMENT_TYPES.get(path.suffix, "application/octet-stream")}
+
+
+def _run_artifacts(run: Dict[str, Any]) -> Tuple[Dict[str, List[Path]], List[Path]]:
+    """The run's artifact files that still exist: by test key, and the run-wide ones."""
+    directory = run.get("artifacts_dir")
+    per_test: Dict[str, List[Path]] = {}
+    run_files: List[Path] = []
+    if not directory:
+        return per_test, run_files
+    for artifact in run.get("artifacts") or []:
+        path = Path(directory) / artifact["path"]
+        if not path.is_file():
+            continue
+        if artifact.get("test"):
+            per_test.setdefault(artifact["test"], []).append(path)
+        else:
+            run_files.append(path)
+    return per_test, run_files
+
+
+def _profiles(profile_dir: Optional[Path], package: str) -> List[Path]:
+    """Profiles of a package, any kind (named by `go_profile_name`, as the benchmark runner does)."""
+    if profile_dir is None or not profile_dir.is_dir():
+        return []
+    return sorted(profile_dir.glob(go_profile_name(package, "*")))
+
+
+def _codeowners_match(pattern: str, path: str) -> bool:
+    """gitignore-style CODEOWNERS pattern against a relative file path."""
+    if pattern in ("*", "/*", "**"):
+        return True
+    # A slash other than a trailing one anchors the pattern at the root
+    anchored = "/" in pattern.rstrip("/")
+    contents_only = pattern.endswith("/")
+    regex = ("" if anchored else r'(?:.*/)?') + _glob(pattern.strip("/"))
+    # A pattern naming a directory owns everything below it
+    regex += r'/.*' if contents_only else r'(?:/.*)?'
+    return re.fullmatch(regex, path) is not None
+
+
+def _glob(pattern: str) -> str:
+    """Regex for a glob where `*` stays within a path element and `**` crosses them."""
+    regex = ""
+    i = 0
+    while i < len(pattern):
+        if pattern.startswith("**/", i):
+            regex, i = regex + r'(?:.*/)?', i + 3
+        elif pattern.startswith("/**", i) and i + 3 == len(pattern):
+            regex, i = regex + r'/.*', i + 3
+        elif pattern[i] == "*":
+            regex, i = regex + r'[^/]*', i + 1
+        elif pattern[i] == "?":
+            regex, i = regex + r'[^/]', i + 1
+        else:
+            regex, i = regex + re.escape(pattern[i]), i + 1
+    return regex
+
+
+def _digest(text: str) -> str:
+    return hashlib.md5(text.encode("utf-8"), usedforsecurity=False).hexdigest()
+
+
+def _millis(timestamp: Optional[str]) -> int:
+    moment = datetime.fromisoformat(timestamp) if timestamp else datetime.now()
+    return int(moment.timestamp() * 1000)
+
+
+def _duration_ms(seconds: Optional[float]) -> int:
+    return int(round((seconds or 0.0) * 1000))
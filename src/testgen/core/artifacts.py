@@ -0,0 +1,286 @@
+"""
+Run Artifacts for TestGen AI.
+
+Every recorded run gets a managed directory for what the runners produce
+besides per-test results, indexed in the run store:
+
+    artifacts/<run_id>/output/        Raw runner stdout/stderr
+    artifacts/<run_id>/json/          `go test -json` streams, pytest JSON reports
+    artifacts/<run_id>/coverage/      Cover profiles
+    artifacts/<run_id>/golden-diff/   Expected-vs-actual diffs of failing tests
+    artifacts/<run_id>/pprof/         Profiles and their `go tool pprof -top` summaries
+    artifacts/<run_id>/sandbox-log/   Logs of sandboxed runs
+    artifacts/<run_id>/snapshot/      The generated test code the run executed
+
+Retention (`[retention]` in testgen.toml, enforced by `testgen gc`): the
+last N runs keep their artifacts, failing runs keep them for N days, and
+a size cap removes the oldest beyond it. Run records themselves stay
+(they're small and feed the run history) until `RunStore` prunes them.
+"""
+
+import shutil
+import subprocess
+from dataclasses import asdict, dataclass, field
+from datetime import datetime, timedelta
+from pathlib import Path
+from typing import Any, Dict, Iterable, List, Optional
+
+from .base_runner import TestResults
+
+
+ARTIFACT_KINDS = ("output", "json", "coverage", "golden-diff", "pprof", "sandbox-log", "snapshot")
+
+# Generated test files: TestFileWriter's header, or the fixed testgen_*_test.go files
+GENERATED_MARKERS = ("Generator: TestGen AI",)
+GENERATED_PREFIX = "testgen_"
+
+SKIPPED_DIRECTORIES = {"vendor", "node_modules", "venv", "__pycache__", "site-packages"}
+
+# Artifact directories without a run record are only orphans once nothing
+# was written to them for this long: a run in progress isn't recorded yet
+ORPHAN_GRACE = timedelta(hours=24)
+
+
+@dataclass
+class Artifact:
+    """One file kept for a run."""
+    
+    kind: str
+    path: str                  # relative to the run's artifact directory
+    size: int
+    test: Optional[str] = None  # test key (see `run_store.test_key`) for per-test artifacts
+
+
+class RunArtifacts:
+    """
+    Artifact directory of one run.
+    
+    Example:
+        >>> artifacts = RunStore().new_artifacts()
+        >>> runner.artifacts = artifacts
+        >>> results = runner.run_tests("tests")
+        >>> RunStore().record_run(results, artifacts=artifacts)
+    """
+    
+    def __init__(self, run_id: str, directory: Path):
+        """
+        Initialize run artifacts.
+        
+        Args:
+            run_id: ID the run will be recorded under
+            directory: The run's artifact directory (created on first use)
+        """
+        self.run_id = run_id
+        self.directory = directory
+        self.artifacts: List[Artifact] = []
+    
+    @property
+    def size(self) -> int:
+        return sum(a.size for a in self.artifacts)
+    
+    def add_text(self, kind: str, name: str, content: str, test: Optional[str] = None) -> Artifact:
+        """Keep text (output, a diff, a summary) as `<kind>/<name>`."""
+        path = self._reserve(kind, name)
+        path.write_text(content, encoding='utf-8')
+        return self._index(kind, path, test)
+    
+    def add_file(self, kind: str, source: Path, name: Optional[str] = None, test: Optional[str] = None) -> Optional[Artifact]:
+        """Copy a file (cover profile, JSON report) into the run; None if it doesn't exist."""
+        source = Path(source)
+        if not source.is_file():
+            return None
+        path = self._reserve(kind, name or source.name)
+        shutil.copyfile(source, path)
+        return self._index(kind, path, test)
+    
+    def add_output(self, label: str, stdout: Optional[str], stderr: Optional[str], stdout_kind: str = "output") -> None:
+        """
+        Keep a runner subprocess's output.
+        
+        Args:
+            label: Base file name (e.g. "go-test", "pytest")
+            stdout: Standard output (a `-json` stream: pass `stdout_kind="json"`)
+            stderr: Standard error
+            stdout_kind: Kind stdout is kept as
+        """
+        if stdout:
+            self.add_text(stdout_kind, f"{label}.{'json' if stdout_kind == 'json' else 'stdout.txt'}", stdout)
+        if stderr:
+            self.add_text("output", f"{label}.stderr.txt", stderr)
+    
+    def add_golden_diffs(self, results: TestResults) -> None:
+        """Keep the expected-vs-actual diff of every failing test that printed one."""
+        from .allure import golden_diff
+        from .run_store import test_key
+        
+        for test in results.tests:
+            if test.status not in ("failed", "error", "passed_on_retry"):
+                continue
+            diff = golden_diff("\n".join(p for p in (test.message, test.traceback) if p))
+            if diff:
+                name = test.name.replace("/", "_").replace("::", ".").replace(" ", "_")
+                self.add_text("golden-diff", f"{name}.diff", diff, test=test_key(test))
+    
+    def add_pprof(self, profile: Path, cwd: Optional[str] = None) -> None:
+        """Keep a pprof profile and its top functions (`go tool pprof -top`)."""
+        if self.add_file("pprof", profile) is None:
+            return
+        try:
+            completed = subprocess.run(
+                ["go", "tool", "pprof", "-top", "-nodecount=40", str(profile)],
+                capture_output=True, text=True, cwd=cwd, timeout=120
+            )
+        except (OSError, subprocess.TimeoutExpired):
+            return
+        if completed.returncode == 0 and completed.stdout.strip():
+            self.add_text("pprof", f"{Path(profile).name}.top.txt", completed.stdout)
+    
+    def add_snapshot(self, root: Path, files: Iterable[Path]) -> None:
+        """Keep the generated ones of a run's test files as they were when it started."""
+        root = Path(root).resolve()
+        for path in sorted(Path(f).resolve() for f in files):
+            if not path.is_relative_to(root):
+                continue
+            relative = path.relative_to(root)
+            if any(part in SKIPPED_DIRECTORIES or part.startswith(".") for part in relative.parts):
+                continue
+            if not path.is_file() or not is_generated(path):
+                continue
+            self.add_file("snapshot", path, name=str(relative))
+    
+    def to_list(self) -> List[Dict[str, Any]]:
+        return [asdict(a) for a in self.artifacts]
+    
+    def _reserve(self, kind: str, name: str) -> Path:
+        """Free path for `<kind>/<name>`: repeated runs in one session get `-2`, `-3`..."""
+        if kind not in ARTIFACT_KINDS:
+            raise ValueError(f"Unknown artifact kind: {kind}")
+        relative = Path(name)
+        base, dot, suffix = relative.name.partition(".")
+        path = self.directory / kind / relative
+        number = 2
+        while path.exists():
+            path = self.directory / kind / relative.parent / f"{base}-{number}{dot}{suffix}"
+            number += 1
+        path.parent.mkdir(parents=True, exist_ok=True)
+        return path
+    
+    def _index(self, kind: str, path: Path, test: Optional[str]) -> Artifact:
+        artifact = Artifact(kind=kind, path=str(path.relative_to(self.directory)), size=path.stat().st_size, test=test)
+        self.artifacts.append(artifact)
+        return artifact
+
+
+def is_generated(path: Path) -> bool:
+    """Whether a test file was written by TestGen."""
+    if path.name.startswith(GENERATED_PREFIX):
+        return True
+    try:
+        with open(path, encoding='utf-8', errors='replace') as f:
+            head = f.read(1024)
+    except OSError:
+        return False
+    return any(marker in head for marker in GENERATED_MARKERS)
+
+
+@dataclass
+class GCReport:
+    """What `collect_garbage` removed (or would remove, on a dry run)."""
+    
+    removed_runs: List[str] = field(default_factory=list)   # runs whose artifacts were removed
+    orphans: List[str] = field(default_factory=list)        # artifact directories without a run
+    cache_entries: int = 0                                  # expired scan/LLM cache entries
+    freed: int = 0                                          # bytes
+    kept_runs: int = 0
+    kept_size: int = 0
+
+
+def collect_garbage(
+    store,
+    keep_last: int = 20,
+    keep_failing_days: int = 14,
+    max_size_mb: float = 500,
+    dry_run: bool = False,
+    now: Optional[datetime] = None
+) -> GCReport:
+    """
+    Apply the retention policy to run artifacts and the cache.
+    
+    A run keeps its artifacts if it's one of the last `keep_last` runs, or
+    it failed less than `keep_failing_days` days ago. Then, while the kept
+    artifacts exceed `max_size_mb`, the oldest are removed (never the
+    latest run's). Artifact directories of runs no longer in the store
+    (untouched for `ORPHAN_GRACE`, so runs still in progress stay) and
+    expired cache entries are removed too.
+    
+    Args:
+        store: RunStore
+        keep_last: Most recent runs that always keep their artifacts
+        keep_failing_days: Days failing runs keep their artifacts
+        max_size_mb: Cap on all kept artifacts (0 = no cap)
+        dry_run: Only report what would be removed
+        now: Current time (for tests)
+        
+    Returns:
+        GCReport
+    """
+    from .cache import CacheManager
+    
+    now = now or datetime.now()
+    report = GCReport()
+    runs = store.list_runs()
+    with_artifacts = [r for r in runs if r.get("artifacts_size")]
+    
+    recent = {r["id"] for r in runs[-keep_last:]} if keep_last > 0 else set()
+    kept, removed = [], []
+    for run in with_artifacts:
+        failing = (run.get("failed") or 0) + (run.get("errors") or 0) > 0
+        age = now - datetime.fromisoformat(run["timestamp"])
+        if run["id"] in recent or (failing and age < timedelta(days=keep_failing_days)):
+            kept.append(run)
+        else:
+            removed.append(run)
+    
+    if max_size_mb > 0:
+        cap = int(max_size_mb * 1024 * 1024)
+        latest = runs[-1]["id"] if runs else None
+        while sum(r["artifacts_size"] for r in kept) > cap and kept and kept[0]["id"] != latest:
+            removed.append(kept.pop(0))
+    
+    for run in removed:
+        report.removed_runs.append(run["id"])
+        report.freed += run["artifacts_size"]
+        if not dry_run:
+            store.drop_artifacts(run["id"])
+    report.kept_runs = len(kept)
+    report.kept_size = sum(r["artifacts_size"] for r in kept)
+    
+    known = {r["id"] for r in runs}
+    if store.artifacts_dir.is_dir():
+        for directory in sorted(store.artifacts_dir.iterdir()):
+            if not directory.is_dir() or directory.name in known:
+                continue
+            if now - _last_modified(directory) < ORPHAN_GRACE:
+                continue
+            report.orphans.append(directory.name)
+            report.freed += _tree_size(directory)
+            if not dry_run:
+                shutil.rmtree(directory, ignore_errors=True)
+    
+    cache_dir = store.runs_dir.parent
+    if cache_dir.is_dir():
+        entries, size = CacheManager(str(cache_dir)).prune_expired(dry_run=dry_run)
+        report.cache_entries = entries
+        report.freed += size
+    
+    return report
+
+
+def _last_modified(directory: Path) -> datetime:
+    """Latest modification time of a directory or anything in it."""
+    times = [directory.stat().st_mtime] + [p.stat().st_mtime for p in directory.rglob("*")]
+    return datetime.fromtimestamp(max(times))
+
+
+def _tree_size(directory: Path) -> int:
+    return sum(p.stat().st_size for p in directory.rglob("*") if p.is_file())
@@ -9,23 +9,46 @@ from pathlib import Path
 from typing import List, Optional, Dict, Any
 from dataclasses import dataclass
 
+from .resource_usage import ResourceUsage
+
+
+@dataclass
+class TestAttempt:
+    """Outcome of one execution attempt of a test."""
+    
+    attempt: int  # 1 = initial run, 2+ = retries
+    status: str  # passed, failed, skipped, error
+    duration: float = 0.0
+    message: Optional[str] = None
+
 
 @dataclass
 class TestResult:
     """Single test result (language-agnostic)."""
     
     name: str
-    status: str  # passed, failed, skipped, error
+    status: str  # passed, failed, skipped, error, passed_on_retry
     duration: float = 0.0
     message: Optional[str] = None
     traceback: Optional[str] = None
     file_path: Optional[str] = None
     line_number: Optional[int] = None
+    suite: Optional[str] = None  # Package/module the test belongs to (e.g. Go import path)
+    attempts: List[TestAttempt] = None
+    
+    def __post_init__(self):
+        if self.attempts is None:
+            self.attempts = []
     
     @property
     def passed(self) -> bool:
         """Check if test passed."""
         return self.status == "passed"
+    
+    @property
+    def passed_on_retry(self) -> bool:
+        """Check if test failed at first but passed when retried (flaky)."""
+        return self.status == "passed_on_retry"
 
 
 @dataclass
@@ -40,7 +63,12 @@ class TestResults:
         failed: Number of failed tests
         skipped: Number of skipped tests
         errors: Number of errors
+        passed_on_retry: Number of tests that only passed after a retry
         duration: Total execution time
+        stopped_early: Run was stopped by fail-fast before all tests ran
+        resource_usage: CPU/memory/writes per suite (Go package) or per runner subprocess
+        stale_generated: Stale `go generate` output per Go package (import path -> files)
+        stale_symbols: What that output declares per Go package (import path -> identifiers)
         language: Programming language
         framework: Test framework used
     """
@@ -51,13 +79,29 @@ class TestResults:
     failed: int = 0
     skipped: int = 0
     errors: int = 0
+    passed_on_retry: int = 0
     duration: float = 0.0
     language: str = "unknown"
     framework: str = "unknown"
+    stopped_early: bool = False
+    resource_usage: Dict[str, ResourceUsage] = None
+    stale_generated: Dict[str, List[str]] = None
+    stale_symbols: Dict[str, List[str]] = None
     
     def __post_init__(self):
         if self.tests is None:
             self.tests = []
+        if self.resource_usage is None:
+            self.resource_usage = {}
+        if self.stale_generated is None:
+            self.stale_generated = {}
+        if self.stale_symbols is None:
+            self.stale_symbols = {}
+    
+    @property
+    def flaky_tests(self) -> List[TestResult]:
+        """Tests that passed only after being retried."""
+        return [t for t in self.tests if t.passed_on_retry]
     
     @property
     def success(self) -> bool:
@@ -74,10 +118,12 @@ class TestResults:
     def __str__(self) -> str:
         """String representation."""
         status = "[PASS]" if self.success else "[FAIL]"
+        retried = f", {self.passed_on_retry} passed on retry" if self.passed_on_retry else ""
+        stopped = " (stopped early)" if self.stopped_early else ""
         return (
-            f"{status} {self.passed}/{self.total} passed "
+            f"{status} {self.passed}/{self.total} passed{retried} "
             f"({self.pass_rate:.1f}%) in {self.duration:.2f}s "
-            f"[{self.language}/{self.framework}]"
+            f"[{self.language}/{self.framework}]{stopped}"
         )
 
 
@@ -89,6 +135,9 @@ class BaseTestRunner(ABC):
     Provides a common interface for running tests across different languages.
     """
     
+    # Set to the current run's `artifacts.RunArtifacts` to keep raw output and reports
+    artifacts = None
+    
     def __init__(self, verbose: bool = False):
         """
         Initialize test runner.
@@ -187,6 +236,36 @@ class BaseTestRunner(ABC):
         """
         pass
     
+    def rerun_tests(
+        self,
+        test_dir: str,
+        tests: List[TestResult],
+        pattern: Optional[str] = None,
+        **kwargs
+    ) -> TestResults:
+        """
+        Re-run only the given (previously failed) tests.
+        
+        The default implementation re-runs the whole selection and keeps the
+        results for the requested tests. Runners that can select individual
+        tests should override this.
+        
+        Args:
+            test_dir: Directory containing tests
+            tests: Tests to re-run
+            pattern: File/test pattern used for the original run
+            **kwargs: Additional runner-specific arguments
+            
+        Returns:
+            TestResults containing (at least) the requested tests
+        """
+        results = self.run_tests(test_dir, pattern, **kwargs)
+        names = {t.name for t in tests}
+        matching = [t for t in results.tests if t.name in names]
+        if matching:
+            results.tests = matching
+        return results
+    
     def get_language(self) -> str:
         """
         Get language name.
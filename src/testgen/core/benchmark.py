@@ -0,0 +1,679 @@
+"""
+Cross-Language Micro-Benchmark Harness for TestGen AI.
+
+Runs performance-classified tests with warmup and measured iterations and
+reports the same statistics for every language:
+- Python: test functions timed in a separate interpreter
+- JavaScript: Jest-style `test()`/`it()` callbacks timed under Node
+- Go: native `go test -bench` benchmarks (one sample per `-count` run)
+
+Results are `BenchmarkResult` models, and `BenchmarkStore` keeps their
+history so regressions are tracked the same way across languages.
+"""
+
+import hashlib
+import json
+import math
+import os
+import re
+import statistics
+import subprocess
+import sys
+import tempfile
+from pathlib import Path
+from typing import Dict, List, Optional, Any
+
+from pydantic import BaseModel
+
+from .result_models import BenchmarkResult, Language, TestFramework
+
+
+# Marker that separates harness output from anything the tests print
+RESULT_MARKER = "@@TESTGEN_BENCH@@"
+
+
+PYTHON_DRIVER = r'''
+import asyncio, cProfile, importlib.util, inspect, json, os, sys, time
+
+MARKER = "@@MARKER@@"
+
+path, warmup, iterations, profile_dir = sys.argv[1], int(sys.argv[2]), int(sys.argv[3]), sys.argv[4]
+wanted = set(sys.argv[5:])
+sys.path.insert(0, os.path.dirname(os.path.abspath(path)))
+
+spec = importlib.util.spec_from_file_location("_testgen_bench_target", path)
+module = importlib.util.module_from_spec(spec)
+spec.loader.exec_module(module)
+
+
+def passthrough(fn, *args, **kwargs):
+    # Stand-in for the pytest-benchmark `benchmark` fixture
+    return fn(*args, **kwargs)
+
+
+def collect():
+    for name, obj in vars(module).items():
+        if name.startswith("test") and inspect.isfunction(obj):
+            yield name, obj
+        elif name.startswith("Test") and inspect.isclass(obj):
+            for attr in sorted(dir(obj)):
+                if attr.startswith("test") and callable(getattr(obj, attr)):
+                    instance = obj()
+                    if hasattr(instance, "setup_method"):
+                        instance.setup_method(getattr(obj, attr))
+                    yield f"{name}::{attr}", getattr(instance, attr)
+
+
+def make_call(fn):
+    params = [p for p in inspect.signature(fn).parameters.values()
+              if p.default is inspect.Parameter.empty and p.kind in (p.POSITIONAL_OR_KEYWORD, p.KEYWORD_ONLY)]
+    kwargs = {}
+    for p in params:
+        if p.name == "benchmark":
+            kwargs["benchmark"] = passthrough
+        else:
+            raise TypeError(f"requires fixture '{p.name}'")
+    if inspect.iscoroutinefunction(fn):
+        return lambda: asyncio.run(fn(**kwargs))
+    return lambda: fn(**kwargs)
+
+
+for name, fn in collect():
+    if wanted and name not in wanted and name.split("::")[-1] not in wanted:
+        continue
+    result = {"name": name, "samples": []}
+    try:
+        call = make_call(fn)
+        for _ in range(warmup):
+            call()
+        for _ in range(iterations):
+            start = time.perf_counter_ns()
+            call()
+            result["samples"].append((time.perf_counter_ns() - start) / 1e9)
+        if profile_dir != "-":
+            os.makedirs(profile_dir, exist_ok=True)
+            profile_path = os.path.join(profile_dir, name.replace("::", ".") + ".prof")
+            cProfile.runctx("call()", {"call": call}, {}, profile_path)
+            result["profile_path"] = profile_path
+    except BaseException as e:
+        result["error"] = f"{type(e).__name__}: {e}"
+    print(MARKER + json.dumps(result), flush=True)
+'''
+
+
+JAVASCRIPT_DRIVER = r'''
+const path = require('path');
+const { performance } = require('perf_hooks');
+
+const MARKER = '@@MARKER@@';
+
+const [file, warmupArg, iterationsArg, ...wanted] = process.argv.slice(1);
+const warmup = parseInt(warmupArg, 10);
+const iterations = parseInt(iterationsArg, 10);
+const target = path.resolve(file);
+
+const tests = [];
+const scope = [];
+const hooks = { beforeEach: [], afterEach: [], beforeAll: [], afterAll: [] };
+
+global.describe = (name, fn) => { scope.push(name); fn(); scope.pop(); };
+global.describe.skip = () => {};
+global.describe.only = global.describe;
+global.test = global.it = (name, fn) => {
+  tests.push({ name: [...scope, name].join(' '), fn, beforeEach: [...hooks.beforeEach], afterEach: [...hooks.afterEach] });
+};
+global.test.skip = global.it.skip = () => {};
+global.test.only = global.it.only = global.test;
+for (const hook of Object.keys(hooks)) global[hook] = (fn) => hooks[hook].push(fn);
+
+function fallbackExpect(actual) {
+  const fail = (msg) => { throw new Error(msg); };
+  const eq = (a, b) => JSON.stringify(a) === JSON.stringify(b);
+  const matchers = (negate) => {
+    const check = (ok, msg) => { if (ok === negate) fail(msg); };
+    return {
+      toBe: (v) => check(Object.is(actual, v), `expected ${actual} to be ${v}`),
+      toEqual: (v) => check(eq(actual, v), 'values are not equal'),
+      toStrictEqual: (v) => check(eq(actual, v), 'values are not equal'),
+      toBeTruthy: () => check(!!actual, `expected ${actual} to be truthy`),
+      toBeFalsy: () => check(!actual, `expected ${actual} to be falsy`),
+      toBeDefined: () => check(actual !== undefined, 'expected value to be defined'),
+      toBeUndefined: () => check(actual === undefined, 'expected undefined'),
+      toBeNull: () => check(actual === null, 'expected null'),
+      toContain: (v) => check(actual.includes(v), `expected to contain ${v}`),
+      toHaveLength: (n) => check(actual.length === n, `expected length ${n}`),
+      toBeGreaterThan: (n) => check(actual > n, `expected > ${n}`),
+      toBeLessThan: (n) => check(actual < n, `expected < ${n}`),
+      toThrow: () => { let threw = false; try { actual(); } catch (e) { threw = true; } check(threw, 'expected to throw'); },
+    };
+  };
+  return Object.assign(matchers(false), { not: matchers(true) });
+}
+
+let expectImpl = fallbackExpect;
+try {
+  const mod = require(require.resolve('expect', { paths: [path.dirname(target)] }));
+  expectImpl = mod.expect || mod;
+} catch (e) { /* project has no `expect` package */ }
+global.expect = expectImpl;
+
+const call = (fn) => fn.length > 0 ? new Promise((resolve, reject) => fn((err) => err ? reject(err) : resolve())) : fn();
+
+(async () => {
+  try {
+    require(target);
+  } catch (e) {
+    console.log(MARKER + JSON.stringify({ name: path.basename(file), samples: [], error: String(e) }));
+    return;
+  }
+  for (const fn of hooks.beforeAll) await call(fn);
+  for (const t of tests) {
+    if (wanted.length && !wanted.includes(t.name)) continue;
+    const result = { name: t.name, samples: [] };
+    try {
+      for (let i = 0; i < warmup + iterations; i++) {
+        for (const fn of t.beforeEach) await call(fn);
+        const start = performance.now();
+        await call(t.fn);
+        const elapsed = (performance.now() - start) / 1000;
+        for (const fn of t.afterEach) await call(fn);
+        if (i >= warmup) result.samples.push(elapsed);
+      }
+    } catch (e) {
+      result.error = String(e && e.stack ? e.message : e);
+    }
+    console.log(MARKER + JSON.stringify(result));
+  }
+  for (const fn of hooks.afterAll) await call(fn);
+})();
+'''
+
+
+# Both drivers print results after the marker
+PYTHON_DRIVER = PYTHON_DRIVER.replace("@@MARKER@@", RESULT_MARKER)
+JAVASCRIPT_DRIVER = JAVASCRIPT_DRIVER.replace("@@MARKER@@", RESULT_MARKER)
+
+
+# BenchmarkParse-8   	  123456	      9876 ns/op	     512 B/op	       3 allocs/op
+GO_BENCH_LINE = re.compile(
+    r'^(Benchmark\S+?)(?:-\d+)?\s+(\d+)\s+([\d.]+) ns/op'
+    r'(?:.*?\s([\d.]+) B/op)?(?:.*?\s([\d.]+) allocs/op)?'
+)
+# pkg: example.com/app/internal/store
+GO_PKG_LINE = re.compile(r'^pkg:\s*(\S+)', re.MULTILINE)
+
+
+def percentile(sorted_samples: List[float], pct: float) -> float:
+    """Linear-interpolated percentile of already sorted samples."""
+    if not sorted_samples:
+        return 0.0
+    
+    rank = (len(sorted_samples) - 1) * pct / 100
+    low = math.floor(rank)
+    high = math.ceil(rank)
+    if low == high:
+        return sorted_samples[low]
+    return sorted_samples[low] + (sorted_samples[high] - sorted_samples[low]) * (rank - low)
+
+
+def calculate_statistics(samples: List[float]) -> Dict[str, Any]:
+    """
+    Calculate benchmark statistics.
+    
+    Outliers are samples outside Tukey's fences (1.5 x IQR beyond the
+    first/third quartile).
+    
+    Args:
+        samples: Per-iteration timings in seconds
+        
+    Returns:
+        Dictionary with mean, p50, p95, p99, stddev, min, max and outliers
+    """
+    if not samples:
+        return {
+            "mean": 0.0, "p50": 0.0, "p95": 0.0, "p99": 0.0,
+            "stddev": 0.0, "min": 0.0, "max": 0.0, "outliers": []
+        }
+    
+    ordered = sorted(samples)
+    q1 = percentile(ordered, 25)
+    q3 = percentile(ordered, 75)
+    iqr = q3 - q1
+    low_fence = q1 - 1.5 * iqr
+    high_fence = q3 + 1.5 * iqr
+    
+    return {
+        "mean": statistics.fmean(ordered),
+        "p50": percentile(ordered, 50),
+        "p95": percentile(ordered, 95),
+        "p99": percentile(ordered, 99),
+        "stddev": statistics.stdev(ordered) if len(ordered) > 1 else 0.0,
+        "min": ordered[0],
+        "max": ordered[-1],
+        "outliers": [s for s in samples if s < low_fence or s > high_fence],
+    }
+
+
+class BenchmarkHarness:
+    """
+    Run performance tests with warmup and measured iterations.
+    
+    Example:
+        >>> harness = BenchmarkHarness("python", iterations=50, warmup_iterations=5)
+        >>> for result in harness.run("tests/test_perf_parser.py"):
+        ...     print(result.name, result.p95)
+    """
+    
+    SUPPORTED_LANGUAGES = ["python", "javascript", "typescript", "go"]
+    
+    def __init__(
+        self,
+        language: str,
+        framework: Optional[str] = None,
+        iterations: int = 100,
+        warmup_iterations: int = 10,
+        profile: bool = False,
+        profile_dir: str = ".testgen-cache/profiles",
+        timeout: int = 1800,
+        go_count: int = 5,
+        go_benchtime: Optional[str] = None
+    ):
+        """
+        Initialize harness.
+        
+        Args:
+            language: Language of the tests (python, javascript, typescript, go)
+            framework: Test framework (defaults per language)
+            iterations: Measured iterations per test (Python/JS)
+            warmup_iterations: Unmeasured iterations before measuring (not used for Go)
+            profile: Write a CPU profile per benchmark
+            profile_dir: Where profiles are written
+            timeout: Timeout per harness process in seconds
+            go_count: Number of `-count` runs (samples) per Go benchmark
+            go_benchtime: Go `-benchtime` ("2s", or "1000x" for a fixed b.N);
+                default: Go's own (1s), so b.N is calibrated per benchmark
+        """
+        self.language = language.lower()
+        self.framework = framework or {
+            "python": "pytest",
+            "javascript": "jest",
+            "typescript": "jest",
+            "go": "testing",
+        }.get(self.language, "unknown")
+        self.iterations = iterations
+        self.warmup_iterations = warmup_iterations
+        self.profile = profile
+        self.profile_dir = profile_dir
+        self.timeout = timeout
+        self.go_count = go_count
+        self.go_benchtime = go_benchtime
+    
+    @classmethod
+    def from_config(cls, config: Any, language: str, framework: Optional[str] = None) -> "BenchmarkHarness":
+        """Create a harness from a `TestExecutionConfig`."""
+        return cls(
+            language,
+            framework,
+            iterations=config.iterations,
+            warmup_iterations=config.warmup_iterations,
+            profile=config.profile,
+            timeout=config.timeout,
+            go_benchtime=config.go_benchtime
+        )
+    
+    def run(self, target: str, names: Optional[List[str]] = None) -> List[BenchmarkResult]:
+        """
+        Benchmark the tests in a file (Python/JS) or package directory (Go).
+        
+        Args:
+            target: Test file, or Go package directory
+            names: Only these tests/benchmarks (default: all)
+            
+        Returns:
+            One BenchmarkResult per test
+        """
+        if self.language == "python":
+            return self.run_python(target, names)
+        if self.language in ("javascript", "typescript"):
+            return self.run_javascript(target, names)
+        if self.language == "go":
+            return self.run_go(target, names)
+        
+        raise ValueError(f"Benchmarks are not supported for {self.language}")
+    
+    def run_python(self, test_file: str, names: Optional[List[str]] = None) -> List[BenchmarkResult]:
+        """Time Python test functions in a fresh interpreter."""
+        profile_dir = str(Path(self.profile_dir).resolve()) if self.profile else "-"
+        cmd = [
+            sys.executable, "-c", PYTHON_DRIVER,
+            str(test_file), str(self.warmup_iterations), str(self.iterations), profile_dir,
+            *(names or [])
+        ]
+        return self._run_driver(cmd, test_file, cwd=str(Path(test_file).parent))
+    
+    def run_javascript(self, test_file: str, names: Optional[List[str]] = None) -> List[BenchmarkResult]:
+        """Time Jest-style test callbacks under Node."""
+        cmd = ["node"]
+        if self.profile:
+            cmd.extend(["--cpu-prof", "--cpu-prof-dir", str(Path(self.profile_dir).resolve())])
+        cmd.extend([
+            "-e", JAVASCRIPT_DRIVER,
+            str(test_file), str(self.warmup_iterations), str(self.iterations),
+            *(names or [])
+        ])
+        results = self._run_driver(cmd, test_file, cwd=str(Path(test_file).parent))
+        if self.profile:
+            for result in results:
+                result.profile_path = str(Path(self.profile_dir).resolve())
+        return results
+    
+    def run_go(self, package_dir: str, names: Optional[List[str]] = None) -> List[BenchmarkResult]:
+        """Run native Go benchmarks and turn each `-count` run into a sample."""
+        bench = "^(" + "|".join(re.escape(n) for n in names) + ")$" if names else "."
+        cmd = [
+            "go", "test", "-run", "^$",
+            "-bench", bench,
+            "-benchmem",
+            "-count", str(self.go_count),
+        ]
+        if self.go_benchtime:
+            cmd.extend(["-benchtime", self.go_benchtime])
+        profile_path = None
+        if self.profile:
+            Path(self.profile_dir).mkdir(parents=True, exist_ok=True)
+            # Renamed after the run, when the import path is known (see `go_profile_name`)
+            handle, profile_path = tempfile.mkstemp(suffix=".cpu.prof.tmp", dir=str(Path(self.profile_dir).resolve()))
+            os.close(handle)
+            cmd.extend(["-cpuprofile", profile_path])
+        cmd.append(".")
+        
+        try:
+            result = subprocess.run(
+                cmd,
+                cwd=package_dir,
+                capture_output=True,
+                text=True,
+                timeout=self.timeout
+            )
+        except (subprocess.TimeoutExpired, FileNotFoundError) as e:
+            if profile_path:
+                os.remove(profile_path)
+            if isinstance(e, subprocess.TimeoutExpired):
+                return [self._error_result(package_dir, f"Benchmarks timed out after {self.timeout}s")]
+            return [self._error_result(package_dir, "go not found")]
+        
+        if profile_path:
+            package = GO_PKG_LINE.search(result.stdout)
+            if package and os.path.getsize(profile_path):
+                keyed = str(Path(profile_path).with_name(go_profile_name(package.group(1))))
+                os.replace(profile_path, keyed)
+                profile_path = keyed
+            else:
+                os.remove(profile_path)
+                profile_path = None
+        
+        results = self._parse_go_output(result.stdout, package_dir, profile_path)
+        if not results and result.returncode != 0:
+            return [self._error_result(package_dir, (result.stderr or result.stdout).strip())]
+        return results
+    
+    def _parse_go_output(self, output: str, package_dir: str, profile_path: Optional[str] = None) -> List[BenchmarkResult]:
+        """Parse `go test -bench -benchmem` output."""
+        samples: Dict[str, List[float]] = {}
+        memory: Dict[str, tuple] = {}
+        package = str(package_dir)
+        
+        for line in output.split('\n'):
+            line = line.strip()
+            if line.startswith("pkg:"):
+                package = line[4:].strip()
+                continue
+            
+            match = GO_BENCH_LINE.match(line)
+            if not match:
+                continue
+            
+            name = match.group(1)
+            samples.setdefault(name, []).append(float(match.group(3)) / 1e9)
+            memory[name] = (
+                float(match.group(4)) if match.group(4) else None,
+                float(match.group(5)) if match.group(5) else None
+            )
+        
+        return [
+            self._build_result(
+                name, package, values,
+                bytes_per_op=memory[name][0],
+                allocs_per_op=memory[name][1],
+                profile_path=profile_path,
+                warmup_iterations=0
+            )
+            for name, values in samples.items()
+        ]
+    
+    def _run_driver(self, cmd: List[str], test_file: str, cwd: str) -> List[BenchmarkResult]:
+        """Run a Python/JS driver process and collect its results."""
+        if self.profile:
+            Path(self.profile_dir).mkdir(parents=True, exist_ok=True)
+        
+        try:
+            result = subprocess.run(
+                cmd,
+                cwd=cwd,
+                capture_output=True,
+                text=True,
+                timeout=self.timeout
+            )
+        except subprocess.TimeoutExpired:
+            return [self._error_result(test_file, f"Benchmarks timed out after {self.timeout}s")]
+        except FileNotFoundError:
+            return [self._error_result(test_file, f"{cmd[0]} not found")]
+        
+        results = []
+        for line in result.stdout.split('\n'):
+            if not line.startswith(RESULT_MARKER):
+                continue
+            try:
+                data = json.loads(line[len(RESULT_MARKER):])
+            except json.JSONDecodeError:
+                continue
+            results.append(self._build_result(
+                data["name"], str(test_file), data.get("samples", []),
+                profile_path=data.get("profile_path"),
+                error=data.get("error")
+            ))
+        
+        if not results and result.returncode != 0:
+            return [self._error_result(test_file, (result.stderr or result.stdout).strip())]
+        return results
+    
+    def _build_result(
+        self,
+        name: str,
+        file_path: str,
+        samples: List[float],
+        bytes_per_op: Optional[float] = None,
+        allocs_per_op: Optional[float] = None,
+        profile_path: Optional[str] = None,
+        error: Optional[str] = None,
+        warmup_iterations: Optional[int] = None
+    ) -> BenchmarkResult:
+        """Build a BenchmarkResult with statistics from raw samples."""
+        return BenchmarkResult(
+            name=name,
+            file_path=file_path,
+            language=self._language_enum(),
+            framework=self._framework_enum(),
+            iterations=self.iterations,
+            warmup_iterations=self.warmup_iterations if warmup_iterations is None else warmup_iterations,
+            samples=samples,
+            bytes_per_op=bytes_per_op,
+            allocs_per_op=allocs_per_op,
+            profile_path=profile_path,
+            error=error,
+            **calculate_statistics(samples)
+        )
+    
+    def _error_result(self, target: str, message: str) -> BenchmarkResult:
+        """Result for a target that could not be benchmarked at all."""
+        return self._build_result(Path(target).name, str(target), [], error=message or "Benchmark run failed")
+    
+    def _language_enum(self) -> Language:
+        try:
+            return Language(self.language)
+        except ValueError:
+            return Language.UNKNOWN
+    
+    def _framework_enum(self) -> TestFramework:
+        try:
+            return TestFramework(self.framework)
+        except ValueError:
+            return TestFramework.UNKNOWN
+
+
+class BenchmarkRegression(BaseModel):
+    """A benchmark that got slower than its recorded baseline."""
+    key: str
+    baseline_p50: float
+    current_p50: float
+    change: float  # Relative change, 0.25 = 25% slower
+
+
+class BenchmarkStore:
+    """
+    History of benchmark results for regression tracking.
+    
+    Results for all languages are stored in one JSON file keyed by
+    `BenchmarkResult.key`, keeping the most recent runs per benchmark.
+    """
+    
+    def __init__(self, store_dir: str = ".testgen-cache/benchmarks", max_history: int = 20):
+        """
+        Initialize store.
+        
+        Args:
+            store_dir: Directory for the history file
+            max_history: Runs kept per benchmark
+        """
+        self.store_dir = Path(store_dir)
+        self.history_file = self.store_dir / "history.json"
+        self.max_history = max_history
+    
+    def load(self) -> Dict[str, List[BenchmarkResult]]:
+        """Load history keyed by benchmark."""
+        if not self.history_file.exists():
+            return {}
+        
+        try:
+            data = json.loads(self.history_file.read_text(encoding='utf-8'))
+        except (json.JSONDecodeError, OSError):
+            return {}
+        
+        return {
+            key: [BenchmarkResult(**entry) for entry in entries]
+            for key, entries in data.items()
+        }
+    
+    def record(self, results: List[BenchmarkResult]) -> None:
+        """Append successful results to the history."""
+        history = self.load()
+        
+        for result in results:
+            if not result.succeeded:
+                continue
+            entries = history.setdefault(result.key, [])
+            entries.append(result)
+            history[result.key] = entries[-self.max_history:]
+        
+        self.store_dir.mkdir(parents=True, exist_ok=True)
+        self.history_file.write_text(
+            json.dumps(
+                {key: [r.to_dict() for r in entries] for key, entries in history.items()},
+                indent=2
+            ),
+            encoding='utf-8'
+        )
+    
+    def find_regressions(
+        self,
+        results: List[BenchmarkResult],
+        threshold: float = 0.10
+    ) -> List[BenchmarkRegression]:
+        """
+        Compare results with the median p50 of previously recorded runs.
+        
+        Args:
+            results: Current results (compare before calling `record`)
+            threshold: Relative slowdown that counts as a regression
+            
+        Returns:
+            Regressions, worst first
+        """
+        history = self.load()
+        regressions = []
+        
+        for result in results:
+            previous = history.get(result.key)
+            if not result.succeeded or not previous:
+                continue
+            
+            baseline = statistics.median(r.p50 for r in previous)
+            if baseline <= 0:
+                continue
+            
+            change = (result.p50 - baseline) / baseline
+            if change > threshold:
+                regressions.append(BenchmarkRegression(
+                    key=result.key,
+                    baseline_p50=baseline,
+                    current_p50=result.p50,
+                    change=change
+                ))
+        
+        return sorted(regressions, key=lambda r: r.change, reverse=True)
+
+
+def go_profile_name(import_path: str, kind: str = "cpu") -> str:
+    """
+    File name of a Go package's profile: `<last element>-<hash>.<kind>.prof`.
+    
+    The hash of the full import path keeps `internal/store` and `cmd/store`
+    apart; the last element keeps the name readable.
+    """
+    digest = hashlib.md5(import_path.encode("utf-8"), usedforsecurity=False).hexdigest()[:10]
+    return f"{import_path.rstrip('/').rsplit('/', 1)[-1]}-{digest}.{kind}.prof"
+
+
+def format_duration(seconds: float) -> str:
+    """Format a per-operation time with a readable unit."""
+    if seconds >= 1:
+        return f"{seconds:.2f}s"
+    if seconds >= 1e-3:
+        return f"{seconds * 1e3:.2f}ms"
+    if seconds >= 1e-6:
+        return f"{seconds * 1e6:.2f}µs"
+    return f"{seconds * 1e9:.1f}ns"
+
+
+def format_benchmark_report(results: List[BenchmarkResult]) -> str:
+    """Format benchmark results as a text table."""
+    lines = []
+    lines.append("=" * 100)
+    lines.append("BENCHMARK RESULTS")
+    lines.append("=" * 100)
+    lines.append(
+        f"{'Benchmark':<40} {'mean':>10} {'p50':>10} {'p95':>10} {'p99':>10} {'stddev':>10} {'outliers':>8}"
+    )
+    
+    for r in results:
+        if r.error:
+            lines.append(f"{r.name[:40]:<40} ERROR: {r.error.splitlines()[0]}")
+            continue
+        lines.append(
+            f"{r.name[:40]:<40} "
+            + " ".join(f"{format_duration(v):>10}" for v in (r.mean, r.p50, r.p95, r.p99, r.stddev))
+            + f" {len(r.outliers):>8}"
+        )
+    
+    lines.append("=" * 100)
+    return "\n".join(lines)
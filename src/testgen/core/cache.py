@@ -8,7 +8,7 @@ to improve performance and reduce API costs.
 import json
 import hashlib
 from pathlib import Path
-from typing import Optional, Any, Dict
+from typing import Optional, Any, Dict, Tuple
 from datetime import datetime, timedelta
 from dataclasses import dataclass, asdict
 
@@ -337,6 +337,39 @@ class CacheManager:
         
         return count
     
+    def prune_expired(self, dry_run: bool = False) -> Tuple[int, int]:
+        """
+        Delete expired entries (they are otherwise only removed when read).
+        
+        Args:
+            dry_run: Only count them
+            
+        Returns:
+            (entries, bytes) deleted
+        """
+        count, size = 0, 0
+        
+        for category in ["scans", "llm", "general"]:
+            category_dir = self.cache_dir / category
+            
+            if not category_dir.exists():
+                continue
+            
+            for cache_file in category_dir.glob("*.json"):
+                try:
+                    with open(cache_file, 'r', encoding='utf-8') as f:
+                        entry = CacheEntry(**json.load(f))
+                except Exception:
+                    continue
+                
+                if entry.is_expired():
+                    count += 1
+                    size += cache_file.stat().st_size
+                    if not dry_run:
+                        cache_file.unlink()
+        
+        return count, size
+    
     def get_statistics(self) -> Dict[str, Any]:
         """
         Get cache statistics.
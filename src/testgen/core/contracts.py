@@ -0,0 +1,1083 @@
+"""
+Consumer-Driven Contract Tests.
+
+For an HTTP client in one service (the consumer) and the handlers of
+another (the provider), both in the same repository:
+- the consumer's contract is built from its client code (which endpoints
+  it calls) and its tests (the responses it was tested against:
+  httptest handlers, `responses`/`requests_mock`/`respx`, nock) and kept
+  as Pact-style JSON in the repository
+- provider verification tests replay the contract against the provider's
+  handler in-process (Go httptest, Flask/FastAPI test clients)
+- breaks are reported when the consumer's contract changes or the
+  provider no longer satisfies it
+
+Contracts are declared in testgen.toml (see `project_config.ContractConfig`).
+"""
+
+import ast
+import json
+import os
+import re
+from dataclasses import dataclass, field
+from http import HTTPStatus
+from pathlib import Path
+from typing import Any, Dict, List, Optional, Tuple
+from urllib.parse import urlsplit
+
+from .load_test import find_go_handlers
+from .project_config import ContractConfig
+
+
+PACT_SPECIFICATION = "2.0.0"
+
+HTTP_METHODS = ("GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS")
+
+SOURCE_EXTENSIONS = {".go": "go", ".py": "python", ".js": "javascript", ".ts": "javascript", ".mjs": "javascript"}
+SKIPPED_DIRS = {"node_modules", "vendor", "venv", ".venv", "__pycache__", "dist", "build"}
+
+# Python mocking libraries' receivers (responses, requests_mock, respx, pytest-httpx)
+PYTHON_MOCK_RECEIVERS = {"responses", "rsps", "requests_mock", "m", "mocker", "respx", "respx_mock", "httpx_mock"}
+
+# Go client calls
+GO_NEW_REQUEST = re.compile(r'\bhttp\.NewRequest(?:WithContext)?\(')
+GO_SHORTHAND = re.compile(r'\b(?:http|[\w.]*[cC]lient)\.(Get|Head|Post|PostForm)\(')
+# Python client calls: requests.get(url), self.session.post(url), httpx.Client().delete(url)
+PYTHON_CLIENT = re.compile(r'\b(?:requests|httpx|[\w.]*(?:session|client|Session|Client))\.(get|head|post|put|patch|delete)\(')
+# JavaScript client calls
+JS_FETCH = re.compile(r'\bfetch\(')
+JS_CLIENT = re.compile(r'\b(?:axios|[\w.]*(?:client|http|api|Client|Api))\.(get|head|post|put|patch|delete)\(')
+
+# Go test stubs: switch r.URL.Path { case "/users/1": ... } or if r.URL.Path == "/users/1"
+GO_STUB_PATH = re.compile(r'case\s+"(/[^"]*)"\s*:|r\.URL\.Path\s*[!=]=\s*"(/[^"]*)"')
+GO_STUB_METHOD = re.compile(r'r\.Method\s*[!=]=\s*(?:http\.Method(\w+)|"(\w+)")')
+GO_STUB_STATUS = re.compile(r'\.WriteHeader\(\s*(?:http\.(Status\w+)|(\d{3}))\s*\)|http\.Error\([^,]+,[^,]+,\s*(?:http\.(Status\w+)|(\d{3}))\s*\)')
+GO_STUB_BODY = re.compile(r'(?:\.Write\(\[\]byte\(|fmt\.Fprint(?:ln)?\(\s*\w+\s*,\s*|io\.WriteString\(\s*\w+\s*,\s*)(`[^`]*`|"(?:[^"\\]|\\.)*")')
+# nock(base).get('/users/1').reply(200, {...})
+JS_NOCK = re.compile(
+    r'\.(get|head|post|put|patch|delete)\(\s*[\'"`](/[^\'"`]*)[\'"`][^)]*\)\s*\.reply\(\s*(\d{3})\s*(?:,\s*(\{.*?\}|\[.*?\]))?\s*\)',
+    re.DOTALL
+)
+
+# Provider routes
+GO_ROUTE = re.compile(
+    r'\.(?:HandleFunc|Handle)\(\s*"(?:(GET|HEAD|POST|PUT|PATCH|DELETE|OPTIONS)\s+)?(/[^"]*)"'
+    r'(?:[^\n]*?\.Methods\(\s*"(\w+)")?'
+    r'|\.(Get|Head|Post|Put|Patch|Delete|GET|HEAD|POST|PUT|PATCH|DELETE)\(\s*"(/[^"]*)"'
+)
+PYTHON_ROUTE = re.compile(
+    r'@\w+\.route\(\s*["\'](/[^"\']*)["\'](?:[^)]*methods\s*=\s*[\[(]([^\])]*)[\])])?'
+    r'|@\w+\.(get|head|post|put|patch|delete)\(\s*["\'](/[^"\']*)["\']'
+)
+JS_ROUTE = re.compile(r'\b(?:app|router)\.(get|head|post|put|patch|delete|all)\(\s*[\'"`](/[^\'"`]*)[\'"`]')
+PYTHON_APP = re.compile(r'^(\w+)\s*=\s*(Flask|FastAPI)\(', re.MULTILINE)
+
+
+@dataclass
+class Interaction:
+    """One request the consumer makes and the response it relies on."""
+    
+    method: str
+    path: str                            # concrete (/users/1) or a template (/users/{id})
+    query: str = ""
+    headers: Dict[str, str] = field(default_factory=dict)
+    body: Any = None
+    status: Optional[int] = None         # None: called in code, no response recorded in tests
+    response_headers: Dict[str, str] = field(default_factory=dict)
+    response_body: Any = None
+    source: str = ""                     # file:line it was found at
+    
+    @property
+    def description(self) -> str:
+        return f"{self.method} {self.path}" + (f"?{self.query}" if self.query else "")
+    
+    @property
+    def is_template(self) -> bool:
+        return "{" in self.path
+    
+    def to_pact(self) -> Dict[str, Any]:
+        request: Dict[str, Any] = {"method": self.method, "path": self.path}
+        if self.query:
+            request["query"] = self.query
+        if self.headers:
+            request["headers"] = self.headers
+        if self.body is not None:
+            request["body"] = self.body
+        response: Dict[str, Any] = {}
+        if self.status is not None:
+            response["status"] = self.status
+        if self.response_headers:
+            response["headers"] = self.response_headers
+        if self.response_body is not None:
+            response["body"] = self.response_body
+        pact = {"description": self.description, "request": request, "response": response}
+        if self.response_body is not None:
+            # Consumers rely on the shape of the body, not the example values
+            pact["response"]["matchingRules"] = {"$.body": {"match": "type"}}
+        if self.source:
+            pact["_source"] = self.source
+        return pact
+    
+    @classmethod
+    def from_pact(cls, data: Dict[str, Any]) -> "Interaction":
+        request = data.get("request", {})
+        response = data.get("response", {})
+        return cls(
+            method=request.get("method", "GET").upper(),
+            path=request.get("path", "/"),
+            query=request.get("query", ""),
+            headers=request.get("headers", {}),
+            body=request.get("body"),
+            status=response.get("status"),
+            response_headers=response.get("headers", {}),
+            response_body=response.get("body"),
+            source=data.get("_source", "")
+        )
+
+
+@dataclass
+class Contract:
+    """A consumer's expectations of a provider (Pact-style JSON)."""
+    
+    consumer: str
+    provider: str
+    interactions: List[Interaction] = field(default_factory=list)
+    
+    @property
+    def file_name(self) -> str:
+        return f"{self.consumer}-{self.provider}.json"
+    
+    def to_dict(self) -> Dict[str, Any]:
+        return {
+            "consumer": {"name": self.consumer},
+            "provider": {"name": self.provider},
+            "interactions": [i.to_pact() for i in self.interactions],
+            "metadata": {"pactSpecification": {"version": PACT_SPECIFICATION}, "generator": "testgen"},
+        }
+    
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "Contract":
+        return cls(
+            consumer=data.get("consumer", {}).get("name", ""),
+            provider=data.get("provider", {}).get("name", ""),
+            interactions=[Interaction.from_pact(i) for i in data.get("interactions", [])]
+        )
+    
+    def save(self, path: Path) -> None:
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(json.dumps(self.to_dict(), indent=2) + "\n", encoding='utf-8')
+    
+    @classmethod
+    def load(cls, path: Path) -> Optional["Contract"]:
+        try:
+            return cls.from_dict(json.loads(path.read_text(encoding='utf-8')))
+        except (OSError, json.JSONDecodeError):
+            return None
+
+
+@dataclass
+class ContractBreak:
+    """A change on either side that the other side doesn't agree with."""
+    
+    consumer: str
+    provider: str
+    interaction: str
+    side: str    # "consumer": the contract changed; "provider": the provider doesn't satisfy it
+    reason: str
+
+
+def extract_client_calls(code: str, language: str, base_url: Optional[str] = None, source: str = "") -> List[Interaction]:
+    """
+    Find HTTP calls in client code.
+    
+    Paths are templates built from the URL expression: the base URL is
+    dropped and variables become `{name}` (`c.baseURL + "/users/" + id`
+    -> `/users/{id}`).
+    
+    Args:
+        code: Source code
+        language: "go", "python" or "javascript"
+        base_url: Regex the URL expression must match (calls to other services are skipped)
+        source: File name for `Interaction.source`
+        
+    Returns:
+        Interactions without responses
+    """
+    calls: List[Tuple[int, str, str]] = []  # (position, method, url expression)
+    
+    if language == "go":
+        for match in GO_NEW_REQUEST.finditer(code):
+            args = _call_args(code, match.end() - 1)
+            if args and not re.match(r'^(?:http\.Method|")', args[0]):
+                args = args[1:]  # NewRequestWithContext(ctx, ...)
+            if len(args) >= 2:
+                method = re.sub(r'^http\.Method', "", args[0]).strip('"').upper()
+                calls.append((match.start(), method, args[1]))
+        for match in GO_SHORTHAND.finditer(code):
+            args = _call_args(code, match.end() - 1)
+            if args:
+                method = "POST" if match.group(1).startswith("Post") else match.group(1).upper()
+                calls.append((match.start(), method, args[0]))
+    elif language == "python":
+        for match in PYTHON_CLIENT.finditer(code):
+            args = _call_args(code, match.end() - 1)
+            if args:
+                calls.append((match.start(), match.group(1).upper(), args[0]))
+    elif language == "javascript":
+        for match in JS_FETCH.finditer(code):
+            args = _call_args(code, match.end() - 1)
+            if args:
+                method = re.search(r'method\s*:\s*[\'"`](\w+)', args[1]) if len(args) > 1 else None
+                calls.append((match.start(), method.group(1).upper() if method else "GET", args[0]))
+        for match in JS_CLIENT.finditer(code):
+            args = _call_args(code, match.end() - 1)
+            if args:
+                calls.append((match.start(), match.group(1).upper(), args[0]))
+    
+    interactions = []
+    for position, method, expression in sorted(calls):
+        if method not in HTTP_METHODS:
+            continue
+        if base_url and not re.search(base_url, expression):
+            continue
+        path, query = url_template(expression, language)
+        if path is None:
+            continue
+        interactions.append(Interaction(
+            method=method, path=path, query=query,
+            source=f"{source}:{code.count(chr(10), 0, position) + 1}" if source else ""
+        ))
+    return interactions
+
+
+def extract_test_interactions(code: str, language: str, source: str = "") -> List[Interaction]:
+    """
+    Find the provider responses a consumer's tests stub.
+    
+    - Go: `httptest` handlers switching on `r.URL.Path` with `WriteHeader`
+      and a JSON body
+    - Python: `responses`, `requests_mock`, `respx` and `pytest-httpx` stubs
+    - JavaScript: nock interceptors
+    
+    Args:
+        code: Test source
+        language: "go", "python" or "javascript"
+        source: File name for `Interaction.source`
+        
+    Returns:
+        Interactions with concrete paths and responses
+    """
+    if language == "go":
+        return _go_test_interactions(code, source)
+    if language == "python":
+        return _python_test_interactions(code, source)
+    if language == "javascript":
+        interactions = []
+        for match in JS_NOCK.finditer(code):
+            interactions.append(Interaction(
+                method=match.group(1).upper(),
+                path=match.group(2).split("?")[0],
+                query=match.group(2).partition("?")[2],
+                status=int(match.group(3)),
+                response_body=_parse_js_literal(match.group(4)) if match.group(4) else None,
+                source=f"{source}:{code.count(chr(10), 0, match.start()) + 1}" if source else ""
+            ))
+        return interactions
+    return []
+
+
+def build_contract(config: ContractConfig, root: Path) -> Contract:
+    """
+    Build the consumer's contract from its client code and tests.
+    
+    Stubbed responses from tests are kept when they match a call in the
+    client code (tests may stub several services); client calls without
+    a stubbed response are kept as path templates without a response.
+    
+    Args:
+        config: Contract declaration
+        root: Project root the directories are relative to
+        
+    Returns:
+        Contract with interactions in a stable order
+    """
+    consumer_dir = root / config.consumer_dir
+    calls: List[Interaction] = []
+    stubs: List[Interaction] = []
+    
+    for path, language in _source_files(consumer_dir):
+        code = path.read_text(encoding='utf-8', errors='ignore')
+        relative = str(path.relative_to(root))
+        if _is_test_file(path):
+            stubs.extend(extract_test_interactions(code, language, relative))
+        else:
+            calls.extend(extract_client_calls(code, language, config.base_url, relative))
+    
+    interactions: Dict[Tuple[str, str, Optional[int]], Interaction] = {}
+    for stub in stubs:
+        if calls and not any(_same_endpoint(call, stub) for call in calls):
+            continue
+        interactions.setdefault((stub.method, stub.description, stub.status), stub)
+    for call in calls:
+        if not any(_same_endpoint(call, stub) for stub in interactions.values()):
+            interactions.setdefault((call.method, call.description, None), call)
+    
+    ordered = sorted(interactions.values(), key=lambda i: (i.path, i.method, i.status or 0))
+    return Contract(config.consumer, config.provider, ordered)
+
+
+def diff_contracts(old: Contract, new: Contract) -> List[ContractBreak]:
+    """
+    Consumer-side changes between the stored contract and a rebuilt one.
+    
+    Args:
+        old: Contract in the repository
+        new: Contract built from the consumer's current code
+        
+    Returns:
+        One entry per added, removed or changed interaction
+    """
+    def key(interaction: Interaction) -> Tuple[str, str]:
+        return interaction.method, interaction.description
+    
+    before = {key(i): i for i in old.interactions}
+    after = {key(i): i for i in new.interactions}
+    changes = []
+    
+    for k, interaction in after.items():
+        if k not in before:
+            changes.append(ContractBreak(new.consumer, new.provider, interaction.description, "consumer", "new interaction"))
+            continue
+        previous = before[k]
+        if previous.status != interaction.status:
+            changes.append(ContractBreak(
+                new.consumer, new.provider, interaction.description, "consumer",
+                f"expected status changed from {previous.status} to {interaction.status}"
+            ))
+        problems = shape_problems(interaction.response_body, previous.response_body)
+        if problems:
+            changes.append(ContractBreak(
+                new.consumer, new.provider, interaction.description, "consumer",
+                "expected response changed: " + "; ".join(problems)
+            ))
+    for k, interaction in before.items():
+        if k not in after:
+            changes.append(ContractBreak(new.consumer, new.provider, interaction.description, "consumer", "interaction removed"))
+    
+    return changes
+
+
+def extract_routes(code: str, language: str) -> List[Tuple[Optional[str], str]]:
+    """
+    Find the routes a provider registers.
+    
+    Args:
+        code: Provider source
+        language: "go", "python" or "javascript"
+        
+    Returns:
+        (method or None for any, route pattern) pairs
+    """
+    routes: List[Tuple[Optional[str], str]] = []
+    if language == "go":
+        for match in GO_ROUTE.finditer(code):
+            if match.group(2):
+                method = match.group(1) or match.group(3)
+                routes.append((method.upper() if method else None, match.group(2)))
+            else:
+                routes.append((match.group(4).upper(), match.group(5)))
+    elif language == "python":
+        for match in PYTHON_ROUTE.finditer(code):
+            if match.group(1):
+                methods = re.findall(r'\w+', match.group(2) or "") or ["GET"]
+                routes.extend((method.upper(), match.group(1)) for method in methods)
+            else:
+                routes.append((match.group(3).upper(), match.group(4)))
+    elif language == "javascript":
+        for match in JS_ROUTE.finditer(code):
+            method = match.group(1).upper()
+            routes.append((None if method == "ALL" else method, match.group(2)))
+    return routes
+
+
+def check_provider_routes(contract: Contract, provider_dir: Path) -> List[ContractBreak]:
+    """
+    Find interactions the provider has no route for (without running it).
+    
+    Args:
+        contract: Consumer contract
+        provider_dir: Provider service directory
+        
+    Returns:
+        Breaks for unmatched interactions (none when no routes can be found)
+    """
+    routes = []
+    for path, language in _source_files(provider_dir):
+        if not _is_test_file(path):
+            routes.extend(extract_routes(path.read_text(encoding='utf-8', errors='ignore'), language))
+    if not routes:
+        return []
+    
+    breaks = []
+    for interaction in contract.interactions:
+        concrete = re.sub(r'\{[^}]+\}', "x", interaction.path)
+        matching = [method for method, route in routes if _route_regex(route).fullmatch(concrete)]
+        if not matching:
+            reason = f"provider has no route for {interaction.path}"
+        elif None not in matching and interaction.method not in matching and not (
+            interaction.method == "HEAD" and "GET" in matching
+        ):
+            reason = f"provider route for {interaction.path} doesn't accept {interaction.method}"
+        else:
+            continue
+        breaks.append(ContractBreak(contract.consumer, contract.provider, interaction.description, "provider", reason))
+    return breaks
+
+
+def shape_problems(want: Any, got: Any, path: str = "$") -> List[str]:
+    """
+    Differences in JSON shape (Pact type matching).
+    
+    Every field the consumer expects must be present with the same JSON
+    type; extra fields are fine. Array elements are compared with the
+    first expected element.
+    """
+    if want is None:
+        return []
+    if isinstance(want, dict):
+        if not isinstance(got, dict):
+            return [f"{path}: expected an object"]
+        problems = []
+        for key, value in want.items():
+            if key not in got:
+                problems.append(f"{path}.{key}: missing")
+            else:
+                problems.extend(shape_problems(value, got[key], f"{path}.{key}"))
+        return problems
+    if isinstance(want, list):
+        if not isinstance(got, list):
+            return [f"{path}: expected an array"]
+        if not want:
+            return []
+        return [p for i, item in enumerate(got) for p in shape_problems(want[0], item, f"{path}[{i}]")]
+    if _json_type(want) != _json_type(got):
+        return [f"{path}: expected {_json_type(want)}, got {_json_type(got)}"]
+    return []
+
+
+GO_PROVIDER_TEST = """// Code generated by testgen contracts. Replays the {consumer} consumer's
+// contract ({contract_path}) against the {provider} handler.
+
+package {package}
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func {test_name}(t *testing.T) {
+	data, err := os.ReadFile({contract_literal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contract struct {
+		Interactions []struct {
+			Description string
+			Request     struct {
+				Method  string
+				Path    string
+				Query   string
+				Headers map[string]string
+				Body    json.RawMessage
+			}
+			Response struct {
+				Status int
+				Body   json.RawMessage
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &contract); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := {handler}
+	for _, interaction := range contract.Interactions {
+		interaction := interaction
+		t.Run(interaction.Description, func(t *testing.T) {
+			if interaction.Response.Status == 0 {
+				t.Skip("no response recorded in the consumer's tests")
+			}
+			var body io.Reader
+			if len(interaction.Request.Body) > 0 {
+				body = bytes.NewReader(interaction.Request.Body)
+			}
+			target := interaction.Request.Path
+			if interaction.Request.Query != "" {
+				target += "?" + interaction.Request.Query
+			}
+			req := httptest.NewRequest(interaction.Request.Method, target, body)
+			for name, value := range interaction.Request.Headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != interaction.Response.Status {
+				t.Fatalf("status %d, consumer expects %d", rec.Code, interaction.Response.Status)
+			}
+			if len(interaction.Response.Body) == 0 {
+				return
+			}
+			var want, got any
+			if err := json.Unmarshal(interaction.Response.Body, &want); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("response is not JSON: %v", err)
+			}
+			for _, problem := range contractShape("$", want, got) {
+				t.Error(problem)
+			}
+		})
+	}
+}
+
+// contractShape reports fields of want that are missing from got or have
+// another JSON type; extra fields are fine.
+func contractShape(path string, want, got any) []string {
+	switch w := want.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return []string{path + ": expected an object"}
+		}
+		var problems []string
+		for key, value := range w {
+			if _, ok := g[key]; !ok {
+				problems = append(problems, path+"."+key+": missing")
+				continue
+			}
+			problems = append(problems, contractShape(path+"."+key, value, g[key])...)
+		}
+		return problems
+	case []any:
+		g, ok := got.([]any)
+		if !ok {
+			return []string{path + ": expected an array"}
+		}
+		if len(w) == 0 {
+			return nil
+		}
+		var problems []string
+		for i, item := range g {
+			problems = append(problems, contractShape(fmt.Sprintf("%s[%d]", path, i), w[0], item)...)
+		}
+		return problems
+	default:
+		if fmt.Sprintf("%T", want) != fmt.Sprintf("%T", got) {
+			return []string{fmt.Sprintf("%s: expected %T, got %T", path, want, got)}
+		}
+		return nil
+	}
+}
+"""
+
+PYTHON_PROVIDER_TEST = '''"""
+Provider verification for the {consumer} consumer's contract.
+
+Generated by testgen contracts: replays {contract_path} against the
+{provider} app in-process.
+"""
+
+import json
+import sys
+from pathlib import Path
+
+import pytest
+
+sys.path.insert(0, str(Path(__file__).resolve().parent{parents}))
+from {module} import {app}  # noqa: E402
+
+CONTRACT = Path(__file__).resolve().parent / {contract_literal}
+INTERACTIONS = json.loads(CONTRACT.read_text())["interactions"]
+
+
+@pytest.fixture
+def client():
+{client}
+
+
+def shape_problems(want, got, path="$"):
+    """Fields the consumer expects that are missing or have another JSON type."""
+    if want is None:
+        return []
+    if isinstance(want, dict):
+        if not isinstance(got, dict):
+            return [f"{{path}}: expected an object"]
+        problems = []
+        for key, value in want.items():
+            if key not in got:
+                problems.append(f"{{path}}.{{key}}: missing")
+            else:
+                problems.extend(shape_problems(value, got[key], f"{{path}}.{{key}}"))
+        return problems
+    if isinstance(want, list):
+        if not isinstance(got, list):
+            return [f"{{path}}: expected an array"]
+        return [p for i, item in enumerate(got) for p in shape_problems(want[0], item, f"{{path}}[{{i}}]")] if want else []
+    json_type = lambda v: "number" if isinstance(v, (int, float)) and not isinstance(v, bool) else type(v).__name__
+    return [] if json_type(want) == json_type(got) else [f"{{path}}: expected {{json_type(want)}}, got {{json_type(got)}}"]
+
+
+@pytest.mark.parametrize("interaction", INTERACTIONS, ids=[i["description"] for i in INTERACTIONS])
+def {test_name}(client, interaction):
+    request, expected = interaction["request"], interaction["response"]
+    if "status" not in expected:
+        pytest.skip("no response recorded in the consumer's tests")
+    target = request["path"] + ("?" + request["query"] if request.get("query") else "")
+    kwargs = {{"headers": request.get("headers") or {{}}}}
+    if request.get("body") is not None:
+        kwargs["json"] = request["body"]
+
+{send}
+
+    assert status == expected["status"], f"status {{status}}, consumer expects {{expected['status']}}"
+    if expected.get("body") is not None:
+        assert shape_problems(expected["body"], body) == []
+'''
+
+PYTHON_CLIENTS = {
+    "Flask": (
+        "    return {app}.test_client()",
+        "    response = client.open(target, method=request[\"method\"], **kwargs)\n"
+        "    status, body = response.status_code, response.get_json(silent=True)"
+    ),
+    "FastAPI": (
+        "    from fastapi.testclient import TestClient\n    return TestClient({app})",
+        "    response = client.request(request[\"method\"], target, **kwargs)\n"
+        "    status = response.status_code\n"
+        "    body = response.json() if response.content else None"
+    ),
+}
+
+
+def generate_provider_test(contract: Contract, config: ContractConfig, root: Path) -> Optional[Tuple[Path, str]]:
+    """
+    Generate the provider verification test for a contract.
+    
+    Go providers need a handler constructor (`func NewRouter() http.Handler`);
+    Python providers a module-level Flask or FastAPI app.
+    
+    Args:
+        contract: Consumer contract (written to the contracts directory)
+        config: Contract declaration
+        root: Project root
+        
+    Returns:
+        (test file path, source), or None when no handler/app is found
+    """
+    provider_dir = root / config.provider_dir
+    contract_path = root / config.contracts_dir / contract.file_name
+    identifier = "".join(part.capitalize() for part in re.split(r'[^A-Za-z0-9]+', contract.consumer) if part)
+    
+    for path in sorted(provider_dir.glob("*.go")):
+        if path.name.endswith("_test.go"):
+            continue
+        code = path.read_text(encoding='utf-8', errors='ignore')
+        constructors = [h for h in find_go_handlers(code) if h.constructor]
+        package = re.search(r'^package\s+(\w+)', code, re.MULTILINE)
+        if constructors and package:
+            test_file = provider_dir / f"contract_{_snake(contract.consumer)}_test.go"
+            relative = _relative(contract_path, provider_dir)
+            source = (
+                GO_PROVIDER_TEST
+                .replace("{consumer}", contract.consumer)
+                .replace("{provider}", contract.provider)
+                .replace("{contract_path}", str(contract_path.relative_to(root)))
+                .replace("{package}", package.group(1))
+                .replace("{test_name}", f"TestContract{identifier}")
+                .replace("{contract_literal}", json.dumps(relative))
+                .replace("{handler}", constructors[0].expression)
+            )
+            return test_file, source
+    
+    for path in sorted(provider_dir.rglob("*.py")):
+        if _is_test_file(path) or SKIPPED_DIRS & set(path.relative_to(provider_dir).parts):
+            continue
+        match = PYTHON_APP.search(path.read_text(encoding='utf-8', errors='ignore'))
+        if not match:
+            continue
+        app, framework = match.groups()
+        test_dir = provider_dir / "tests"
+        module = ".".join(path.relative_to(provider_dir).with_suffix("").parts)
+        client, send = PYTHON_CLIENTS[framework]
+        source = PYTHON_PROVIDER_TEST.format(
+            consumer=contract.consumer,
+            provider=contract.provider,
+            contract_path=contract_path.relative_to(root),
+            parents=".parent",
+            module=module,
+            app=app,
+            contract_literal=json.dumps(_relative(contract_path, test_dir)),
+            client=client.format(app=app),
+            send=send,
+            test_name=f"test_contract_{_snake(contract.consumer)}",
+        )
+        return test_dir / f"test_contract_{_snake(contract.consumer)}.py", source
+    
+    return None
+
+
+def breaks_from_results(contract: Contract, tests: List[Any]) -> List[ContractBreak]:
+    """
+    Provider-side breaks from the results of the verification tests.
+    
+    Args:
+        contract: Consumer contract
+        tests: `base_runner.TestResult`s of the provider's test run
+        
+    Returns:
+        One break per failed interaction
+    """
+    identifier = _snake(contract.consumer)
+    failed = [t for t in tests if t.status in ("failed", "error")]
+    breaks = []
+    for test in failed:
+        name = test.name.rsplit("::", 1)[-1]  # pytest node IDs
+        if any(other.name.startswith(name + "/") for other in failed):
+            continue  # Go parent test of failed subtests
+        if not re.search(rf'(?i)^(?:TestContract|test_contract_){re.escape(identifier).replace("_", "_?")}', name):
+            continue
+        if "[" in name:  # test_contract_web[GET /users/1]
+            interaction = name.partition("[")[2].rstrip("]")
+        else:  # TestContractWeb/GET_/users/1
+            interaction = name.split("/", 1)[1].replace("_", " ", 1) if "/" in name else name
+        breaks.append(ContractBreak(
+            contract.consumer, contract.provider, interaction, "provider",
+            re.sub(r'^\S+_test\.go:\d+:\s*', "", (test.message or "verification failed").strip().splitlines()[0])
+        ))
+    return breaks
+
+
+def url_template(expression: str, language: str) -> Tuple[Optional[str], str]:
+    """
+    Path template and query of a URL expression.
+    
+    Args:
+        expression: URL argument as written in the source
+        language: "go", "python" or "javascript"
+        
+    Returns:
+        (path template, query); path is None when there's no literal path
+    """
+    expression = expression.strip()
+    text = None
+    
+    sprintf = re.match(r'^fmt\.Sprintf\(\s*"((?:[^"\\]|\\.)*)"\s*(?:,(.*))?\)$', expression, re.DOTALL)
+    if sprintf:
+        args = _split_args(sprintf.group(2) or "")
+        index = [0]
+        
+        def verb(match: re.Match) -> str:
+            arg = args[index[0]] if index[0] < len(args) else "param"
+            index[0] += 1
+            return "\x00" + arg + "\x01"
+        text = re.sub(r'%[-+# 0-9.]*[a-zA-Z]', verb, sprintf.group(1))
+    elif re.match(r'^f["\']', expression):  # Python f-string
+        text = re.sub(r'\{([^{}]+)\}', lambda m: "\x00" + m.group(1) + "\x01", expression[2:-1])
+    elif expression.startswith("`"):  # JavaScript template literal
+        text = re.sub(r'\$\{([^{}]+)\}', lambda m: "\x00" + m.group(1) + "\x01", expression[1:-1])
+    else:  # "literal" or a + b + "literal" concatenation
+        parts = []
+        for part in _split_concatenation(expression):
+            literal = re.match(r'^(["\'])(.*)\1$', part, re.DOTALL)
+            parts.append(literal.group(2) if literal else "\x00" + part + "\x01")
+        text = "".join(parts)
+    
+    # Drop the base URL: a leading variable, or scheme://host of a literal
+    text = re.sub(r'^\x00[^\x01]*\x01', "", text)
+    if re.match(r'^[a-z][a-z0-9+.-]*://', text):
+        text = urlsplit(text.replace("\x00", "{").replace("\x01", "}"))._replace(scheme="", netloc="").geturl()
+        text = text.replace("{", "\x00").replace("}", "\x01")
+    if not text.startswith("/"):
+        return None, ""
+    
+    path, _, query = text.partition("?")
+    path = re.sub(r'\x00([^\x01]*)\x01', lambda m: "{" + _placeholder(m.group(1)) + "}", path)
+    query = "" if "\x00" in query else query
+    return path or "/", query
+
+
+def _placeholder(expression: str) -> str:
+    """`user.ID` -> `ID`, `strconv.Itoa(id)` -> `id`."""
+    names = re.findall(r'[A-Za-z_]\w*', expression)
+    names = [n for n in names if n not in ("strconv", "Itoa", "FormatInt", "str", "String", "encodeURIComponent", "url", "PathEscape", "quote")]
+    return names[-1] if names else "param"
+
+
+def _go_test_interactions(code: str, source: str) -> List[Interaction]:
+    interactions = []
+    paths = list(GO_STUB_PATH.finditer(code))
+    for index, match in enumerate(paths):
+        end = paths[index + 1].start() if index + 1 < len(paths) else len(code)
+        # The stub's branch: up to the next path check or the end of the handler
+        segment = code[match.end():end]
+        closing = segment.find("}))")
+        if closing != -1:
+            segment = segment[:closing]
+        window = code[max(0, match.start() - 200):match.end()] + segment
+        
+        method_match = GO_STUB_METHOD.search(window)
+        method = (method_match.group(1) or method_match.group(2)).upper() if method_match else "GET"
+        status_match = GO_STUB_STATUS.search(segment)
+        status = 200
+        if status_match:
+            name = status_match.group(1) or status_match.group(3)
+            number = status_match.group(2) or status_match.group(4)
+            status = _go_status(name) if name else int(number)
+        body_match = GO_STUB_BODY.search(segment)
+        body = None
+        if body_match:
+            literal = body_match.group(1)
+            text = literal[1:-1] if literal.startswith("`") else _go_unquote(literal)
+            try:
+                body = json.loads(text)
+            except json.JSONDecodeError:
+                body = None
+        
+        path = match.group(1) or match.group(2)
+        interactions.append(Interaction(
+            method=method,
+            path=path.split("?")[0],
+            query=path.partition("?")[2],
+            status=status,
+            response_body=body,
+            source=f"{source}:{code.count(chr(10), 0, match.start()) + 1}" if source else ""
+        ))
+    return interactions
+
+
+def _python_test_interactions(code: str, source: str) -> List[Interaction]:
+    try:
+        tree = ast.parse(code)
+    except SyntaxError:
+        return []
+    
+    interactions = []
+    mocked = set()  # respx routes already read through their .mock() call
+    for node in ast.walk(tree):
+        if not isinstance(node, ast.Call) or not isinstance(node.func, ast.Attribute) or id(node) in mocked:
+            continue
+        receiver = node.func.value
+        # respx.get(url).mock(return_value=httpx.Response(200, json=...))
+        if node.func.attr == "mock" and isinstance(receiver, ast.Call) and isinstance(receiver.func, ast.Attribute):
+            inner = receiver
+            mocked.add(id(inner))
+            if not (isinstance(inner.func.value, ast.Name) and inner.func.value.id in PYTHON_MOCK_RECEIVERS):
+                continue
+            response = _keyword(node, "return_value")
+            status, body = 200, None
+            if isinstance(response, ast.Call):
+                status = _literal(response.args[0]) if response.args else _literal(_keyword(response, "status_code")) or 200
+                body = _literal(_keyword(response, "json"))
+            method, url = inner.func.attr.upper(), inner.args[0] if inner.args else _keyword(inner, "url")
+        elif isinstance(receiver, ast.Name) and receiver.id in PYTHON_MOCK_RECEIVERS:
+            attr = node.func.attr
+            args = list(node.args)
+            if attr in ("add", "add_response", "replace", "upsert"):
+                method_node = args.pop(0) if args and not _is_url(args[0]) else _keyword(node, "method")
+                method = (_literal(method_node) or getattr(method_node, "attr", None) or "GET")
+            elif attr.upper() in HTTP_METHODS:
+                method = attr
+            else:
+                continue
+            url = args[0] if args else _keyword(node, "url")
+            status = _literal(_keyword(node, "status")) or _literal(_keyword(node, "status_code")) or 200
+            body = _literal(_keyword(node, "json"))
+            method = str(method).upper()
+        else:
+            continue
+        
+        if url is None:
+            continue
+        path, query = url_template(ast.unparse(url), "python")
+        if path is None or method not in HTTP_METHODS:
+            continue
+        interactions.append(Interaction(
+            method=method, path=path, query=query, status=int(status), response_body=body,
+            source=f"{source}:{node.lineno}" if source else ""
+        ))
+    return interactions
+
+
+def _keyword(call: ast.Call, name: str) -> Optional[ast.AST]:
+    for keyword in call.keywords:
+        if keyword.arg == name:
+            return keyword.value
+    return None
+
+
+def _literal(node: Optional[ast.AST]) -> Any:
+    if node is None:
+        return None
+    try:
+        return ast.literal_eval(node)
+    except (ValueError, SyntaxError, TypeError):
+        return None
+
+
+def _is_url(node: ast.AST) -> bool:
+    text = ast.unparse(node)
+    return "/" in text or "url" in text.lower()
+
+
+def _parse_js_literal(text: str) -> Any:
+    """JSON from a JavaScript object literal (unquoted keys, single quotes)."""
+    normalized = re.sub(r'([{,]\s*)([A-Za-z_$][\w$]*)\s*:', r'\1"\2":', text)
+    normalized = re.sub(r"'((?:[^'\\]|\\.)*)'", lambda m: json.dumps(m.group(1)), normalized)
+    normalized = re.sub(r',\s*([}\]])', r'\1', normalized)
+    try:
+        return json.loads(normalized)
+    except json.JSONDecodeError:
+        return None
+
+
+def _go_status(name: str) -> int:
+    """`StatusNotFound` -> 404."""
+    constant = re.sub(r'(?<!^)(?=[A-Z])', "_", name[len("Status"):]).upper()
+    try:
+        return int(HTTPStatus[constant])
+    except KeyError:
+        return 200
+
+
+def _go_unquote(literal: str) -> str:
+    try:
+        return json.loads(literal)
+    except json.JSONDecodeError:
+        return literal[1:-1]
+
+
+def _json_type(value: Any) -> str:
+    if isinstance(value, bool):
+        return "boolean"
+    if isinstance(value, (int, float)):
+        return "number"
+    if isinstance(value, str):
+        return "string"
+    if isinstance(value, list):
+        return "array"
+    if isinstance(value, dict):
+        return "object"
+    return "null"
+
+
+def _route_regex(route: str) -> re.Pattern:
+    """Route pattern -> regex: {id}, {id:[0-9]+}, <int:id>, :id, trailing-slash subtrees."""
+    prefix = route.endswith("/") and route != "/" and "{$}" not in route  # net/http subtree
+    pattern = re.escape(route.replace("{$}", ""))
+    pattern = re.sub(r'\\\{[^}]*\\\.\\\.\\\.\\\}', ".*", pattern)  # {path...}
+    pattern = re.sub(r'\\\{[^}]*\\\}|<[^>]+>|:\w+|\\\*', "[^/]+", pattern)
+    return re.compile(pattern + (".*" if prefix else "/?"))
+
+
+def _same_endpoint(call: Interaction, stub: Interaction) -> bool:
+    """Whether a stubbed request (concrete path) is the client call (template)."""
+    if call.method != stub.method:
+        return False
+    pattern = re.escape(call.path)
+    pattern = re.sub(r'\\\{[^}]*\\\}', "[^/]+", pattern)
+    return re.fullmatch(pattern, stub.path) is not None
+
+
+def _source_files(directory: Path):
+    if not directory.is_dir():
+        return
+    for path in sorted(directory.rglob("*")):
+        language = SOURCE_EXTENSIONS.get(path.suffix)
+        if language and path.is_file() and not SKIPPED_DIRS & set(path.relative_to(directory).parts):
+            yield path, language
+
+
+def _is_test_file(path: Path) -> bool:
+    name = path.name
+    return (
+        name.endswith("_test.go")
+        or (name.startswith("test_") and name.endswith(".py"))
+        or name.endswith("_test.py")
+        or re.search(r'\.(?:test|spec)\.[jt]s$', name) is not None
+        or "__tests__" in path.parts
+    )
+
+
+def _call_args(code: str, open_index: int) -> List[str]:
+    """Top-level arguments of the call whose `(` is at open_index."""
+    depth = 0
+    quote = None
+    start = open_index + 1
+    args = []
+    index = open_index
+    while index < len(code):
+        char = code[index]
+        if quote:
+            if char == "\\" and quote != "`":
+                index += 1
+            elif char == quote:
+                quote = None
+        elif char in "\"'`":
+            quote = char
+        elif char in "([{":
+            depth += 1
+        elif char in ")]}":
+            depth -= 1
+            if depth == 0:
+                args.append(code[start:index])
+                break
+        elif char == "," and depth == 1:
+            args.append(code[start:index])
+            start = index + 1
+        index += 1
+    return [a.strip() for a in args if a.strip()]
+
+
+def _split_args(text: str) -> List[str]:
+    return _call_args("(" + text + ")", 0)
+
+
+def _split_concatenation(expression: str) -> List[str]:
+    """`a + "/x/" + b` -> ['a', '"/x/"', 'b'] (top level only)."""
+    parts = []
+    depth = 0
+    quote = None
+    start = 0
+    for index, char in enumerate(expression):
+        if quote:
+            if char == quote and expression[index - 1] != "\\":
+                quote = None
+        elif char in "\"'`":
+            quote = char
+        elif char in "([{":
+            depth += 1
+        elif char in ")]}":
+            depth -= 1
+        elif char == "+" and depth == 0:
+            parts.append(expression[start:index].strip())
+            start = index + 1
+    parts.append(expression[start:].strip())
+    return [p for p in parts if p]
+
+
+def _relative(target: Path, start: Path) -> str:
+    """Relative path from a directory to a file (may go up)."""
+    return os.path.relpath(target, start).replace(os.sep, "/")
+
+
+def _snake(name: str) -> str:
+    return re.sub(r'[^A-Za-z0-9]+', "_", name).strip("_").lower()
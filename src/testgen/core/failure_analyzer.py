@@ -6,11 +6,11 @@ Analyze test failures and extract patterns across ALL 14 languages.
 
 from typing import List, Dict, Optional, Set, Tuple
 from enum import Enum
-from dataclasses import dataclass
+from dataclasses import dataclass, field
 from collections import Counter
 import re
 
-from .result_models import TestResult, TestSuite, ExecutionSummary, Language, TestFramework, ErrorInfo
+from .result_models import TestResult, TestSuite, ExecutionSummary, Language, TestFramework, ErrorInfo, TestStatus
 
 
 class FailureType(str, Enum):
@@ -25,6 +25,7 @@ class FailureType(str, Enum):
     FILE_NOT_FOUND = "file_not_found"
     PERMISSION_ERROR = "permission_error"
     MEMORY_ERROR = "memory_error"
+    STALE_GENERATED = "stale_generated"  # generated code (go generate) out of date, not a logic failure
     UNKNOWN = "unknown"
 
 
@@ -48,6 +49,12 @@ class FailureAnalysis:
     flaky_candidates: List[str]  # Tests that might be flaky
     language: Language
     framework: TestFramework
+    stale_generated: List[str] = field(default_factory=list)  # Failures caused by stale generated code
+    
+    @property
+    def logic_failures(self) -> int:
+        """Failures not explained by stale generated code."""
+        return self.total_failures - len(self.stale_generated)
 
 
 class FailureAnalyzer:
@@ -132,31 +139,46 @@ class FailureAnalyzer:
         ],
     }
     
-    def __init__(self, language: Language = Language.UNKNOWN, framework: TestFramework = TestFramework.UNKNOWN):
+    def __init__(
+        self,
+        language: Language = Language.UNKNOWN,
+        framework: TestFramework = TestFramework.UNKNOWN,
+        stale_generated: Optional[Dict[str, List[str]]] = None,
+        stale_symbols: Optional[Dict[str, List[str]]] = None
+    ):
         """
         Initialize failure analyzer.
         
         Args:
             language: Programming language
             framework: Test framework
+            stale_generated: Suites (Go packages) with stale generated code -> stale files
+            stale_symbols: Suites -> identifiers the stale files declare
         """
         self.language = language
         self.framework = framework
+        self.stale_generated: Dict[str, List[str]] = dict(stale_generated or {})
+        self.stale_symbols: Dict[str, List[str]] = dict(stale_symbols or {})
         self.failed_tests: List[Tuple[TestResult, str]] = []  # (test, suite_name)
+        self.retried_tests: List[Tuple[TestResult, str]] = []  # passed only after a retry
     
     def add_test(self, test: TestResult, suite_name: str = "") -> None:
-        """Add a failed test for analysis."""
-        if test.failed:
+        """Add a failed (or passed-on-retry) test for analysis."""
+        # A package that doesn't build (e.g. against a stale mock) reports an error, not a failed test
+        if test.failed or (test.status == TestStatus.ERROR and self.caused_by_stale_generated(test, suite_name)):
             self.failed_tests.append((test, suite_name))
+        elif test.passed_on_retry:
+            self.retried_tests.append((test, suite_name))
     
     def add_suite(self, suite: TestSuite) -> None:
-        """Add all failed tests from a suite."""
+        """Add all failed and passed-on-retry tests from a suite."""
         for test in suite.tests:
-            if test.failed:
-                self.add_test(test, suite.name)
+            self.add_test(test, suite.name)
     
     def add_summary(self, summary: ExecutionSummary) -> None:
         """Add all failed tests from an execution summary."""
+        self.stale_generated.update(summary.stale_generated)
+        self.stale_symbols.update(summary.stale_symbols)
         for suite in summary.suites:
             self.add_suite(suite)
     
@@ -191,6 +213,43 @@ class FailureAnalyzer:
         
         return FailureType.UNKNOWN
     
+    def caused_by_stale_generated(self, test: TestResult, suite_name: str = "") -> bool:
+        """
+        Whether a failure comes from stale generated code (see `go_generate.check_generated`).
+        
+        Only failures in a suite with stale generated code whose output
+        mentions what that code declares, or a stale file: a mock missing
+        a method fails to build naming the mock, a stale String() table
+        fails an assertion showing the type.
+        
+        Args:
+            test: Failed test
+            suite_name: Suite (Go package) the test belongs to
+            
+        Returns:
+            True when the failure is the stale code's doing
+        """
+        if suite_name not in self.stale_generated or not test.error:
+            return False
+        output = "\n".join(filter(None, [test.error.message, test.error.traceback]))
+        names = self.stale_symbols.get(suite_name, []) + self.stale_generated[suite_name]
+        return any(re.search(rf'\b{re.escape(name)}\b', output) for name in names)
+    
+    def classify_test(self, test: TestResult, suite_name: str = "") -> FailureType:
+        """
+        Classify a failed test, taking its suite into account.
+        
+        Args:
+            test: Failed test
+            suite_name: Suite (Go package) the test belongs to
+            
+        Returns:
+            Classified failure type (STALE_GENERATED when `caused_by_stale_generated`)
+        """
+        if self.caused_by_stale_generated(test, suite_name):
+            return FailureType.STALE_GENERATED
+        return self.classify_failure(test.error.message if test.error else "")
+    
     def count_failure_types(self) -> Dict[FailureType, int]:
         """
         Count failures by type.
@@ -200,13 +259,25 @@ class FailureAnalyzer:
         """
         counts = {ftype: 0 for ftype in FailureType}
         
-        for test, _ in self.failed_tests:
-            if test.error and test.error.message:
-                ftype = self.classify_failure(test.error.message)
-                counts[ftype] += 1
+        for test, suite_name in self.failed_tests:
+            if (test.error and test.error.message) or self.caused_by_stale_generated(test, suite_name):
+                counts[self.classify_test(test, suite_name)] += 1
         
         return counts
     
+    def get_stale_generated_failures(self) -> List[str]:
+        """
+        Failures caused by stale generated code, rather than by the logic under test.
+        
+        Returns:
+            "TestName (package)" of each such failure
+        """
+        return [
+            f"{test.name} ({suite_name})"
+            for test, suite_name in self.failed_tests
+            if self.caused_by_stale_generated(test, suite_name)
+        ]
+    
     def extract_error_patterns(self, min_occurrences: int = 2) -> List[FailurePattern]:
         """
         Extract common error patterns.
@@ -239,7 +310,7 @@ class FailureAnalyzer:
                 test_examples = tests_with_suite[:3]  # First 3 examples
                 
                 # Classify failure type
-                ftype = self.classify_failure(test_examples[0][0].error.message)
+                ftype = self.classify_test(*test_examples[0])
                 
                 pattern = FailurePattern(
                     type=ftype,
@@ -300,6 +371,7 @@ class FailureAnalyzer:
         Identify potentially flaky tests.
         
         Flaky tests are identified by:
+        - Passing only after a retry (confirmed flaky)
         - Timeout errors
         - Network errors
         - Intermittent errors
@@ -309,11 +381,14 @@ class FailureAnalyzer:
         """
         flaky = set()
         
+        for test, suite_name in self.retried_tests:
+            flaky.add(f"{test.name} ({suite_name})")
+        
         for test, suite_name in self.failed_tests:
             if not test.error:
                 continue
             
-            ftype = self.classify_failure(test.error.message)
+            ftype = self.classify_test(test, suite_name)
             
             # Timeouts and network errors are often flaky
             if ftype in [FailureType.TIMEOUT, FailureType.NETWORK_ERROR]:
@@ -335,7 +410,8 @@ class FailureAnalyzer:
             most_common_errors=self.get_most_common_errors(),
             flaky_candidates=self.identify_flaky_tests(),
             language=self.language,
-            framework=self.framework
+            framework=self.framework,
+            stale_generated=self.get_stale_generated_failures()
         )
     
     def generate_failure_report(self) -> str:
@@ -356,6 +432,8 @@ class FailureAnalyzer:
         report.append("")
         
         report.append(f"Total Failures: {analysis.total_failures}")
+        if analysis.stale_generated:
+            report.append(f"Logic Failures: {analysis.logic_failures}")
         report.append("")
         
         # Failure types breakdown
@@ -382,6 +460,22 @@ class FailureAnalyzer:
                 report.append(f"  {i}. ({count}x) {error[:60]}...")
         report.append("")
         
+        # Failures explained by stale generated code
+        if analysis.stale_generated:
+            report.append(f"🧬 Stale Generated Code ({len(analysis.stale_generated)} failures, re-run go generate):")
+            for package, files in sorted(self.stale_generated.items()):
+                report.append(f"  - {package}: {', '.join(files)}")
+            report.append("")
+        
+        # Tests that passed only on retry
+        if self.retried_tests:
+            report.append(f"🔁 Passed on Retry ({len(self.retried_tests)}):")
+            for test, suite_name in self.retried_tests[:5]:
+                report.append(f"  - {test.name} ({suite_name}): {len(test.attempts)} attempts")
+            if len(self.retried_tests) > 5:
+                report.append(f"  ... and {len(self.retried_tests) - 5} more")
+            report.append("")
+        
         # Flaky tests
         if analysis.flaky_candidates:
             report.append(f"⚠️  Potentially Flaky Tests ({len(analysis.flaky_candidates)}):")
@@ -408,9 +502,8 @@ class FailureAnalyzer:
         matching = []
         
         for test, suite_name in self.failed_tests:
-            if test.error and test.error.message:
-                ftype = self.classify_failure(test.error.message)
-                if ftype == failure_type:
+            if (test.error and test.error.message) or self.caused_by_stale_generated(test, suite_name):
+                if self.classify_test(test, suite_name) == failure_type:
                     matching.append((test, suite_name))
         
         return matching
@@ -437,7 +530,8 @@ def quick_failure_analysis(summary: ExecutionSummary) -> Dict[str, any]:
         "total_failures": analysis.total_failures,
         "failure_types": {k.value: v for k, v in analysis.failure_types.items() if v > 0},
         "common_patterns_count": len(analysis.common_patterns),
-        "flaky_test_count": len(analysis.flaky_candidates)
+        "flaky_test_count": len(analysis.flaky_candidates),
+        "stale_generated_count": len(analysis.stale_generated)
     }
 
 
@@ -0,0 +1,662 @@
+"""
+Fault-Injection Tests for Go I/O and Dependencies.
+
+Finds the seams where Go code meets the outside world (`io.Reader`,
+`io.Writer`, `context.Context` and interfaces with error-returning
+methods) and generates tests that make them fail:
+
+- readers that fail immediately, midway, with one-byte reads or with a
+  timeout (`testing/iotest`)
+- writers that fail, fail midway or short-write
+- contexts cancelled mid-operation
+- dependencies that fail on the Nth call (`fault<Interface>` wrappers)
+
+Tests assert that the injected error comes back out (`errors.Is`) and
+that closers passed in are closed after the failure. Functions whose
+arguments are all seams get complete tests; the rest (methods,
+dependencies needing a real implementation) are described for the LLM
+prompt with the generated wrappers to use.
+"""
+
+import re
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Dict, List, Optional, Tuple
+
+from .load_test import GO_PACKAGE
+
+
+FAULT_TEST_FILE = "testgen_fault_test.go"
+
+READER_TYPES = ("io.Reader", "io.ReadCloser")
+WRITER_TYPES = ("io.Writer", "io.WriteCloser")
+CONTEXT_TYPE = "context.Context"
+
+# func Name(params) results {   |   func (s *Store) Name(params) results {
+GO_FUNC = re.compile(r'^func\s+(?:\(\s*(?:\w+\s+)?\*?(\w+)(?:\[[^\]]*\])?\s*\)\s*)?(\w+)\s*\(', re.MULTILINE)
+GO_INTERFACE = re.compile(r'^type\s+(\w+)\s+interface\s*\{', re.MULTILINE)
+GO_IMPORT = re.compile(r'^\s*(?:(\w+|\.)\s+)?"([^"]+)"', re.MULTILINE)
+
+# Inputs for functions that parse what they read: complete, and cut off so
+# the function has to read again (and hit the fault)
+SAMPLE_INPUTS = {
+    "json": ('{"id": 1, "name": "test", "items": [1, 2, 3]}\n', '{"id": 1, "name": "test", "ite'),
+    "csv": ("id,name\n1,test\n2,other\n", "id,name\n1,test\n2,oth"),
+    "xml": ("<item><id>1</id><name>test</name></item>\n", "<item><id>1</id><na"),
+    "lines": ("first line\nsecond line\n", "first line\nsecond li"),
+}
+
+# Bytes a writer accepts before failing in the "fails midway" case
+WRITE_LIMIT = 8
+
+STD_IMPORTS = ("context", "errors", "io", "strings", "testing", "testing/iotest")
+
+
+@dataclass
+class GoParam:
+    """A Go function parameter."""
+    
+    name: str
+    type: str
+
+
+@dataclass
+class GoFunction:
+    """A Go function or method declaration with a body."""
+    
+    name: str
+    receiver: Optional[str]
+    line: int
+    params: List[GoParam]
+    results: List[GoParam]
+    body: str          # between the braces
+    start: int         # offset of `func`
+    body_start: int    # offset of the opening brace
+    
+    def end_line(self, code: str) -> int:
+        """Line of the closing brace."""
+        return code.count("\n", 0, self.body_start + len(self.body) + 1) + 1
+
+
+@dataclass
+class FaultSeam:
+    """A Go function or method with I/O, context or dependency seams."""
+    
+    name: str
+    line: int
+    params: List[GoParam]
+    results: int                                    # number of results
+    returns_error: bool
+    receiver: Optional[str] = None
+    dependencies: List[GoParam] = field(default_factory=list)   # interface-typed params
+    closes: List[str] = field(default_factory=list)             # params the function closes
+    input_format: str = "lines"
+    
+    @property
+    def qualified_name(self) -> str:
+        return f"{self.receiver}.{self.name}" if self.receiver else self.name
+    
+    @property
+    def readers(self) -> List[GoParam]:
+        return [p for p in self.params if p.type in READER_TYPES]
+    
+    @property
+    def writers(self) -> List[GoParam]:
+        return [p for p in self.params if p.type in WRITER_TYPES]
+    
+    @property
+    def context(self) -> Optional[GoParam]:
+        return next((p for p in self.params if p.type == CONTEXT_TYPE), None)
+    
+    @property
+    def kinds(self) -> List[str]:
+        kinds = []
+        if self.readers:
+            kinds.append("reader")
+        if self.writers:
+            kinds.append("writer")
+        if self.context:
+            kinds.append("context")
+        if self.dependencies:
+            kinds.append("dependency")
+        return kinds
+    
+    @property
+    def generatable(self) -> bool:
+        """Whether a complete test can be generated: a function whose arguments are all I/O seams."""
+        return (
+            self.receiver is None
+            and self.returns_error
+            and bool(self.readers or self.writers)
+            and len(self.readers) <= 1
+            and len(self.writers) <= 1
+            and all(p.type in READER_TYPES + WRITER_TYPES + (CONTEXT_TYPE,) for p in self.params)
+        )
+
+
+@dataclass
+class GoInterface:
+    """A Go interface with error-returning methods (a dependency seam)."""
+    
+    name: str
+    methods: List[Tuple[str, List[GoParam], List[str]]]   # (name, params, result types)
+    
+    @property
+    def wrapper(self) -> str:
+        return "fault" + self.name[0].upper() + self.name[1:]
+
+
+def find_go_interfaces(code: str) -> List[GoInterface]:
+    """
+    Find interfaces with at least one method returning an error.
+    
+    Args:
+        code: Go source
+        
+    Returns:
+        Interfaces in source order (embedded interfaces are not expanded)
+    """
+    interfaces = []
+    for match in GO_INTERFACE.finditer(code):
+        body = _braced(code, match.end() - 1)
+        methods = []
+        for line in body.splitlines():
+            line = re.sub(r'//.*', "", line).strip()
+            method = re.match(r'^(\w+)\s*\(', line)
+            if not method:
+                continue
+            params_text, rest = _parenthesized(line, method.end() - 1)
+            results = [p.type for p in _parse_params(_strip_parens(rest.strip()))] if rest.strip() else []
+            methods.append((method.group(1), _parse_params(params_text), results))
+        if any(results and results[-1] == "error" for _, _, results in methods):
+            interfaces.append(GoInterface(match.group(1), methods))
+    return interfaces
+
+
+def scan_go_functions(code: str) -> List[GoFunction]:
+    """
+    Parse the signatures and bodies of the functions and methods in Go source.
+    
+    Declarations without a body (assembly stubs) are skipped.
+    
+    Args:
+        code: Go source
+        
+    Returns:
+        Functions in source order
+    """
+    functions = []
+    for match in GO_FUNC.finditer(code):
+        params_text, rest = _parenthesized(code, match.end() - 1)
+        # The body's brace, not one in the results (`interface{}`, `struct{}`)
+        opening = re.search(r'\{[ \t]*(?:\n|//|\S.*\}[ \t]*$)', rest, re.MULTILINE)
+        if opening is None:
+            continue
+        results_text = rest[:opening.start()].strip()
+        brace = match.end() - 1 + len(params_text) + 2 + opening.start()
+        functions.append(GoFunction(
+            name=match.group(2),
+            receiver=match.group(1),
+            line=code.count("\n", 0, match.start()) + 1,
+            params=_parse_params(params_text),
+            results=_parse_params(_strip_parens(results_text)) if results_text else [],
+            body=_braced(code, brace),
+            start=match.start(),
+            body_start=brace,
+        ))
+    return functions
+
+
+def find_fault_seams(code: str, interfaces: Optional[List[str]] = None) -> List[FaultSeam]:
+    """
+    Find functions and methods that take readers, writers, contexts or dependencies.
+    
+    Args:
+        code: Go source
+        interfaces: Names of the package's dependency interfaces
+            (default: those declared in code)
+            
+    Returns:
+        Seams in source order
+    """
+    if interfaces is None:
+        interfaces = [i.name for i in find_go_interfaces(code)]
+    
+    seams = []
+    for function in scan_go_functions(code):
+        dependencies = [p for p in function.params if p.type.lstrip("*") in interfaces]
+        seam = FaultSeam(
+            name=function.name,
+            line=function.line,
+            params=function.params,
+            results=len(function.results),
+            returns_error=bool(function.results) and function.results[-1].type == "error",
+            receiver=function.receiver,
+            dependencies=dependencies,
+        )
+        if not seam.kinds:
+            continue
+        
+        seam.closes = [p.name for p in function.params if re.search(rf'\b{re.escape(p.name)}\.Close\(\)', function.body)]
+        seam.input_format = next((f for f in ("json", "csv", "xml") if re.search(rf'\b{f}\.', function.body)), "lines")
+        seams.append(seam)
+    return seams
+
+
+def describe_fault_seams(seams: List[FaultSeam], interfaces: List[GoInterface]) -> str:
+    """
+    Describe seams for the fault-injection prompt block.
+    
+    Args:
+        seams: Seams found in the code
+        interfaces: Dependency interfaces (their wrappers are generated)
+        
+    Returns:
+        One line per seam and wrapper
+    """
+    lines = []
+    for seam in seams:
+        if seam.generatable:
+            continue  # Covered by the generated tests
+        seam_params = ", ".join(f"{p.name} {p.type}" for p in seam.params if p.type in READER_TYPES + WRITER_TYPES + (CONTEXT_TYPE,))
+        seam_params = ", ".join(filter(None, [seam_params] + [f"{p.name} {p.type}" for p in seam.dependencies]))
+        closes = f"; closes {', '.join(seam.closes)}" if seam.closes else ""
+        lines.append(f"- {seam.qualified_name} (line {seam.line}): {seam_params}{closes}")
+    for interface in interfaces:
+        methods = ", ".join(name for name, _, results in interface.methods if results and results[-1] == "error")
+        lines.append(
+            f"- {interface.wrapper}{{{interface.name}: real, failOn: n, err: errInjected}} "
+            f"fails call n of {methods}"
+        )
+    return "\n".join(lines)
+
+
+def generate_go_fault_test(
+    package: str,
+    seams: List[FaultSeam],
+    interfaces: List[GoInterface],
+    imports: Optional[Dict[str, str]] = None
+) -> str:
+    """
+    Generate the Go fault-injection test file for a package.
+    
+    Args:
+        package: Go package name
+        seams: Seams found in the package (complete tests for the generatable ones)
+        interfaces: Dependency interfaces to generate `fault<Interface>` wrappers for
+        imports: Package qualifier -> import path of the package's own imports
+            (for types in interface methods)
+            
+    Returns:
+        Go source
+    """
+    tests = "".join(_fault_test(seam) for seam in seams if seam.generatable)
+    wrappers = "".join(_fault_wrapper(interface) for interface in interfaces)
+    body = tests + GO_FAULT_HELPERS + wrappers
+    
+    needed = [path for path in STD_IMPORTS if re.search(rf'\b{path.split("/")[-1]}\.', body)]
+    for qualifier, path in sorted((imports or {}).items()):
+        if path not in needed and re.search(rf'\b{re.escape(qualifier)}\.', wrappers):
+            alias = "" if path.split("/")[-1] == qualifier else f"{qualifier} "
+            needed.append(f"{alias}{path}" if alias else path)
+    import_block = "\n".join(f'\t{_quote_import(path)}' for path in needed)
+    
+    return (
+        GO_FAULT_TEST
+        .replace("{package}", package)
+        .replace("{imports}", import_block)
+        .replace("{body}", body)
+    )
+
+
+def write_go_fault_test(package_dir: str) -> Optional[Path]:
+    """
+    Write `testgen_fault_test.go` for the seams in a package directory.
+    
+    Args:
+        package_dir: Go package directory
+        
+    Returns:
+        Path of the written file, or None when the package has nothing to inject faults into
+    """
+    package = None
+    sources = []
+    imports: Dict[str, str] = {}
+    for source in sorted(Path(package_dir).glob("*.go")):
+        if source.name.endswith("_test.go"):
+            continue
+        code = source.read_text(encoding='utf-8', errors='ignore')
+        match = GO_PACKAGE.search(code)
+        if match and package is None:
+            package = match.group(1)
+        sources.append(code)
+        imports.update(_go_imports(code))
+    
+    interfaces = [i for code in sources for i in find_go_interfaces(code)]
+    names = [i.name for i in interfaces]
+    seams = [s for code in sources for s in find_fault_seams(code, names)]
+    
+    if package is None or not (interfaces or any(s.generatable for s in seams)):
+        return None
+    
+    path = Path(package_dir) / FAULT_TEST_FILE
+    path.write_text(generate_go_fault_test(package, seams, interfaces, imports), encoding='utf-8')
+    return path
+
+
+GO_FAULT_TEST = """// Code generated by testgen faults. Fault-injection tests: readers that fail
+// midway, writers that fail or short-write, contexts cancelled mid-operation,
+// and fault<Interface> wrappers that fail a dependency's Nth call.
+
+package {package}
+
+import (
+{imports}
+)
+{body}"""
+
+GO_FAULT_HELPERS = """
+var errInjected = errors.New("testgen: injected fault")
+
+// testgenFaultWriter accepts limit bytes, then fails every write (or
+// short-writes half of it with io.ErrShortWrite).
+type testgenFaultWriter struct {
+	limit     int
+	short     bool
+	written   int
+	attempted int
+}
+
+func (w *testgenFaultWriter) Write(p []byte) (int, error) {
+	w.attempted += len(p)
+	if w.written+len(p) <= w.limit {
+		w.written += len(p)
+		return len(p), nil
+	}
+	if w.short {
+		w.written += len(p) / 2
+		return len(p) / 2, io.ErrShortWrite
+	}
+	n := w.limit - w.written
+	w.written = w.limit
+	return n, errInjected
+}
+
+// testgenCloser records whether the code under test closed it.
+type testgenCloser struct {
+	io.Reader
+	io.Writer
+	closed bool
+}
+
+func (c *testgenCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+// testgenCancelReader returns data on the first read, then cancels the
+// context and fails with its error, like a request body whose request
+// was cancelled.
+type testgenCancelReader struct {
+	data   io.Reader
+	ctx    context.Context
+	cancel context.CancelFunc
+	reads  int
+}
+
+func (r *testgenCancelReader) Read(p []byte) (int, error) {
+	r.reads++
+	if r.reads == 1 {
+		return r.data.Read(p)
+	}
+	r.cancel()
+	return 0, r.ctx.Err()
+}
+
+// testgenCancelWriter accepts the first write, then cancels the context
+// and fails with its error.
+type testgenCancelWriter struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	writes int
+}
+
+func (w *testgenCancelWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes == 1 {
+		return len(p), nil
+	}
+	w.cancel()
+	return 0, w.ctx.Err()
+}
+"""
+
+
+def _fault_test(seam: FaultSeam) -> str:
+    """Subtests injecting every applicable fault into one function."""
+    complete, partial = SAMPLE_INPUTS[seam.input_format]
+    reader = seam.readers[0] if seam.readers else None
+    writer = seam.writers[0] if seam.writers else None
+    cases = []
+    
+    if reader:
+        cases.append(("reader fails immediately", "iotest.ErrReader(errInjected)", None, "errInjected", None))
+        midway = f"io.MultiReader(strings.NewReader({_go_string(partial)}), iotest.ErrReader(errInjected))"
+        cases.append(("reader fails midway", midway, None, "errInjected", None))
+        cases.append(("reader fails midway with one-byte reads", f"iotest.OneByteReader({midway})", None, "errInjected", None))
+        cases.append(("reader times out", f"iotest.TimeoutReader(strings.NewReader({_go_string(partial)}))", None, "iotest.ErrTimeout", None))
+    
+    if writer:
+        source = f"strings.NewReader({_go_string(complete)})" if reader else None
+        cases.append(("writer fails", source, "&testgenFaultWriter{}", "errInjected", "dst.attempted == 0"))
+        cases.append((
+            "writer fails midway", source, f"&testgenFaultWriter{{limit: {WRITE_LIMIT}}}", "errInjected",
+            f"dst.attempted <= {WRITE_LIMIT}"
+        ))
+        cases.append(("short write", source, "&testgenFaultWriter{short: true}", "io.ErrShortWrite", "dst.attempted == 0"))
+    
+    if seam.context and reader:
+        cases.append(("context cancelled mid-read", "cancel", None, "context.Canceled", None))
+    elif seam.context and writer:
+        cases.append(("context cancelled mid-write", None, "cancel", "context.Canceled", None))
+    
+    subtests = "".join(_fault_subtest(seam, reader, writer, complete, *case) for case in cases)
+    test_name = "TestFault" + seam.name[0].upper() + seam.name[1:]
+    return f"\nfunc {test_name}(t *testing.T) {{{subtests}}}\n"
+
+
+def _fault_subtest(
+    seam: FaultSeam,
+    reader: Optional[GoParam],
+    writer: Optional[GoParam],
+    complete: str,
+    name: str,
+    source: Optional[str],
+    sink: Optional[str],
+    want: str,
+    skip_if: Optional[str]
+) -> str:
+    setup = []
+    if seam.context:
+        setup.append("ctx, cancel := context.WithCancel(context.Background())")
+        setup.append("defer cancel()")
+    
+    if reader:
+        if source == "cancel":
+            source = f"&testgenCancelReader{{data: strings.NewReader({_go_string(complete)}), ctx: ctx, cancel: cancel}}"
+        setup.append(f"src := &testgenCloser{{Reader: {source}}}" if reader.type == "io.ReadCloser" else f"src := {source}")
+    if writer:
+        if sink == "cancel":
+            sink = "&testgenCancelWriter{ctx: ctx, cancel: cancel}"
+        elif sink is None:
+            sink = "io.Discard"
+        setup.append(f"dst := {sink}")
+        if writer.type == "io.WriteCloser":
+            setup.append("out := &testgenCloser{Writer: dst}")
+    
+    args = []
+    for param in seam.params:
+        if param.type in READER_TYPES:
+            args.append("src")
+        elif param.type in WRITER_TYPES:
+            args.append("out" if param.type == "io.WriteCloser" else "dst")
+        else:
+            args.append("ctx")
+    
+    blanks = "_, " * (seam.results - 1)
+    lines = setup + [f"{blanks}err := {seam.name}({', '.join(args)})"]
+    if skip_if:
+        lines += [f"if {skip_if} {{", '\tt.Skip("no output to fail for the sample input")', "}"]
+    lines += [
+        f"if !errors.Is(err, {want}) {{",
+        f'\tt.Errorf("error not propagated: got %v, want %v", err, {want})',
+        "}",
+    ]
+    if reader and reader.type == "io.ReadCloser" and reader.name in seam.closes:
+        lines += ["if !src.closed {", '\tt.Error("reader not closed after the failure")', "}"]
+    if writer and writer.type == "io.WriteCloser" and writer.name in seam.closes:
+        lines += ["if !out.closed {", '\tt.Error("writer not closed after the failure")', "}"]
+    
+    body = "".join(f"\t\t{line}\n" for line in lines)
+    return f"\n\tt.Run({_go_string(name)}, func(t *testing.T) {{\n{body}\t}})\n"
+
+
+def _fault_wrapper(interface: GoInterface) -> str:
+    """`fault<Interface>`: embeds the real dependency and fails call failOn of its error-returning methods."""
+    wrapper = interface.wrapper
+    methods = []
+    for name, params, results in interface.methods:
+        if not results or results[-1] != "error":
+            continue
+        names = [f"p{i}" for i in range(len(params))]
+        signature = ", ".join(f"{n} {p.type}" for n, p in zip(names, params))
+        call_args = ", ".join(n + ("..." if p.type.startswith("...") else "") for n, p in zip(names, params))
+        result_list = results[0] if len(results) == 1 else "(" + ", ".join(results) + ")"
+        zeros = [f"var r{i} {t}" for i, t in enumerate(results[:-1])]
+        returns = ", ".join([f"r{i}" for i in range(len(results) - 1)] + ["f.err"])
+        lines = [
+            "f.calls++",
+            "if f.calls == f.failOn {",
+            *(f"\t{z}" for z in zeros),
+            f"\treturn {returns}",
+            "}",
+            f"return f.{interface.name}.{name}({call_args})",
+        ]
+        body = "".join(f"\t{line}\n" for line in lines)
+        methods.append(f"\nfunc (f *{wrapper}) {name}({signature}) {result_list} {{\n{body}}}\n")
+    
+    return (
+        f"\n// {wrapper} wraps a {interface.name} and fails call number failOn\n"
+        f"// (counted across its error-returning methods) with err.\n"
+        f"type {wrapper} struct {{\n"
+        f"\t{interface.name}\n"
+        f"\tfailOn int\n"
+        f"\tcalls  int\n"
+        f"\terr    error\n"
+        f"}}\n"
+        + "".join(methods)
+    )
+
+
+def _parse_params(text: str) -> List[GoParam]:
+    """Parse a Go parameter or result list (`a, b int, w io.Writer` or `int, error`)."""
+    items = [item.strip() for item in _split_top_level(text) if item.strip()]
+    if not items:
+        return []
+    
+    # Either every parameter is named or none is
+    named = any(re.match(r'^\w+\s+\S', item) and not item.startswith(("func", "chan", "map", "struct", "interface")) for item in items)
+    if not named:
+        return [GoParam(f"p{i}", item) for i, item in enumerate(items)]
+    
+    params: List[GoParam] = []
+    pending: List[str] = []
+    for item in items:
+        parts = item.split(None, 1)
+        if len(parts) == 1:
+            pending.append(parts[0])
+            continue
+        for name in pending + [parts[0]]:
+            params.append(GoParam(name, parts[1].strip()))
+        pending = []
+    return params
+
+
+def _split_top_level(text: str) -> List[str]:
+    parts = []
+    depth = 0
+    start = 0
+    for index, char in enumerate(text):
+        if char in "([{":
+            depth += 1
+        elif char in ")]}":
+            depth -= 1
+        elif char == "," and depth == 0:
+            parts.append(text[start:index])
+            start = index + 1
+    parts.append(text[start:])
+    return parts
+
+
+def _parenthesized(code: str, open_index: int) -> Tuple[str, str]:
+    """Contents of the parentheses opening at open_index, and the rest of the code."""
+    depth = 0
+    for index in range(open_index, len(code)):
+        if code[index] == "(":
+            depth += 1
+        elif code[index] == ")":
+            depth -= 1
+            if depth == 0:
+                return code[open_index + 1:index], code[index + 1:]
+    return code[open_index + 1:], ""
+
+
+def _braced(code: str, open_index: int) -> str:
+    """Contents of the braces opening at open_index (string and comment aware)."""
+    depth = 0
+    index = open_index
+    while index < len(code):
+        char = code[index]
+        if char in "\"'`":
+            end = code.find(char, index + 1)
+            while char != "`" and end != -1 and code[end - 1] == "\\" and code[end - 2] != "\\":
+                end = code.find(char, end + 1)
+            index = end if end != -1 else len(code)
+        elif code.startswith("//", index):
+            index = code.find("\n", index)
+            index = index if index != -1 else len(code)
+        elif char == "{":
+            depth += 1
+        elif char == "}":
+            depth -= 1
+            if depth == 0:
+                return code[open_index + 1:index]
+        index += 1
+    return code[open_index + 1:]
+
+
+def _strip_parens(text: str) -> str:
+    text = text.strip()
+    return text[1:-1] if text.startswith("(") and text.endswith(")") else text
+
+
+def _go_imports(code: str) -> Dict[str, str]:
+    """Qualifier -> import path for a Go file's imports."""
+    block = re.search(r'^import\s*\((.*?)\)', code, re.MULTILINE | re.DOTALL)
+    text = block.group(1) if block else "\n".join(re.findall(r'^import\s+(.*)$', code, re.MULTILINE))
+    imports = {}
+    for alias, path in GO_IMPORT.findall(text):
+        if alias in ("_", "."):
+            continue
+        imports[alias or re.sub(r'^v\d+$', "", path.split("/")[-1]) or path.split("/")[-2]] = path
+    return imports
+
+
+def _quote_import(spec: str) -> str:
+    alias, _, path = spec.rpartition(" ")
+    return f'{alias} "{path}"' if alias else f'"{path}"'
+
+
+def _go_string(text: str) -> str:
+    """Go interpreted string literal."""
+    return '"' + text.replace("\\", "\\\\").replace('"', '\\"').replace("\n", "\\n") + '"'
@@ -8,9 +8,12 @@ creating test directories, and following naming conventions.
 import os
 from pathlib import Path
 from typing import Optional, List
-from dataclasses import dataclass
+from dataclasses import dataclass, field
 from datetime import datetime
 
+from .go_trial import trial_run
+from .typescript_types import TS_EXTENSIONS, typecheck_test_file
+
 
 @dataclass
 class WriteResult:
@@ -21,6 +24,7 @@ class WriteResult:
     created_new: bool
     lines_written: int
     error: Optional[str] = None
+    diagnostics: List[str] = field(default_factory=list)  # Compiler errors of rejected tests
     
     def __str__(self) -> str:
         """String representation."""
@@ -51,7 +55,9 @@ class TestFileWriter:
         self,
         output_dir: str = "tests",
         add_header: bool = True,
-        backup_existing: bool = True
+        backup_existing: bool = True,
+        typecheck: bool = True,
+        trial: bool = True
     ):
         """
         Initialize test file writer.
@@ -60,10 +66,16 @@ class TestFileWriter:
             output_dir: Output directory for tests (default: "tests")
             add_header: Whether to add file header comments
             backup_existing: Whether to backup existing files before overwriting
+            typecheck: Reject TypeScript tests that fail `tsc --noEmit` (when
+                the project has TypeScript installed)
+            trial: Build and run Go tests through `go test -overlay` first
+                and reject them unless they pass (when Go is installed)
         """
         self.output_dir = Path(output_dir)
         self.add_header = add_header
         self.backup_existing = backup_existing
+        self.typecheck = typecheck
+        self.trial = trial
     
     def save_test_file(
         self,
@@ -105,23 +117,57 @@ class TestFileWriter:
                     error="Either output_path or source_file must be provided"
                 )
             
+            # Add header if requested
+            if self.add_header:
+                code = self._add_file_header(code, source_file, file_path)
+            
+            # Reject Go tests that don't build or pass, before anything is written
+            if self.trial and file_path.name.endswith("_test.go"):
+                run = trial_run({file_path: code})
+                if run.checked and not run.ok:
+                    return WriteResult(
+                        file_path=file_path,
+                        success=False,
+                        created_new=False,
+                        lines_written=0,
+                        error=f"Generated test fails its go test trial run ({len(run.problems)} problems)",
+                        diagnostics=run.problems
+                    )
+            
             # Auto-create directory (Task 41 requirement)
             file_path.parent.mkdir(parents=True, exist_ok=True)
             
             # Check if file exists
             created_new = not file_path.exists()
+            previous = None if created_new else file_path.read_text(encoding='utf-8')
             
             # Backup existing file if needed
+            backup_path = None
             if not created_new and self.backup_existing:
-                self._backup_file(file_path)
-            
-            # Add header if requested
-            if self.add_header:
-                code = self._add_file_header(code, source_file)
+                backup_path = self._backup_file(file_path)
             
             # Write file
             file_path.write_text(code, encoding='utf-8')
             
+            # Reject TypeScript tests that don't compile (checked in place so imports resolve)
+            if self.typecheck and file_path.name.endswith(TS_EXTENSIONS):
+                check = typecheck_test_file(str(file_path))
+                if not check.ok:
+                    if previous is None:
+                        file_path.unlink()
+                    else:
+                        file_path.write_text(previous, encoding='utf-8')
+                    if backup_path is not None:
+                        backup_path.unlink(missing_ok=True)
+                    return WriteResult(
+                        file_path=file_path,
+                        success=False,
+                        created_new=False,
+                        lines_written=0,
+                        error=f"Generated test fails tsc --noEmit ({len(check.diagnostics)} errors)",
+                        diagnostics=check.diagnostics
+                    )
+            
             # Count lines
             lines_written = len(code.split('\n'))
             
@@ -145,7 +191,8 @@ class TestFileWriter:
         """
         Get test file path from source file (Task 41 requirement).
         
-        Uses naming convention: test_<original_file>.py
+        Uses naming convention: test_<original_file>.py (Go: <original_file>_test.go
+        next to the source, in the package it tests)
         
         Args:
             source_file: Source file path
@@ -160,6 +207,10 @@ class TestFileWriter:
         """
         source_path = Path(source_file)
         
+        # Go tests live in the package they test
+        if source_path.suffix == ".go":
+            return source_path.with_name(f"{source_path.stem}_test.go")
+        
         # Get base name without extension
         base_name = source_path.stem
         
@@ -181,17 +232,47 @@ class TestFileWriter:
         else:
             return self.output_dir / test_name
     
-    def _add_file_header(self, code: str, source_file: Optional[str] = None) -> str:
+    def _add_file_header(
+        self,
+        code: str,
+        source_file: Optional[str] = None,
+        file_path: Optional[Path] = None
+    ) -> str:
         """
         Add header comment to test file.
         
         Args:
             code: Test code
             source_file: Source file path
-            
+            file_path: Test file path (JavaScript/TypeScript files get a block
+                comment, Go files line comments)
+                
         Returns:
             Code with header
         """
+        if file_path is not None and file_path.name.endswith(TS_EXTENSIONS + (".js", ".jsx", ".mjs", ".cjs")):
+            lines = ['/**', ' * Auto-generated test file.', ' *']
+            if source_file:
+                lines.append(f' * Tests for: {source_file}')
+            lines.extend([
+                f' * Generated: {datetime.now().strftime("%Y-%m-%d %H:%M:%S")}',
+                ' * Generator: TestGen AI',
+                ' */',
+                ''
+            ])
+            return '\n'.join(lines) + '\n' + code
+        
+        if file_path is not None and file_path.suffix == ".go":
+            lines = ['// Auto-generated test file.', '//']
+            if source_file:
+                lines.append(f'// Tests for: {source_file}')
+            lines.extend([
+                f'// Generated: {datetime.now().strftime("%Y-%m-%d %H:%M:%S")}',
+                '// Generator: TestGen AI',
+                ''
+            ])
+            return '\n'.join(lines) + '\n' + code
+        
         lines = [
             '"""',
             'Auto-generated test file.',
@@ -212,23 +293,30 @@ class TestFileWriter:
         
         return '\n'.join(lines) + '\n' + code
     
-    def _backup_file(self, file_path: Path) -> None:
+    def _backup_file(self, file_path: Path) -> Optional[Path]:
         """
         Create backup of existing file.
         
         Args:
             file_path: File to backup
+            
+        Returns:
+            Backup path, or None if there was nothing to back up
         """
         if not file_path.exists():
-            return
+            return None
         
         # Create backup with timestamp
         timestamp = datetime.now().strftime("%Y%m%d_%H%M%S")
         backup_name = f"{file_path.stem}.backup_{timestamp}{file_path.suffix}"
+        if file_path.suffix == ".go":
+            # A .go backup would be compiled into the package alongside the new file
+            backup_name = f"{file_path.name}.backup_{timestamp}"
         backup_path = file_path.parent / backup_name
         
         # Copy file
         backup_path.write_text(file_path.read_text(encoding='utf-8'), encoding='utf-8')
+        return backup_path
     
     def save_batch(
         self,
@@ -0,0 +1,582 @@
+"""
+Generated Go Benchmarks for Hot Paths.
+
+Writes `BenchmarkXxx` functions for performance-sensitive Go functions,
+either flagged by the user (`testgen bench --func`, or a
+`//testgen:benchmark` comment above the function) or found by
+CPU-profiling the package's slow tests (`go tool pprof -top -cum`):
+
+- sub-benchmarks across input sizes (`b.Run("n=1000", ...)`)
+- `b.ReportAllocs()`, and `b.ResetTimer()` after the inputs are built
+- results assigned to package-level sinks, so the compiler can't
+  eliminate the work
+
+Functions whose parameters can all be built from a size get complete
+benchmarks in `testgen_bench_test.go`; the rest (methods, parameters of
+the package's own types) are described for the LLM prompt.
+"""
+
+import re
+import subprocess
+import tempfile
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Callable, Dict, Iterable, List, Optional, Tuple
+
+from .fault_injection import (
+    GoParam, _go_imports, _go_string, _quote_import, scan_go_functions,
+)
+from .load_test import GO_PACKAGE
+from .run_store import TestHistory
+
+
+BENCH_TEST_FILE = "testgen_bench_test.go"
+BENCH_DIRECTIVE = "//testgen:benchmark"
+
+INPUT_SIZES = (10, 100, 1000)
+
+# Parameters built from the size n ({format} is the input format the function parses)
+SIZED_INPUTS = {
+    "string": "testgenBenchInput({format}, n)",
+    "[]byte": "[]byte(testgenBenchInput({format}, n))",
+    "io.Reader": "bytes.NewReader(data)",
+    "[]string": "testgenBenchStrings(n)",
+    "[]int": "rand.New(rand.NewSource(1)).Perm(n)",
+    "[]float64": "testgenBenchFloats(n)",
+    "map[string]int": "testgenBenchMap(n)",
+    "int": "n",
+    "int64": "int64(n)",
+    "int32": "int32(n)",
+    "uint": "uint(n)",
+    "uint64": "uint64(n)",
+}
+# Parameters that don't scale
+FIXED_INPUTS = {
+    "bool": "true",
+    "float64": "1.5",
+    "context.Context": "context.Background()",
+}
+
+# Identifiers the generated benchmark bodies use themselves
+RESERVED_NAMES = {"b", "n", "i", "data", "err", "_"}
+
+# `go tool pprof -top -cum` line: flat flat% sum% cum cum% name
+PPROF_LINE = re.compile(r'^\s*[\d.]+\w*\s+[\d.]+%\s+[\d.]+%\s+[\d.]+\w*\s+([\d.]+)%\s+(.+?)\s*$')
+TEST_FUNCTION = re.compile(r'^(?:Test|Benchmark|Fuzz|Example)|^(?:init|main)$')
+GENERATED_BENCHMARK = re.compile(r'^// Benchmark\w+ benchmarks ([\w.]+)', re.MULTILINE)
+
+STD_IMPORTS = ("bytes", "context", "fmt", "math/rand", "strings", "testing")
+
+
+@dataclass
+class BenchmarkTarget:
+    """A Go function or method to benchmark."""
+    
+    name: str
+    line: int
+    params: List[GoParam]
+    results: List[GoParam]
+    receiver: Optional[str] = None
+    input_format: str = "lines"
+    flagged: bool = False                 # --func or //testgen:benchmark
+    cpu_percent: Optional[float] = None   # cumulative CPU share in the slow tests' profile
+    end_line: int = 0                     # line of the closing brace
+    
+    @property
+    def qualified_name(self) -> str:
+        return f"{self.receiver}.{self.name}" if self.receiver else self.name
+    
+    @property
+    def benchmark_name(self) -> str:
+        return "Benchmark" + "".join(part[0].upper() + part[1:] for part in self.qualified_name.split("."))
+    
+    @property
+    def reason(self) -> str:
+        if self.cpu_percent is not None:
+            return f"{self.cpu_percent:.1f}% of CPU in slow tests"
+        return "flagged"
+    
+    @property
+    def generatable(self) -> bool:
+        """Whether a complete benchmark can be generated: a function whose arguments all come from a size."""
+        return (
+            self.receiver is None
+            and all(p.type in SIZED_INPUTS or p.type in FIXED_INPUTS for p in self.params)
+            and any(p.type in SIZED_INPUTS for p in self.params)
+            and sum(p.type == "io.Reader" for p in self.params) <= 1
+        )
+
+
+def find_go_functions(code: str) -> List[BenchmarkTarget]:
+    """
+    Find the functions and methods of a Go source file.
+    
+    Args:
+        code: Go source
+        
+    Returns:
+        Functions in source order, `flagged` if preceded by `//testgen:benchmark`
+    """
+    return [
+        BenchmarkTarget(
+            name=function.name,
+            line=function.line,
+            params=function.params,
+            results=function.results,
+            receiver=function.receiver,
+            input_format=next((f for f in ("json", "csv") if re.search(rf'\b{f}\.', function.body)), "lines"),
+            flagged=BENCH_DIRECTIVE in _doc_comment(code, function.start),
+            end_line=function.end_line(code),
+        )
+        for function in scan_go_functions(code)
+    ]
+
+
+def parse_pprof_top(output: str, import_path: str, min_percent: float = 5.0, limit: int = 5) -> Dict[str, float]:
+    """
+    Find a package's hot paths in `go tool pprof -top -cum` output.
+    
+    Args:
+        output: pprof output
+        import_path: Import path of the package
+        min_percent: Cumulative CPU share that makes a function hot
+        limit: Most hot paths returned
+        
+    Returns:
+        Qualified name (`Parse`, `Store.Get`) -> cumulative CPU %, hottest first
+    """
+    prefix = import_path + "."
+    hot: Dict[str, float] = {}
+    for line in output.splitlines():
+        match = PPROF_LINE.match(line)
+        if not match or not match.group(2).startswith(prefix):
+            continue
+        symbol = match.group(2)[len(prefix):].replace(" (inline)", "")
+        method = re.match(r'^\(\*?(\w+)(?:\[[^\]]*\])?\)\.(\w+)', symbol)
+        if method:
+            name = f"{method.group(1)}.{method.group(2)}"
+        else:
+            function = re.match(r'^(\w+)', symbol)   # closures (Parse.func1) count for their function
+            if not function or TEST_FUNCTION.search(function.group(1)):
+                continue
+            name = function.group(1)
+        percent = float(match.group(1))
+        if percent >= min_percent:
+            hot[name] = max(hot.get(name, 0.0), percent)
+    return dict(sorted(hot.items(), key=lambda item: -item[1])[:limit])
+
+
+def slow_tests(history: Dict[str, TestHistory], import_path: str, min_duration: float = 0.1) -> List[str]:
+    """
+    Top-level tests of a package that are slow in the stored runs.
+    
+    Args:
+        history: `RunStore.test_history()` of Go runs
+        import_path: Import path of the package
+        min_duration: Average seconds that makes a test slow
+        
+    Returns:
+        Test names, slowest first
+    """
+    durations = {
+        entry.name: entry.average_duration
+        for entry in history.values()
+        if entry.suite == import_path and "/" not in entry.name
+        and entry.average_duration is not None and entry.average_duration >= min_duration
+    }
+    return sorted(durations, key=lambda name: -durations[name])
+
+
+def go_import_path(package_dir: str) -> Optional[str]:
+    """Import path of the Go package in a directory (`go list`)."""
+    try:
+        completed = subprocess.run(
+            ["go", "list", "-f", "{{.ImportPath}}", "."],
+            cwd=package_dir, capture_output=True, text=True, timeout=120
+        )
+    except (OSError, subprocess.TimeoutExpired):
+        return None
+    path = completed.stdout.strip()
+    return path if completed.returncode == 0 and path and not path.startswith("_") else None
+
+
+def profile_hot_paths(
+    package_dir: str,
+    tests: Optional[List[str]] = None,
+    min_percent: float = 5.0,
+    limit: int = 5,
+    timeout: int = 600
+) -> Dict[str, float]:
+    """
+    CPU-profile a package's tests and find its hot paths.
+    
+    Args:
+        package_dir: Go package directory
+        tests: Top-level tests to profile (default: all)
+        min_percent: Cumulative CPU share that makes a function hot
+        limit: Most hot paths returned
+        timeout: Timeout of the profiled test run in seconds
+        
+    Returns:
+        Qualified name -> cumulative CPU %, hottest first (empty if nothing could be profiled)
+    """
+    import_path = go_import_path(package_dir)
+    if import_path is None:
+        return {}
+    
+    run = "^(" + "|".join(re.escape(t) for t in tests) + ")$" if tests else "."
+    with tempfile.TemporaryDirectory(prefix="testgen-bench-") as tmp:
+        profile = Path(tmp) / "cpu.out"
+        try:
+            # -o keeps the test binary -cpuprofile leaves behind out of the package
+            subprocess.run(
+                ["go", "test", "-run", run, "-count=1", "-cpuprofile", str(profile), "-o", str(Path(tmp) / "pkg.test"), "."],
+                cwd=package_dir, capture_output=True, text=True, timeout=timeout
+            )
+            if not profile.exists():
+                return {}
+            top = subprocess.run(
+                ["go", "tool", "pprof", "-top", "-cum", "-nodecount=200", str(profile)],
+                cwd=package_dir, capture_output=True, text=True, timeout=120
+            )
+        except (OSError, subprocess.TimeoutExpired):
+            return {}
+    return parse_pprof_top(top.stdout, import_path, min_percent, limit)
+
+
+def select_benchmark_targets(
+    package_dir: str,
+    names: Iterable[str] = (),
+    hot: Optional[Dict[str, float]] = None
+) -> List[BenchmarkTarget]:
+    """
+    Functions of a package to benchmark.
+    
+    Flagged functions (`names` or `//testgen:benchmark`), hot paths and the
+    targets of benchmarks generated before; functions that already have a
+    hand-written `Benchmark<Name>` are left out.
+    
+    Args:
+        package_dir: Go package directory
+        names: Functions flagged by the user (`Name` or `Type.Method`)
+        hot: Hot paths found by profiling (see `profile_hot_paths`)
+        
+    Returns:
+        Targets in source order
+    """
+    hot = hot or {}
+    names = set(names)
+    existing = set()
+    previous = set()
+    targets = []
+    for source in sorted(Path(package_dir).glob("*.go")):
+        code = source.read_text(encoding='utf-8', errors='ignore')
+        if source.name == BENCH_TEST_FILE:
+            previous.update(GENERATED_BENCHMARK.findall(code))
+        elif source.name.endswith("_test.go"):
+            existing.update(re.findall(r'^func\s+(Benchmark\w+)\s*\(', code, re.MULTILINE))
+        else:
+            targets.extend(find_go_functions(code))
+    
+    selected = []
+    for target in targets:
+        target.cpu_percent = hot.get(target.qualified_name)
+        target.flagged = target.flagged or target.qualified_name in names
+        wanted = target.flagged or target.cpu_percent is not None or target.qualified_name in previous
+        if wanted and target.benchmark_name not in existing:
+            selected.append(target)
+    return selected
+
+
+def describe_hot_paths(targets: List[BenchmarkTarget]) -> str:
+    """
+    Describe targets without generated benchmarks for the benchmark prompt block.
+    
+    Args:
+        targets: Targets to benchmark
+        
+    Returns:
+        One line per target
+    """
+    lines = []
+    for target in targets:
+        if target.generatable:
+            continue  # Covered by the generated benchmarks
+        params = ", ".join(f"{p.name} {p.type}" for p in target.params)
+        results = ", ".join(p.type for p in target.results)
+        results = f" ({results})" if len(target.results) > 1 else f" {results}" if results else ""
+        lines.append(
+            f"- {target.benchmark_name}: {target.qualified_name}({params}){results} "
+            f"(line {target.line}, {target.reason})"
+        )
+    return "\n".join(lines)
+
+
+def generate_go_benchmarks(
+    package: str,
+    targets: List[BenchmarkTarget],
+    imports: Optional[Dict[str, str]] = None
+) -> str:
+    """
+    Generate the Go benchmark file for a package.
+    
+    Args:
+        package: Go package name
+        targets: Targets (complete benchmarks for the generatable ones)
+        imports: Package qualifier -> import path of the package's own imports
+            (for types of the sinks)
+            
+    Returns:
+        Go source
+    """
+    generatable = [t for t in targets if t.generatable]
+    benchmarks = "".join(_benchmark(target) for target in generatable)
+    helpers = _input_helpers(benchmarks)
+    if any(p.type == "error" for t in generatable for p in t.results):
+        helpers = "\nvar testgenSinkErr error\n" + helpers
+    return _test_file(GO_BENCH_TEST, package, benchmarks, helpers, imports)
+
+
+def write_go_benchmarks(package_dir: str, targets: List[BenchmarkTarget]) -> Optional[Path]:
+    """
+    Write `testgen_bench_test.go` for the generatable targets of a package.
+    
+    Args:
+        package_dir: Go package directory
+        targets: Targets (see `select_benchmark_targets`)
+        
+    Returns:
+        Path of the written file, or None when none of the targets is generatable
+    """
+    if not any(t.generatable for t in targets):
+        return None
+    return _write_test_file(
+        package_dir, BENCH_TEST_FILE,
+        lambda package, imports: generate_go_benchmarks(package, targets, imports)
+    )
+
+
+GO_BENCH_TEST = """// Code generated by testgen bench. Benchmarks for hot paths:
+//
+//	go test -run '^$' -bench . -benchmem .
+//
+// Each benchmark runs sub-benchmarks across input sizes and assigns its
+// results to package-level sinks, so the compiler can't eliminate the work.
+
+package {package}
+
+import (
+{imports}
+)
+
+var testgenBenchSizes = []int{{sizes}}
+{body}"""
+
+GO_BENCH_HELPERS = {
+    "testgenBenchInput": """
+// testgenBenchInput returns n records in the format the function parses:
+// a JSON array, CSV rows under a header, or lines of text.
+func testgenBenchInput(format string, n int) string {
+	var sb strings.Builder
+	switch format {
+	case "json":
+		sb.WriteString("[")
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			fmt.Fprintf(&sb, `{"id": %d, "name": "item %d"}`, i, i)
+		}
+		sb.WriteString("]")
+	case "csv":
+		sb.WriteString("id,name\\n")
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&sb, "%d,item %d\\n", i, i)
+		}
+	default:
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&sb, "line %d of the sample input\\n", i)
+		}
+	}
+	return sb.String()
+}
+""",
+    "testgenBenchStrings": """
+func testgenBenchStrings(n int) []string {
+	s := make([]string, n)
+	for i := range s {
+		s[i] = fmt.Sprintf("item %d", i)
+	}
+	return s
+}
+""",
+    "testgenBenchFloats": """
+func testgenBenchFloats(n int) []float64 {
+	r := rand.New(rand.NewSource(1))
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = r.Float64() * 1000
+	}
+	return s
+}
+""",
+    "testgenBenchMap": """
+func testgenBenchMap(n int) map[string]int {
+	m := make(map[string]int, n)
+	for i := 0; i < n; i++ {
+		m[fmt.Sprintf("key %d", i)] = i
+	}
+	return m
+}
+""",
+}
+
+
+def _test_file(template: str, package: str, tests: str, helpers: str, imports: Optional[Dict[str, str]]) -> str:
+    """
+    Fill a generated test file template, importing what its code uses:
+    standard packages anywhere, the package's own imports in `tests`
+    (the helpers never use them).
+    """
+    body = tests + helpers
+    needed = [path for path in STD_IMPORTS if re.search(rf'\b{path.split("/")[-1]}\.', body)]
+    for qualifier, path in sorted((imports or {}).items()):
+        if path not in needed and re.search(rf'\b{re.escape(qualifier)}\.', tests):
+            alias = "" if path.split("/")[-1] == qualifier else f"{qualifier} "
+            needed.append(f"{alias}{path}")
+    import_block = "\n".join(f'\t{_quote_import(path)}' for path in needed)
+    
+    return (
+        template
+        .replace("{package}", package)
+        .replace("{imports}", import_block)
+        .replace("{sizes}", ", ".join(str(size) for size in INPUT_SIZES))
+        .replace("{body}", body)
+    )
+
+
+def _write_test_file(
+    package_dir: str,
+    file_name: str,
+    generate: Callable[[str, Dict[str, str]], str]
+) -> Optional[Path]:
+    """
+    Write a generated test file into a package.
+    
+    `generate` gets the package name and the package's imports (qualifier
+    -> import path) and returns the source.
+    
+    Returns:
+        Path of the written file, or None when the directory has no Go package
+    """
+    package = None
+    imports: Dict[str, str] = {}
+    for source in sorted(Path(package_dir).glob("*.go")):
+        if source.name.endswith("_test.go"):
+            continue
+        code = source.read_text(encoding='utf-8', errors='ignore')
+        match = GO_PACKAGE.search(code)
+        if match and package is None:
+            package = match.group(1)
+        imports.update(_go_imports(code))
+    
+    if package is None:
+        return None
+    
+    path = Path(package_dir) / file_name
+    path.write_text(generate(package, imports), encoding='utf-8')
+    return path
+
+
+def _benchmark(target: BenchmarkTarget) -> str:
+    """Sub-benchmarks across input sizes for one function."""
+    setup, call, readers = _inputs(target)
+    declarations, assigned = _sinks(target, "testgenSink")
+    
+    lines = list(setup)
+    if target.results and target.results[-1].type == "error":
+        # Benchmark the work, not the error path of an input the function rejects
+        blanks = "_, " * (len(target.results) - 1)
+        lines += [
+            f"if {blanks}err := {call}; err != nil {{",
+            '\tb.Skipf("sample input rejected: %v", err)',
+            "}",
+        ]
+    lines += ["b.ReportAllocs()", "b.ResetTimer()", "for i := 0; i < b.N; i++ {"]
+    lines += [f"\t{reader}.Reset(data)" for reader in readers]
+    lines.append(f"\t{assigned} = {call}" if assigned else f"\t{call}")
+    lines.append("}")
+    
+    body = "".join(f"\t\t\t{line}\n" for line in lines)
+    return (
+        declarations
+        + f"\n// {target.benchmark_name} benchmarks {target.qualified_name} ({target.reason}).\n"
+        f"func {target.benchmark_name}(b *testing.B) {{\n"
+        f"\tfor _, n := range testgenBenchSizes {{\n"
+        f'\t\tb.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {{\n'
+        f"{body}"
+        f"\t\t}})\n"
+        f"\t}}\n"
+        f"}}\n"
+    )
+
+
+def _inputs(target: BenchmarkTarget, helpers: str = "testgenBench") -> Tuple[List[str], str, List[str]]:
+    """
+    Statements building a generatable target's arguments for size `n`,
+    the call, and the readers to `Reset(data)` before each call.
+    
+    `helpers` prefixes the input helper names (see `_input_helpers`).
+    """
+    fmt_name = _go_string(target.input_format)
+    args = []
+    setup = []
+    readers = []
+    for index, param in enumerate(target.params):
+        arg = param.name if param.name not in RESERVED_NAMES and re.match(r'^[A-Za-z]\w*$', param.name) else f"in{index}"
+        if arg in args:
+            arg = f"in{index}"
+        args.append(arg)
+        if param.type == "io.Reader":
+            setup.append(f"data := []byte(testgenBenchInput({fmt_name}, n))")
+            readers.append(arg)
+        expression = SIZED_INPUTS.get(param.type) or FIXED_INPUTS[param.type]
+        setup.append(f"{arg} := {expression.replace('{format}', fmt_name)}")
+    setup = [line.replace("testgenBench", helpers) for line in setup]
+    return setup, f"{target.name}({', '.join(args)})", readers
+
+
+def _sinks(target: BenchmarkTarget, prefix: str) -> Tuple[str, str]:
+    """
+    Package-level sink declarations for a target's results, and the
+    left-hand side assigning a call's results to them (errors go to
+    `<prefix>Err`, declared once per file).
+    """
+    base = prefix + target.benchmark_name[len("Benchmark"):]
+    values = [p for p in target.results if p.type != "error"]
+    names = [base if len(values) == 1 else f"{base}{index}" for index in range(len(values))]
+    declarations = "".join(f"\nvar {name} {result.type}\n" for name, result in zip(names, values))
+    
+    assigned = []
+    remaining = iter(names)
+    for result in target.results:
+        assigned.append(f"{prefix}Err" if result.type == "error" else next(remaining))
+    return declarations, ", ".join(assigned)
+
+
+def _input_helpers(code: str, helpers: str = "testgenBench") -> str:
+    """The input helper functions code uses (named with the `helpers` prefix)."""
+    return "".join(
+        helper.replace("testgenBench", helpers)
+        for name, helper in GO_BENCH_HELPERS.items()
+        if re.search(rf'\b{name.replace("testgenBench", helpers)}\b', code)
+    )
+
+
+def _doc_comment(code: str, start: int) -> str:
+    """The `//` comment lines directly above position start."""
+    lines = code[:start].splitlines()
+    comment = []
+    while lines and lines[-1].strip().startswith("//"):
+        comment.insert(0, lines.pop().strip())
+    return "\n".join(comment)
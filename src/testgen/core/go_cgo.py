@@ -0,0 +1,326 @@
+"""
+Cgo-aware Go Test Runs.
+
+Packages that `import "C"` need a C toolchain to build. Without one (or
+with CGO_ENABLED=0) `go test ./...` fails every package that is, or
+depends on, a cgo package with a build error, and the whole run reads as
+one FAIL. Before running, the Go runner checks for a C compiler; when
+there is none it finds the affected packages with `go list -deps -test`,
+reports them as skipped with the reason, and runs the rest with
+CGO_ENABLED=0. The listing is made once per directory and run
+(`CgoPlanner`): retries and ordered steps are planned from it. Its
+`go list -json` helpers (`json_stream`, `package_of`, `variant_of`) are
+shared with the other Go modules.
+
+Generated tests of cgo code must not call into C from parallel tests
+unless the package documents that it is thread-safe: most C libraries
+aren't. `parallel_cgo_problems` flags tests that do, so the trial run
+sends them back to the LLM.
+"""
+
+import json
+import os
+import re
+import shlex
+import shutil
+import subprocess
+import sys
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Dict, List, Optional
+
+from .base_runner import TestResult
+
+
+# import "C" on its own, or "C" in an import block (the preamble comment goes right above)
+CGO_IMPORT = re.compile(r'^import\s+"C"\s*$|^import\s*\([^)]*?^\s*"C"\s*$', re.MULTILINE)
+
+# "safe for concurrent use", "thread-safe", "goroutine-safe" (but not "not thread-safe")
+THREAD_SAFE = re.compile(r'\b(?:thread|goroutine|concurrency)[- ]safe\b|\bsafe for (?:concurrent|parallel) use\b', re.IGNORECASE)
+NEGATION = re.compile(r"(?:\bnot|n't|\bnever)\s+(?:\w+\s+)?$", re.IGNORECASE)
+
+GO_FUNC = re.compile(r'^func\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)\s*[\[(]', re.MULTILINE)
+GO_TEST_FUNC = re.compile(r'^func\s+(Test\w*)\s*\(', re.MULTILINE)
+
+
+@dataclass
+class CToolchain:
+    """Whether cgo packages can be built here."""
+    
+    compiler: str              # CC, or Go's default for the platform
+    path: Optional[str] = None  # resolved compiler, None when not found
+    disabled: bool = False     # CGO_ENABLED=0 in the environment
+    
+    @property
+    def available(self) -> bool:
+        return self.path is not None and not self.disabled
+    
+    @property
+    def reason(self) -> Optional[str]:
+        """Why cgo packages can't be built (None when they can)."""
+        if self.disabled:
+            return "CGO_ENABLED=0"
+        if self.path is None:
+            return f"no C compiler ({self.compiler} not found)"
+        return None
+
+
+@dataclass
+class CgoPlan:
+    """Which packages of a `go test` run can build without a C toolchain."""
+    
+    toolchain: CToolchain
+    packages: List[str] = field(default_factory=list)     # runnable import paths
+    skipped: Dict[str, str] = field(default_factory=dict)  # import path -> reason
+    cgo_packages: List[str] = field(default_factory=list)  # packages with cgo files (any module)
+    
+    @property
+    def env(self) -> Dict[str, str]:
+        """CGO_ENABLED for the runnable packages."""
+        return {"CGO_ENABLED": "1" if self.toolchain.available else "0"}
+    
+    def skipped_results(self) -> List[TestResult]:
+        """One skipped result per package left out, with the reason."""
+        return [
+            TestResult(name=package, status="skipped", message=reason, suite=package)
+            for package, reason in self.skipped.items()
+        ]
+
+
+def uses_cgo(source: str) -> bool:
+    """Whether Go source imports "C"."""
+    return bool(CGO_IMPORT.search(source))
+
+
+def documented_thread_safe(source: str) -> bool:
+    """Whether the comments of Go source say the code is safe for concurrent use."""
+    comments = "\n".join(
+        line.strip()[2:] for line in source.splitlines() if line.strip().startswith("//")
+    )
+    comments += "\n" + "\n".join(re.findall(r'/\*(.*?)\*/', source, re.DOTALL))
+    return any(
+        not NEGATION.search(comments[max(0, match.start() - 20):match.start()])
+        for match in THREAD_SAFE.finditer(comments)
+    )
+
+
+def find_c_toolchain(env: Optional[Dict[str, str]] = None) -> CToolchain:
+    """
+    Find the C compiler cgo would use.
+    
+    Args:
+        env: Environment of the go command (default: this process's)
+    
+    Returns:
+        CToolchain (`available` is False without a compiler or with CGO_ENABLED=0)
+    """
+    env = os.environ if env is None else env
+    default = "clang" if sys.platform in ("darwin", "freebsd", "openbsd") else "gcc"
+    compiler = env.get("CC") or default
+    try:
+        executable = shlex.split(compiler)[0]
+    except (ValueError, IndexError):
+        executable = compiler
+    return CToolchain(
+        compiler=compiler,
+        path=shutil.which(executable, path=env.get("PATH")),
+        disabled=env.get("CGO_ENABLED") == "0"
+    )
+
+
+class CgoPlanner:
+    """
+    Cgo plans for the `go test` runs of one testgen run.
+    
+    Packages are only listed without a C toolchain, and then once per
+    directory (`go list -deps -test ./...`): later runs there (retries,
+    ordered steps, single packages) are planned from that listing.
+    """
+    
+    def __init__(self):
+        self._listings: Dict[str, Optional[List[dict]]] = {}  # resolved directory -> `go list` entries
+    
+    def plan(
+        self,
+        test_dir: str,
+        patterns: List[str],
+        env: Optional[Dict[str, str]] = None,
+        timeout: int = 120
+    ) -> Optional[CgoPlan]:
+        """
+        Decide which packages of a run can build, given the C toolchain.
+        
+        With a C compiler every package runs (CGO_ENABLED=1). Without one,
+        packages with cgo files, and packages whose code or tests depend on
+        one, are skipped and the rest run with CGO_ENABLED=0.
+        
+        Args:
+            test_dir: Directory go test runs in
+            patterns: Package patterns of the run ("./...", ".", import paths)
+            env: Environment of the go command
+            timeout: Timeout of `go list` in seconds
+        
+        Returns:
+            CgoPlan, or None when packages couldn't be listed (run as given)
+        """
+        toolchain = find_c_toolchain(env)
+        if toolchain.available:
+            return CgoPlan(toolchain=toolchain, packages=list(patterns))
+        
+        directory = str(Path(test_dir).resolve())
+        if directory not in self._listings:
+            self._listings[directory] = _list_packages(test_dir, ["./..."], env, timeout)
+        entries = self._listings[directory]
+        if not entries:
+            return None
+        
+        selected = [e for e in entries if not e.get("DepOnly") and _selected(e, patterns, directory)]
+        if not all(any(_selected(e, [p], directory) for e in selected) for p in patterns):
+            # Patterns outside the directory's packages: list them as given
+            entries = _list_packages(test_dir, patterns, env, timeout)
+            if not entries:
+                return None
+            selected = [e for e in entries if not e.get("DepOnly")]
+        
+        cgo = {variant_of(e["ImportPath"]) for e in entries if e.get("CgoFiles")}
+        plan = CgoPlan(toolchain=toolchain, cgo_packages=sorted(cgo))
+        
+        # Test variants ("pkg [pkg.test]", "pkg_test [pkg.test]", "pkg.test") count for pkg
+        deps_of: Dict[str, set] = {}
+        for entry in selected:
+            deps = deps_of.setdefault(package_of(entry["ImportPath"]), set())
+            deps.update(variant_of(d) for d in entry.get("Deps") or [])
+        
+        for package, deps in deps_of.items():
+            if package in cgo:
+                plan.skipped[package] = f"cgo package: {toolchain.reason}"
+            elif deps & cgo:
+                plan.skipped[package] = f"depends on cgo package {min(deps & cgo)}: {toolchain.reason}"
+            else:
+                plan.packages.append(package)
+        
+        if not plan.skipped:
+            plan.packages = list(patterns)
+        return plan
+
+
+def cgo_functions(package_dir: str) -> List[str]:
+    """Functions and methods declared in the cgo files of a package (they call into C)."""
+    names = []
+    for go_file in sorted(Path(package_dir).glob("*.go")):
+        if go_file.name.endswith("_test.go"):
+            continue
+        source = go_file.read_text(encoding='utf-8', errors='ignore')
+        if uses_cgo(source):
+            names += [name for name in GO_FUNC.findall(source) if name not in names]
+    return names
+
+
+def package_thread_safe(package_dir: str) -> bool:
+    """Whether any non-test file of a package documents it as thread-safe."""
+    return any(
+        documented_thread_safe(f.read_text(encoding='utf-8', errors='ignore'))
+        for f in Path(package_dir).glob("*.go") if not f.name.endswith("_test.go")
+    )
+
+
+def parallel_cgo_problems(test_source: str, package_dir: str) -> List[str]:
+    """
+    Tests that call into C while running in parallel.
+    
+    Only for cgo packages that aren't documented thread-safe.
+    
+    Args:
+        test_source: Generated Go test file
+        package_dir: Package the tests are for
+    
+    Returns:
+        "TestName: ..." per parallel test calling a function of a cgo file
+    """
+    functions = cgo_functions(package_dir)
+    if not functions or package_thread_safe(package_dir):
+        return []
+    
+    starts = [m.start() for m in GO_FUNC.finditer(test_source)] + [len(test_source)]
+    problems = []
+    for start, end in zip(starts, starts[1:]):
+        body = test_source[start:end]
+        test = GO_TEST_FUNC.match(body)
+        if not test or ".Parallel()" not in body:
+            continue
+        called = [name for name in functions if re.search(rf'\b{re.escape(name)}\s*\(', body)]
+        if called:
+            problems.append(
+                f"{test.group(1)}: calls into C ({', '.join(called)}) from a parallel test; "
+                "the package isn't documented thread-safe, so don't use t.Parallel() here"
+            )
+    return problems
+
+
+def json_stream(output: str) -> List[dict]:
+    """Objects of concatenated JSON (`go list -json`)."""
+    decoder = json.JSONDecoder()
+    objects = []
+    index = 0
+    while True:
+        while index < len(output) and output[index].isspace():
+            index += 1
+        if index >= len(output):
+            break
+        try:
+            value, index = decoder.raw_decode(output, index)
+        except json.JSONDecodeError:
+            break
+        if isinstance(value, dict):
+            objects.append(value)
+    return objects
+
+
+def variant_of(import_path: str) -> str:
+    """Package of a test variant: "pkg [pkg.test]" -> "pkg"."""
+    return import_path.split(" [", 1)[0]
+
+
+def package_of(import_path: str) -> str:
+    """Package a listed entry is tested with: "pkg_test [pkg.test]" and "pkg.test" -> "pkg"."""
+    package = variant_of(import_path)
+    for suffix in (".test", "_test"):
+        if package.endswith(suffix):
+            return package[:-len(suffix)]
+    return package
+
+
+def _list_packages(
+    test_dir: str,
+    patterns: List[str],
+    env: Optional[Dict[str, str]],
+    timeout: int
+) -> Optional[List[dict]]:
+    """`go list -deps -test` entries of the patterns, standard library left out (None when go list can't run)."""
+    try:
+        listed = subprocess.run(
+            ["go", "list", "-e", "-deps", "-test",
+             "-json=ImportPath,Dir,Standard,DepOnly,CgoFiles,Deps", *patterns],
+            capture_output=True, text=True, cwd=test_dir, timeout=timeout,
+            # Cgo files are listed as such only with cgo on
+            env={**(env if env is not None else os.environ), "CGO_ENABLED": "1"}
+        )
+    except (OSError, subprocess.TimeoutExpired):
+        return None
+    return [e for e in json_stream(listed.stdout or "") if e.get("ImportPath") and not e.get("Standard")]
+
+
+def _selected(entry: dict, patterns: List[str], directory: str) -> bool:
+    """Whether a listed package matches one of the patterns of a run in directory."""
+    package = package_of(entry["ImportPath"])
+    package_dir = Path(entry.get("Dir") or "")
+    for pattern in patterns:
+        recursive = pattern.endswith("/...")
+        base = pattern[:-len("/...")] if recursive else pattern
+        if pattern.startswith("."):
+            base_dir = (Path(directory) / base).resolve()
+            if package_dir == base_dir or (recursive and base_dir in package_dir.parents):
+                return True
+        elif package == base or (recursive and package.startswith(base + "/")):
+            return True
+    return False
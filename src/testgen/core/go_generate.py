@@ -0,0 +1,446 @@
+"""
+`go:generate` Awareness for Go Test Runs.
+
+Packages with `//go:generate` directives keep generated code (mocks from
+mockgen, String methods from stringer, ...) next to the code it was
+generated from. When an input changes and nobody re-runs `go generate`,
+the tests fail for reasons that have nothing to do with the logic under
+test: a mock missing the method an interface just gained, a String()
+table one constant short.
+
+Before a Go run, every package with directives is checked:
+
+- by modification time: a generated file (`// Code generated ... DO NOT EDIT.`)
+  older than the package's hand-written sources or the files its directives
+  name is stale. Cheap, but only a hint: a fresh clone gives every file the
+  checkout time
+- optionally (opt-in, it runs the generators) by running `go generate` in a
+  scratch copy of the module and comparing what it writes with the working
+  tree: a file it would change or create is stale. Definitive, but needs
+  the generators installed; where they aren't, the mtime verdict stands
+
+Stale files count for the package holding them and for every package
+whose code or tests import it (`go list -deps -test`): a stale mock breaks
+the tests of its consumers. `FailureAnalyzer` labels a failure in those
+packages as stale generated code only when its output mentions what the
+stale files declare (the mock type, the stringer table), so logic
+failures next to stale code still count as logic failures.
+"""
+
+import filecmp
+import os
+import re
+import shlex
+import shutil
+import subprocess
+import tempfile
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Dict, Iterator, List, Optional, Tuple
+
+from .go_cgo import json_stream, package_of, variant_of
+
+
+# Directives take no space after the slashes: "// go:generate" is a plain comment
+GENERATE_DIRECTIVE = re.compile(r'^//go:generate[ \t]+(.+?)\s*$', re.MULTILINE)
+
+# https://go.dev/s/generatedcode
+GENERATED_HEADER = re.compile(r'^// Code generated .* DO NOT EDIT\.$', re.MULTILINE)
+
+# Top-level declarations: the receiver type of methods, the name of anything else
+METHOD_RECEIVER = re.compile(r'^func\s*\(\s*(?:\w+\s+)?\*?(\w+)', re.MULTILINE)
+DECLARED_NAME = re.compile(r'^(?:func|type|var|const)\s+(\w+)', re.MULTILINE)
+
+# Flags naming the file a generator writes (mockgen -destination, stringer -output, ...)
+OUTPUT_FLAGS = ("-destination", "-output", "-o", "--output", "-out")
+
+SKIPPED_DIRS = ("vendor", "testdata", "node_modules")
+
+
+@dataclass
+class GenerateDirective:
+    """A `//go:generate` line."""
+    
+    file: Path
+    line: int
+    command: str
+    
+    @property
+    def args(self) -> List[str]:
+        try:
+            return shlex.split(self.command)
+        except ValueError:
+            return self.command.split()
+    
+    @property
+    def tool(self) -> str:
+        """The generator: "mockgen", "stringer", or what `go run` runs."""
+        args = self.args
+        if len(args) > 2 and args[0] == "go" and args[1] == "run":
+            return next((a for a in args[2:] if not a.startswith("-")), "go run")
+        return args[0] if args else ""
+    
+    def outputs(self) -> List[Path]:
+        """Files the directive names as its output."""
+        return [
+            Path(os.path.normpath(self.file.parent / value))
+            for flag, value in _flags(self.args) if flag in OUTPUT_FLAGS
+        ]
+    
+    def inputs(self) -> List[Path]:
+        """Existing files the directive reads (-source=store.go, a template, ...)."""
+        found = []
+        outputs = set(self.outputs())
+        for arg in self.args[1:]:
+            value = arg.split("=", 1)[1] if arg.startswith("-") and "=" in arg else arg
+            if value.startswith("-") or not value:
+                continue
+            candidate = Path(os.path.normpath(self.file.parent / value))
+            if candidate not in outputs and candidate.is_file():
+                found.append(candidate)
+        return found
+
+
+@dataclass
+class GeneratedPackage:
+    """A Go package with `//go:generate` directives and the state of its generated files."""
+    
+    directory: Path
+    import_path: str
+    directives: List[GenerateDirective] = field(default_factory=list)
+    generated: List[Path] = field(default_factory=list)       # files carrying the generated-code header
+    stale: Dict[Path, str] = field(default_factory=dict)       # stale file -> why
+    symbols: Dict[Path, List[str]] = field(default_factory=dict)  # stale file -> what it declares, before and after
+    importers: Dict[str, List[str]] = field(default_factory=dict)  # package holding stale files -> packages importing it
+    checked_by: str = "mtime"                                  # "mtime" or "go generate"
+    error: Optional[str] = None                                # why go generate couldn't run
+    
+    @property
+    def is_stale(self) -> bool:
+        return bool(self.stale)
+
+
+def find_generate_directives(root: str) -> List[GeneratedPackage]:
+    """
+    Find packages with `//go:generate` directives.
+    
+    Args:
+        root: Directory to search (module root or a package)
+    
+    Returns:
+        GeneratedPackage per package with directives (not checked yet)
+    """
+    root_path = Path(root).resolve()
+    module_root, module_path = _module(root_path)
+    
+    packages: Dict[Path, GeneratedPackage] = {}
+    for go_file in sorted(root_path.rglob("*.go")):
+        relative = go_file.relative_to(root_path).parts[:-1]
+        if any(part in SKIPPED_DIRS or part.startswith((".", "_")) for part in relative):
+            continue
+        try:
+            source = go_file.read_text(encoding='utf-8', errors='ignore')
+        except OSError:
+            continue
+        
+        for match in GENERATE_DIRECTIVE.finditer(source):
+            package = packages.get(go_file.parent)
+            if package is None:
+                package = packages[go_file.parent] = GeneratedPackage(
+                    directory=go_file.parent,
+                    import_path=_import_path(module_root, module_path, go_file.parent)
+                )
+            package.directives.append(GenerateDirective(
+                file=go_file,
+                line=source.count("\n", 0, match.start()) + 1,
+                command=match.group(1)
+            ))
+    
+    for package in packages.values():
+        outputs = {path for d in package.directives for path in d.outputs() if path.is_file()}
+        in_package = {f for f in package.directory.glob("*.go") if _is_generated(f)}
+        package.generated = sorted(outputs | in_package)
+    
+    return list(packages.values())
+
+
+def check_generated(root: str, regenerate: bool = False, timeout: int = 300) -> List[GeneratedPackage]:
+    """
+    Find packages whose generated code is stale compared with its inputs.
+    
+    Args:
+        root: Directory to check (module root or a package)
+        regenerate: Also run `go generate` in a scratch copy of the module
+            and compare its output with the working tree (runs the generators)
+        timeout: Timeout of each `go generate` in seconds
+    
+    Returns:
+        Every package with directives (`stale` lists what is out of date,
+        `error` why go generate couldn't run)
+    """
+    packages = find_generate_directives(root)
+    for package in packages:
+        _check_mtimes(package)
+    if not packages:
+        return packages
+    
+    if shutil.which("go") is None:
+        if regenerate:
+            for package in packages:
+                package.error = "go not found"
+        return packages
+    
+    if regenerate:
+        _regenerate(Path(root).resolve(), packages, timeout)
+    if any(package.is_stale for package in packages):
+        _find_importers(Path(root).resolve(), packages, timeout)
+    return packages
+
+
+def find_module_root(directory: str) -> str:
+    """The module root above a directory (the directory itself outside a module)."""
+    root, _ = _module(Path(directory).resolve())
+    return str(root) if root is not None else directory
+
+
+def go_symbols(source: str) -> List[str]:
+    """Top-level identifiers Go source declares, and the receiver types of its methods."""
+    names = METHOD_RECEIVER.findall(source) + DECLARED_NAME.findall(source)
+    return [name for name in dict.fromkeys(names) if name != "_"]
+
+
+def stale_packages(packages: List[GeneratedPackage]) -> Dict[str, List[str]]:
+    """
+    Stale generated files by the import path of the packages whose tests they break.
+    
+    A mock written to another package (mockgen -destination=../mocks/...)
+    is reported under that package, and under the packages importing it
+    as "<import path>/<file>".
+    
+    Returns:
+        Import path -> stale files (the shape of `TestResults.stale_generated`)
+    """
+    stale: Dict[str, List[str]] = {}
+    for import_path, _, _, name in _stale_files(packages):
+        names = stale.setdefault(import_path, [])
+        if name not in names:
+            names.append(name)
+    return stale
+
+
+def stale_symbols(packages: List[GeneratedPackage]) -> Dict[str, List[str]]:
+    """
+    What the stale generated files declare, by the import path of the packages whose tests they break.
+    
+    Failures mentioning one of these (a mock type, a stringer table, the
+    type a generated method belongs to) are the stale code's doing.
+    
+    Returns:
+        Import path -> identifiers (the shape of `TestResults.stale_symbols`)
+    """
+    symbols: Dict[str, List[str]] = {}
+    for import_path, package, path, _ in _stale_files(packages):
+        names = symbols.setdefault(import_path, [])
+        names.extend(name for name in package.symbols.get(path, []) if name not in names)
+    return symbols
+
+
+def describe_generated(packages: List[GeneratedPackage], root: Optional[str] = None) -> str:
+    """
+    Human-readable report of generated code per package.
+    
+    Args:
+        packages: Result of `check_generated`
+        root: Directory paths are shown relative to
+    
+    Returns:
+        One block per package: its generators and any stale files with why
+    """
+    base = Path(root).resolve() if root else None
+    
+    def _show(path: Path) -> str:
+        if base is not None:
+            try:
+                return str(path.relative_to(base)) or "."
+            except ValueError:
+                pass
+        return str(path)
+    
+    lines = []
+    for package in packages:
+        tools = ", ".join(dict.fromkeys(d.tool for d in package.directives))
+        state = f"{len(package.stale)} stale" if package.stale else "up to date"
+        lines.append(f"{package.import_path} ({tools}): {state} [{package.checked_by}]")
+        for path, reason in package.stale.items():
+            lines.append(f"  {_show(path)}: {reason}")
+        for holder, importers in package.importers.items():
+            lines.append(f"  {holder} is imported by {', '.join(importers)}")
+        if package.error:
+            lines.append(f"  go generate not run: {package.error}")
+    return "\n".join(lines)
+
+
+def _check_mtimes(package: GeneratedPackage) -> None:
+    """Flag generated files older than the newest input of the package."""
+    generated = set(package.generated)
+    # Files the directives name (mockgen -source=store.go) or else the package's own sources
+    inputs = [path for d in package.directives for path in d.inputs() if path not in generated]
+    if not inputs:
+        inputs = [
+            f for f in package.directory.glob("*.go")
+            if f not in generated and not f.name.endswith("_test.go")
+        ]
+    if not inputs:
+        return
+    
+    newest = max(inputs, key=lambda f: f.stat().st_mtime)
+    for path in package.generated:
+        if path.stat().st_mtime < newest.stat().st_mtime:
+            package.stale[path] = f"older than {newest.name}"
+            package.symbols[path] = go_symbols(path.read_text(encoding='utf-8', errors='ignore'))
+
+
+def _regenerate(root: Path, packages: List[GeneratedPackage], timeout: int) -> None:
+    """Run `go generate` per package in a scratch copy of the module and diff the results."""
+    module_root, _ = _module(root)
+    copy_from = module_root or root
+    
+    with tempfile.TemporaryDirectory(prefix="testgen-generate-") as scratch:
+        copy = Path(scratch) / copy_from.name
+        # copy2 keeps modification times, so only what go generate rewrites differs
+        shutil.copytree(
+            copy_from, copy, symlinks=True,
+            ignore=shutil.ignore_patterns(".git", ".testgen-cache", "node_modules")
+        )
+        
+        for package in packages:
+            scratch_dir = copy / package.directory.relative_to(copy_from)
+            try:
+                result = subprocess.run(
+                    ["go", "generate", "."],
+                    capture_output=True, text=True, cwd=scratch_dir, timeout=timeout
+                )
+            except (OSError, subprocess.TimeoutExpired) as e:
+                package.error = str(e)
+                continue
+            if result.returncode != 0:
+                # Usually a generator that isn't installed; the mtime check stands
+                lines = [l.strip() for l in (result.stderr or result.stdout).splitlines() if l.strip()]
+                package.error = lines[-1] if lines else f"exit status {result.returncode}"
+                continue
+            
+            package.checked_by = "go generate"
+            package.stale = {}
+            package.symbols = {}
+            directories = {package.directory} | {p.parent for d in package.directives for p in d.outputs()}
+            for directory in sorted(directories):
+                scratch_package = copy / directory.relative_to(copy_from)
+                if not scratch_package.is_dir():
+                    continue
+                for regenerated in sorted(scratch_package.glob("*.go")):
+                    original = directory / regenerated.name
+                    if not original.exists():
+                        package.stale[original] = "missing (go generate creates it)"
+                    elif not filecmp.cmp(original, regenerated, shallow=False):
+                        package.stale[original] = "differs from go generate output"
+                        if original not in package.generated:
+                            package.generated.append(original)
+                    else:
+                        continue
+                    # Old names (a mock method now gone) and new ones (the method it gained)
+                    sources = [regenerated] + ([original] if original.exists() else [])
+                    package.symbols[original] = go_symbols("\n".join(
+                        f.read_text(encoding='utf-8', errors='ignore') for f in sources
+                    ))
+
+
+def _find_importers(root: Path, packages: List[GeneratedPackage], timeout: int) -> None:
+    """Record the packages of the module whose code or tests import a package holding stale files."""
+    module_root, module_path = _module(root)
+    holders = {
+        _import_path(module_root, module_path, path.parent)
+        for package in packages for path in package.stale
+    }
+    try:
+        listed = subprocess.run(
+            ["go", "list", "-e", "-deps", "-test", "-json=ImportPath,DepOnly,Deps", "./..."],
+            capture_output=True, text=True, cwd=module_root or root, timeout=timeout
+        )
+    except (OSError, subprocess.TimeoutExpired):
+        return
+    
+    # Test variants ("pkg [pkg.test]", "pkg_test [pkg.test]", "pkg.test") count for pkg
+    importers: Dict[str, set] = {holder: set() for holder in holders}
+    for entry in json_stream(listed.stdout or ""):
+        if entry.get("DepOnly") or not entry.get("ImportPath"):
+            continue
+        package = package_of(entry["ImportPath"])
+        for holder in holders & {variant_of(d) for d in entry.get("Deps") or []}:
+            if package != holder:
+                importers[holder].add(package)
+    
+    for package in packages:
+        for path in package.stale:
+            holder = _import_path(module_root, module_path, path.parent)
+            if importers[holder]:
+                package.importers[holder] = sorted(importers[holder])
+
+
+def _stale_files(packages: List[GeneratedPackage]) -> Iterator[Tuple[str, GeneratedPackage, Path, str]]:
+    """(import path, package, stale file, name shown there) for the holder of every stale file and its importers."""
+    for package in packages:
+        module_root, module_path = _module(package.directory)
+        for path in package.stale:
+            holder = _import_path(module_root, module_path, path.parent)
+            yield holder, package, path, path.name
+            for importer in package.importers.get(holder, []):
+                yield importer, package, path, f"{holder}/{path.name}"
+
+
+def _is_generated(path: Path) -> bool:
+    """Whether a Go file carries the generated-code header (before the package clause)."""
+    try:
+        with open(path, encoding='utf-8', errors='ignore') as f:
+            head = []
+            for line in f:
+                if line.startswith("package "):
+                    break
+                head.append(line.rstrip("\r\n"))
+    except OSError:
+        return False
+    return bool(GENERATED_HEADER.search("\n".join(head)))
+
+
+def _flags(args: List[str]) -> List[tuple]:
+    """(flag, value) pairs of `-flag=value` and `-flag value` arguments."""
+    pairs = []
+    for i, arg in enumerate(args):
+        if not arg.startswith("-"):
+            continue
+        if "=" in arg:
+            flag, value = arg.split("=", 1)
+            pairs.append((flag, value))
+        elif i + 1 < len(args) and not args[i + 1].startswith("-"):
+            pairs.append((arg, args[i + 1]))
+    return pairs
+
+
+def _module(directory: Path) -> tuple:
+    """(module root, module path) of the go.mod above a directory, or (None, "")."""
+    for candidate in [directory, *directory.parents]:
+        go_mod = candidate / "go.mod"
+        if go_mod.is_file():
+            match = re.search(r'^module\s+(\S+)', go_mod.read_text(encoding='utf-8', errors='ignore'), re.MULTILINE)
+            return candidate, (match.group(1).strip('"') if match else "")
+    return None, ""
+
+
+def _import_path(module_root: Optional[Path], module_path: str, directory: Path) -> str:
+    """Import path of a package directory (as `go test -json` reports it)."""
+    if module_root is None or not module_path:
+        return directory.name
+    try:
+        relative = directory.resolve().relative_to(module_root.resolve())
+    except ValueError:
+        return directory.name
+    return module_path if not relative.parts else f"{module_path}/{relative.as_posix()}"
@@ -4,11 +4,22 @@ Go Test Runner.
 Implements BaseTestRunner for Go projects using built-in testing package.
 """
 
+import json
+import os
+import re
 import subprocess
+import tempfile
 from pathlib import Path
-from typing import List, Optional
+from typing import Dict, List, Optional
 
 from .base_runner import BaseTestRunner, TestResults, TestResult
+from .go_cgo import CgoPlan, CgoPlanner, package_of
+from .resource_usage import (
+    SUPPORTED as USAGE_SUPPORTED,
+    collect_usage,
+    exec_wrapper_command,
+    exec_wrapper_env,
+)
 
 
 class GoTestRunner(BaseTestRunner):
@@ -16,6 +27,8 @@ class GoTestRunner(BaseTestRunner):
     
     def __init__(self, verbose: bool = False):
         super().__init__(verbose)
+        # Packages are listed for cgo once per directory for the runs of this runner
+        self.cgo_planner = CgoPlanner()
     
     def get_language(self) -> str:
         return "go"
@@ -60,37 +73,213 @@ class GoTestRunner(BaseTestRunner):
         return total
     
     def build_command(self, test_dir: str, pattern: Optional[str] = None, **kwargs) -> List[str]:
-        cmd = ["go", "test"]
+        # -json gives per-test events (package, name, outcome, elapsed)
+        cmd = ["go", "test", "-json"]
         
         if self.verbose:
             cmd.append("-v")
         
-        # Add package path
-        cmd.append("./...")
+        cmd.extend(kwargs.get("extra_args") or [])
+        
+        # Restrict to specific tests (e.g. retries of failed tests)
+        run_filter = kwargs.get("run")
+        if run_filter:
+            cmd.extend(["-run", run_filter])
+        
+        # Add package paths
+        cmd.extend(kwargs.get("packages") or ["./..."])
         
         return cmd
     
     def run_tests(self, test_dir: str, pattern: Optional[str] = None, **kwargs) -> TestResults:
-        cmd = self.build_command(test_dir, pattern, **kwargs)
+        extra_args = list(kwargs.get("extra_args") or [])
+        
+        # Without a C toolchain, packages needing cgo are skipped (with why) instead of failing the build
+        plan = self.cgo_planner.plan(test_dir, kwargs.get("packages") or ["./..."]) if kwargs.get("check_cgo", True) else None
+        if plan is not None and plan.skipped:
+            if not plan.packages:
+                return self._with_skipped(
+                    TestResults(language=self.get_language(), framework=self.get_framework()), plan
+                )
+            kwargs = {**kwargs, "packages": plan.packages}
+        
+        # Opt-in (measure_usage=True): run every package's test binary through the usage
+        # wrapper (CPU, peak RSS, writes); packages served from the test cache don't run,
+        # so they report no usage
+        measure = USAGE_SUPPORTED and kwargs.get("measure_usage", False) and "-exec" not in extra_args
         
         try:
-            result = subprocess.run(
-                cmd,
-                capture_output=True,
-                text=True,
-                cwd=test_dir,
-                timeout=300
-            )
-            return self._parse_output(result)
+            with tempfile.TemporaryDirectory(prefix="testgen-usage-") as usage_dir:
+                env = None
+                if measure:
+                    kwargs = {**kwargs, "extra_args": extra_args + ["-exec", exec_wrapper_command(usage_dir)]}
+                    env = exec_wrapper_env()
+                if plan is not None:
+                    env = {**(env or os.environ), **plan.env}
+                
+                cmd = self.build_command(test_dir, pattern, **kwargs)
+                result = subprocess.run(
+                    cmd,
+                    capture_output=True,
+                    text=True,
+                    cwd=test_dir,
+                    timeout=300,
+                    env=env
+                )
+                results = self._parse_output(result)
+                if measure:
+                    results.resource_usage = collect_usage(usage_dir)
+                if self.artifacts is not None:
+                    self._keep_artifacts(result, test_dir, extra_args)
+                return self._with_skipped(results, plan)
         except subprocess.TimeoutExpired:
             return TestResults(total=0, errors=1, language=self.get_language(), framework=self.get_framework())
         except Exception as e:
             return TestResults(total=0, errors=1, language=self.get_language(), framework=self.get_framework())
     
+    def _with_skipped(self, results: TestResults, plan: Optional[CgoPlan]) -> TestResults:
+        """Add the packages a cgo plan left out as skipped results."""
+        if plan is None or not plan.skipped:
+            return results
+        skipped = plan.skipped_results()
+        results.tests.extend(skipped)
+        results.skipped += len(skipped)
+        results.total += len(skipped)
+        return results
+    
+    def _keep_artifacts(self, result: subprocess.CompletedProcess, test_dir: str, extra_args: List[str]) -> None:
+        """Keep the `-json` stream, stderr, the cover profile and profiles of a run."""
+        self.artifacts.add_output("go-test", result.stdout, result.stderr, stdout_kind="json")
+        
+        coverprofile = _flag_value(extra_args, "-coverprofile")
+        if coverprofile:
+            self.artifacts.add_file("coverage", Path(test_dir) / coverprofile)
+        for flag in ("-cpuprofile", "-memprofile", "-blockprofile", "-mutexprofile"):
+            profile = _flag_value(extra_args, flag)
+            if profile:
+                self.artifacts.add_pprof(Path(test_dir) / profile, cwd=test_dir)
+    
+    def rerun_tests(
+        self,
+        test_dir: str,
+        tests: List[TestResult],
+        pattern: Optional[str] = None,
+        **kwargs
+    ) -> TestResults:
+        """
+        Re-run only the given tests in one `go test -run '^(TestA|TestB)$' <packages>`.
+        
+        Subtests are retried through their top-level test, since `-run`
+        selects subtests only below a matching parent. The pattern applies
+        to every package, so a name failing in one package also reruns in
+        the others; callers match results by package and name.
+        """
+        packages: List[str] = []
+        names: List[str] = []
+        for test in tests:
+            if test.suite and test.name == test.suite:
+                continue  # package-level error (build failure), not a test -run can select
+            package = test.suite or "./..."
+            top_level = test.name.split("/")[0]
+            if package not in packages:
+                packages.append(package)
+            if top_level not in names:
+                names.append(top_level)
+        
+        if not names:
+            return TestResults(language=self.get_language(), framework=self.get_framework())
+        
+        run_filter = "^(" + "|".join(re.escape(n) for n in names) + ")$"
+        return self.run_tests(test_dir, pattern, **{**kwargs, "run": run_filter, "packages": packages})
+    
     def _parse_output(self, result: subprocess.CompletedProcess) -> TestResults:
+        output = result.stdout or ""
+        
+        events = []
+        for line in output.split('\n'):
+            line = line.strip()
+            if not line.startswith('{'):
+                continue
+            try:
+                events.append(json.loads(line))
+            except json.JSONDecodeError:
+                continue
+        
+        if events:
+            return self._parse_json_events(events, result.stderr or "")
+        
+        return self._parse_text_output(result)
+    
+    def _parse_json_events(self, events: List[dict], stderr: str = "") -> TestResults:
+        """Parse `go test -json` (test2json) events into per-test results."""
+        results = TestResults(language=self.get_language(), framework=self.get_framework())
+        
+        outputs: Dict[tuple, List[str]] = {}
+        # Compiler errors: build-output events since Go 1.24, on stderr before
+        build_outputs = _build_output(stderr)
+        failed_tests_by_package: Dict[str, int] = {}
+        
+        for event in events:
+            action = event.get("Action")
+            package = event.get("Package", "")
+            test_name = event.get("Test")
+            key = (package, test_name)
+            
+            if action == "output":
+                outputs.setdefault(key, []).append(event.get("Output", ""))
+                continue
+            if action == "build-output":
+                build_outputs.setdefault(package_of(event.get("ImportPath", "")), []).append(event.get("Output", ""))
+                continue
+            
+            if action not in ("pass", "fail", "skip"):
+                continue
+            
+            if test_name:
+                status = {"pass": "passed", "fail": "failed", "skip": "skipped"}[action]
+                message = None
+                if status != "passed":
+                    message = "".join(
+                        line for line in outputs.get(key, [])
+                        if not line.startswith(("=== ", "--- "))
+                    ).strip() or None
+                results.tests.append(TestResult(
+                    name=test_name,
+                    status=status,
+                    duration=float(event.get("Elapsed") or 0.0),
+                    message=message,
+                    suite=package
+                ))
+                if status == "failed":
+                    failed_tests_by_package[package] = failed_tests_by_package.get(package, 0) + 1
+            elif package:
+                results.duration += float(event.get("Elapsed") or 0.0)
+                # Package failed without any failing test: build error, panic in init, etc.
+                if action == "fail" and not failed_tests_by_package.get(package):
+                    results.tests.append(TestResult(
+                        name=package,
+                        status="error",
+                        message="".join(build_outputs.get(package, []) + outputs.get(key, [])).strip() or None,
+                        suite=package
+                    ))
+        
+        for test in results.tests:
+            if test.status == "passed":
+                results.passed += 1
+            elif test.status == "failed":
+                results.failed += 1
+            elif test.status == "skipped":
+                results.skipped += 1
+            else:
+                results.errors += 1
+        
+        results.total = len(results.tests)
+        return results
+    
+    def _parse_text_output(self, result: subprocess.CompletedProcess) -> TestResults:
         results = TestResults(language=self.get_language(), framework=self.get_framework())
         
-        output = result.stdout
+        output = result.stdout or ""
         
         # Parse go test output
         for line in output.split('\n'):
@@ -102,7 +291,7 @@ class GoTestRunner(BaseTestRunner):
                 results.skipped += 1
         
         results.total = results.passed + results.failed + results.skipped
-       
+        
         # Check exit code
         if result.returncode == 0:
             results.passed = max(results.passed, 1)
@@ -116,3 +305,25 @@ class GoTestRunner(BaseTestRunner):
             return test_file.endswith('_test.go') and 'func Test' in content
         except:
             return False
+
+
+def _flag_value(args: List[str], flag: str) -> Optional[str]:
+    """Value of `-flag=value` or `-flag value` in go test arguments."""
+    for i, arg in enumerate(args):
+        if arg.startswith(flag + "="):
+            return arg[len(flag) + 1:]
+        if arg == flag and i + 1 < len(args):
+            return args[i + 1]
+    return None
+
+
+def _build_output(stderr: str) -> Dict[str, List[str]]:
+    """Compiler output on stderr by package (each block starts "# pkg [pkg.test]")."""
+    blocks: Dict[str, List[str]] = {}
+    current = None
+    for line in stderr.splitlines(keepends=True):
+        if line.startswith("# "):
+            current = blocks.setdefault(package_of(line[2:].strip()), [])
+        elif current is not None:
+            current.append(line)
+    return blocks
@@ -0,0 +1,235 @@
+"""
+Trial Runs of Generated Go Tests.
+
+Generated `_test.go` files are compiled and run through `go test -overlay`
+before anything is written: the overlay maps where each file will live to
+a scratch copy, so the package sees the tests while the working tree
+stays untouched. Build errors and failures go back to the LLM with the
+attempt, and the files are written only once they build and pass, so a
+developer editing the package never finds half-broken tests in it (and
+watch mode can validate while they edit). Tests of cgo packages that
+call into C from parallel tests are sent back too, unless the package is
+documented thread-safe.
+"""
+
+import json
+import re
+import shutil
+import subprocess
+import tempfile
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Dict, List, Optional
+
+from .base_runner import TestResults
+from .go_cgo import find_c_toolchain, parallel_cgo_problems, uses_cgo
+from .go_runner import GoTestRunner
+
+
+# Top-level tests, gofmt'd or not (generated code may not be)
+GO_TEST_NAME = re.compile(r'^func\s+(Test\w*)\s*\(\s*\w+\s+\*testing\.T\s*\)', re.MULTILINE)
+
+# vet: ../../tmp/testgen-trial-x/0/foo_test.go:7:11: undefined: bar
+COMPILER_LINE = re.compile(r'^(?:vet:\s*)?(\S+\.go):(\d+(?::\d+)?): (.*)$')
+
+
+@dataclass
+class TrialRun:
+    """Generated Go test files, built and run without being written."""
+    
+    files: Dict[Path, str]                                   # where each file goes -> source
+    build_errors: List[str] = field(default_factory=list)    # "foo_test.go:7:11: undefined: bar"
+    results: Optional[TestResults] = None
+    not_run: List[str] = field(default_factory=list)          # declared tests without a result
+    cgo_problems: List[str] = field(default_factory=list)     # parallel tests calling into C
+    checked: bool = True                                     # False without a Go toolchain
+    reason: Optional[str] = None                             # why it wasn't checked
+    attempts: int = 1
+    written: bool = False
+    
+    @property
+    def failures(self) -> List[str]:
+        """Failed tests as "TestName: message"."""
+        if self.results is None:
+            return []
+        return [
+            f"{t.name}: {(t.message or 'failed').strip()}"
+            for t in self.results.tests if t.status in ("failed", "error")
+        ]
+    
+    @property
+    def problems(self) -> List[str]:
+        """Everything keeping the files from being written."""
+        return (
+            self.build_errors + self.failures + [f"{name}: didn't run" for name in self.not_run]
+            + self.cgo_problems
+        )
+    
+    @property
+    def ok(self) -> bool:
+        return self.checked and not self.problems
+
+
+def trial_run(files: Dict[Path, str], timeout: int = 300) -> TrialRun:
+    """
+    Build and run generated Go test files through `go test -overlay`.
+    
+    Only the tests declared in the files run (`-count=1`, so a cached
+    result never stands in for them). Nothing in the working tree is
+    created or modified.
+    
+    Args:
+        files: Path each test file will be written to -> its source
+        timeout: Timeout of each go command in seconds
+        
+    Returns:
+        TrialRun (`checked` is False when go isn't installed, or when the
+        packages use cgo and there is no C compiler)
+    """
+    trial = TrialRun(files={Path(path).resolve(): code for path, code in files.items()})
+    if shutil.which("go") is None:
+        trial.checked, trial.reason = False, "go not found"
+        return trial
+    
+    runner = GoTestRunner()
+    trial.results = TestResults(language=runner.get_language(), framework=runner.get_framework())
+    with tempfile.TemporaryDirectory(prefix="testgen-trial-") as scratch:
+        replace = {}
+        scratch_names = {}
+        for index, (path, code) in enumerate(trial.files.items()):
+            # One subdirectory per file: generated files may share a name across packages
+            copy = Path(scratch) / str(index) / path.name
+            copy.parent.mkdir()
+            copy.write_text(code, encoding='utf-8')
+            replace[str(path)] = str(copy)
+            scratch_names[str(copy)] = path.name
+        overlay = Path(scratch) / "overlay.json"
+        overlay.write_text(json.dumps({"Replace": replace}), encoding='utf-8')
+        
+        by_package: Dict[Path, List[str]] = {}
+        for path, code in trial.files.items():
+            by_package.setdefault(path.parent, []).extend(GO_TEST_NAME.findall(code))
+        
+        toolchain = find_c_toolchain()
+        unchecked = []
+        for package_dir, names in by_package.items():
+            if not package_dir.is_dir():
+                trial.build_errors.append(f"{package_dir}: no such package directory")
+                continue
+            if not toolchain.available and any(
+                uses_cgo(f.read_text(encoding='utf-8', errors='ignore')) for f in package_dir.glob("*.go")
+            ):
+                # The runner would only report the package as skipped
+                unchecked.append(f"{package_dir.name}: cgo package, {toolchain.reason}")
+                continue
+            run_filter = "^(" + "|".join(names) + ")$" if names else "^$"
+            results = runner.run_tests(
+                str(package_dir), run=run_filter, packages=["."],
+                extra_args=["-count=1", f"-overlay={overlay}"], measure_usage=False
+            )
+            if any(t.status == "error" for t in results.tests) or results.errors:
+                trial.build_errors += _build_errors(package_dir, overlay, scratch_names, timeout)
+                continue
+            trial.results.tests.extend(results.tests)
+            trial.results.total += results.total
+            trial.results.passed += results.passed
+            trial.results.failed += results.failed
+            trial.results.skipped += results.skipped
+            trial.results.duration += results.duration
+            ran = {t.name for t in results.tests}
+            trial.not_run += [name for name in names if name not in ran]
+        
+        if unchecked:
+            trial.reason = "; ".join(unchecked)
+            trial.checked = len(unchecked) < len(by_package)
+    
+    for path, code in trial.files.items():
+        trial.cgo_problems += parallel_cgo_problems(code, str(path.parent))
+    
+    return trial
+
+
+def write_trial(trial: TrialRun) -> List[Path]:
+    """
+    Write the files of a trial run.
+    
+    Args:
+        trial: A trial run (normally one that is `ok`)
+        
+    Returns:
+        Written paths
+    """
+    for path, code in trial.files.items():
+        path.parent.mkdir(parents=True, exist_ok=True)
+        path.write_text(code, encoding='utf-8')
+    trial.written = True
+    return list(trial.files)
+
+
+def generate_go_tests(
+    test_file: Path,
+    code: str,
+    llm_client,
+    prompt: Optional[str] = None,
+    max_attempts: int = 3
+) -> TrialRun:
+    """
+    Generate a Go test file, trial-running each attempt until one builds and passes.
+    
+    Every attempt runs through `go test -overlay`; its build errors and
+    failures are sent back to the LLM along with the attempt. The file is
+    written only when an attempt is `ok` (or, without a Go toolchain, as
+    generated).
+    
+    Args:
+        test_file: Where the test file goes (in the package under test)
+        code: Code under test
+        llm_client: LLMClient (or anything with `generate(prompt).content`)
+        prompt: Prompt of the first attempt (default: the Go template)
+        max_attempts: Attempts before giving up
+        
+    Returns:
+        TrialRun of the last attempt (`written` tells whether the file was written)
+    """
+    from .language_config import Language
+    from .prompt_templates import PromptTemplates
+    
+    prompt = prompt or PromptTemplates.get_prompt(Language.GO, code)
+    trial = TrialRun(files={})
+    for attempt in range(1, max_attempts + 1):
+        response = llm_client.generate(prompt).content
+        blocks = re.findall(r'```[\w+-]*\n(.*?)```', response, re.DOTALL)
+        source = (max(blocks, key=len) if blocks else response.strip()).rstrip("\n") + "\n"
+        
+        trial = trial_run({test_file: source})
+        trial.attempts = attempt
+        if trial.ok or not trial.checked:
+            write_trial(trial)
+            return trial
+        prompt = PromptTemplates.get_trial_repair_prompt(code, source, trial.problems)
+    return trial
+
+
+def _build_errors(package_dir: Path, overlay: Path, scratch_names: Dict[str, str], timeout: int) -> List[str]:
+    """Compiler errors of a package under the overlay, located in the generated files."""
+    # The compiler's messages aren't in the -json stream of every Go version; vet prints them on stderr
+    try:
+        build = subprocess.run(
+            ["go", "vet", f"-overlay={overlay}", "."],
+            capture_output=True, text=True, cwd=package_dir, timeout=timeout
+        )
+    except (OSError, subprocess.TimeoutExpired) as e:
+        return [f"{package_dir.name}: go vet failed: {e}"]
+    
+    errors = []
+    for line in build.stderr.splitlines():
+        match = COMPILER_LINE.match(line.strip())
+        if not match:
+            continue
+        file = str((package_dir / match.group(1)).resolve())
+        name = scratch_names.get(file, Path(match.group(1)).name)
+        errors.append(f"{name}:{match.group(2)}: {match.group(3)}")
+    if not errors:
+        # Not a compile error (missing module, bad go.mod, ...): keep go's own words
+        errors = [line.strip() for line in build.stderr.splitlines() if line.strip() and not line.startswith("#")][:5]
+    return errors or ["build failed"]
@@ -6,6 +6,7 @@ Implements BaseTestRunner for JavaScript/TypeScript projects using Jest.
 
 import subprocess
 import json
+import re
 from pathlib import Path
 from typing import List, Optional, Dict, Any
 
@@ -173,6 +174,9 @@ class JavaScriptTestRunner(BaseTestRunner):
         if self.verbose:
             cmd.append("--verbose")
         
+        # Add pre-built arguments (e.g. from TestExecutionConfig)
+        cmd.extend(kwargs.pop("extra_args", None) or [])
+        
         # Add custom arguments
         for key, value in kwargs.items():
             if isinstance(value, bool) and value:
@@ -264,6 +268,23 @@ class JavaScriptTestRunner(BaseTestRunner):
                 )]
             )
     
+    def rerun_tests(
+        self,
+        test_dir: str,
+        tests: List[TestResult],
+        pattern: Optional[str] = None,
+        **kwargs
+    ) -> TestResults:
+        """
+        Re-run only the given tests using Jest's --testNamePattern.
+        """
+        names = [t.name.strip() for t in tests if t.name.strip()]
+        if not names:
+            return super().rerun_tests(test_dir, tests, pattern, **kwargs)
+        
+        name_pattern = "^(" + "|".join(re.escape(n) for n in names) + ")$"
+        return self.run_tests(test_dir, pattern, **{**kwargs, "testNamePattern": name_pattern})
+    
     def _parse_json_output(self, json_data: Dict[str, Any]) -> TestResults:
         """Parse Jest JSON output."""
         results = TestResults(
@@ -0,0 +1,124 @@
+"""
+JUnit XML Report Parsing.
+
+Reads the JUnit XML format written by Gradle (`build/test-results`),
+Maven Surefire, `swift test --xunit-output` and most other runners into
+language-agnostic TestResults:
+
+    <testsuite name="com.example.CartTest" tests="2" failures="1" time="0.2">
+      <testcase name="addsItem" classname="com.example.CartTest" time="0.1"/>
+      <testcase name="removesItem" classname="com.example.CartTest" time="0.1">
+        <failure message="expected 1 but was 2">stack trace</failure>
+      </testcase>
+    </testsuite>
+"""
+
+import xml.etree.ElementTree as ET
+from pathlib import Path
+from typing import Iterable, List
+
+from .base_runner import TestResults, TestResult
+
+
+def parse_junit_xml(content: str, language: str, framework: str) -> TestResults:
+    """
+    Parse one JUnit XML document (`<testsuite>` or `<testsuites>` root).
+    
+    Args:
+        content: XML text
+        language: Language for the results
+        framework: Framework for the results
+        
+    Returns:
+        TestResults with one TestResult per `<testcase>` (suite = classname)
+    """
+    results = TestResults(language=language, framework=framework)
+    
+    try:
+        root = ET.fromstring(content)
+    except ET.ParseError as e:
+        results.tests.append(TestResult(name="junit-xml", status="error", message=f"Unreadable report: {e}"))
+        _count(results)
+        return results
+    
+    suites = [root] if root.tag == "testsuite" else list(root.iter("testsuite"))
+    
+    for suite in suites:
+        for case in suite.findall("testcase"):
+            results.tests.append(_parse_testcase(case, suite.get("name")))
+        results.duration += _float(suite.get("time"))
+    
+    _count(results)
+    return results
+
+
+def parse_junit_xml_files(files: Iterable[Path], language: str, framework: str) -> TestResults:
+    """
+    Parse and combine several JUnit XML reports (e.g. one per test class).
+    
+    Args:
+        files: Report files
+        language: Language for the results
+        framework: Framework for the results
+        
+    Returns:
+        Combined TestResults
+    """
+    combined = TestResults(language=language, framework=framework)
+    
+    for report in files:
+        try:
+            content = Path(report).read_text(encoding='utf-8', errors='replace')
+        except OSError:
+            continue
+        results = parse_junit_xml(content, language, framework)
+        combined.tests.extend(results.tests)
+        combined.duration += results.duration
+    
+    _count(combined)
+    return combined
+
+
+def _parse_testcase(case: ET.Element, suite_name: str) -> TestResult:
+    """Turn a `<testcase>` into a TestResult."""
+    status, message, traceback = "passed", None, None
+    
+    for tag, case_status in (("failure", "failed"), ("error", "error"), ("skipped", "skipped")):
+        element = case.find(tag)
+        if element is not None:
+            status = case_status
+            message = element.get("message") or None
+            text = (element.text or "").strip()
+            if case_status == "skipped":
+                message = message or text or None
+            else:
+                traceback = text or None
+                message = message or (text.splitlines()[0] if text else None)
+            break
+    
+    return TestResult(
+        name=case.get("name", "unknown"),
+        status=status,
+        duration=_float(case.get("time")),
+        message=message,
+        traceback=traceback,
+        file_path=case.get("file"),
+        suite=case.get("classname") or suite_name
+    )
+
+
+def _count(results: TestResults) -> None:
+    """Derive the counters from the per-test statuses."""
+    statuses: List[str] = [t.status for t in results.tests]
+    results.total = len(statuses)
+    results.passed = statuses.count("passed")
+    results.failed = statuses.count("failed")
+    results.skipped = statuses.count("skipped")
+    results.errors = statuses.count("error")
+
+
+def _float(value) -> float:
+    try:
+        return float(value)
+    except (TypeError, ValueError):
+        return 0.0
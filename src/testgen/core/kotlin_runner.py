@@ -1,24 +1,55 @@
-"""Kotlin Test Runner (JUnit)."""
+"""
+Kotlin Test Runner (JUnit 5 / Kotest).
 
+Runs Gradle's `test` task from the project root, preferring the `./gradlew`
+wrapper, and reads the JUnit XML reports Gradle writes to
+`build/test-results/` instead of scraping the console output.
+"""
+
+import os
+import re
 import subprocess
+import time
 from pathlib import Path
 from typing import List, Optional
+
 from .base_runner import BaseTestRunner, TestResults, TestResult
+from .junit_xml import parse_junit_xml_files
+
+
+GRADLE_BUILD_FILES = ("build.gradle.kts", "build.gradle")
+GRADLE_SETTINGS_FILES = ("settings.gradle.kts", "settings.gradle")
+
+# Kotest test declarations: test("..."), should("..."), it("..."), "name" { ... }
+KOTEST_CASE = re.compile(
+    r'^\s*(?:(?:test|should|it|expect|[Tt]hen)\s*\(\s*"|"[^"\n]*"\s*(?:\.config\([^)]*\))?\s*\{)',
+    re.MULTILINE
+)
 
 
 class KotlinTestRunner(BaseTestRunner):
-    """Test runner for Kotlin projects using Gradle test."""
+    """Test runner for Kotlin projects using Gradle test (JUnit 5 or Kotest)."""
     
-    def __init__(self, verbose: bool = False):
+    def __init__(self, verbose: bool = False, framework: str = "junit"):
+        """
+        Initialize Kotlin runner.
+        
+        Args:
+            verbose: Enable verbose output
+            framework: "junit" or "kotest" (see `LanguageDetector`)
+        """
         super().__init__(verbose)
+        self.framework = framework
     
     def get_language(self) -> str:
         return "kotlin"
     
     def get_framework(self) -> str:
-        return "junit"
+        return self.framework
     
     def get_test_patterns(self) -> List[str]:
+        if self.framework == "kotest":
+            return ["*Test.kt", "*Spec.kt"]
         return ["*Test.kt"]
     
     def supports_coverage(self) -> bool:
@@ -28,12 +59,13 @@ class KotlinTestRunner(BaseTestRunner):
         return True
     
     def discover_tests(self, test_dir: str, pattern: Optional[str] = None) -> List[Path]:
-        if pattern is None:
-            pattern = "*Test.kt"
         test_path = Path(test_dir)
         if not test_path.exists():
             return []
-        return sorted(list(test_path.rglob(pattern)))
+        
+        patterns = [pattern] if pattern else self.get_test_patterns()
+        test_files = {f for p in patterns for f in test_path.rglob(p)}
+        return sorted(f for f in test_files if "build" not in f.relative_to(test_path).parts)
     
     def count_tests(self, test_dir: str, pattern: Optional[str] = None) -> int:
         test_files = self.discover_tests(test_dir, pattern)
@@ -42,20 +74,162 @@ class KotlinTestRunner(BaseTestRunner):
             try:
                 content = test_file.read_text()
                 total += content.count("@Test")
+                if "io.kotest" in content:
+                    total += len(KOTEST_CASE.findall(content))
             except:
                 pass
         return total
     
+    def find_project_root(self, test_dir: str) -> Path:
+        """
+        Find the Gradle project root for a test directory.
+        
+        The directory holding the `gradlew` wrapper wins, then the one with
+        the settings file, then the nearest build file.
+        
+        Args:
+            test_dir: Test directory (e.g. src/test/kotlin)
+            
+        Returns:
+            Project root (the test directory itself if nothing is found)
+        """
+        start = Path(test_dir).resolve()
+        candidates = [start, *start.parents]
+        
+        for markers in (("gradlew",), GRADLE_SETTINGS_FILES, GRADLE_BUILD_FILES):
+            for directory in candidates:
+                if any((directory / marker).exists() for marker in markers):
+                    return directory
+        
+        return start
+    
+    def find_module_dir(self, test_dir: str, project_root: Path) -> Path:
+        """Nearest directory with a Gradle build file between the tests and the root."""
+        start = Path(test_dir).resolve()
+        for directory in [start, *start.parents]:
+            if any((directory / f).exists() for f in GRADLE_BUILD_FILES):
+                return directory
+            if directory == project_root:
+                break
+        return project_root
+    
+    def _gradle_executable(self, project_root: Path) -> str:
+        if os.name == "nt" and (project_root / "gradlew.bat").exists():
+            return str(project_root / "gradlew.bat")
+        if (project_root / "gradlew").exists():
+            return "./gradlew"
+        return "gradle"
+    
     def build_command(self, test_dir: str, pattern: Optional[str] = None, **kwargs) -> List[str]:
-        return ["gradle", "test"]
+        project_root = self.find_project_root(test_dir)
+        module_dir = self.find_module_dir(test_dir, project_root)
+        
+        # Subprojects run their own task (":app:test") so other modules aren't tested
+        task = "test"
+        if module_dir != project_root:
+            task = ":" + ":".join(module_dir.relative_to(project_root).parts) + ":test"
+        
+        cmd = [self._gradle_executable(project_root), task, "--console=plain"]
+        
+        # Test filters: explicit ones (retries) or a class/method pattern
+        filters = list(kwargs.get("tests") or [])
+        if not filters and pattern and not pattern.endswith(".kt"):
+            filters.append(pattern)
+        for test_filter in filters:
+            cmd.extend(["--tests", test_filter])
+        
+        cmd.extend(kwargs.get("extra_args") or [])
+        return cmd
     
     def run_tests(self, test_dir: str, pattern: Optional[str] = None, **kwargs) -> TestResults:
+        project_root = self.find_project_root(test_dir)
+        module_dir = self.find_module_dir(test_dir, project_root)
         cmd = self.build_command(test_dir, pattern, **kwargs)
+        
+        if self.verbose:
+            print(f"Running: {' '.join(cmd)} (in {project_root})")
+        
+        started = time.time()
         try:
-            result = subprocess.run(cmd, capture_output=True, text=True, timeout=300)
-            return self._parse_output(result)
-        except:
+            result = subprocess.run(
+                cmd,
+                capture_output=True,
+                text=True,
+                cwd=project_root,
+                timeout=kwargs.get("timeout", 600)
+            )
+        except subprocess.TimeoutExpired:
             return TestResults(total=0, errors=1, language=self.get_language(), framework=self.get_framework())
+        except Exception:
+            return TestResults(total=0, errors=1, language=self.get_language(), framework=self.get_framework())
+        
+        results = self._parse_reports(module_dir, since=started)
+        if results is None:
+            return self._parse_output(result)
+        
+        if result.returncode != 0 and results.success:
+            # Gradle failed without failing tests: compilation error, daemon crash, ...
+            results.tests.append(TestResult(
+                name="gradle test",
+                status="error",
+                message=(result.stderr or result.stdout or "").strip()[-2000:] or None
+            ))
+            results.errors += 1
+            results.total += 1
+        
+        return results
+    
+    def rerun_tests(
+        self,
+        test_dir: str,
+        tests: List[TestResult],
+        pattern: Optional[str] = None,
+        **kwargs
+    ) -> TestResults:
+        """
+        Re-run only the given tests with Gradle `--tests` filters.
+        
+        JUnit tests are filtered by `Class.method`; Kotest test names are
+        free text, so Kotest specs are re-run as a whole.
+        """
+        filters = []
+        for test in tests:
+            if not test.suite:
+                continue
+            if self.framework == "kotest":
+                test_filter = test.suite
+            else:
+                # Parameterized display names look like "adds(int)[1]"
+                method = re.split(r'[(\[]', test.name)[0].strip()
+                test_filter = f"{test.suite}.{method}"
+            if test_filter not in filters:
+                filters.append(test_filter)
+        
+        if not filters:
+            return super().rerun_tests(test_dir, tests, pattern, **kwargs)
+        
+        return self.run_tests(test_dir, pattern, **{**kwargs, "tests": filters})
+    
+    def _parse_reports(self, module_dir: Path, since: Optional[float] = None) -> Optional[TestResults]:
+        """
+        Parse Gradle's JUnit XML reports below `build/test-results`.
+        
+        Reports written by this run are preferred; when the task was
+        up-to-date (nothing written), the existing reports still describe
+        the current code.
+        
+        Returns:
+            TestResults, or None if there are no reports
+        """
+        reports = sorted(module_dir.glob("**/build/test-results/**/TEST-*.xml"))
+        if not reports:
+            return None
+        
+        if since is not None:
+            fresh = [r for r in reports if r.stat().st_mtime >= since - 1]
+            reports = fresh or reports
+        
+        return parse_junit_xml_files(reports, self.get_language(), self.get_framework())
     
     def _parse_output(self, result: subprocess.CompletedProcess) -> TestResults:
         results = TestResults(language=self.get_language(), framework=self.get_framework())
@@ -77,6 +251,6 @@ class KotlinTestRunner(BaseTestRunner):
     def validate_test_file(self, test_file: str) -> bool:
         try:
             content = Path(test_file).read_text()
-            return '@Test' in content
+            return '@Test' in content or 'io.kotest' in content
         except:
             return False
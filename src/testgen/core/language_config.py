@@ -27,6 +27,7 @@ class Language(Enum):
     C = "c"
     HTML = "html"
     CSS = "css"
+    SHELL = "shell"
     UNKNOWN = "unknown"
 
 
@@ -247,7 +248,7 @@ LANGUAGE_CONFIGS = {
         language=Language.SWIFT,
         file_extensions=[".swift"],
         test_file_patterns=["*Tests.swift"],
-        test_frameworks=["xctest"],
+        test_frameworks=["xctest", "swift-testing"],
         default_framework="xctest",
         comment_style="//",
         import_keyword="import",
@@ -266,8 +267,8 @@ LANGUAGE_CONFIGS = {
         name="Kotlin",
         language=Language.KOTLIN,
         file_extensions=[".kt", ".kts"],
-        test_file_patterns=["*Test.kt"],
-        test_frameworks=["junit", "kotlintest"],
+        test_file_patterns=["*Test.kt", "*Spec.kt"],
+        test_frameworks=["junit", "kotest"],
         default_framework="junit",
         comment_style="//",
         import_keyword="import",
@@ -341,6 +342,26 @@ LANGUAGE_CONFIGS = {
         test_file_suffix=".test",
         tree_sitter_language="css"
     ),
+    
+    Language.SHELL: LanguageConfig(
+        name="Shell",
+        language=Language.SHELL,
+        file_extensions=[".sh", ".bash"],
+        test_file_patterns=["*.bats"],
+        test_frameworks=["bats"],
+        default_framework="bats",
+        comment_style="#",
+        import_keyword="source",
+        test_directory="test",
+        assertion_style="run",
+        function_pattern="function",
+        class_pattern="",
+        test_function_prefix="@test",
+        test_class_prefix="",
+        test_file_prefix="",
+        test_file_suffix="",
+        tree_sitter_language="bash"
+    ),
 }
 
 
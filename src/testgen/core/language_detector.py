@@ -18,6 +18,9 @@ class Language(Enum):
     GO = "go"
     CSHARP = "csharp"
     RUBY = "ruby"
+    KOTLIN = "kotlin"
+    SWIFT = "swift"
+    SHELL = "shell"
     UNKNOWN = "unknown"
 
 
@@ -37,6 +40,13 @@ class TestFramework(Enum):
     JUNIT = "junit"
     TESTNG = "testng"
     
+    # Kotlin (JUnit above)
+    KOTEST = "kotest"
+    
+    # Swift
+    XCTEST = "xctest"
+    SWIFT_TESTING = "swift-testing"
+    
     # Go
     GO_TEST = "go_test"
     
@@ -49,6 +59,9 @@ class TestFramework(Enum):
     RSPEC = "rspec"
     MINITEST = "minitest"
     
+    # Shell
+    BATS = "bats"
+    
     UNKNOWN = "unknown"
 
 
@@ -75,6 +88,7 @@ class LanguageDetector:
         "build.gradle.kts": Language.JAVA,
         "go.mod": Language.GO,
         "Gemfile": Language.RUBY,
+        "Package.swift": Language.SWIFT,
         "*.csproj": Language.CSHARP,
         "*.sln": Language.CSHARP,
     }
@@ -104,6 +118,9 @@ class LanguageDetector:
             else:
                 # Direct file check
                 if (path / filename).exists():
+                    if language == Language.JAVA:
+                        # Maven/Gradle build Kotlin as well as Java
+                        return self._detect_jvm_language(path)
                     return language
         
         # Check by file extensions
@@ -115,6 +132,8 @@ class LanguageDetector:
             return Language.JAVASCRIPT
         elif file_counts.get(".ts", 0) > 0:
             return Language.TYPESCRIPT
+        elif file_counts.get(".kt", 0) > file_counts.get(".java", 0):
+            return Language.KOTLIN
         elif file_counts.get(".java", 0) > 0:
             return Language.JAVA
         elif file_counts.get(".go", 0) > 0:
@@ -123,6 +142,8 @@ class LanguageDetector:
             return Language.CSHARP
         elif file_counts.get(".rb", 0) > 0:
             return Language.RUBY
+        elif file_counts.get(".sh", 0) + file_counts.get(".bash", 0) + file_counts.get(".bats", 0) > 0:
+            return Language.SHELL
         
         return Language.UNKNOWN
     
@@ -152,12 +173,18 @@ class LanguageDetector:
             return self._detect_javascript_framework(path)
         elif language == Language.JAVA:
             return self._detect_java_framework(path)
+        elif language == Language.KOTLIN:
+            return self._detect_kotlin_framework(path)
+        elif language == Language.SWIFT:
+            return self._detect_swift_framework(path)
         elif language == Language.GO:
             return TestFramework.GO_TEST
         elif language == Language.CSHARP:
             return self._detect_csharp_framework(path)
         elif language == Language.RUBY:
             return self._detect_ruby_framework(path)
+        elif language == Language.SHELL:
+            return TestFramework.BATS
         
         return TestFramework.UNKNOWN
     
@@ -251,6 +278,74 @@ class LanguageDetector:
         
         return TestFramework.JUNIT
     
+    def _detect_jvm_language(self, path: Path) -> Language:
+        """Tell Kotlin from Java for a Maven/Gradle project."""
+        build_files = list(path.glob("build.gradle*")) + list(path.glob("pom.xml"))
+        for build_file in build_files:
+            content = build_file.read_text()
+            if (
+                "org.jetbrains.kotlin" in content
+                or 'kotlin("' in content
+                or "kotlin-maven-plugin" in content
+            ):
+                return Language.KOTLIN
+        
+        # Kotlin DSL build files (build.gradle.kts) are common in Java projects too,
+        # so fall back to counting sources
+        file_counts = self._count_file_extensions(path)
+        if file_counts.get(".kt", 0) > file_counts.get(".java", 0):
+            return Language.KOTLIN
+        
+        return Language.JAVA
+    
+    def _detect_kotlin_framework(self, path: Path) -> TestFramework:
+        """Detect Kotlin test framework (Kotest or JUnit 5)."""
+        # Check build files and the version catalog
+        build_files = (
+            list(path.glob("build.gradle*"))
+            + list(path.glob("*/build.gradle*"))
+            + list(path.glob("gradle/*.versions.toml"))
+            + list(path.glob("pom.xml"))
+        )
+        for build_file in build_files:
+            content = build_file.read_text()
+            if "io.kotest" in content or "kotest-runner" in content:
+                return TestFramework.KOTEST
+        
+        # Check test files for Kotest imports
+        test_files = list(path.rglob("*Test.kt")) + list(path.rglob("*Spec.kt"))
+        for test_file in test_files[:5]:  # Check first 5 files
+            try:
+                if "import io.kotest" in test_file.read_text():
+                    return TestFramework.KOTEST
+            except:
+                pass
+        
+        return TestFramework.JUNIT
+    
+    def _detect_swift_framework(self, path: Path) -> TestFramework:
+        """Detect Swift test framework (swift-testing or XCTest)."""
+        test_dir = path / "Tests" if (path / "Tests").exists() else path
+        test_files = list(test_dir.rglob("*.swift"))
+        
+        xctest_files = 0
+        swift_testing_files = 0
+        for test_file in test_files[:20]:  # Check first 20 files
+            try:
+                content = test_file.read_text()
+                if "import Testing" in content:
+                    swift_testing_files += 1
+                elif "import XCTest" in content:
+                    xctest_files += 1
+            except:
+                pass
+        
+        # Mixed packages: the framework new tests are being written in wins
+        if swift_testing_files and swift_testing_files >= xctest_files:
+            return TestFramework.SWIFT_TESTING
+        
+        return TestFramework.XCTEST
+    
     def _detect_csharp_framework(self, path: Path) -> TestFramework:
         """Detect C# test framework."""
         # Check .csproj files
@@ -326,14 +421,22 @@ class LanguageDetector:
             ".js": Language.JAVASCRIPT,
             ".ts": Language.TYPESCRIPT,
             ".java": Language.JAVA,
+            ".kt": Language.KOTLIN,
+            ".swift": Language.SWIFT,
             ".go": Language.GO,
             ".cs": Language.CSHARP,
             ".rb": Language.RUBY,
+            ".sh": Language.SHELL,
+            ".bash": Language.SHELL,
         }
         
-        languages_found = set()
+        # Files per language (a language can have several extensions)
+        files_per_language = {}
         for ext, count in extensions.items():
-            if ext in lang_indicators and count > 5:  # At least 5 files
-                languages_found.add(lang_indicators[ext])
+            if ext in lang_indicators:
+                language = lang_indicators[ext]
+                files_per_language[language] = files_per_language.get(language, 0) + count
+        
+        languages_found = {lang for lang, count in files_per_language.items() if count > 5}  # At least 5 files
         
         return len(languages_found) > 1
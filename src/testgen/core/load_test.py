@@ -0,0 +1,615 @@
+"""
+Local Load Tests for HTTP Handlers.
+
+Generates small Go load tests that drive concurrent requests against a
+handler served in-process by `httptest`, and runs them:
+
+    go test -tags testgen_load -run '^TestLoad' -v .
+
+Handlers are loaded on the method and path they are registered with
+(`mux.HandleFunc("POST /users", CreateUser)`, chi's `r.Get(...)`, gorilla's
+`.Methods(...)`); handlers registered nowhere in the package need the route
+given explicitly. Each test reports every request latency, the request
+count, errors (transport errors and 5xx responses) and 4xx responses; they
+become `BenchmarkResult`s with throughput, latency percentiles and error
+rates, so `BenchmarkStore` tracks them like any other benchmark. A test
+where every response is 4xx loaded the wrong route and is reported as an
+error. Services that aren't Go handlers can be
+started locally and loaded over HTTP with `LoadTester.run_url`.
+"""
+
+import http.client
+import json
+import os
+import re
+import shlex
+import subprocess
+import threading
+import time
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Dict, List, Optional, Any, Tuple
+from urllib.parse import urlsplit
+
+from .benchmark import RESULT_MARKER, calculate_statistics, format_duration
+from .result_models import BenchmarkResult, Language, TestFramework
+
+
+LOAD_TEST_FILE = "testgen_load_test.go"
+LOAD_BUILD_TAG = "testgen_load"  # keeps load tests out of plain `go test`
+
+# Latencies kept per test (evenly spaced over the sorted samples)
+MAX_SAMPLES = 10000
+
+LOCAL_HOSTS = ("localhost", "127.0.0.1", "::1", "0.0.0.0")
+
+# func Health(w http.ResponseWriter, r *http.Request)
+GO_HANDLER_FUNC = re.compile(
+    r'^func\s+([A-Z]\w*)\s*\(\s*\w+\s+http\.ResponseWriter\s*,\s*\w+\s+\*http\.Request\s*\)',
+    re.MULTILINE
+)
+# func NewRouter() http.Handler  |  func Routes() *http.ServeMux
+GO_HANDLER_CONSTRUCTOR = re.compile(
+    r'^func\s+([A-Z]\w*)\s*\(\s*\)\s*(?:http\.Handler|\*http\.ServeMux|http\.HandlerFunc)\s*\{',
+    re.MULTILINE
+)
+GO_PACKAGE = re.compile(r'^package\s+(\w+)', re.MULTILINE)
+GO_FUNC_START = re.compile(r'^func\s', re.MULTILINE)
+
+# mux.HandleFunc("POST /users/{id}", Update)  |  r.Handle("/x", http.HandlerFunc(X)).Methods("PUT")
+GO_HANDLE_ROUTE = re.compile(
+    r'\b\w+\.Handle(?:Func)?\(\s*"([^"]+)"\s*,\s*(?:http\.HandlerFunc\(\s*)?(?:\w+\.)?([A-Z]\w*)\b'
+    r'(?:\(\s*\))?\s*\)*(?:\s*\.\s*Methods\(\s*"([A-Z]+)")?'
+)
+# r.Get("/health", Health) (chi), e.GET("/health", Health) (echo, gin)
+GO_METHOD_ROUTE = re.compile(
+    r'\b\w+\.(Get|Post|Put|Patch|Delete|Head|Options|GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS)'
+    r'\(\s*"([^"]+)"\s*,\s*(?:http\.HandlerFunc\(\s*)?(?:\w+\.)?([A-Z]\w*)\b'
+)
+# Path parameters: {id}, {path...}, :id, *rest
+PATH_PARAMETER = re.compile(r'\{[^}/]+\}|(?<=/):\w+|(?<=/)\*\w*$')
+
+GO_LOAD_TEST = """//go:build testgen_load
+
+// Code generated by testgen load. Load tests for the package's HTTP handlers:
+//
+//	go test -tags testgen_load -run '^TestLoad' -v .
+//
+// TESTGEN_LOAD_CONCURRENCY (default 10) and TESTGEN_LOAD_DURATION (default 10s)
+// set the number of concurrent clients and how long they send requests.
+
+package {package}
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+{tests}
+type testgenLoadResult struct {
+	Name         string    `json:"name"`
+	Concurrency  int       `json:"concurrency"`
+	Duration     float64   `json:"duration"`
+	Requests     int       `json:"requests"`
+	Errors       int       `json:"errors"`
+	ClientErrors int       `json:"client_errors"`
+	Samples      []float64 `json:"samples"`
+}
+
+// testgenLoad sends requests from concurrent clients to handler, served by
+// httptest, for the configured duration and prints the latencies for testgen.
+func testgenLoad(t *testing.T, handler http.Handler, method, path string) {
+	t.Helper()
+	concurrency := 10
+	if v, err := strconv.Atoi(os.Getenv("TESTGEN_LOAD_CONCURRENCY")); err == nil && v > 0 {
+		concurrency = v
+	}
+	duration := 10 * time.Second
+	if v, err := time.ParseDuration(os.Getenv("TESTGEN_LOAD_DURATION")); err == nil && v > 0 {
+		duration = v
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	client := server.Client()
+	client.Transport.(*http.Transport).MaxIdleConnsPerHost = concurrency
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		latencies []float64
+		failures  int
+		rejected  int
+	)
+	start := time.Now()
+	deadline := start.Add(duration)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var local []float64
+			failed, clientErrors := 0, 0
+			for time.Now().Before(deadline) {
+				req, err := http.NewRequest(method, server.URL+path, nil)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				began := time.Now()
+				resp, err := client.Do(req)
+				if err == nil {
+					io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}
+				local = append(local, time.Since(began).Seconds())
+				if err != nil || resp.StatusCode >= 500 {
+					failed++
+				} else if resp.StatusCode >= 400 {
+					clientErrors++
+				}
+			}
+			mu.Lock()
+			latencies = append(latencies, local...)
+			failures += failed
+			rejected += clientErrors
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	out, err := json.Marshal(testgenLoadResult{
+		Name:         t.Name(),
+		Concurrency:  concurrency,
+		Duration:     time.Since(start).Seconds(),
+		Requests:     len(latencies),
+		Errors:       failures,
+		ClientErrors: rejected,
+		Samples:      testgenDownsample(latencies, {max_samples}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println("{marker}" + string(out))
+}
+
+// testgenDownsample keeps at most n evenly spaced latencies of the sorted samples.
+func testgenDownsample(latencies []float64, n int) []float64 {
+	sort.Float64s(latencies)
+	if len(latencies) <= n {
+		return latencies
+	}
+	kept := make([]float64, n)
+	for i := range kept {
+		kept[i] = latencies[i*len(latencies)/n]
+	}
+	return kept
+}
+"""
+
+GO_LOAD_TEST_CASE = """
+func TestLoad{name}(t *testing.T) {
+	testgenLoad(t, {handler}, "{method}", "{path}")
+}
+"""
+
+
+@dataclass
+class GoHandler:
+    """A Go HTTP handler that can be served by httptest without setup."""
+    
+    name: str
+    constructor: bool = False     # func X() http.Handler rather than a HandlerFunc
+    method: Optional[str] = None  # route it is registered on (None: unknown)
+    path: Optional[str] = None
+    
+    @property
+    def expression(self) -> str:
+        """Go expression for the http.Handler."""
+        return f"{self.name}()" if self.constructor else f"http.HandlerFunc({self.name})"
+    
+    @property
+    def routed(self) -> bool:
+        return self.method is not None and self.path is not None
+
+
+def find_go_routes(code: str) -> List[Tuple[int, str, str, str]]:
+    """
+    Find route registrations in Go source.
+    
+    Args:
+        code: Go source
+        
+    Returns:
+        (offset, handler name, method, request path) in source order; path
+        parameters are filled with a sample value
+    """
+    routes = []
+    for match in GO_HANDLE_ROUTE.finditer(code):
+        pattern, handler, methods = match.groups()
+        # Go 1.22 patterns: "POST /users", "GET example.com/x"
+        method, _, path = pattern.partition(" ") if " " in pattern.strip() else ("", "", pattern)
+        path = path.strip()
+        if not path.startswith("/"):
+            path = "/" + path.split("/", 1)[1] if "/" in path else "/"
+        routes.append((match.start(), handler, (methods or method or "GET").upper(), _sample_path(path)))
+    for match in GO_METHOD_ROUTE.finditer(code):
+        method, path, handler = match.groups()
+        routes.append((match.start(), handler, method.upper(), _sample_path(path)))
+    return sorted(routes)
+
+
+def find_go_handlers(code: str, routes: Optional[Dict[str, str]] = None) -> List[GoHandler]:
+    """
+    Find exported package-level handlers and handler constructors.
+    
+    Methods (`func (s *Server) ServeX`) are skipped: they need a receiver
+    built by hand. A handler gets the route it is registered on in `code`;
+    a constructor the first route registered in its body.
+    
+    Args:
+        code: Go source (all of the package's files, for registrations in other files)
+        routes: Handler name -> "METHOD /path", for handlers registered elsewhere
+        
+    Returns:
+        Handlers in source order (`routed` is False when the route is unknown)
+    """
+    registered = find_go_routes(code)
+    starts = [m.start() for m in GO_FUNC_START.finditer(code)] + [len(code)]
+    
+    found = []
+    for match in GO_HANDLER_FUNC.finditer(code):
+        handler = GoHandler(match.group(1))
+        route = next(((method, path) for _, name, method, path in registered if name == handler.name), None)
+        if route:
+            handler.method, handler.path = route
+        found.append((match.start(), handler))
+    for match in GO_HANDLER_CONSTRUCTOR.finditer(code):
+        handler = GoHandler(match.group(1), constructor=True)
+        end = next(start for start in starts if start > match.start())
+        route = next(((method, path) for offset, _, method, path in registered if match.start() < offset < end), None)
+        if route:
+            handler.method, handler.path = route
+        found.append((match.start(), handler))
+    
+    handlers = [handler for _, handler in sorted(found, key=lambda item: item[0])]
+    for handler in handlers:
+        given = (routes or {}).get(handler.name)
+        if given:
+            method, _, path = given.strip().partition(" ")
+            handler.method, handler.path = method.upper(), _sample_path(path.strip() or "/")
+    return handlers
+
+
+def generate_go_load_test(package: str, handlers: List[GoHandler]) -> str:
+    """
+    Generate the Go load test file for a package.
+    
+    Args:
+        package: Go package name
+        handlers: Handlers to load
+        
+    Returns:
+        Go source (build-tagged with `testgen_load`)
+    """
+    tests = "".join(
+        GO_LOAD_TEST_CASE
+        .replace("{name}", handler.name)
+        .replace("{handler}", handler.expression)
+        .replace("{method}", handler.method)
+        .replace("{path}", handler.path)
+        for handler in handlers
+    )
+    return (
+        GO_LOAD_TEST
+        .replace("{package}", package)
+        .replace("{tests}", tests)
+        .replace("{max_samples}", str(MAX_SAMPLES))
+        .replace("{marker}", RESULT_MARKER)
+    )
+
+
+def write_go_load_test(package_dir: str, routes: Optional[Dict[str, str]] = None) -> Optional[Path]:
+    """
+    Write `testgen_load_test.go` for the routed handlers in a package directory.
+    
+    Args:
+        package_dir: Go package directory
+        routes: Handler name -> "METHOD /path", for handlers the package doesn't register
+        
+    Returns:
+        Path of the written file, or None when the package has no handlers with a route
+    """
+    package = None
+    sources = []
+    for source in sorted(Path(package_dir).glob("*.go")):
+        if source.name.endswith("_test.go"):
+            continue
+        code = source.read_text(encoding='utf-8', errors='ignore')
+        match = GO_PACKAGE.search(code)
+        if match and package is None:
+            package = match.group(1)
+        sources.append(code)
+    
+    # One source, so registrations in router.go route handlers of handlers.go
+    handlers = [h for h in find_go_handlers("\n".join(sources), routes) if h.routed]
+    if not handlers or package is None:
+        return None
+    
+    path = Path(package_dir) / LOAD_TEST_FILE
+    path.write_text(generate_go_load_test(package, handlers), encoding='utf-8')
+    return path
+
+
+class LoadTester:
+    """
+    Run local load tests and report them as benchmark results.
+    
+    Example:
+        >>> tester = LoadTester(concurrency=20, duration=5)
+        >>> for result in tester.run_go("internal/api"):
+        ...     print(result.name, result.throughput, result.p99)
+    """
+    
+    def __init__(self, concurrency: int = 10, duration: float = 10.0, timeout: Optional[int] = None):
+        """
+        Initialize load tester.
+        
+        Args:
+            concurrency: Concurrent clients
+            duration: Seconds each load test sends requests
+            timeout: Timeout per `go test` process (default: generous for the duration)
+        """
+        self.concurrency = concurrency
+        self.duration = duration
+        self.timeout = timeout or int(duration * 10 + 300)
+    
+    def run_go(self, package_dir: str, names: Optional[List[str]] = None) -> List[BenchmarkResult]:
+        """Run a package's generated load tests (`TestLoad*`)."""
+        run = "^(" + "|".join(re.escape(n) for n in names) + ")$" if names else "^TestLoad"
+        cmd = ["go", "test", "-tags", LOAD_BUILD_TAG, "-run", run, "-count=1", "-v", "."]
+        env = {
+            **os.environ,
+            "TESTGEN_LOAD_CONCURRENCY": str(self.concurrency),
+            "TESTGEN_LOAD_DURATION": f"{self.duration}s",
+        }
+        
+        try:
+            result = subprocess.run(
+                cmd,
+                cwd=package_dir,
+                capture_output=True,
+                text=True,
+                timeout=self.timeout,
+                env=env
+            )
+        except subprocess.TimeoutExpired:
+            return [self._error_result(package_dir, f"Load tests timed out after {self.timeout}s")]
+        except FileNotFoundError:
+            return [self._error_result(package_dir, "go not found")]
+        
+        results = self._parse_go_output(result.stdout, package_dir)
+        if not results and result.returncode != 0:
+            return [self._error_result(package_dir, (result.stderr or result.stdout).strip())]
+        return results
+    
+    def run_url(
+        self,
+        url: str,
+        name: Optional[str] = None,
+        method: str = "GET",
+        command: Optional[str] = None,
+        cwd: Optional[str] = None,
+        startup_timeout: float = 30.0
+    ) -> BenchmarkResult:
+        """
+        Load a locally running service over HTTP.
+        
+        Args:
+            url: URL on localhost
+            name: Result name (default: "<method> <path>")
+            method: HTTP method
+            command: Command that starts the service (stopped afterwards)
+            cwd: Working directory for the command
+            startup_timeout: Seconds to wait for the service to answer
+            
+        Returns:
+            BenchmarkResult for the URL
+        """
+        parts = urlsplit(url)
+        name = name or f"{method} {parts.path or '/'}"
+        if parts.hostname not in LOCAL_HOSTS:
+            return self._error_result(url, f"Load tests only run against local services, not {parts.hostname}", name)
+        
+        process = None
+        if command:
+            process = subprocess.Popen(
+                shlex.split(command), cwd=cwd, stdout=subprocess.DEVNULL, stderr=subprocess.DEVNULL
+            )
+        
+        try:
+            if not _wait_for(parts, startup_timeout):
+                return self._error_result(url, f"Service did not answer within {startup_timeout}s", name)
+            data = self._drive(parts, method)
+        finally:
+            if process is not None:
+                process.terminate()
+                try:
+                    process.wait(timeout=10)
+                except subprocess.TimeoutExpired:
+                    process.kill()
+        
+        return self._build_result(name, url, data, Language.UNKNOWN, TestFramework.UNKNOWN)
+    
+    def _drive(self, parts, method: str) -> Dict[str, Any]:
+        """Send requests from concurrent threads until the duration is over."""
+        lock = threading.Lock()
+        latencies: List[float] = []
+        failures, rejected = [0], [0]
+        path = (parts.path or "/") + (f"?{parts.query}" if parts.query else "")
+        connection_class = http.client.HTTPSConnection if parts.scheme == "https" else http.client.HTTPConnection
+        
+        start = time.perf_counter()
+        deadline = start + self.duration
+        
+        def client():
+            local, failed, client_errors = [], 0, 0
+            connection = connection_class(parts.hostname, parts.port, timeout=30)
+            while time.perf_counter() < deadline:
+                began = time.perf_counter()
+                try:
+                    connection.request(method, path)
+                    response = connection.getresponse()
+                    response.read()
+                    if response.status >= 500:
+                        failed += 1
+                    elif response.status >= 400:
+                        client_errors += 1
+                except (OSError, http.client.HTTPException):
+                    failed += 1
+                    connection.close()
+                    connection = connection_class(parts.hostname, parts.port, timeout=30)
+                local.append(time.perf_counter() - began)
+            connection.close()
+            with lock:
+                latencies.extend(local)
+                failures[0] += failed
+                rejected[0] += client_errors
+        
+        threads = [threading.Thread(target=client) for _ in range(self.concurrency)]
+        for thread in threads:
+            thread.start()
+        for thread in threads:
+            thread.join()
+        
+        ordered = sorted(latencies)
+        if len(ordered) > MAX_SAMPLES:
+            ordered = [ordered[i * len(ordered) // MAX_SAMPLES] for i in range(MAX_SAMPLES)]
+        
+        return {
+            "concurrency": self.concurrency,
+            "duration": time.perf_counter() - start,
+            "requests": len(latencies),
+            "errors": failures[0],
+            "client_errors": rejected[0],
+            "samples": ordered,
+        }
+    
+    def _parse_go_output(self, output: str, package_dir: str) -> List[BenchmarkResult]:
+        """Collect the result lines printed by `testgenLoad`."""
+        results = []
+        package = str(package_dir)
+        for line in output.split('\n'):
+            line = line.strip()
+            # ok  	example.com/api	21.3s: the import path, as for native benchmarks
+            status = re.match(r'^(?:ok|FAIL)\s+(\S+)\s', line)
+            if status:
+                package = status.group(1)
+                continue
+            if not line.startswith(RESULT_MARKER):
+                continue
+            try:
+                data = json.loads(line[len(RESULT_MARKER):])
+            except json.JSONDecodeError:
+                continue
+            results.append(self._build_result(
+                data["name"], str(package_dir), data, Language.GO, TestFramework.GO_TESTING
+            ))
+        for result in results:
+            result.file_path = package
+        return results
+    
+    def _build_result(
+        self,
+        name: str,
+        file_path: str,
+        data: Dict[str, Any],
+        language: Language,
+        framework: TestFramework
+    ) -> BenchmarkResult:
+        """BenchmarkResult with latency statistics, throughput and error rates."""
+        samples = data.get("samples") or []
+        requests = data.get("requests", len(samples))
+        duration = data.get("duration") or 0.0
+        client_errors = data.get("client_errors", 0)
+        error = None
+        if not requests:
+            error = "No requests completed"
+        elif client_errors == requests:
+            error = f"Every response was 4xx ({requests} requests): check the method and path"
+        return BenchmarkResult(
+            name=name,
+            file_path=file_path,
+            language=language,
+            framework=framework,
+            iterations=requests,
+            samples=samples,
+            concurrency=data.get("concurrency", self.concurrency),
+            throughput=requests / duration if duration else 0.0,
+            error_rate=data.get("errors", 0) / requests if requests else 0.0,
+            client_error_rate=client_errors / requests if requests else 0.0,
+            error=error,
+            **calculate_statistics(samples)
+        )
+    
+    def _error_result(self, target: str, message: str, name: Optional[str] = None) -> BenchmarkResult:
+        """Result for a target that could not be load tested at all."""
+        return BenchmarkResult(
+            name=name or Path(target).name,
+            file_path=str(target),
+            concurrency=self.concurrency,
+            error=message or "Load test failed"
+        )
+
+
+def _sample_path(path: str) -> str:
+    """Request path for a route pattern: path parameters get a sample value."""
+    return PATH_PARAMETER.sub("1", path.replace("{$}", "")) or "/"
+
+
+def _wait_for(parts, timeout: float) -> bool:
+    """Wait until something accepts connections on the URL's host and port."""
+    port = parts.port or (443 if parts.scheme == "https" else 80)
+    deadline = time.monotonic() + timeout
+    while True:
+        connection = http.client.HTTPConnection(parts.hostname, port, timeout=2)
+        try:
+            connection.connect()
+            return True
+        except OSError:
+            if time.monotonic() >= deadline:
+                return False
+            time.sleep(0.2)
+        finally:
+            connection.close()
+
+
+def format_load_report(results: List[BenchmarkResult]) -> str:
+    """Format load test results as a text table."""
+    lines = []
+    lines.append("=" * 100)
+    lines.append("LOAD TEST RESULTS")
+    lines.append("=" * 100)
+    lines.append(
+        f"{'Load test':<36} {'clients':>7} {'requests':>9} {'req/s':>9} {'p50':>10} {'p95':>10} {'p99':>10} {'errors':>7} {'4xx':>7}"
+    )
+    
+    for r in results:
+        if r.error:
+            lines.append(f"{r.name[:36]:<36} ERROR: {r.error.splitlines()[0]}")
+            continue
+        lines.append(
+            f"{r.name[:36]:<36} {r.concurrency or 0:>7} {r.iterations:>9} {r.throughput or 0:>9.1f} "
+            + " ".join(f"{format_duration(v):>10}" for v in (r.p50, r.p95, r.p99))
+            + f" {(r.error_rate or 0) * 100:>6.1f}% {(r.client_error_rate or 0) * 100:>6.1f}%"
+        )
+    
+    lines.append("=" * 100)
+    return "\n".join(lines)
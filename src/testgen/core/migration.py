@@ -0,0 +1,1342 @@
+"""
+Test Framework Migration.
+
+Rewrites existing test files from one framework to another:
+
+    unittest -> pytest     self.assertEqual(a, b)                 ->  assert a == b
+    stdlib   -> testify    if got != want { t.Errorf(...) }       ->  assert.Equal(t, want, got, ...)
+    testify  -> stdlib     require.NoError(t, err)                ->  if err != nil { t.Fatalf(...) }
+    jest     -> vitest     jest.fn()                              ->  vi.fn() (and vitest imports)
+    junit4   -> junit5     @Before, assertEquals("msg", a, b)     ->  @BeforeEach, assertEquals(a, b, "msg")
+
+The deterministic rewrites cover the common API. What they can't
+translate (subTest, jest.requireActual, JUnit rules, multi-statement
+checks, ...) is listed as leftovers with line numbers, and can be handed
+to the LLM together with the target framework's prompt requirements.
+
+Nothing is proposed unverified: the file is run before and after the
+migration and kept only if every test has the same outcome. Go runs use
+`go test -overlay` and never touch the file. Other languages overwrite
+the test file in place for the run, since conftest, relative imports and
+Java class names tie it to its location: the original is saved next to
+it (`<file>.testgen-orig`) and restored afterwards, on Ctrl-C and on
+SIGTERM, and a copy left behind by a killed run is restored by the next
+one. The result is a unified diff per file.
+"""
+
+import ast
+import json
+import re
+import shutil
+import signal
+import subprocess
+import tempfile
+import threading
+from contextlib import contextmanager
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Callable, Dict, Iterator, List, Optional, Tuple
+
+from .base_runner import TestResults
+from .go_runner import GoTestRunner
+from .junit_xml import parse_junit_xml_files
+from .language_config import Language
+from .python_runner import PythonTestRunner
+from .table_tests import _gofmt, find_test_functions, make_patch
+from .test_merger import TestMerger
+
+
+@dataclass
+class Leftover:
+    """A line the deterministic rewrite couldn't migrate."""
+    
+    line: int
+    text: str
+    reason: str
+    
+    def __str__(self) -> str:
+        return f"line {self.line}: {self.text.strip()}  ({self.reason})"
+
+
+@dataclass
+class Migration:
+    """One supported source -> target framework pair."""
+    
+    source: str
+    target: str
+    language: Language
+    rewrite: Callable[[str], str]
+    leftovers: List[Tuple[str, str]]       # (regex over the migrated code, reason)
+    check: Optional[Callable[[str], List["Leftover"]]] = None  # leftovers a regex can't find
+    template: Optional[Tuple[Language, str]] = None  # target's prompt template, if there is one
+    notes: List[str] = field(default_factory=list)   # target guidance for the LLM
+
+
+@dataclass
+class MigrationResult:
+    """Outcome of migrating one test file."""
+    
+    path: Path
+    migration: Migration
+    original: str
+    migrated: str
+    leftovers: List[Leftover] = field(default_factory=list)
+    outcomes: Dict[str, Tuple[str, str]] = field(default_factory=dict)  # test -> (before, after)
+    verified: bool = False
+    problem: Optional[str] = None
+    llm_used: bool = False
+    
+    @property
+    def changed(self) -> bool:
+        return self.migrated != self.original
+    
+    @property
+    def patch(self) -> str:
+        return make_patch(self.original, self.migrated, self.path)
+
+
+UNITTEST_COMPARISONS = {
+    "assertEqual": "==", "assertEquals": "==", "assertNotEqual": "!=", "assertNotEquals": "!=",
+    "assertDictEqual": "==", "assertListEqual": "==", "assertTupleEqual": "==", "assertSetEqual": "==",
+    "assertMultiLineEqual": "==",
+    "assertIs": "is", "assertIsNot": "is not", "assertIn": "in", "assertNotIn": "not in",
+    "assertGreater": ">", "assertGreaterEqual": ">=", "assertLess": "<", "assertLessEqual": "<=",
+}
+
+UNITTEST_CHECKS = {
+    "assertTrue": "{0}",
+    "assertFalse": "not {0}",
+    "assertIsNone": "{0} is None",
+    "assertIsNotNone": "{0} is not None",
+    "assertIsInstance": "isinstance({0}, {1})",
+    "assertNotIsInstance": "not isinstance({0}, {1})",
+    "assertRegex": "re.search({1}, {0})",
+    "assertNotRegex": "not re.search({1}, {0})",
+}
+
+UNITTEST_RAISES = {
+    "assertRaises": "pytest.raises",
+    "assertRaisesRegex": "pytest.raises",
+    "assertWarns": "pytest.warns",
+    "assertWarnsRegex": "pytest.warns",
+}
+
+UNITTEST_HOOKS = {
+    "setUp": "setup_method",
+    "tearDown": "teardown_method",
+    "setUpClass": "setup_class",
+    "tearDownClass": "teardown_class",
+}
+
+UNITTEST_LEFTOVERS = [
+    (r'self\.subTest\(', "subTest has no pytest equivalent: use @pytest.mark.parametrize"),
+    (r'self\.addCleanup\(', "addCleanup: use a yield fixture or teardown_method"),
+    (r'self\.(?:maxDiff|longMessage)\b', "unittest diff setting: pytest shows full diffs, drop it"),
+    (r'super\(\)\.(?:setUp|tearDown)\w*\(', "TestCase hook called on a base that no longer has it"),
+    (r'^\s*class\s+(?!Test)\w+\(.*\bTestCase\b', "class isn't named Test*: pytest wouldn't collect it without TestCase"),
+    (r'self\.assert\w+\(', "no direct pytest equivalent"),
+]
+
+
+def unittest_to_pytest(code: str) -> str:
+    """
+    Rewrite unittest TestCases as pytest classes with plain asserts.
+    
+    Args:
+        code: Python test module
+        
+    Returns:
+        Migrated source (unchanged if it doesn't parse)
+    """
+    try:
+        tree = ast.parse(code)
+    except SyntaxError:
+        return code
+    
+    lines = code.splitlines(keepends=True)
+    starts = [0]
+    for line in lines:
+        starts.append(starts[-1] + len(line))
+    
+    def offset(lineno: int, col: int) -> int:
+        return starts[lineno - 1] + len(lines[lineno - 1].encode('utf-8')[:col].decode('utf-8', errors='replace'))
+    
+    def span(node: ast.AST) -> Tuple[int, int]:
+        return offset(node.lineno, node.col_offset), offset(node.end_lineno, node.end_col_offset)
+    
+    def text(node: ast.AST) -> str:
+        return ast.get_source_segment(code, node)
+    
+    def operand(node: ast.AST) -> str:
+        if isinstance(node, (ast.BoolOp, ast.Compare, ast.IfExp, ast.Lambda, ast.NamedExpr)) or (
+            isinstance(node, ast.UnaryOp) and isinstance(node.op, ast.Not)
+        ):
+            return f"({text(node)})"
+        return text(node)
+    
+    edits: List[Tuple[int, int, str]] = []
+    testcase_names = {"TestCase"} if re.search(r'^from\s+unittest\s+import\s+.*\bTestCase\b', code, re.MULTILINE) else set()
+    raises_names = set()
+    needs = set()
+    
+    for node in ast.walk(tree):
+        # class TestX(unittest.TestCase): -> class TestX:
+        if isinstance(node, ast.ClassDef) and node.name.startswith("Test"):
+            bases = [b for b in node.bases if not _is_testcase(b, testcase_names)]
+            if len(bases) < len(node.bases) and not node.keywords:
+                start = code.index("(", offset(node.lineno, node.col_offset))
+                end = span(node.bases[-1])[1]
+                end = code.index(")", end) + 1
+                edits.append((start, end, f"({', '.join(text(b) for b in bases)})" if bases else ""))
+        
+        # setUp -> setup_method, ...
+        elif isinstance(node, ast.FunctionDef) and node.name in UNITTEST_HOOKS:
+            start = code.index(node.name, offset(node.lineno, node.col_offset))
+            edits.append((start, start + len(node.name), UNITTEST_HOOKS[node.name]))
+        
+        # with self.assertRaises(E) as cm: -> with pytest.raises(E) as cm:
+        elif isinstance(node, ast.With):
+            for item in node.items:
+                call = item.context_expr
+                name = _self_call(call)
+                if name not in UNITTEST_RAISES or not call.args:
+                    continue
+                if item.optional_vars is not None and name.startswith("assertWarns"):
+                    continue
+                arguments = [text(call.args[0])]
+                if name.endswith("Regex") and len(call.args) > 1:
+                    arguments.append(f"match={text(call.args[1])}")
+                edits.append((*span(call), f"{UNITTEST_RAISES[name]}({', '.join(arguments)})"))
+                needs.add("pytest")
+                if isinstance(item.optional_vars, ast.Name):
+                    raises_names.add(item.optional_vars.id)
+        
+        # self.assertX(...) statements
+        elif isinstance(node, ast.Expr) and isinstance(node.value, ast.Call):
+            replacement = _assert_statement(node.value, operand, text, code[offset(node.lineno, 0):offset(node.lineno, node.col_offset)])
+            if replacement is not None:
+                statement, used = replacement
+                edits.append((*span(node), statement))
+                needs.update(used)
+        
+        # self.skipTest / self.fail -> pytest.skip / pytest.fail
+        if isinstance(node, ast.Call) and _self_call(node) in ("skipTest", "fail"):
+            edits.append((*span(node.func), "pytest.skip" if _self_call(node) == "skipTest" else "pytest.fail"))
+            needs.add("pytest")
+        
+        # @unittest.skip(...) -> @pytest.mark.skip(...)
+        if isinstance(node, (ast.ClassDef, ast.FunctionDef, ast.AsyncFunctionDef)):
+            for decorator in node.decorator_list:
+                replacement = _skip_decorator(decorator, operand, text)
+                if replacement is not None:
+                    edits.append((*span(decorator), replacement))
+                    needs.add("pytest")
+        
+        # if __name__ == "__main__": unittest.main()
+        if isinstance(node, ast.If) and _is_main_block(node):
+            edits.append((starts[node.lineno - 1], starts[node.end_lineno], ""))
+    
+    # Outer edits win over edits inside them (e.g. skipTest inside a rewritten assert)
+    applied: List[Tuple[int, int, str]] = []
+    for start, end, replacement in sorted(edits, key=lambda e: (e[0], -e[1])):
+        if applied and start < applied[-1][1]:
+            continue
+        applied.append((start, end, replacement))
+    for start, end, replacement in reversed(applied):
+        code = code[:start] + replacement + code[end:]
+    
+    for name in raises_names:
+        code = re.sub(rf'\b{re.escape(name)}\.exception\b', f"{name}.value", code)
+    
+    code = _fix_python_imports(code, needs)
+    return code.rstrip("\n") + "\n"
+
+
+def _self_call(node: ast.AST) -> Optional[str]:
+    """`self.assertEqual(...)` -> "assertEqual"."""
+    if (
+        isinstance(node, ast.Call)
+        and isinstance(node.func, ast.Attribute)
+        and isinstance(node.func.value, ast.Name)
+        and node.func.value.id == "self"
+    ):
+        return node.func.attr
+    return None
+
+
+def _is_testcase(base: ast.AST, names: set) -> bool:
+    if isinstance(base, ast.Attribute):
+        return base.attr == "TestCase" and isinstance(base.value, ast.Name) and base.value.id == "unittest"
+    return isinstance(base, ast.Name) and base.id in names
+
+
+def _is_main_block(node: ast.If) -> bool:
+    """`if __name__ == "__main__": unittest.main()`."""
+    test = node.test
+    if not (
+        isinstance(test, ast.Compare)
+        and isinstance(test.left, ast.Name) and test.left.id == "__name__"
+        and isinstance(test.comparators[0], ast.Constant) and test.comparators[0].value == "__main__"
+    ):
+        return False
+    return all(
+        isinstance(s, ast.Expr) and isinstance(s.value, ast.Call)
+        and isinstance(s.value.func, ast.Attribute) and s.value.func.attr == "main"
+        and isinstance(s.value.func.value, ast.Name) and s.value.func.value.id == "unittest"
+        for s in node.body
+    ) and not node.orelse
+
+
+def _assert_statement(call: ast.Call, operand, text, indent: str) -> Optional[Tuple[str, set]]:
+    """The pytest statement for a `self.assertX(...)` call, and what it needs imported."""
+    name = _self_call(call)
+    if name is None:
+        return None
+    keywords = {k.arg: k.value for k in call.keywords}
+    args = list(call.args)
+    
+    if name in UNITTEST_COMPARISONS and len(args) >= 2 and set(keywords) <= {"msg"}:
+        condition = f"{operand(args[0])} {UNITTEST_COMPARISONS[name]} {operand(args[1])}"
+        message, needs = (args[2:3] or [keywords.get("msg")])[0], set()
+    elif name in UNITTEST_CHECKS and set(keywords) <= {"msg"}:
+        arity = UNITTEST_CHECKS[name].count("{")
+        if len(args) < arity:
+            return None
+        template = UNITTEST_CHECKS[name]
+        condition = template.format(*(operand(a) if template != "{0}" and arity == 1 else text(a) for a in args[:arity]))
+        message = (args[arity:arity + 1] or [keywords.get("msg")])[0]
+        needs = {"re"} if "re.search" in condition else set()
+    elif name in ("assertAlmostEqual", "assertNotAlmostEqual") and len(args) >= 2 and set(keywords) <= {"places", "msg", "delta"}:
+        places = args[2] if len(args) > 2 else keywords.get("places")
+        if "delta" in keywords:
+            tolerance = text(keywords["delta"])
+        elif places is None:
+            tolerance = "1e-7"
+        elif isinstance(places, ast.Constant) and isinstance(places.value, int):
+            tolerance = f"1e-{places.value}"
+        else:
+            return None
+        comparison = "==" if name == "assertAlmostEqual" else "!="
+        condition = f"{operand(args[0])} {comparison} pytest.approx({text(args[1])}, abs={tolerance})"
+        message, needs = (args[3:4] or [keywords.get("msg")])[0], {"pytest"}
+    elif name in ("assertRaises", "assertRaisesRegex", "assertWarns", "assertWarnsRegex") and len(args) >= (3 if name.endswith("Regex") else 2):
+        # Callable form: self.assertRaises(E, func, *args) -> with pytest.raises(E): func(*args)
+        first = 2 if name.endswith("Regex") else 1
+        arguments = [text(args[0])] + ([f"match={text(args[1])}"] if first == 2 else [])
+        call_args = [text(a) for a in args[first + 1:]] + [
+            f"{k.arg}={text(k.value)}" if k.arg else f"**{text(k.value)}" for k in call.keywords
+        ]
+        statement = (
+            f"with {UNITTEST_RAISES[name]}({', '.join(arguments)}):\n"
+            f"{indent}    {text(args[first])}({', '.join(call_args)})"
+        )
+        return statement, {"pytest"}
+    else:
+        return None
+    
+    if "\n" in condition:
+        condition = f"({condition})"
+    statement = f"assert {condition}"
+    if message is not None:
+        statement += f", {text(message)}"
+    return statement, needs
+
+
+def _skip_decorator(decorator: ast.AST, operand, text) -> Optional[str]:
+    """`@unittest.skipIf(cond, "why")` -> `@pytest.mark.skipif(cond, reason="why")`."""
+    target = decorator.func if isinstance(decorator, ast.Call) else decorator
+    if not (isinstance(target, ast.Attribute) and isinstance(target.value, ast.Name) and target.value.id == "unittest"):
+        return None
+    args = decorator.args if isinstance(decorator, ast.Call) else []
+    
+    if target.attr == "expectedFailure" and not isinstance(decorator, ast.Call):
+        return "pytest.mark.xfail"
+    if target.attr == "skip" and len(args) == 1:
+        return f"pytest.mark.skip(reason={text(args[0])})"
+    if target.attr in ("skipIf", "skipUnless") and len(args) == 2:
+        condition = text(args[0]) if target.attr == "skipIf" else f"not {operand(args[0])}"
+        return f"pytest.mark.skipif({condition}, reason={text(args[1])})"
+    return None
+
+
+def _fix_python_imports(code: str, needs: set) -> str:
+    """Drop `import unittest` when it's no longer used and add pytest/re imports."""
+    lines = code.splitlines(keepends=True)
+    anchor = None
+    
+    for index, line in enumerate(lines):
+        if re.match(r'import\s+unittest\s*$', line):
+            anchor = index
+            rest = "".join(lines[:index] + lines[index + 1:])
+            if not re.search(r'\bunittest\b', rest):
+                lines[index] = ""
+        elif re.match(r'from\s+unittest\s+import\s+', line):
+            anchor = index if anchor is None else anchor
+            names = [n.strip() for n in line.split("import", 1)[1].split(",")]
+            if "TestCase" in names and not re.search(r'\bTestCase\b', "".join(lines[:index] + lines[index + 1:])):
+                names.remove("TestCase")
+                lines[index] = f"from unittest import {', '.join(names)}\n" if names else ""
+    
+    for module in sorted(needs, key=lambda m: (m == "pytest", m)):
+        if re.search(rf'^import\s+{module}\s*$', code, re.MULTILINE):
+            continue
+        if anchor is None:
+            anchor = next((i for i, l in enumerate(lines) if re.match(r'(?:import|from)\s+\w', l)), 0)
+            lines.insert(anchor, f"import {module}\n")
+            continue
+        lines[anchor] += f"import {module}\n"
+    
+    return "".join(lines)
+
+
+TESTIFY_ASSERT = "github.com/stretchr/testify/assert"
+TESTIFY_REQUIRE = "github.com/stretchr/testify/require"
+
+# if <cond> {
+#     t.Errorf(...)
+# }
+GO_IF_CHECK = re.compile(
+    r'^(?P<indent>[ \t]*)if (?P<cond>[^\n;]+?) \{\n'
+    r'[ \t]*(?P<t>\w+)\.(?P<call>Errorf|Error|Fatalf|Fatal)\((?P<args>[^\n]*)\)[ \t]*\n'
+    r'(?P=indent)\}[ \t]*$',
+    re.MULTILINE
+)
+
+# assert.Equal(t, want, got) on one line
+GO_TESTIFY_CALL = re.compile(
+    r'^(?P<indent>[ \t]*)(?P<pkg>assert|require)\.(?P<name>\w+)\((?P<args>.*)\)[ \t]*$',
+    re.MULTILINE
+)
+
+GO_LITERAL = re.compile(r'^(?:-?\d[\d_.eExXa-fA-F]*|"(?:[^"\\]|\\.)*"|`[^`]*`|\'(?:[^\'\\]|\\.)+\'|true|false)$')
+GO_SIMPLE = re.compile(r'^!?[\w.]+(?:\(.*\))?$')
+
+GO_STDLIB_LEFTOVERS = [
+    (r'\b\w+\.(?:Errorf|Error|Fatalf|Fatal)\(', "not a one-statement if check testify can express: migrate it by hand"),
+]
+
+GO_TESTIFY_LEFTOVERS = [
+    (r'\b(?:assert|require)\.\w+\(', "no one-line stdlib equivalent"),
+    (r'\b(?:assert|require)\.New\(', "assertion objects: call the checks directly"),
+]
+
+
+def go_stdlib_to_testify(code: str) -> str:
+    """
+    Rewrite `if cond { t.Errorf(...) }` checks as testify assertions.
+    
+    Error/Errorf become `assert.X`, Fatal/Fatalf `require.X`; the
+    original message and arguments are kept as msgAndArgs.
+    
+    Args:
+        code: Go test file
+        
+    Returns:
+        Migrated source
+    """
+    packages = set()
+    
+    def replace(match: re.Match) -> str:
+        translated = _testify_check(match.group("cond").strip())
+        if translated is None:
+            return match.group(0)
+        name, operands = translated
+        package = "require" if match.group("call").startswith("Fatal") else "assert"
+        packages.add(package)
+        args = [match.group("t")] + operands + _split_top_level(match.group("args"), ",")
+        return f"{match.group('indent')}{package}.{name}({', '.join(args)})"
+    
+    code = GO_IF_CHECK.sub(replace, code)
+    if not packages:
+        return code
+    
+    code = _edit_go_imports(
+        code,
+        add=[TESTIFY_ASSERT] * ("assert" in packages) + [TESTIFY_REQUIRE] * ("require" in packages),
+        remove=[p for p in ("reflect", "errors", "strings") if not re.search(rf'\b{p}\.', _without_imports(code))],
+    )
+    return _gofmt(code)
+
+
+def _testify_check(condition: str) -> Optional[Tuple[str, List[str]]]:
+    """The testify assertion that fails exactly when `condition` is true."""
+    negated = condition.startswith("!") and GO_SIMPLE.match(condition) is not None
+    inner = condition[1:] if negated else condition
+    call = re.match(r'^(reflect\.DeepEqual|errors\.Is|strings\.Contains)\((.*)\)$', inner)
+    if call:
+        args = _split_top_level(call.group(2), ",")
+        if len(args) != 2:
+            return None
+        if call.group(1) == "reflect.DeepEqual":
+            return ("Equal" if negated else "NotEqual"), [args[1], args[0]]
+        if call.group(1) == "errors.Is":
+            return ("ErrorIs" if negated else "NotErrorIs"), args
+        return ("Contains" if negated else "NotContains"), args
+    
+    for operator, (equal, not_equal) in (("!=", ("Equal", "EqualValues")), ("==", ("NotEqual", "NotEqualValues"))):
+        sides = _split_top_level(condition, operator)
+        if len(sides) != 2 or any(re.search(r'&&|\|\||[<>]', s) for s in sides):
+            continue
+        left, right = sides
+        if right == "nil":
+            if re.match(r'^(?:err|\w+Err)$', left):
+                return ("NoError" if operator == "!=" else "Error"), [left]
+            return ("Nil" if operator == "!=" else "NotNil"), [left]
+        length = re.match(r'^len\((.+)\)$', left)
+        if length and operator == "!=":
+            return "Len", [length.group(1), right]
+        if GO_LITERAL.match(left) and not GO_LITERAL.match(right):
+            left, right = right, left
+        # Untyped numeric constants would be compared as int: compare values instead
+        numeric = re.match(r'^-?\d', right) is not None
+        return (not_equal if numeric else equal), [right, left]
+    
+    if GO_SIMPLE.match(condition) and not re.search(r'[=<>&|]', condition):
+        return ("True", [condition[1:]]) if condition.startswith("!") else ("False", [condition])
+    return None
+
+
+def go_testify_to_stdlib(code: str) -> str:
+    """
+    Rewrite one-line testify assertions as plain `if` checks.
+    
+    `assert.X` fails with t.Errorf, `require.X` with t.Fatalf. Assertions
+    without a faithful one-line equivalent are left for review.
+    
+    Args:
+        code: Go test file
+        
+    Returns:
+        Migrated source
+    """
+    needed = set()
+    
+    def replace(match: re.Match) -> str:
+        args = _split_top_level(match.group("args"), ",")
+        if not args:
+            return match.group(0)
+        check = _stdlib_check(match.group("name"), args[1:])
+        if check is None:
+            return match.group(0)
+        condition, fmt, values = check
+        message = args[1 + _TESTIFY_ARITY[match.group("name")]:]
+        if message:
+            if not re.match(r'^"(?:[^"\\]|\\.)*"$', message[0]):
+                return match.group(0)
+            literal = message[0][1:-1] if len(message) > 1 else message[0][1:-1].replace("%", "%%")
+            fmt = f"{literal}: {fmt}"
+            values = message[1:] + values
+        needed.update(re.findall(r'\b(reflect|errors|strings)\.', condition))
+        t = args[0]
+        fatal = match.group("pkg") == "require"
+        method = ("Fatalf" if fatal else "Errorf") if values else ("Fatal" if fatal else "Error")
+        indent = match.group("indent")
+        call = f"{t}.{method}({', '.join([_go_quote(fmt)] + values)})"
+        return f"{indent}if {condition} {{\n{indent}\t{call}\n{indent}}}"
+    
+    code = GO_TESTIFY_CALL.sub(replace, code)
+    body = _without_imports(code)
+    code = _edit_go_imports(
+        code,
+        add=sorted(needed),
+        remove=[p for p, q in ((TESTIFY_ASSERT, "assert"), (TESTIFY_REQUIRE, "require")) if not re.search(rf'\b{q}\.', body)],
+    )
+    return _gofmt(code)
+
+
+# Arguments before msgAndArgs
+_TESTIFY_ARITY = {
+    "Equal": 2, "EqualValues": 2, "NotEqual": 2, "True": 1, "False": 1, "Nil": 1, "NotNil": 1,
+    "NoError": 1, "Error": 1, "ErrorIs": 2, "Len": 2, "Contains": 2,
+}
+
+
+def _stdlib_check(name: str, args: List[str]) -> Optional[Tuple[str, str, List[str]]]:
+    """(condition, message format, message args) of the `if` that replaces a testify call."""
+    if name not in _TESTIFY_ARITY or len(args) < _TESTIFY_ARITY[name]:
+        return None
+    
+    def negate(expression: str) -> str:
+        return f"!{expression}" if GO_SIMPLE.match(expression) and not expression.startswith("!") else f"!({expression})"
+    
+    if name in ("Equal", "NotEqual"):
+        want, got = args[0], args[1]
+        if GO_LITERAL.match(want):
+            condition = f"{got} != {want}" if name == "Equal" else f"{got} == {want}"
+        else:
+            condition = f"{'!' if name == 'Equal' else ''}reflect.DeepEqual({got}, {want})"
+        if name == "Equal":
+            return condition, "got %v, want %v", [got, want]
+        return condition, "got %v, want a different value", [got]
+    if name == "EqualValues" and GO_LITERAL.match(args[0]):
+        # Untyped constants convert to the actual's type, like EqualValues does
+        return f"{args[1]} != {args[0]}", "got %v, want %v", [args[1], args[0]]
+    if name == "True":
+        return negate(args[0]), "expected true", []
+    if name == "False":
+        return args[0], "expected false", []
+    if name == "Nil":
+        return f"{args[0]} != nil", "expected nil, got %v", [args[0]]
+    if name == "NotNil":
+        return f"{args[0]} == nil", "expected a non-nil value", []
+    if name == "NoError":
+        return f"{args[0]} != nil", "unexpected error: %v", [args[0]]
+    if name == "Error":
+        return f"{args[0]} == nil", "expected an error", []
+    if name == "ErrorIs":
+        return f"!errors.Is({args[0]}, {args[1]})", "got error %v, want %v", [args[0], args[1]]
+    if name == "Len":
+        return f"len({args[0]}) != {args[1]}", "len = %d, want %d", [f"len({args[0]})", args[1]]
+    if name == "Contains" and re.match(r'^"(?:[^"\\]|\\.)*"$', args[1]):
+        return f"!strings.Contains({args[0]}, {args[1]})", "%q doesn't contain %q", [args[0], args[1]]
+    return None
+
+
+def _split_top_level(text: str, separator: str) -> List[str]:
+    """Split Go/JS text on a separator outside brackets and string literals."""
+    parts = []
+    depth = 0
+    quote = None
+    start = 0
+    index = 0
+    while index < len(text):
+        char = text[index]
+        if quote:
+            if char == "\\" and quote != "`":
+                index += 1
+            elif char == quote:
+                quote = None
+        elif char in "\"'`":
+            quote = char
+        elif char in "([{":
+            depth += 1
+        elif char in ")]}":
+            depth -= 1
+        elif depth == 0 and text.startswith(separator, index):
+            parts.append(text[start:index])
+            start = index + len(separator)
+            index = start
+            continue
+        index += 1
+    parts.append(text[start:])
+    parts = [p.strip() for p in parts]
+    return parts if any(parts) else []
+
+
+def _go_quote(text: str) -> str:
+    """A Go string literal for a format built from literal pieces (already escaped)."""
+    return f'"{text}"'
+
+
+def _without_imports(code: str) -> str:
+    return re.sub(r'^import\s*(?:\([^)]*\)|"[^"\n]*")', "", code, flags=re.MULTILINE)
+
+
+def _edit_go_imports(code: str, add: List[str], remove: List[str]) -> str:
+    """
+    Add and remove imports of a Go file.
+    
+    Standard library imports join the first group, others get their own
+    group (gofmt sorts within groups).
+    """
+    block = re.search(r'^import\s*\((.*?)\n\)', code, re.MULTILINE | re.DOTALL)
+    if block is None:
+        single = re.search(r'^import\s+("[^"\n]+")[ \t]*$', code, re.MULTILINE)
+        if single is None:
+            package = re.search(r'^package\s+\w+[^\n]*\n', code, re.MULTILINE)
+            at = package.end() if package else 0
+            code = code[:at] + "\nimport (\n)\n" + code[at:]
+        else:
+            code = code[:single.start()] + f"import (\n\t{single.group(1)}\n)" + code[single.end():]
+        block = re.search(r'^import\s*\((.*?)\n\)', code, re.MULTILINE | re.DOTALL)
+    
+    lines = [l for l in block.group(1).split("\n")[1:] if not any(re.search(rf'"{re.escape(p)}"', l) for p in remove)]
+    present = set(re.findall(r'"([^"]+)"', "\n".join(lines)))
+    groups: List[List[str]] = [[]]
+    for line in lines:
+        if line.strip():
+            groups[-1].append(line)
+        elif groups[-1]:
+            groups.append([])
+    groups = [g for g in groups if g] or [[]]
+    
+    for path in add:
+        if path in present:
+            continue
+        if "." not in path.split("/")[0]:
+            groups[0].append(f'\t"{path}"')
+        elif len(groups) > 1 or (groups[0] and all("." in l.split('"')[1].split("/")[0] for l in groups[0])):
+            groups[-1].append(f'\t"{path}"')
+        else:
+            groups.append([f'\t"{path}"'])
+        present.add(path)
+    
+    body = "\n\n".join("\n".join(g) for g in groups if g)
+    return code[:block.start()] + ("import (\n" + body + "\n)" if body else "") + code[block.end():]
+
+
+# jest.X that exist with the same behavior as vi.X
+JEST_SAME_AS_VI = {
+    "fn", "spyOn", "mock", "unmock", "doMock", "doUnmock", "mocked", "isMockFunction",
+    "clearAllMocks", "resetAllMocks", "restoreAllMocks", "resetModules",
+    "useFakeTimers", "useRealTimers", "advanceTimersByTime", "advanceTimersToNextTimer",
+    "runAllTimers", "runOnlyPendingTimers", "runAllTicks", "clearAllTimers", "getTimerCount",
+    "setSystemTime", "getRealSystemTime",
+}
+
+JEST_TYPES = {"Mock": "Mock", "Mocked": "Mocked", "SpyInstance": "MockInstance", "MockedFunction": "Mock"}
+
+VITEST_GLOBALS = ["describe", "it", "test", "expect", "vi", "beforeEach", "afterEach", "beforeAll", "afterAll"]
+
+JEST_LEFTOVERS = [
+    (r'\bjest\.requireActual\(', "vi.importActual is async: `await vi.importActual(...)` inside an async mock factory"),
+    (r'\bjest\.\w+', "no vi equivalent with the same behavior"),
+    (r'\b(?:it|test)(?:\.\w+)?\([^\n]*,\s*(?:async\s*)?(?:function\s*)?\(\s*done\s*\)', "done callbacks are deprecated in Vitest: return a Promise"),
+]
+
+
+def jest_to_vitest(code: str) -> str:
+    """
+    Rewrite a Jest test file for Vitest: `jest.*` -> `vi.*` and explicit imports.
+    
+    Args:
+        code: JavaScript/TypeScript test file
+        
+    Returns:
+        Migrated source
+    """
+    code = re.sub(
+        r'\bjest\.setTimeout\(([^()\n]+)\)', r'vi.setConfig({ testTimeout: \1 })', code
+    )
+    code = re.sub(
+        r'\bjest\.(\w+)\b',
+        lambda m: f"vi.{m.group(1)}" if m.group(1) in JEST_SAME_AS_VI else m.group(0),
+        code
+    )
+    types = set()
+    
+    def replace_type(match: re.Match) -> str:
+        types.add(JEST_TYPES[match.group(1)])
+        return JEST_TYPES[match.group(1)]
+    
+    code = re.sub(rf'\bjest\.({"|".join(JEST_TYPES)})\b', replace_type, code)
+    
+    # @jest/globals imports are replaced by the vitest import below
+    code = re.sub(r'^import\s*\{[^}]*\}\s*from\s*[\'"]@jest/globals[\'"];?[ \t]*\n', "", code, flags=re.MULTILINE)
+    code = re.sub(r'^const\s*\{[^}]*\}\s*=\s*require\([\'"]@jest/globals[\'"]\);?[ \t]*\n', "", code, flags=re.MULTILINE)
+    
+    if re.search(r'from\s+[\'"]vitest[\'"]', code):
+        return code
+    used = [name for name in VITEST_GLOBALS if re.search(rf'(?<![\w.]){name}\s*[.(]', code)]
+    names = used + [f"type {t}" for t in sorted(types)]
+    if not names:
+        return code
+    
+    statement = f"import {{ {', '.join(names)} }} from 'vitest';\n"
+    imports = list(re.finditer(r'^import\s[^;]*?from\s*[\'"][^\'"]+[\'"];?[ \t]*\n|^import\s*[\'"][^\'"]+[\'"];?[ \t]*\n', code, re.MULTILINE))
+    if imports:
+        at = imports[0].start()
+    else:
+        header = re.match(r'(?:\s*(?://[^\n]*|/\*.*?\*/|[\'"]use strict[\'"];?)[ \t]*\n)*', code, re.DOTALL)
+        at = header.end() if header else 0
+        statement += "\n" if not code[at:].startswith("\n") else ""
+    return code[:at] + statement + code[at:]
+
+
+JUNIT_ANNOTATIONS = {
+    "Before": "BeforeEach", "After": "AfterEach", "BeforeClass": "BeforeAll",
+    "AfterClass": "AfterAll", "Ignore": "Disabled",
+}
+
+JUNIT_IMPORTS = {
+    "org.junit.Test": "org.junit.jupiter.api.Test",
+    "org.junit.Before": "org.junit.jupiter.api.BeforeEach",
+    "org.junit.After": "org.junit.jupiter.api.AfterEach",
+    "org.junit.BeforeClass": "org.junit.jupiter.api.BeforeAll",
+    "org.junit.AfterClass": "org.junit.jupiter.api.AfterAll",
+    "org.junit.Ignore": "org.junit.jupiter.api.Disabled",
+    "org.junit.Assert": "org.junit.jupiter.api.Assertions",
+    "org.junit.Assume": "org.junit.jupiter.api.Assumptions",
+}
+
+# Assertions whose JUnit 4 message comes first: name -> arguments without the message
+JUNIT_MESSAGE_FIRST = {
+    "assertEquals": 2, "assertNotEquals": 2, "assertArrayEquals": 2, "assertSame": 2, "assertNotSame": 2,
+    "assertTrue": 1, "assertFalse": 1, "assertNull": 1, "assertNotNull": 1,
+}
+
+JUNIT_LEFTOVERS = [
+    (r'@RunWith\b', "runners: use @ExtendWith (e.g. MockitoExtension) or @ParameterizedTest"),
+    (r'@(?:Rule|ClassRule)\b', "rules: use an extension, @TempDir or assertThrows"),
+    (r'@Category\b', "categories: use @Tag"),
+    (r'\bimport\s+(?:static\s+)?org\.junit\.(?!jupiter)', "JUnit 4 API without a direct JUnit 5 replacement"),
+]
+
+
+def junit4_to_junit5(code: str) -> str:
+    """
+    Rewrite a JUnit 4 test class for JUnit 5 (Jupiter).
+    
+    Args:
+        code: Java test class
+        
+    Returns:
+        Migrated source
+    """
+    # Imports, static imports included (org.junit.Assert.* -> Assertions.*)
+    def replace_import(match: re.Match) -> str:
+        name = match.group(2)
+        for old, new in JUNIT_IMPORTS.items():
+            if name == old or name.startswith(old + "."):
+                return f"{match.group(1)}{new}{name[len(old):]};"
+        if name == "org.junit.Assert.assertThat":
+            return f"{match.group(1)}org.hamcrest.MatcherAssert.assertThat;"
+        return match.group(0)
+    
+    code = re.sub(r'^(import\s+(?:static\s+)?)(org\.junit\.[\w.*]+);', replace_import, code, flags=re.MULTILINE)
+    code = re.sub(r'\bAssert\.(?=\w+\()', "Assertions.", code)
+    code = re.sub(r'\bAssume\.(?=\w+\()', "Assumptions.", code)
+    
+    for old, new in JUNIT_ANNOTATIONS.items():
+        code = re.sub(rf'@{old}\b(?!\.)', f"@{new}", code)
+    
+    code = _junit_message_last(code)
+    code, extra = _junit_test_parameters(code)
+    
+    for name in extra:
+        if not re.search(rf'^import\s+(?:static\s+)?{re.escape(name.rsplit(".", 1)[0])}\.(?:\*|{re.escape(name.rsplit(".", 1)[1])});', code, re.MULTILINE):
+            code = _add_java_import(code, name)
+    return code
+
+
+def _junit_message_last(code: str) -> str:
+    """Move JUnit 4's leading message argument to the end."""
+    pattern = re.compile(rf'\b(?:Assertions\.)?({"|".join(JUNIT_MESSAGE_FIRST)})\(')
+    position = 0
+    while True:
+        match = pattern.search(code, position)
+        if match is None:
+            return code
+        open_index = match.end() - 1
+        close_index = _matching_paren(code, open_index)
+        position = match.end()
+        if close_index is None:
+            continue
+        args = _split_top_level(code[open_index + 1:close_index], ",")
+        base = JUNIT_MESSAGE_FIRST[match.group(1)]
+        if len(args) == base + 1:
+            # assertEquals(double, double, delta) has the same arity: only a literal is surely a message
+            is_message = args[0].startswith('"')
+        elif len(args) == base + 2 and match.group(1) in ("assertEquals", "assertNotEquals", "assertArrayEquals"):
+            is_message = True
+        else:
+            continue
+        if is_message:
+            moved = ", ".join(args[1:] + args[:1])
+            code = code[:open_index + 1] + moved + code[close_index:]
+
+
+def _junit_ambiguous_messages(code: str) -> List[Leftover]:
+    """3-argument assertEquals calls that may be (message, expected, actual) or (expected, actual, delta)."""
+    leftovers = []
+    lines = code.splitlines()
+    for match in re.finditer(r'\b(?:Assertions\.)?assert(?:Not)?(?:Array)?Equals\(', code):
+        close_index = _matching_paren(code, match.end() - 1)
+        if close_index is None:
+            continue
+        args = _split_top_level(code[match.end():close_index], ",")
+        if len(args) == 3 and not re.match(r'^["\d.-]', args[0]) and not re.match(r'^-?[\d.]+(?:[eE]-?\d+)?[dDfF]?$', args[2]):
+            line = code.count("\n", 0, match.start()) + 1
+            leftovers.append(Leftover(line=line, text=lines[line - 1], reason="message or delta? JUnit 5 takes the message last"))
+    return leftovers
+
+
+def _junit_test_parameters(code: str) -> Tuple[str, List[str]]:
+    """`@Test(expected=...)` -> assertThrows around the body; `@Test(timeout=...)` -> @Timeout."""
+    extra: List[str] = []
+    
+    for match in reversed(list(re.finditer(r'^([ \t]*)@Test\(([^)]*)\)', code, re.MULTILINE))):
+        indent = match.group(1)
+        parameters = dict(
+            (k.strip(), v.strip()) for k, _, v in (p.partition("=") for p in match.group(2).split(","))
+        )
+        if set(parameters) - {"expected", "timeout"}:
+            continue
+        annotation = f"{indent}@Test"
+        if "timeout" in parameters:
+            annotation += f"\n{indent}@Timeout(value = {parameters['timeout']}, unit = TimeUnit.MILLISECONDS)"
+            extra += ["org.junit.jupiter.api.Timeout", "java.util.concurrent.TimeUnit"]
+        
+        tail = code[match.end():]
+        if "expected" in parameters:
+            open_index = tail.find("{")
+            close_index = _matching_paren(tail, open_index, "{", "}") if open_index >= 0 else None
+            if close_index is None:
+                continue
+            body = tail[open_index + 1:close_index]
+            body_lines = body.rstrip().lstrip("\n").split("\n")
+            inner = "\n".join(("    " + line) if line.strip() else line for line in body_lines)
+            body_indent = indent + "    "
+            tail = (
+                tail[:open_index + 1]
+                + f"\n{body_indent}assertThrows({parameters['expected']}, () -> {{\n{inner}\n{body_indent}}});\n{indent}"
+                + tail[close_index:]
+            )
+            extra.append("org.junit.jupiter.api.Assertions.assertThrows")
+        code = code[:match.start()] + annotation + tail
+    
+    return code, extra
+
+
+def _matching_paren(code: str, open_index: int, opening: str = "(", closing: str = ")") -> Optional[int]:
+    depth = 0
+    quote = None
+    index = open_index
+    while index < len(code):
+        char = code[index]
+        if quote:
+            if char == "\\":
+                index += 1
+            elif char == quote:
+                quote = None
+        elif char in "\"'":
+            quote = char
+        elif char == opening:
+            depth += 1
+        elif char == closing:
+            depth -= 1
+            if depth == 0:
+                return index
+        index += 1
+    return None
+
+
+def _add_java_import(code: str, name: str) -> str:
+    static = name.rsplit(".", 1)[-1][0].islower()
+    statement = f"import {'static ' if static else ''}{name};\n"
+    imports = list(re.finditer(r'^import\s[^;]+;[ \t]*\n', code, re.MULTILINE))
+    if not static:
+        imports = [m for m in imports if not m.group(0).startswith("import static")] or imports
+    if imports:
+        at = imports[-1].end()
+    else:
+        package = re.search(r'^package\s[^;]+;[ \t]*\n', code, re.MULTILINE)
+        at = package.end() if package else 0
+        statement = "\n" + statement
+    return code[:at] + statement + code[at:]
+
+
+MIGRATIONS: Dict[Tuple[str, str], Migration] = {
+    ("unittest", "pytest"): Migration(
+        "unittest", "pytest", Language.PYTHON, unittest_to_pytest, UNITTEST_LEFTOVERS,
+        template=(Language.PYTHON, "pytest"),
+        notes=["Plain classes named Test* with plain `assert`; fixtures instead of setUp state that needs cleanup"],
+    ),
+    ("stdlib", "testify"): Migration(
+        "stdlib", "testify", Language.GO, go_stdlib_to_testify, GO_STDLIB_LEFTOVERS,
+        notes=[
+            "Use github.com/stretchr/testify: `assert.X(t, ...)` for t.Error checks, `require.X(t, ...)` for t.Fatal",
+            "Expected value before actual: assert.Equal(t, want, got)",
+        ],
+    ),
+    ("testify", "stdlib"): Migration(
+        "testify", "stdlib", Language.GO, go_testify_to_stdlib, GO_TESTIFY_LEFTOVERS,
+        template=(Language.GO, "testing"),
+        notes=["No assertion libraries: `if` checks with t.Errorf (assert) or t.Fatalf (require), reporting got and want"],
+    ),
+    ("jest", "vitest"): Migration(
+        "jest", "vitest", Language.JAVASCRIPT, jest_to_vitest, JEST_LEFTOVERS,
+        notes=[
+            "Use Vitest: import describe/it/expect/vi from 'vitest', vi.* instead of jest.*",
+            "vi.mock factories must return the module's `default` export explicitly",
+        ],
+    ),
+    ("junit4", "junit5"): Migration(
+        "junit4", "junit5", Language.JAVA, junit4_to_junit5, JUNIT_LEFTOVERS,
+        check=_junit_ambiguous_messages,
+        template=(Language.JAVA, "junit"),
+        notes=["Assertion messages go last; assertThrows instead of expected exceptions and rules; @ExtendWith instead of @RunWith"],
+    ),
+}
+
+
+def get_migration(source: str, target: str) -> Migration:
+    """
+    Look up a supported migration.
+    
+    Raises:
+        ValueError: If the pair isn't supported
+    """
+    migration = MIGRATIONS.get((source.lower(), target.lower()))
+    if migration is None:
+        supported = ", ".join(f"{s} -> {t}" for s, t in MIGRATIONS)
+        raise ValueError(f"Unsupported migration {source} -> {target} (supported: {supported})")
+    return migration
+
+
+def find_leftovers(code: str, migration: Migration) -> List[Leftover]:
+    """Lines of migrated code that still use the source framework."""
+    leftovers = []
+    seen = set()
+    for number, line in enumerate(code.splitlines(), start=1):
+        for pattern, reason in migration.leftovers:
+            if number not in seen and re.search(pattern, line):
+                leftovers.append(Leftover(line=number, text=line, reason=reason))
+                seen.add(number)
+    if migration.check is not None:
+        leftovers += [l for l in migration.check(code) if l.line not in seen]
+    return sorted(leftovers, key=lambda l: l.line)
+
+
+def find_test_files(directory: Path, source: str) -> List[Path]:
+    """
+    Test files under a directory that use the source framework.
+    
+    Args:
+        directory: Directory to search
+        source: Source framework ("unittest", "stdlib", "testify", "jest", "junit4")
+        
+    Returns:
+        Sorted file paths (vendor, node_modules and hidden directories skipped)
+    """
+    globs = {
+        "unittest": ["test_*.py", "*_test.py"],
+        "stdlib": ["*_test.go"],
+        "testify": ["*_test.go"],
+        "jest": ["*.test.[jt]s", "*.spec.[jt]s", "*.test.[jt]sx", "*.spec.[jt]sx"],
+        "junit4": ["*Test.java", "*Tests.java"],
+    }
+    markers = {
+        "unittest": r'\bunittest\b',
+        "stdlib": r'\*testing\.T\b',
+        "testify": r'github\.com/stretchr/testify/(?:assert|require)',
+        "jest": r'\b(?:describe|it|test)\(',
+        "junit4": r'\bimport\s+(?:static\s+)?org\.junit\.(?!jupiter)',
+    }
+    files = set()
+    for pattern in globs.get(source, []):
+        for path in directory.rglob(pattern):
+            parts = path.relative_to(directory).parts
+            if any(p in ("vendor", "node_modules", "testdata") or p.startswith(".") for p in parts):
+                continue
+            try:
+                if re.search(markers[source], path.read_text(encoding='utf-8', errors='replace')):
+                    files.add(path)
+            except OSError:
+                continue
+    return sorted(files)
+
+
+def complete_with_llm(code: str, leftovers: List[Leftover], migration: Migration, llm_client) -> str:
+    """
+    Ask the LLM to migrate the leftovers, then re-apply the deterministic rewrite.
+    
+    Args:
+        code: Partly migrated source
+        leftovers: What's left to migrate
+        migration: The migration
+        llm_client: LLMClient (or anything with `generate(prompt).content`)
+        
+    Returns:
+        The completed source (the input if the response has no code)
+    """
+    from .prompt_templates import PromptTemplates
+    
+    prompt = PromptTemplates.get_migration_prompt(
+        migration.language,
+        code,
+        source=migration.source,
+        target=migration.target,
+        leftovers="\n".join(f"- {l}" for l in leftovers),
+        template=migration.template,
+        notes=migration.notes,
+    )
+    response = llm_client.generate(prompt).content
+    blocks = re.findall(r'```[\w+-]*\n(.*?)```', response, re.DOTALL)
+    completed = max(blocks, key=len) if blocks else response.strip()
+    if not completed.strip():
+        return code
+    return migration.rewrite(completed.rstrip("\n") + "\n")
+
+
+def declared_tests(code: str, language: Language) -> List[str]:
+    """Names of the tests a file defines (to check none were lost)."""
+    if language == Language.PYTHON:
+        return [t["name"] for t in TestMerger()._extract_tests(code)]
+    if language == Language.GO:
+        return [f.name for f in find_test_functions(code)]
+    if language == Language.JAVA:
+        return re.findall(r'@Test\b[^\n]*\n(?:\s*@[^\n]*\n)*\s*(?:public\s+)?void\s+(\w+)', code)
+    return [m.group(2) for m in re.finditer(r'\b(?:it|test)(?:\.\w+)?\(\s*([\'"`])(.*?)\1', code)]
+
+
+def verify_migration(result: MigrationResult) -> bool:
+    """
+    Run the tests before and after the migration and compare per-test outcomes.
+    
+    Sets `result.verified`, `outcomes` and `problem`.
+    
+    Args:
+        result: A migration whose `migrated` source is filled in
+        
+    Returns:
+        Whether every test has the same outcome
+    """
+    before, problem = run_outcomes(result.path, result.original, result.migration.source, result.migration.language)
+    if problem or not before:
+        result.problem = "original tests didn't run: " + (problem or "no results")
+        return False
+    
+    after, problem = run_outcomes(result.path, result.migrated, result.migration.target, result.migration.language)
+    if problem:
+        result.problem = "migrated tests didn't run: " + problem
+        return False
+    
+    result.outcomes = {name: (status, after.get(name, "not run")) for name, status in before.items()}
+    changed = [f"{name}: {b} -> {a}" for name, (b, a) in result.outcomes.items() if b != a]
+    if changed:
+        result.problem = "outcomes differ: " + ", ".join(changed)
+        return False
+    
+    result.verified = True
+    return True
+
+
+def migrate_file(
+    test_file: str,
+    source: str,
+    target: str,
+    verify: bool = True,
+    llm_client=None
+) -> MigrationResult:
+    """
+    Migrate one test file between frameworks.
+    
+    Args:
+        test_file: Path of the test file
+        source: Framework it uses now
+        target: Framework to migrate to
+        verify: Run it before and after (needs the toolchain and both
+            frameworks installed); without it the migration is accepted
+            once no tests are lost
+        llm_client: If given, leftovers are sent to the LLM to finish
+        
+    Returns:
+        MigrationResult with the migrated source and a patch (the file isn't modified)
+    """
+    migration = get_migration(source, target)
+    path = Path(test_file)
+    original = path.read_text(encoding='utf-8')
+    migrated = migration.rewrite(original)
+    result = MigrationResult(
+        path=path, migration=migration, original=original, migrated=migrated,
+        leftovers=find_leftovers(migrated, migration)
+    )
+    
+    if llm_client is not None and result.leftovers:
+        completed = complete_with_llm(migrated, result.leftovers, migration, llm_client)
+        if completed != migrated:
+            result.migrated = completed
+            result.leftovers = find_leftovers(completed, migration)
+            result.llm_used = True
+    
+    if not result.changed:
+        return result
+    
+    lost = [n for n in declared_tests(original, migration.language) if n not in declared_tests(result.migrated, migration.language)]
+    if lost:
+        result.problem = "tests lost in the migration: " + ", ".join(lost)
+        return result
+    
+    if verify:
+        verify_migration(result)
+    else:
+        result.verified = True
+    return result
+
+
+def run_outcomes(path: Path, code: str, framework: str, language: Language) -> Tuple[Dict[str, str], Optional[str]]:
+    """
+    Run a test file with `code` in place of its content and collect per-test outcomes.
+    
+    Except for Go (`-overlay`), the file is overwritten with `code` for the
+    run and restored afterwards (see `_swapped`).
+    
+    Args:
+        path: Test file
+        code: Source to run as that file
+        framework: Framework to run it with (e.g. "jest" vs "vitest", "testify")
+        language: Language of the file
+        
+    Returns:
+        (test name -> status, problem if the tests couldn't run)
+    """
+    if language == Language.GO:
+        return _go_outcomes(path, code, framework)
+    with _swapped(path, code):
+        if language == Language.PYTHON:
+            return _python_outcomes(path)
+        if language == Language.JAVA:
+            return _java_outcomes(path)
+        return _javascript_outcomes(path, framework)
+
+
+# Original of a file overwritten for a verification run, until it is restored
+SWAP_BACKUP_SUFFIX = ".testgen-orig"
+
+
+@contextmanager
+def _swapped(path: Path, code: str) -> Iterator[None]:
+    """
+    Overwrite a file with `code` in place for the duration.
+    
+    The original is copied to `<file>.testgen-orig` first and written back
+    when the block ends, raises, or the process gets Ctrl-C or SIGTERM.
+    A backup left by a run that was killed outright is restored before
+    anything else.
+    """
+    backup = path.with_name(path.name + SWAP_BACKUP_SUFFIX)
+    if backup.exists():
+        shutil.copy2(backup, path)
+        backup.unlink()
+    original = path.read_text(encoding='utf-8')
+    if code == original:
+        yield
+        return
+    shutil.copy2(path, backup)
+    with _unwind_on_signals():
+        try:
+            path.write_text(code, encoding='utf-8')
+            yield
+        finally:
+            path.write_text(original, encoding='utf-8')
+            backup.unlink(missing_ok=True)
+
+
+@contextmanager
+def _unwind_on_signals() -> Iterator[None]:
+    """Turn SIGTERM and SIGHUP into SystemExit for the duration, so `finally` blocks run."""
+    # Handlers can only be installed from the main thread
+    if threading.current_thread() is not threading.main_thread():
+        yield
+        return
+    
+    def _exit(signum, frame):
+        raise SystemExit(128 + signum)
+    
+    previous = {}
+    for name in ("SIGTERM", "SIGHUP"):
+        number = getattr(signal, name, None)
+        if number is not None:
+            previous[number] = signal.signal(number, _exit)
+    try:
+        yield
+    finally:
+        for number, handler in previous.items():
+            signal.signal(number, handler)
+
+
+def _statuses(results: TestResults, name: Callable = lambda t: t.name) -> Dict[str, str]:
+    return {name(t): ("skipped" if t.status in ("pending", "todo", "disabled") else t.status) for t in results.tests}
+
+
+def _go_outcomes(path: Path, code: str, framework: str) -> Tuple[Dict[str, str], Optional[str]]:
+    package_dir = path.parent
+    if framework == "testify":
+        module = next((d / "go.mod" for d in [package_dir, *package_dir.parents] if (d / "go.mod").exists()), None)
+        if module is None or "github.com/stretchr/testify" not in module.read_text(encoding='utf-8'):
+            return {}, "github.com/stretchr/testify isn't required in go.mod (go get github.com/stretchr/testify)"
+    
+    names = "|".join(f.name for f in find_test_functions(code)) or "^$"
+    runner = GoTestRunner()
+    with tempfile.TemporaryDirectory(prefix="testgen-migrate-") as scratch:
+        extra_args = ["-count=1"]
+        if code != path.read_text(encoding='utf-8'):
+            replacement = Path(scratch) / path.name
+            replacement.write_text(code, encoding='utf-8')
+            overlay = Path(scratch) / "overlay.json"
+            overlay.write_text(json.dumps({"Replace": {str(path.resolve()): str(replacement)}}), encoding='utf-8')
+            extra_args.append(f"-overlay={overlay}")
+        results = runner.run_tests(str(package_dir), run=f"^({names})$", packages=["."], extra_args=extra_args, measure_usage=False)
+    
+    error = next((t for t in results.tests if t.status == "error"), None)
+    if error is not None:
+        return {}, (error.message or "build failed").strip().splitlines()[-1]
+    return _statuses(results), None
+
+
+def _python_outcomes(path: Path) -> Tuple[Dict[str, str], Optional[str]]:
+    with tempfile.TemporaryDirectory(prefix="testgen-migrate-") as scratch:
+        report = Path(scratch) / "junit.xml"
+        results = PythonTestRunner().run_tests(
+            str(path.parent), node_ids=[str(path)], extra_args=[f"--junitxml={report}", "-p", "no:cacheprovider"]
+        )
+        if not report.exists():
+            message = next((t.message for t in results.tests if t.message), None)
+            return {}, message or "pytest didn't run"
+        parsed = parse_junit_xml_files([report], "python", "pytest")
+    return _statuses(parsed, lambda t: f"{t.suite}.{t.name}"), None
+
+
+def _javascript_outcomes(path: Path, framework: str) -> Tuple[Dict[str, str], Optional[str]]:
+    root = next((d for d in path.parents if (d / "package.json").exists()), None)
+    if root is None:
+        return {}, "no package.json"
+    binary = root / "node_modules" / ".bin" / framework
+    if not binary.exists():
+        return {}, f"{framework} isn't installed in {root}"
+    
+    cmd = [str(binary), str(path), "--json"] if framework == "jest" else [str(binary), "run", str(path), "--reporter=json"]
+    try:
+        completed = subprocess.run(cmd, capture_output=True, text=True, cwd=root, timeout=300)
+    except (OSError, subprocess.TimeoutExpired) as e:
+        return {}, str(e)
+    start = completed.stdout.find("{")
+    try:
+        data = json.loads(completed.stdout[start:]) if start >= 0 else {}
+    except json.JSONDecodeError:
+        data = {}
+    if not data.get("testResults"):
+        return {}, (completed.stderr.strip().splitlines() or ["no results"])[-1]
+    
+    outcomes = {}
+    for test_file in data["testResults"]:
+        for assertion in test_file.get("assertionResults", []):
+            name = " > ".join(assertion.get("ancestorTitles", []) + [assertion.get("title", "")])
+            status = assertion.get("status", "unknown")
+            outcomes[name] = "skipped" if status in ("pending", "todo", "skipped", "disabled") else status
+    return outcomes, None
+
+
+def _java_outcomes(path: Path) -> Tuple[Dict[str, str], Optional[str]]:
+    root = next(
+        (d for d in path.parents if any((d / f).exists() for f in ("pom.xml", "build.gradle", "build.gradle.kts"))),
+        None
+    )
+    if root is None:
+        return {}, "no Maven or Gradle build"
+    
+    if (root / "pom.xml").exists():
+        tool = "mvn"
+        cmd = ["mvn", "-q", "test", f"-Dtest={path.stem}", "-Dsurefire.failIfNoSpecifiedTests=false"]
+        reports = root / "target" / "surefire-reports"
+    else:
+        tool = str(root / "gradlew") if (root / "gradlew").exists() else "gradle"
+        cmd = [tool, "test", "--tests", f"*{path.stem}"]
+        reports = root / "build" / "test-results" / "test"
+    if shutil.which(tool) is None and not Path(tool).exists():
+        return {}, f"{Path(tool).name} isn't installed"
+    
+    for stale in reports.glob(f"TEST-*{path.stem}.xml"):
+        stale.unlink()
+    try:
+        completed = subprocess.run(cmd, capture_output=True, text=True, cwd=root, timeout=600)
+    except (OSError, subprocess.TimeoutExpired) as e:
+        return {}, str(e)
+    files = list(reports.glob(f"TEST-*{path.stem}.xml"))
+    if not files:
+        errors = [l for l in completed.stdout.splitlines() + completed.stderr.splitlines() if "ERROR" in l or "error:" in l]
+        return {}, (errors or ["build failed"])[0].strip()
+    
+    parsed = parse_junit_xml_files(files, "java", "junit")
+    return _statuses(parsed, lambda t: f"{t.suite}.{t.name.removesuffix('()')}"), None
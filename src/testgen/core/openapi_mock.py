@@ -0,0 +1,1116 @@
+"""
+OpenAPI Mock Servers for TestGen AI.
+
+Generates local mock servers from OpenAPI 3 specs of upstream services, so
+tests for API clients stop depending on hand-rolled stubs:
+- Go: an in-process `httptest` mock written as a `_test.go` file
+- Standalone: a local HTTP server for integration runs (also usable as a
+  stand-in, see `standins.py`)
+  
+Both flavours serve schema-valid example responses, support per-test
+overrides and error injection, and record requests that don't match the
+spec as violations.
+
+Standalone usage:
+
+    python -m testgen.core.openapi_mock serve api/payments.yaml --port 8080
+    
+Control endpoints of the standalone server:
+    POST /__mock/overrides   {"method", "path", "status", "body", "headers"}
+    POST /__mock/errors      {"method", "path", "status"}
+    GET  /__mock/violations
+    POST /__mock/reset
+"""
+
+import argparse
+import json
+import re
+import threading
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+from pathlib import Path
+from typing import Any, Dict, List, Optional, Tuple
+from urllib.parse import urlsplit, parse_qsl
+
+
+HTTP_METHODS = ["get", "put", "post", "delete", "options", "head", "patch", "trace"]
+CONTROL_PREFIX = "/__mock"
+
+
+class OpenAPIError(Exception):
+    """Raised for specs that can't be loaded or used."""
+    pass
+
+
+# ===== Spec loading =====
+
+def load_spec(path: str) -> Dict[str, Any]:
+    """
+    Load an OpenAPI spec from JSON or YAML.
+    
+    Args:
+        path: Spec file
+        
+    Returns:
+        Parsed spec
+    """
+    spec_path = Path(path)
+    text = spec_path.read_text(encoding='utf-8')
+    
+    if spec_path.suffix.lower() == ".json":
+        spec = json.loads(text)
+    else:
+        try:
+            import yaml
+        except ImportError:
+            raise OpenAPIError(f"Reading {path} requires PyYAML (pip install pyyaml)")
+        spec = yaml.safe_load(text)
+    
+    if not isinstance(spec, dict) or "paths" not in spec:
+        raise OpenAPIError(f"{path} is not an OpenAPI spec (no 'paths')")
+    return spec
+
+
+def discover_specs(root: str) -> List[Path]:
+    """
+    Find OpenAPI specs in a repository.
+    
+    Args:
+        root: Repository root
+        
+    Returns:
+        JSON/YAML files declaring `openapi` or `swagger` at the top level
+    """
+    specs = []
+    ignored = {"node_modules", ".git", "vendor", ".venv", "venv", "__pycache__"}
+    
+    for path in sorted(Path(root).rglob("*")):
+        if path.suffix.lower() not in (".yaml", ".yml", ".json") or not path.is_file():
+            continue
+        if ignored.intersection(path.parts):
+            continue
+        try:
+            head = path.read_text(encoding='utf-8', errors='ignore')[:2000]
+        except OSError:
+            continue
+        if re.search(r'^\s*\{?\s*"?(openapi|swagger)"?\s*:', head, re.MULTILINE):
+            specs.append(path)
+    
+    return specs
+
+
+def resolve_ref(spec: Dict[str, Any], node: Any) -> Any:
+    """Follow local `$ref`s (e.g. #/components/schemas/User)."""
+    seen = set()
+    while isinstance(node, dict) and "$ref" in node:
+        ref = node["$ref"]
+        if ref in seen or not ref.startswith("#/"):
+            raise OpenAPIError(f"Unsupported or circular $ref: {ref}")
+        seen.add(ref)
+        target = spec
+        for part in ref[2:].split("/"):
+            target = target[part.replace("~1", "/").replace("~0", "~")]
+        node = target
+    return node
+
+
+# ===== Example generation =====
+
+def example_for_schema(schema: Dict[str, Any], spec: Dict[str, Any], depth: int = 0) -> Any:
+    """
+    Build a value that is valid against a schema.
+    
+    Explicit `example`/`default`/`enum` values win; otherwise a value is
+    derived from type, format and constraints.
+    
+    Args:
+        schema: JSON schema (may contain $ref)
+        spec: Full spec for resolving refs
+        depth: Recursion depth guard
+        
+    Returns:
+        Example value
+    """
+    schema = resolve_ref(spec, schema or {})
+    
+    if "example" in schema:
+        return schema["example"]
+    if "default" in schema:
+        return schema["default"]
+    if schema.get("enum"):
+        return schema["enum"][0]
+    if "const" in schema:
+        return schema["const"]
+    
+    if "allOf" in schema:
+        merged: Dict[str, Any] = {}
+        for part in schema["allOf"]:
+            value = example_for_schema(part, spec, depth + 1)
+            if isinstance(value, dict):
+                merged.update(value)
+        return merged
+    for key in ("oneOf", "anyOf"):
+        if schema.get(key):
+            return example_for_schema(schema[key][0], spec, depth + 1)
+    
+    schema_type = schema.get("type")
+    if isinstance(schema_type, list):
+        schema_type = next((t for t in schema_type if t != "null"), "null")
+    if schema_type is None:
+        schema_type = "object" if "properties" in schema else "string"
+    
+    if depth > 8:
+        return None
+    
+    if schema_type == "object":
+        properties = schema.get("properties", {})
+        return {
+            name: example_for_schema(prop, spec, depth + 1)
+            for name, prop in properties.items()
+        }
+    if schema_type == "array":
+        count = max(1, schema.get("minItems", 1))
+        return [example_for_schema(schema.get("items", {}), spec, depth + 1) for _ in range(count)]
+    if schema_type == "integer":
+        return int(_number_in_range(schema, 1))
+    if schema_type == "number":
+        return float(_number_in_range(schema, 1.5))
+    if schema_type == "boolean":
+        return True
+    if schema_type == "null":
+        return None
+    return _example_string(schema)
+
+
+def _number_in_range(schema: Dict[str, Any], preferred: float) -> float:
+    low = schema.get("minimum", schema.get("exclusiveMinimum"))
+    high = schema.get("maximum", schema.get("exclusiveMaximum"))
+    value = preferred
+    if isinstance(low, (int, float)) and value <= low:
+        value = low + 1 if "exclusiveMinimum" in schema and "minimum" not in schema else low
+    if isinstance(high, (int, float)) and value >= high:
+        value = high - 1 if "exclusiveMaximum" in schema and "maximum" not in schema else high
+    return value
+
+
+def _example_string(schema: Dict[str, Any]) -> str:
+    formats = {
+        "date-time": "2024-01-01T00:00:00Z",
+        "date": "2024-01-01",
+        "time": "00:00:00",
+        "email": "user@example.com",
+        "uuid": "00000000-0000-4000-8000-000000000000",
+        "uri": "https://example.com",
+        "url": "https://example.com",
+        "hostname": "example.com",
+        "ipv4": "127.0.0.1",
+        "ipv6": "::1",
+        "byte": "ZXhhbXBsZQ==",
+    }
+    value = formats.get(schema.get("format"), "string")
+    
+    min_length = schema.get("minLength", 0)
+    max_length = schema.get("maxLength")
+    if len(value) < min_length:
+        value = value + "x" * (min_length - len(value))
+    if max_length is not None and len(value) > max_length:
+        value = value[:max_length]
+    return value
+
+
+# ===== Validation =====
+
+def validate_value(value: Any, schema: Dict[str, Any], spec: Dict[str, Any], location: str = "$") -> List[str]:
+    """
+    Validate a value against a schema (the subset of JSON Schema used by OpenAPI).
+    
+    Args:
+        value: Decoded JSON value
+        schema: Schema to validate against
+        spec: Full spec for resolving refs
+        location: Path used in messages
+        
+    Returns:
+        List of problems (empty = valid)
+    """
+    schema = resolve_ref(spec, schema or {})
+    problems: List[str] = []
+    
+    if value is None and (schema.get("nullable") or "null" in (schema.get("type") or [])):
+        return problems
+    
+    if "allOf" in schema:
+        for part in schema["allOf"]:
+            problems.extend(validate_value(value, part, spec, location))
+    for key in ("oneOf", "anyOf"):
+        if schema.get(key):
+            if all(validate_value(value, option, spec, location) for option in schema[key]):
+                problems.append(f"{location}: does not match any {key} schema")
+    
+    if schema.get("enum") and value not in schema["enum"]:
+        problems.append(f"{location}: {value!r} is not one of {schema['enum']}")
+    
+    schema_type = schema.get("type")
+    types = schema_type if isinstance(schema_type, list) else [schema_type] if schema_type else []
+    if types and not any(_matches_type(value, t) for t in types):
+        problems.append(f"{location}: expected {'/'.join(types)}, got {type(value).__name__}")
+        return problems
+    
+    if isinstance(value, dict):
+        for name in schema.get("required", []):
+            if name not in value:
+                problems.append(f"{location}: missing required property '{name}'")
+        properties = schema.get("properties", {})
+        for name, item in value.items():
+            if name in properties:
+                problems.extend(validate_value(item, properties[name], spec, f"{location}.{name}"))
+            elif schema.get("additionalProperties") is False:
+                problems.append(f"{location}: unexpected property '{name}'")
+    elif isinstance(value, list):
+        if len(value) < schema.get("minItems", 0):
+            problems.append(f"{location}: expected at least {schema['minItems']} items")
+        if "maxItems" in schema and len(value) > schema["maxItems"]:
+            problems.append(f"{location}: expected at most {schema['maxItems']} items")
+        for index, item in enumerate(value):
+            problems.extend(validate_value(item, schema.get("items", {}), spec, f"{location}[{index}]"))
+    elif isinstance(value, str):
+        if len(value) < schema.get("minLength", 0):
+            problems.append(f"{location}: shorter than {schema['minLength']}")
+        if "maxLength" in schema and len(value) > schema["maxLength"]:
+            problems.append(f"{location}: longer than {schema['maxLength']}")
+        if "pattern" in schema and not re.search(schema["pattern"], value):
+            problems.append(f"{location}: does not match pattern {schema['pattern']}")
+    elif isinstance(value, (int, float)) and not isinstance(value, bool):
+        if "minimum" in schema and value < schema["minimum"]:
+            problems.append(f"{location}: below minimum {schema['minimum']}")
+        if "maximum" in schema and value > schema["maximum"]:
+            problems.append(f"{location}: above maximum {schema['maximum']}")
+    
+    return problems
+
+
+def _matches_type(value: Any, schema_type: str) -> bool:
+    if schema_type == "integer":
+        return isinstance(value, int) and not isinstance(value, bool)
+    if schema_type == "number":
+        return isinstance(value, (int, float)) and not isinstance(value, bool)
+    return {
+        "string": isinstance(value, str),
+        "boolean": isinstance(value, bool),
+        "object": isinstance(value, dict),
+        "array": isinstance(value, list),
+        "null": value is None,
+    }.get(schema_type, True)
+
+
+def _coerce_param(raw: str, schema: Dict[str, Any]) -> Any:
+    """Convert a path/query string to the schema's scalar type."""
+    schema_type = schema.get("type")
+    try:
+        if schema_type == "integer":
+            return int(raw)
+        if schema_type == "number":
+            return float(raw)
+    except ValueError:
+        return raw
+    if schema_type == "boolean":
+        return {"true": True, "false": False}.get(raw.lower(), raw)
+    return raw
+
+
+# ===== Operations =====
+
+class Operation:
+    """One method + path of the spec, with everything the mock needs."""
+    
+    def __init__(self, spec: Dict[str, Any], path: str, method: str, definition: Dict[str, Any], shared_params: List[Any]):
+        self.spec = spec
+        self.path = path
+        self.method = method.upper()
+        self.operation_id = definition.get("operationId", f"{method}_{path}")
+        self.parameters = [
+            resolve_ref(spec, p) for p in shared_params + definition.get("parameters", [])
+        ]
+        self.request_body = resolve_ref(spec, definition.get("requestBody", {})) or {}
+        self.responses = definition.get("responses", {})
+        
+        names = re.findall(r'{([^}/]+)}', path)
+        self.path_params = names
+        pattern = re.sub(r'{[^}/]+}', '([^/]+)', re.escape(path).replace(r'\{', '{').replace(r'\}', '}'))
+        self.regex = re.compile(f"^{pattern}$")
+    
+    def match(self, path: str) -> Optional[Dict[str, str]]:
+        """Return path parameters if the concrete path matches."""
+        found = self.regex.match(path)
+        if not found:
+            return None
+        return dict(zip(self.path_params, found.groups()))
+    
+    def success_status(self) -> int:
+        """First documented 2xx status (200 if none)."""
+        statuses = sorted(code for code in self.responses if str(code).startswith("2"))
+        return int(statuses[0]) if statuses else 200
+    
+    def example_response(self, status: Optional[int] = None) -> Tuple[int, Optional[Any]]:
+        """Example body for a status (default: the success status)."""
+        status = status or self.success_status()
+        response = self.responses.get(str(status)) or self.responses.get(status) or self.responses.get("default")
+        response = resolve_ref(self.spec, response or {})
+        
+        media = _json_media(response.get("content", {}))
+        if media is None:
+            return status, None
+        if "example" in media:
+            return status, media["example"]
+        if media.get("examples"):
+            first = resolve_ref(self.spec, next(iter(media["examples"].values())))
+            return status, first.get("value")
+        return status, example_for_schema(media.get("schema", {}), self.spec)
+    
+    def validate_request(self, path_params: Dict[str, str], query: Dict[str, str], body: Optional[bytes], content_type: str) -> List[str]:
+        """Check an incoming request against the operation."""
+        problems = []
+        where = f"{self.method} {self.path}"
+        
+        for param in self.parameters:
+            location = param.get("in")
+            name = param.get("name")
+            schema = param.get("schema", {})
+            if location == "query":
+                if name not in query:
+                    if param.get("required"):
+                        problems.append(f"{where}: missing required query parameter '{name}'")
+                    continue
+                raw = query[name]
+            elif location == "path":
+                raw = path_params.get(name, "")
+            else:
+                continue
+            for problem in validate_value(_coerce_param(raw, schema), schema, self.spec, f"{location}.{name}"):
+                problems.append(f"{where}: {problem}")
+        
+        if self.request_body:
+            media = _json_media(self.request_body.get("content", {}))
+            if not body:
+                if self.request_body.get("required"):
+                    problems.append(f"{where}: missing required request body")
+            elif media is not None:
+                if "json" not in content_type:
+                    problems.append(f"{where}: expected JSON body, got Content-Type '{content_type}'")
+                try:
+                    decoded = json.loads(body)
+                except ValueError:
+                    problems.append(f"{where}: request body is not valid JSON")
+                else:
+                    for problem in validate_value(decoded, media.get("schema", {}), self.spec, "body"):
+                        problems.append(f"{where}: {problem}")
+        
+        return problems
+
+
+def _json_media(content: Dict[str, Any]) -> Optional[Dict[str, Any]]:
+    for media_type, media in content.items():
+        if "json" in media_type or media_type == "*/*":
+            return media or {}
+    return None
+
+
+def list_operations(spec: Dict[str, Any]) -> List[Operation]:
+    """All operations of a spec, literal paths before templated ones."""
+    operations = []
+    for path, item in spec.get("paths", {}).items():
+        item = resolve_ref(spec, item)
+        shared = item.get("parameters", [])
+        for method in HTTP_METHODS:
+            if method in item:
+                operations.append(Operation(spec, path, method, item[method], shared))
+    # `/users/me` must win over `/users/{id}`
+    return sorted(operations, key=lambda op: (op.path.count("{"), -len(op.path)))
+
+
+# ===== Standalone server =====
+
+class MockState:
+    """Overrides, injected errors and recorded violations of a mock."""
+    
+    def __init__(self, spec: Dict[str, Any]):
+        self.spec = spec
+        self.operations = list_operations(spec)
+        self.lock = threading.Lock()
+        self.overrides: Dict[Tuple[str, str], Dict[str, Any]] = {}
+        self.violations: List[str] = []
+        self.requests: List[Dict[str, Any]] = []
+    
+    def find(self, method: str, path: str) -> Tuple[Optional[Operation], Dict[str, str]]:
+        for operation in self.operations:
+            if operation.method != method:
+                continue
+            params = operation.match(path)
+            if params is not None:
+                return operation, params
+        return None, {}
+    
+    def override(self, method: str, path: str, status: int, body: Any = None, headers: Optional[Dict[str, str]] = None) -> None:
+        """Replace the response of an operation (path as written in the spec)."""
+        with self.lock:
+            self.overrides[(method.upper(), path)] = {"status": status, "body": body, "headers": headers or {}}
+    
+    def inject_error(self, method: str, path: str, status: int = 500) -> None:
+        """Make an operation fail with an error status."""
+        self.override(method, path, status, {"error": f"injected {status}"})
+    
+    def reset(self) -> None:
+        with self.lock:
+            self.overrides.clear()
+            self.violations.clear()
+            self.requests.clear()
+    
+    def handle(self, method: str, raw_path: str, body: Optional[bytes], content_type: str) -> Tuple[int, Dict[str, str], Optional[Any]]:
+        """Produce (status, headers, body) for a request and record violations."""
+        parts = urlsplit(raw_path)
+        query = dict(parse_qsl(parts.query))
+        operation, path_params = self.find(method, parts.path)
+        
+        with self.lock:
+            self.requests.append({"method": method, "path": raw_path})
+        
+        if operation is None:
+            message = f"{method} {parts.path}: no such operation in the spec"
+            with self.lock:
+                self.violations.append(message)
+            return 404, {}, {"error": message}
+        
+        problems = operation.validate_request(path_params, query, body, content_type)
+        if problems:
+            with self.lock:
+                self.violations.extend(problems)
+        
+        with self.lock:
+            override = self.overrides.get((method, operation.path))
+        if override is not None:
+            return override["status"], override["headers"], override["body"]
+        
+        if problems:
+            return 400, {}, {"error": "request does not match spec", "violations": problems}
+        
+        status, example = operation.example_response()
+        return status, {}, example
+
+
+def create_mock_server(spec: Dict[str, Any], port: int = 0, host: str = "127.0.0.1") -> Tuple[ThreadingHTTPServer, MockState]:
+    """Create (but don't start) a mock server for a spec."""
+    state = MockState(spec)
+    
+    class MockHandler(BaseHTTPRequestHandler):
+        def _send(self, status: int, headers: Dict[str, str], body: Any) -> None:
+            payload = b""
+            headers = dict(headers)
+            if body is not None:
+                if isinstance(body, str):
+                    payload = body.encode("utf-8")
+                else:
+                    payload = json.dumps(body).encode("utf-8")
+                    headers.setdefault("Content-Type", "application/json")
+            self.send_response(status)
+            for name, value in headers.items():
+                self.send_header(name, value)
+            self.send_header("Content-Length", str(len(payload)))
+            self.end_headers()
+            if self.command != "HEAD":
+                self.wfile.write(payload)
+        
+        def _control(self, body: Optional[bytes]) -> None:
+            action = urlsplit(self.path).path[len(CONTROL_PREFIX):].strip("/")
+            data = json.loads(body) if body else {}
+            if action == "overrides" and self.command == "POST":
+                state.override(data["method"], data["path"], int(data.get("status", 200)), data.get("body"), data.get("headers"))
+                self._send(204, {}, None)
+            elif action == "errors" and self.command == "POST":
+                state.inject_error(data["method"], data["path"], int(data.get("status", 500)))
+                self._send(204, {}, None)
+            elif action == "violations":
+                with state.lock:
+                    self._send(200, {}, list(state.violations))
+            elif action == "reset" and self.command == "POST":
+                state.reset()
+                self._send(204, {}, None)
+            else:
+                self._send(404, {}, {"error": f"unknown control endpoint {self.path}"})
+        
+        def _serve(self) -> None:
+            length = int(self.headers.get("Content-Length") or 0)
+            body = self.rfile.read(length) if length else None
+            
+            if self.path.startswith(CONTROL_PREFIX):
+                self._control(body)
+                return
+            
+            status, headers, response = state.handle(
+                self.command, self.path, body, self.headers.get("Content-Type", "")
+            )
+            self._send(status, headers, response)
+        
+        do_GET = do_POST = do_PUT = do_PATCH = do_DELETE = do_HEAD = do_OPTIONS = _serve
+        
+        def log_message(self, format, *args):
+            pass
+    
+    return ThreadingHTTPServer((host, port), MockHandler), state
+
+
+class MockServer:
+    """
+    Standalone mock server running in a background thread.
+    
+    Example:
+        >>> with MockServer("api/payments.yaml") as mock:
+        ...     mock.inject_error("POST", "/charges", 503)
+        ...     client = PaymentsClient(mock.url)
+        ...     ...
+        ...     assert mock.violations == []
+    """
+    
+    def __init__(self, spec_path: str, port: int = 0):
+        """
+        Initialize server.
+        
+        Args:
+            spec_path: OpenAPI spec file
+            port: Port (0 = any free port)
+        """
+        self.server, self.state = create_mock_server(load_spec(spec_path), port)
+        self._thread: Optional[threading.Thread] = None
+    
+    @property
+    def url(self) -> str:
+        return f"http://127.0.0.1:{self.server.server_address[1]}"
+    
+    @property
+    def violations(self) -> List[str]:
+        with self.state.lock:
+            return list(self.state.violations)
+    
+    def override(self, method: str, path: str, status: int, body: Any = None, headers: Optional[Dict[str, str]] = None) -> None:
+        self.state.override(method, path, status, body, headers)
+    
+    def inject_error(self, method: str, path: str, status: int = 500) -> None:
+        self.state.inject_error(method, path, status)
+    
+    def reset(self) -> None:
+        self.state.reset()
+    
+    def start(self) -> "MockServer":
+        self._thread = threading.Thread(target=self.server.serve_forever, daemon=True)
+        self._thread.start()
+        return self
+    
+    def stop(self) -> None:
+        self.server.shutdown()
+        self.server.server_close()
+    
+    def __enter__(self) -> "MockServer":
+        return self.start()
+    
+    def __exit__(self, exc_type, exc, tb) -> None:
+        self.stop()
+
+
+# ===== Go in-process mock generation =====
+
+def generate_go_mock(spec_path: str, package: str, name: Optional[str] = None) -> str:
+    """
+    Generate an in-process Go mock (`httptest.Server`) for a spec.
+    
+    The generated `New<Name>Mock(t)` starts the server, and on cleanup fails
+    the test for every request that didn't match the spec: unknown
+    operations, and parameters or bodies that don't validate against their
+    schemas (types, enums, required properties, ranges, lengths, patterns).
+    
+    Args:
+        spec_path: OpenAPI spec file
+        package: Go package of the test file
+        name: Mock type prefix (default: derived from the spec title/file)
+        
+    Returns:
+        Go source for a `_test.go` file
+    """
+    spec = load_spec(spec_path)
+    type_name = _go_identifier(name or spec.get("info", {}).get("title") or Path(spec_path).stem) + "Mock"
+    lower = type_name[0].lower() + type_name[1:]
+    
+    routes = []
+    for operation in list_operations(spec):
+        status, example = operation.example_response()
+        body = "" if example is None else json.dumps(example, ensure_ascii=False)
+        params = [
+            "\t\t\t{"
+            f"name: {_go_string(p['name'])}, in: {_go_string(p['in'])}, "
+            f"required: {'true' if p.get('required') else 'false'}, "
+            f"schema: {_go_string(json.dumps(_inline_schema(p.get('schema', {}), spec), ensure_ascii=False))}"
+            "},\n"
+            for p in operation.parameters if p.get("in") in ("query", "path")
+        ]
+        media = _json_media(operation.request_body.get("content", {})) if operation.request_body else None
+        body_schema = json.dumps(_inline_schema(media.get("schema", {}), spec), ensure_ascii=False) if media is not None else ""
+        routes.append(
+            "\t{\n"
+            f"\t\tmethod:       {_go_string(operation.method)},\n"
+            f"\t\tpath:         {_go_string(operation.path)},\n"
+            f"\t\tpattern:      regexp.MustCompile({_go_string(operation.regex.pattern)}),\n"
+            f"\t\tpathParams:   {_go_string_slice(operation.path_params)},\n"
+            f"\t\tstatus:       {status},\n"
+            f"\t\tbody:         {_go_string(body)},\n"
+            f"\t\tbodyRequired: {'true' if operation.request_body.get('required') else 'false'},\n"
+            f"\t\tbodySchema:   {_go_string(body_schema)},\n"
+            + (f"\t\tparams: []{lower}Param{{\n{''.join(params)}\t\t}},\n" if params else "")
+            + "\t},"
+        )
+    
+    return GO_MOCK_TEMPLATE.format(
+        source=Path(spec_path).as_posix(),
+        package=package,
+        type_name=type_name,
+        lower=lower,
+        routes="\n".join(routes),
+    )
+
+
+def go_mock_path(spec_path: str, package: str) -> Path:
+    """
+    Default file for the Go mock of a spec: `<spec>_mock_test.go` in the
+    directory of the Go package it is generated for.
+    
+    The package is searched in the Go module holding the spec (or the
+    spec's directory when there is no go.mod).
+    
+    Raises:
+        OpenAPIError: No directory, or more than one, declares the package
+    """
+    spec = Path(spec_path).resolve()
+    root = next((d for d in spec.parents if (d / "go.mod").is_file()), spec.parent)
+    clause = re.compile(rf'^package\s+{re.escape(package)}\s*$', re.MULTILINE)
+    
+    directories = sorted({
+        f.parent for f in root.rglob("*.go")
+        if not f.name.endswith("_test.go")
+        and not any(part in ("vendor", "testdata") or part.startswith(".") for part in f.relative_to(root).parts)
+        and clause.search(f.read_text(encoding='utf-8', errors='ignore'))
+    })
+    if len(directories) != 1:
+        found = ", ".join(str(d.relative_to(root)) for d in directories) or "none"
+        raise OpenAPIError(f"Cannot tell where Go package '{package}' is (found: {found}); pass an output file")
+    return directories[0] / f"{spec.stem}_mock_test.go"
+
+
+def _inline_schema(schema: Any, spec: Dict[str, Any], depth: int = 0) -> Dict[str, Any]:
+    """A schema with its `$ref`s replaced by what they point to (recursive ones cut off)."""
+    if depth > 8:
+        return {}
+    schema = resolve_ref(spec, schema or {})
+    if not isinstance(schema, dict):
+        return {}
+    inlined = dict(schema)
+    if isinstance(schema.get("properties"), dict):
+        inlined["properties"] = {k: _inline_schema(v, spec, depth + 1) for k, v in schema["properties"].items()}
+    for key in ("items", "additionalProperties"):
+        if isinstance(schema.get(key), dict):
+            inlined[key] = _inline_schema(schema[key], spec, depth + 1)
+    for key in ("allOf", "oneOf", "anyOf"):
+        if isinstance(schema.get(key), list):
+            inlined[key] = [_inline_schema(part, spec, depth + 1) for part in schema[key]]
+    return inlined
+
+
+def _go_identifier(text: str) -> str:
+    words = re.findall(r'[A-Za-z0-9]+', text)
+    ident = "".join(w[:1].upper() + w[1:] for w in words) or "API"
+    if ident[0].isdigit():
+        ident = "API" + ident
+    return ident
+
+
+def _go_string(value: str) -> str:
+    # Go string literals are UTF-8; \uXXXX surrogate pairs (json's ASCII escaping) aren't valid in them
+    return json.dumps(value, ensure_ascii=False)
+
+
+def _go_string_slice(values: List[str]) -> str:
+    if not values:
+        return "nil"
+    return "[]string{" + ", ".join(_go_string(v) for v in values) + "}"
+
+
+GO_MOCK_TEMPLATE = '''// Code generated by testgen from {source}. DO NOT EDIT.
+
+package {package}
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+type {lower}Route struct {{
+	method       string
+	path         string
+	pattern      *regexp.Regexp
+	pathParams   []string
+	status       int
+	body         string
+	params       []{lower}Param
+	bodyRequired bool
+	bodySchema   string // JSON schema of the request body, "" when it has none
+}}
+
+type {lower}Param struct {{
+	name     string
+	in       string // "query" or "path"
+	required bool
+	schema   string
+}}
+
+type {lower}Response struct {{
+	status int
+	body   string
+	header http.Header
+}}
+
+var {lower}Routes = []{lower}Route{{
+{routes}
+}}
+
+// {type_name} is an in-process mock of the API described by {source}.
+// It serves the spec's example responses; use Override and InjectError to
+// change them per test. Requests that don't match the spec fail the test.
+type {type_name} struct {{
+	*httptest.Server
+
+	mu         sync.Mutex
+	overrides  map[string]{lower}Response
+	violations []string
+}}
+
+// New{type_name} starts the mock and stops it when the test finishes.
+func New{type_name}(t testing.TB) *{type_name} {{
+	t.Helper()
+	m := &{type_name}{{overrides: map[string]{lower}Response{{}}}}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.serve))
+	t.Cleanup(func() {{
+		m.Server.Close()
+		for _, v := range m.Violations() {{
+			t.Errorf("{type_name}: %s", v)
+		}}
+	}})
+	return m
+}}
+
+// Override replaces the response of an operation. path is the path as
+// written in the spec, e.g. "/users/{{id}}".
+func (m *{type_name}) Override(method, path string, status int, body string) {{
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.overrides[method+" "+path] = {lower}Response{{status: status, body: body}}
+}}
+
+// InjectError makes an operation fail with the given status code.
+func (m *{type_name}) InjectError(method, path string, status int) {{
+	m.Override(method, path, status, fmt.Sprintf(`{{"error": "injected %d"}}`, status))
+}}
+
+// Violations returns the requests that didn't match the spec so far.
+func (m *{type_name}) Violations() []string {{
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.violations...)
+}}
+
+func (m *{type_name}) violate(format string, args ...any) {{
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.violations = append(m.violations, fmt.Sprintf(format, args...))
+}}
+
+func (m *{type_name}) serve(w http.ResponseWriter, r *http.Request) {{
+	var route *{lower}Route
+	for i := range {lower}Routes {{
+		if {lower}Routes[i].method == r.Method && {lower}Routes[i].pattern.MatchString(r.URL.Path) {{
+			route = &{lower}Routes[i]
+			break
+		}}
+	}}
+	if route == nil {{
+		m.violate("%s %s: no such operation in the spec", r.Method, r.URL.Path)
+		http.Error(w, `{{"error": "no such operation"}}`, http.StatusNotFound)
+		return
+	}}
+
+	pathValues := map[string]string{{}}
+	for i, value := range route.pattern.FindStringSubmatch(r.URL.Path)[1:] {{
+		pathValues[route.pathParams[i]] = value
+	}}
+	query := r.URL.Query()
+	for _, param := range route.params {{
+		raw, ok := pathValues[param.name], param.in == "path"
+		if param.in == "query" {{
+			raw, ok = query.Get(param.name), query.Has(param.name)
+		}}
+		if !ok {{
+			if param.required {{
+				m.violate("%s %s: missing required %s parameter %q", route.method, route.path, param.in, param.name)
+			}}
+			continue
+		}}
+		schema := {lower}Schema(param.schema)
+		for _, problem := range {lower}Validate({lower}Coerce(raw, schema), schema, param.in+"."+param.name) {{
+			m.violate("%s %s: %s", route.method, route.path, problem)
+		}}
+	}}
+
+	raw, _ := io.ReadAll(r.Body)
+	if len(raw) == 0 {{
+		if route.bodyRequired {{
+			m.violate("%s %s: missing required request body", route.method, route.path)
+		}}
+	}} else if route.bodySchema != "" {{
+		var body any
+		if err := json.Unmarshal(raw, &body); err != nil {{
+			m.violate("%s %s: request body is not valid JSON: %v", route.method, route.path, err)
+		}} else {{
+			for _, problem := range {lower}Validate(body, {lower}Schema(route.bodySchema), "body") {{
+				m.violate("%s %s: %s", route.method, route.path, problem)
+			}}
+		}}
+	}}
+
+	m.mu.Lock()
+	resp, ok := m.overrides[route.method+" "+route.path]
+	m.mu.Unlock()
+	if !ok {{
+		resp = {lower}Response{{status: route.status, body: route.body}}
+	}}
+
+	for k, vs := range resp.header {{
+		for _, v := range vs {{
+			w.Header().Add(k, v)
+		}}
+	}}
+	if resp.body != "" {{
+		w.Header().Set("Content-Type", "application/json")
+	}}
+	w.WriteHeader(resp.status)
+	io.WriteString(w, resp.body)
+}}
+
+func {lower}Schema(source string) map[string]any {{
+	schema := map[string]any{{}}
+	json.Unmarshal([]byte(source), &schema)
+	return schema
+}}
+
+// {lower}Coerce converts a path or query value to the type of its schema.
+func {lower}Coerce(raw string, schema map[string]any) any {{
+	switch schema["type"] {{
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {{
+			return n
+		}}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {{
+			return b
+		}}
+	}}
+	return raw
+}}
+
+// {lower}Validate checks a decoded JSON value against a schema (the subset
+// of JSON Schema OpenAPI uses) and returns the problems found.
+func {lower}Validate(value any, schema map[string]any, location string) []string {{
+	var problems []string
+	types := {lower}Types(schema["type"])
+	if value == nil && (schema["nullable"] == true || {lower}Contains(types, "null")) {{
+		return nil
+	}}
+
+	if parts, ok := schema["allOf"].([]any); ok {{
+		for _, part := range parts {{
+			if sub, ok := part.(map[string]any); ok {{
+				problems = append(problems, {lower}Validate(value, sub, location)...)
+			}}
+		}}
+	}}
+	for _, key := range []string{{"oneOf", "anyOf"}} {{
+		options, _ := schema[key].([]any)
+		matched := len(options) == 0
+		for _, option := range options {{
+			if sub, ok := option.(map[string]any); ok && len({lower}Validate(value, sub, location)) == 0 {{
+				matched = true
+				break
+			}}
+		}}
+		if !matched {{
+			problems = append(problems, fmt.Sprintf("%s: does not match any %s schema", location, key))
+		}}
+	}}
+
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {{
+		found := false
+		for _, allowed := range enum {{
+			if reflect.DeepEqual(allowed, value) {{
+				found = true
+				break
+			}}
+		}}
+		if !found {{
+			problems = append(problems, fmt.Sprintf("%s: %v is not one of %v", location, value, enum))
+		}}
+	}}
+
+	if len(types) > 0 {{
+		matched := false
+		for _, t := range types {{
+			matched = matched || {lower}IsType(value, t)
+		}}
+		if !matched {{
+			return append(problems, fmt.Sprintf("%s: expected %s, got %T", location, strings.Join(types, "/"), value))
+		}}
+	}}
+
+	switch v := value.(type) {{
+	case map[string]any:
+		required, _ := schema["required"].([]any)
+		for _, name := range required {{
+			if _, ok := v[fmt.Sprint(name)]; !ok {{
+				problems = append(problems, fmt.Sprintf("%s: missing required property '%v'", location, name))
+			}}
+		}}
+		properties, _ := schema["properties"].(map[string]any)
+		names := make([]string, 0, len(v))
+		for name := range v {{
+			names = append(names, name)
+		}}
+		sort.Strings(names)
+		for _, name := range names {{
+			if sub, ok := properties[name].(map[string]any); ok {{
+				problems = append(problems, {lower}Validate(v[name], sub, location+"."+name)...)
+			}} else if schema["additionalProperties"] == false {{
+				problems = append(problems, fmt.Sprintf("%s: unexpected property '%s'", location, name))
+			}}
+		}}
+	case []any:
+		if n, ok := schema["minItems"].(float64); ok && float64(len(v)) < n {{
+			problems = append(problems, fmt.Sprintf("%s: expected at least %v items", location, n))
+		}}
+		if n, ok := schema["maxItems"].(float64); ok && float64(len(v)) > n {{
+			problems = append(problems, fmt.Sprintf("%s: expected at most %v items", location, n))
+		}}
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range v {{
+			problems = append(problems, {lower}Validate(item, items, fmt.Sprintf("%s[%d]", location, i))...)
+		}}
+	case string:
+		if n, ok := schema["minLength"].(float64); ok && float64(utf8.RuneCountInString(v)) < n {{
+			problems = append(problems, fmt.Sprintf("%s: shorter than %v", location, n))
+		}}
+		if n, ok := schema["maxLength"].(float64); ok && float64(utf8.RuneCountInString(v)) > n {{
+			problems = append(problems, fmt.Sprintf("%s: longer than %v", location, n))
+		}}
+		if pattern, ok := schema["pattern"].(string); ok {{
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {{
+				problems = append(problems, fmt.Sprintf("%s: does not match pattern %s", location, pattern))
+			}}
+		}}
+	case float64:
+		if n, ok := schema["minimum"].(float64); ok && v < n {{
+			problems = append(problems, fmt.Sprintf("%s: below minimum %v", location, n))
+		}}
+		if n, ok := schema["maximum"].(float64); ok && v > n {{
+			problems = append(problems, fmt.Sprintf("%s: above maximum %v", location, n))
+		}}
+	}}
+	return problems
+}}
+
+func {lower}Types(schemaType any) []string {{
+	switch t := schemaType.(type) {{
+	case string:
+		return []string{{t}}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, name := range t {{
+			types = append(types, fmt.Sprint(name))
+		}}
+		return types
+	}}
+	return nil
+}}
+
+func {lower}IsType(value any, schemaType string) bool {{
+	switch schemaType {{
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "null":
+		return value == nil
+	}}
+	return true
+}}
+
+func {lower}Contains(values []string, want string) bool {{
+	for _, v := range values {{
+		if v == want {{
+			return true
+		}}
+	}}
+	return false
+}}
+'''
+
+
+def main(argv: Optional[List[str]] = None) -> None:
+    """Entry point for running a mock as its own process."""
+    parser = argparse.ArgumentParser(description="OpenAPI mock server")
+    subparsers = parser.add_subparsers(dest="command", required=True)
+    
+    serve_parser = subparsers.add_parser("serve", help="Serve a spec")
+    serve_parser.add_argument("spec", help="OpenAPI spec (JSON or YAML)")
+    serve_parser.add_argument("--port", type=int, default=0, help="Port (0 = any free port)")
+    
+    args = parser.parse_args(argv)
+    
+    server, _ = create_mock_server(load_spec(args.spec), args.port)
+    print(server.server_address[1], flush=True)
+    try:
+        server.serve_forever()
+    except KeyboardInterrupt:
+        pass
+    finally:
+        server.server_close()
+
+
+if __name__ == "__main__":
+    main()
@@ -8,7 +8,7 @@ from typing import List, Dict, Optional, Tuple
 from enum import Enum
 from dataclasses import dataclass
 
-from .result_models import TestResult, TestSuite, ExecutionSummary, Language, TestFramework
+from .result_models import TestResult, TestSuite, ExecutionSummary, Language, TestFramework, ResourceUsage
 
 
 class PerformanceLevel(str, Enum):
@@ -33,6 +33,10 @@ class PerformanceThresholds:
     warning_max: float = 5.0        # 1s - 5s = warning
     critical_min: float = 5.0       # > 5s = critical
     
+    # Resource hogs (per suite / Go package test binary)
+    memory_hog_mb: float = 1024.0   # peak RSS above 1 GiB
+    cpu_hog_seconds: float = 60.0   # more than 60s of CPU time
+    
     # Language-specific adjustments
     language_multipliers: Dict[Language, float] = None
     
@@ -73,6 +77,20 @@ class TestPerformanceInfo:
     times_slower_than_average: Optional[float] = None
 
 
+@dataclass
+class SuiteResourceInfo:
+    """Resource usage of a suite next to its wall time."""
+    suite_name: str
+    usage: ResourceUsage
+    wall_time: float
+    memory_hog: bool = False
+    cpu_hog: bool = False
+    
+    @property
+    def is_hog(self) -> bool:
+        return self.memory_hog or self.cpu_hog
+
+
 class PerformanceMonitor:
     """
     Monitor and analyze test performance across ALL 14 languages.
@@ -95,6 +113,7 @@ class PerformanceMonitor:
         self.thresholds = thresholds or PerformanceThresholds()
         self.language = language
         self.tests: List[Tuple[TestResult, str]] = []  # (test, suite_name)
+        self.suite_usage: List[Tuple[str, float, ResourceUsage]] = []  # (suite_name, wall time, usage)
     
     def add_test(self, test: TestResult, suite_name: str = "") -> None:
         """Add a test for performance tracking."""
@@ -104,6 +123,10 @@ class PerformanceMonitor:
         """Add all tests from a suite."""
         for test in suite.tests:
             self.add_test(test, suite.name)
+        
+        if suite.resource_usage is not None:
+            wall_time = suite.resource_usage.wall_time or suite.total_duration
+            self.suite_usage.append((suite.name, wall_time, suite.resource_usage))
     
     def add_summary(self, summary: ExecutionSummary) -> None:
         """Add all tests from an execution summary."""
@@ -300,6 +323,29 @@ class PerformanceMonitor:
             "acceptable": self.get_slow_tests(level=PerformanceLevel.ACCEPTABLE)
         }
     
+    def get_resource_usage(self) -> List[SuiteResourceInfo]:
+        """
+        Get resource usage per suite, flagged against the hog thresholds.
+        
+        Returns:
+            Suites with usage data, highest peak RSS first
+        """
+        infos = [
+            SuiteResourceInfo(
+                suite_name=name,
+                usage=usage,
+                wall_time=wall_time,
+                memory_hog=usage.peak_rss_mb > self.thresholds.memory_hog_mb,
+                cpu_hog=usage.cpu_time > self.thresholds.cpu_hog_seconds
+            )
+            for name, wall_time, usage in self.suite_usage
+        ]
+        return sorted(infos, key=lambda x: x.usage.peak_rss, reverse=True)
+    
+    def get_resource_hogs(self) -> List[SuiteResourceInfo]:
+        """Get suites above the memory or CPU hog threshold."""
+        return [info for info in self.get_resource_usage() if info.is_hog]
+    
     def generate_performance_report(self) -> str:
         """
         Generate human-readable performance report.
@@ -368,11 +414,43 @@ class PerformanceMonitor:
                 report.append(f"  ... and {len(flagged['warning']) - 5} more")
             report.append("")
         
+        resource_usage = self.get_resource_usage()
+        if resource_usage:
+            report.append("Resource Usage (top 5 by peak RSS):")
+            for info in resource_usage[:5]:
+                report.append(f"  - {info.suite_name}: {_format_usage(info)}")
+            report.append("")
+        
+        hogs = [info for info in resource_usage if info.is_hog]
+        if hogs:
+            report.append(
+                f"🐷 RESOURCE HOGS (>{self.thresholds.memory_hog_mb:.0f} MB RSS "
+                f"or >{self.thresholds.cpu_hog_seconds:.0f}s CPU):"
+            )
+            for info in hogs:
+                reasons = []
+                if info.memory_hog:
+                    reasons.append("memory")
+                if info.cpu_hog:
+                    reasons.append("CPU")
+                report.append(f"  - {info.suite_name} [{', '.join(reasons)}]: {_format_usage(info)}")
+            report.append("")
+        
         report.append("=" * 70)
         
         return "\n".join(report)
 
 
+def _format_usage(info: SuiteResourceInfo) -> str:
+    """One-line usage summary: wall, CPU, peak RSS, writes."""
+    usage = info.usage
+    written = f"{usage.bytes_written / (1024 * 1024):.1f} MB" if usage.bytes_written is not None else "n/a"
+    return (
+        f"wall {info.wall_time:.2f}s, CPU {usage.cpu_time:.2f}s, "
+        f"peak RSS {usage.peak_rss_mb:.0f} MB, written {written} ({usage.fs_writes} writes)"
+    )
+
+
 # Utility functions
 
 def analyze_test_performance(
@@ -0,0 +1,612 @@
+"""
+Test Translation for Code Ports.
+
+When code is ported to another language (Python services moving to Go),
+the existing tests are translated along with it so the port can be shown
+to behave the same:
+
+    1. Index the symbols of both implementations (functions, classes,
+       methods / types) and match them by name: `Cart.add_item` -> `Cart.AddItem`,
+       a class to its `NewX` constructor or type.
+    2. Map every source test to the target symbols it exercises, through
+       the source symbols it calls. Tests calling something with no
+       counterpart, or nothing from the ported code, are reported as
+       unmappable instead of being translated.
+    3. Translate the mapped tests with the target language's prompt
+       template, each named deterministically and marked with a
+       `Ported from:` comment (the traceability link back to the source test).
+    4. Run the source tests against the original and the translated tests
+       against the port, and compare outcomes test by test.
+
+Supported ports: Python -> Go and Go -> Python.
+"""
+
+import ast
+import json
+import re
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Dict, List, Optional, Tuple
+
+from .fault_injection import GO_FUNC
+from .language_config import Language, get_language_config
+from .load_test import GO_PACKAGE
+from .migration import run_outcomes
+from .table_tests import _gofmt, find_test_functions
+
+
+GO_TYPE = re.compile(r'^type\s+([A-Za-z_]\w*)(?:\[[^\]]*\])?\s+(?:struct|interface)\b', re.MULTILINE)
+
+SKIPPED_DIRECTORIES = {"vendor", "node_modules", "testdata", "venv", "__pycache__", "site-packages"}
+
+PORTS = {(Language.PYTHON, Language.GO), (Language.GO, Language.PYTHON)}
+
+
+@dataclass
+class Symbol:
+    """A function, method or type of one implementation."""
+    
+    name: str                # qualified: "add_item", "Cart.add_item", "Cart"
+    kind: str                # "function", "method" or "type"
+    file: str
+    line: int
+    signature: str = ""
+    
+    @property
+    def location(self) -> str:
+        return f"{self.file}:{self.line}"
+
+
+@dataclass
+class SourceTest:
+    """A test of the original implementation."""
+    
+    id: str                  # "tests/test_cart.py::TestCart::test_add" or "cart_test.go::TestAdd"
+    file: str
+    line: int
+    code: str
+    calls: List[str] = field(default_factory=list)  # source symbols it exercises
+    
+    @property
+    def name(self) -> str:
+        return self.id.split("::", 1)[1]
+
+
+@dataclass
+class TestMapping:
+    """A source test, the target symbols it maps to and how it ran on both sides."""
+    
+    test: SourceTest
+    target_test: str
+    symbols: Dict[str, Optional[Symbol]] = field(default_factory=dict)  # source symbol -> target
+    problem: Optional[str] = None
+    source_outcome: Optional[str] = None
+    target_outcome: Optional[str] = None
+    
+    @property
+    def mappable(self) -> bool:
+        return self.problem is None
+    
+    @property
+    def parity(self) -> Optional[bool]:
+        """Same outcome on both sides (None until both have run)."""
+        if self.source_outcome is None or self.target_outcome is None:
+            return None
+        return self.source_outcome == self.target_outcome
+    
+    def to_dict(self) -> Dict:
+        return {
+            "source_test": self.test.id,
+            "target_test": self.target_test if self.mappable else None,
+            "symbols": {s: (t.name if t else None) for s, t in self.symbols.items()},
+            "problem": self.problem,
+            "source_outcome": self.source_outcome,
+            "target_outcome": self.target_outcome,
+            "parity": self.parity,
+        }
+
+
+@dataclass
+class PortReport:
+    """Outcome of translating a test suite for a port."""
+    
+    source_language: Language
+    target_language: Language
+    mappings: List[TestMapping] = field(default_factory=list)
+    files: Dict[Path, str] = field(default_factory=dict)   # translated test files
+    problems: List[str] = field(default_factory=list)      # files that couldn't be translated or run
+    
+    @property
+    def mapped(self) -> List[TestMapping]:
+        return [m for m in self.mappings if m.mappable]
+    
+    @property
+    def unmapped(self) -> List[TestMapping]:
+        return [m for m in self.mappings if not m.mappable]
+    
+    @property
+    def diverging(self) -> List[TestMapping]:
+        return [m for m in self.mapped if m.parity is False]
+    
+    def to_dict(self) -> Dict:
+        return {
+            "source_language": self.source_language.value,
+            "target_language": self.target_language.value,
+            "files": [str(f) for f in self.files],
+            "problems": self.problems,
+            "tests": [m.to_dict() for m in self.mappings],
+        }
+
+
+def build_symbol_index(directory: Path, language: Language) -> Dict[str, Symbol]:
+    """
+    Index the functions, methods and types of an implementation (tests excluded).
+    
+    Args:
+        directory: Root of the implementation
+        language: Its language (Python or Go)
+        
+    Returns:
+        Qualified name -> Symbol
+    """
+    index: Dict[str, Symbol] = {}
+    
+    for path in _source_files(directory, language, tests=False):
+        relative = str(path.relative_to(directory))
+        code = path.read_text(encoding='utf-8', errors='replace')
+        
+        if language == Language.PYTHON:
+            try:
+                tree = ast.parse(code)
+            except SyntaxError:
+                continue
+            for node in tree.body:
+                if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                    index[node.name] = Symbol(node.name, "function", relative, node.lineno, _python_signature(node))
+                elif isinstance(node, ast.ClassDef):
+                    index[node.name] = Symbol(node.name, "type", relative, node.lineno, f"class {node.name}")
+                    for item in node.body:
+                        if isinstance(item, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                            name = f"{node.name}.{item.name}"
+                            index[name] = Symbol(name, "method", relative, item.lineno, _python_signature(item))
+        
+        elif language == Language.GO:
+            for match in GO_TYPE.finditer(code):
+                line = code.count("\n", 0, match.start()) + 1
+                index[match.group(1)] = Symbol(match.group(1), "type", relative, line, match.group(0))
+            for match in GO_FUNC.finditer(code):
+                receiver, name = match.group(1), match.group(2)
+                qualified = f"{receiver}.{name}" if receiver else name
+                line = code.count("\n", 0, match.start()) + 1
+                signature = code[match.start():code.find("{", match.end())].strip() if "{" in code[match.end():] else ""
+                index[qualified] = Symbol(qualified, "method" if receiver else "function", relative, line, signature)
+    
+    return index
+
+
+def find_source_tests(directory: Path, language: Language, index: Dict[str, Symbol]) -> List[SourceTest]:
+    """
+    Find the tests of the original implementation and the symbols each calls.
+    
+    Args:
+        directory: Root of the original implementation
+        language: Its language
+        index: Its symbol index (see `build_symbol_index`)
+        
+    Returns:
+        Source tests in file order
+    """
+    tests: List[SourceTest] = []
+    
+    for path in _source_files(directory, language, tests=True):
+        relative = str(path.relative_to(directory))
+        code = path.read_text(encoding='utf-8', errors='replace')
+        
+        if language == Language.PYTHON:
+            try:
+                tree = ast.parse(code)
+            except SyntaxError:
+                continue
+            for node, owner in _python_test_functions(tree):
+                test_id = f"{relative}::{owner}::{node.name}" if owner else f"{relative}::{node.name}"
+                tests.append(SourceTest(
+                    id=test_id, file=relative, line=node.lineno,
+                    code=ast.get_source_segment(code, node), calls=_python_calls(node, index)
+                ))
+        
+        elif language == Language.GO:
+            for function in find_test_functions(code):
+                line = code.count("\n", 0, function.start) + 1 + len(function.doc)
+                source = code[function.start:function.end]
+                tests.append(SourceTest(
+                    id=f"{relative}::{function.name}", file=relative, line=line,
+                    code=source, calls=_go_calls(function.body, index)
+                ))
+    
+    return tests
+
+
+def map_tests(
+    tests: List[SourceTest],
+    source_index: Dict[str, Symbol],
+    target_index: Dict[str, Symbol],
+    target_language: Language
+) -> List[TestMapping]:
+    """
+    Map each source test to the target symbols it should exercise.
+    
+    Args:
+        tests: Source tests (see `find_source_tests`)
+        source_index: Symbols of the original implementation
+        target_index: Symbols of the port
+        target_language: Language of the port
+        
+    Returns:
+        One TestMapping per source test; unmappable ones have a `problem`
+    """
+    by_key: Dict[str, Symbol] = {}
+    for symbol in target_index.values():
+        by_key.setdefault(_symbol_key(symbol.name), symbol)
+    
+    mappings = []
+    used_names = set()
+    for test in tests:
+        mapping = TestMapping(test=test, target_test=_target_test_name(test, target_language, used_names))
+        for name in test.calls:
+            source = source_index[name]
+            key = _symbol_key(name)
+            # A class maps to its constructor function when the port has one
+            candidates = ([f"new{key}"] if source.kind == "type" else []) + [key]
+            mapping.symbols[name] = next((by_key[k] for k in candidates if k in by_key), None)
+        
+        missing = [s for s, t in mapping.symbols.items() if t is None]
+        if not test.calls:
+            mapping.problem = "calls nothing from the ported code"
+        elif missing:
+            mapping.problem = f"no {target_language.value.capitalize()} counterpart for " + ", ".join(missing)
+        mappings.append(mapping)
+    return mappings
+
+
+def describe_ported_tests(mappings: List[TestMapping], source_language: Language, target_language: Language) -> str:
+    """Source tests, the names to give their translations and the symbol mapping, for the prompt."""
+    source_name = get_language_config(source_language).name
+    fence = source_language.value
+    parts = []
+    for mapping in mappings:
+        calls = ", ".join(
+            f"{s} -> {t.name} ({t.signature or t.kind}, {t.location})" for s, t in mapping.symbols.items()
+        )
+        parts.append(
+            f"- {mapping.test.id} -> {mapping.target_test}\n"
+            f"  calls: {calls}\n"
+            f"  ```{fence}\n" + "\n".join("  " + l if l else l for l in mapping.test.code.splitlines()) + "\n  ```"
+        )
+    return f"{source_name} tests ({len(mappings)}):\n\n" + "\n\n".join(parts)
+
+
+def translate_tests(
+    mappings: List[TestMapping],
+    source_language: Language,
+    target_language: Language,
+    target_dir: Path,
+    llm_client
+) -> Tuple[Dict[Path, str], List[str]]:
+    """
+    Translate mapped tests with the LLM, one target test file per source test file.
+    
+    Args:
+        mappings: Mappings (unmappable ones are skipped)
+        source_language: Language of the original
+        target_language: Language of the port
+        target_dir: Root of the port
+        llm_client: LLMClient (or anything with `generate(prompt).content`)
+        
+    Returns:
+        (translated file -> source, problems)
+    """
+    from .prompt_templates import PromptTemplates
+    
+    files: Dict[Path, str] = {}
+    problems: List[str] = []
+    
+    by_file: Dict[str, List[TestMapping]] = {}
+    for mapping in mappings:
+        if mapping.mappable:
+            by_file.setdefault(mapping.test.file, []).append(mapping)
+    
+    for source_file, group in by_file.items():
+        # The port's code under test is the template's {code}
+        target_files = sorted({t.file for m in group for t in m.symbols.values()})
+        code = "\n\n".join(
+            (target_dir / f).read_text(encoding='utf-8', errors='replace') for f in target_files
+        )
+        prompt = PromptTemplates.get_prompt(
+            target_language, code,
+            ported_tests=describe_ported_tests(group, source_language, target_language)
+        )
+        response = llm_client.generate(prompt).content
+        blocks = re.findall(r'```[\w+-]*\n(.*?)```', response, re.DOTALL)
+        translated = max(blocks, key=len) if blocks else response.strip()
+        if not translated.strip():
+            problems.append(f"{source_file}: the LLM returned no code")
+            continue
+        
+        path = _target_test_file(source_file, target_files, target_language, target_dir)
+        files[path] = _finish_translation(translated, group, target_language, path)
+    
+    return files, problems
+
+
+def check_parity(report: PortReport, source_dir: Path) -> None:
+    """
+    Run the source tests on the original and the translations on the port.
+    
+    The translated files must already be written. Fills in each mapped
+    test's `source_outcome` and `target_outcome`; failures to run are
+    added to `report.problems`.
+    """
+    source_files = sorted({m.test.file for m in report.mapped})
+    for source_file in source_files:
+        path = source_dir / source_file
+        outcomes, problem = run_outcomes(path, path.read_text(encoding='utf-8'), _framework(report.source_language), report.source_language)
+        if problem:
+            report.problems.append(f"{source_file}: original tests didn't run: {problem}")
+        for mapping in report.mapped:
+            if mapping.test.file == source_file:
+                mapping.source_outcome = _outcome_for(outcomes, mapping.test.name, report.source_language)
+    
+    for path, code in report.files.items():
+        outcomes, problem = run_outcomes(path, code, _framework(report.target_language), report.target_language)
+        if problem:
+            report.problems.append(f"{path.name}: translated tests didn't run: {problem}")
+        for mapping in report.mapped:
+            if f"Ported from: {mapping.test.id}" in code:
+                mapping.target_outcome = _outcome_for(outcomes, mapping.target_test, report.target_language) or (
+                    "error" if problem else "missing"
+                )
+    
+    for mapping in report.mapped:
+        if mapping.source_outcome is not None and mapping.target_outcome is None:
+            mapping.target_outcome = "missing"
+
+
+def port_tests(
+    source_dir: str,
+    target_dir: str,
+    source_language: Language,
+    target_language: Language,
+    llm_client=None,
+    write: bool = True,
+    run: bool = True
+) -> PortReport:
+    """
+    Translate a test suite for a code port and check the port's parity.
+    
+    Args:
+        source_dir: Root of the original implementation (code and tests)
+        target_dir: Root of the port
+        source_language: Language of the original
+        target_language: Language of the port
+        llm_client: Client for the translation; without one only the
+            mapping is reported
+        write: Write the translated test files into the port
+        run: Run both sides and compare outcomes (needs `write`)
+        
+    Returns:
+        PortReport
+        
+    Raises:
+        ValueError: If the language pair isn't supported
+    """
+    if (source_language, target_language) not in PORTS:
+        supported = ", ".join(f"{s.value} -> {t.value}" for s, t in sorted(PORTS, key=lambda p: p[0].value))
+        raise ValueError(f"Unsupported port {source_language.value} -> {target_language.value} (supported: {supported})")
+    
+    source_root, target_root = Path(source_dir), Path(target_dir)
+    source_index = build_symbol_index(source_root, source_language)
+    target_index = build_symbol_index(target_root, target_language)
+    tests = find_source_tests(source_root, source_language, source_index)
+    
+    report = PortReport(source_language=source_language, target_language=target_language)
+    report.mappings = map_tests(tests, source_index, target_index, target_language)
+    if llm_client is None or not report.mapped:
+        return report
+    
+    report.files, report.problems = translate_tests(
+        report.mappings, source_language, target_language, target_root, llm_client
+    )
+    if write:
+        for path, code in report.files.items():
+            path.parent.mkdir(parents=True, exist_ok=True)
+            path.write_text(code, encoding='utf-8')
+        if run:
+            check_parity(report, source_root)
+    return report
+
+
+def save_report(report: PortReport, path: Path) -> None:
+    """Write the source test -> target test mapping and outcomes as JSON."""
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(json.dumps(report.to_dict(), indent=2) + "\n", encoding='utf-8')
+
+
+def _source_files(directory: Path, language: Language, tests: bool) -> List[Path]:
+    """Implementation (or test) files of a language, skipping vendored and generated trees."""
+    if language == Language.PYTHON:
+        candidates = directory.rglob("*.py")
+        is_test = lambda p: p.name.startswith("test_") or p.name.endswith("_test.py") or p.name == "conftest.py"
+    else:
+        candidates = directory.rglob("*.go")
+        is_test = lambda p: p.name.endswith("_test.go")
+    
+    files = []
+    for path in sorted(candidates):
+        parts = path.relative_to(directory).parts
+        if any(p in SKIPPED_DIRECTORIES or p.startswith(".") for p in parts):
+            continue
+        if is_test(path) == tests and path.name != "conftest.py":
+            files.append(path)
+    return files
+
+
+def _python_signature(node: ast.AST) -> str:
+    returns = f" -> {ast.unparse(node.returns)}" if node.returns is not None else ""
+    return f"def {node.name}({ast.unparse(node.args)}){returns}"
+
+
+def _python_test_functions(tree: ast.Module) -> List[Tuple[ast.AST, Optional[str]]]:
+    """(test function, class name or None) in source order."""
+    found = []
+    for node in tree.body:
+        if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)) and node.name.startswith("test"):
+            found.append((node, None))
+        elif isinstance(node, ast.ClassDef) and node.name.startswith("Test"):
+            for item in node.body:
+                if isinstance(item, (ast.FunctionDef, ast.AsyncFunctionDef)) and item.name.startswith("test"):
+                    found.append((item, node.name))
+    return found
+
+
+def _python_calls(node: ast.AST, index: Dict[str, Symbol]) -> List[str]:
+    """Source symbols a Python test calls, in first-use order."""
+    calls: List[str] = []
+    classes = set()
+    
+    for child in ast.walk(node):
+        if not isinstance(child, ast.Call):
+            continue
+        function = child.func
+        if isinstance(function, ast.Name) and function.id in index:
+            name = function.id
+            if index[name].kind == "type":
+                classes.add(name)
+        elif isinstance(function, ast.Attribute):
+            methods = [s for s in index if s.endswith(f".{function.attr}") and index[s].kind == "method"]
+            preferred = [s for s in methods if s.split(".")[0] in classes]
+            if len(preferred) == 1 or len(methods) == 1:
+                name = (preferred or methods)[0]
+            elif function.attr in index and index[function.attr].kind == "function":
+                name = function.attr   # module.function(...)
+            else:
+                continue
+        else:
+            continue
+        if name not in calls:
+            calls.append(name)
+    
+    # ast.walk is breadth-first: report calls in source order
+    return sorted(calls, key=lambda n: _first_use(node, n))
+
+
+def _first_use(node: ast.AST, name: str) -> Tuple[int, int]:
+    attribute = name.split(".")[-1]
+    positions = [
+        (c.lineno, c.col_offset) for c in ast.walk(node)
+        if isinstance(c, ast.Call) and (
+            (isinstance(c.func, ast.Name) and c.func.id == attribute)
+            or (isinstance(c.func, ast.Attribute) and c.func.attr == attribute)
+        )
+    ]
+    return min(positions, default=(0, 0))
+
+
+def _go_calls(body: str, index: Dict[str, Symbol]) -> List[str]:
+    """Source symbols a Go test calls: package functions, `&T{}`/`T{}` literals and methods."""
+    calls: List[str] = []
+    types = set()
+    
+    for match in re.finditer(r'(?<![\w.])(\w+)\s*(?:\(|\{)|\.(\w+)\s*\(', body):
+        name = None
+        if match.group(1) in index:
+            name = match.group(1)
+            if index[name].kind == "type":
+                types.add(name)
+            elif index[name].kind == "function" and match.group(0).rstrip().endswith("{"):
+                continue
+        elif match.group(2):
+            methods = [s for s in index if s.endswith(f".{match.group(2)}") and index[s].kind == "method"]
+            preferred = [s for s in methods if s.split(".")[0] in types]
+            if len(preferred) == 1 or len(methods) == 1:
+                name = (preferred or methods)[0]
+        if name and name not in calls:
+            calls.append(name)
+    return calls
+
+
+def _symbol_key(name: str) -> str:
+    """Language-neutral key: `Cart.add_item` and `Cart.AddItem` -> "cart.additem"."""
+    return name.replace("_", "").lower()
+
+
+def _camel(name: str) -> str:
+    return "".join(part[:1].upper() + part[1:] for part in re.split(r'_+', name) if part)
+
+
+def _snake(name: str) -> str:
+    return re.sub(r'(?<=[a-z0-9])(?=[A-Z])|(?<=[A-Z])(?=[A-Z][a-z])', "_", name).lower()
+
+
+def _target_test_name(test: SourceTest, language: Language, used: set) -> str:
+    """Deterministic name of a test's translation: TestCart_AddItem, test_cart_add_item."""
+    parts = test.name.split("::")
+    if language == Language.GO:
+        method = _camel(re.sub(r'^test_?', "", parts[-1]))
+        owner = re.sub(r'^Test', "", parts[0]) if len(parts) > 1 else ""
+        name = "Test" + (f"{owner}_{method}" if owner else method)
+    else:
+        words = [re.sub(r'^test_?', "", _snake(p)) for p in parts]
+        name = "test_" + "_".join(w for w in words if w)
+    
+    unique, number = name, 2
+    while unique in used:
+        unique, number = f"{name}{number}", number + 1
+    used.add(unique)
+    return unique
+
+
+def _target_test_file(source_file: str, target_files: List[str], language: Language, target_dir: Path) -> Path:
+    """cart/tests/test_cart.py -> <package of the mapped code>/cart_port_test.go (or tests/test_cart_port.py)."""
+    stem = re.sub(r'^test_|_test$', "", Path(source_file).stem)
+    if language == Language.GO:
+        return target_dir / Path(target_files[0]).parent / f"{stem}_port_test.go"
+    return target_dir / "tests" / f"test_{stem}_port.py"
+
+
+def _finish_translation(code: str, mappings: List[TestMapping], language: Language, path: Path) -> str:
+    """Ensure the package clause and a `Ported from:` comment above each translated test."""
+    comment = get_language_config(language).comment_style
+    
+    if language == Language.GO and not GO_PACKAGE.search(code):
+        siblings = [p for p in path.parent.glob("*.go") if not p.name.endswith("_test.go")]
+        package = next((m.group(1) for p in siblings for m in [GO_PACKAGE.search(p.read_text(encoding='utf-8'))] if m), "main")
+        code = f"package {package}\n\n{code}"
+    
+    for mapping in mappings:
+        trace = f"{comment} Ported from: {mapping.test.id}"
+        if trace in code:
+            continue
+        if language == Language.GO:
+            definition = re.compile(rf'^func\s+{re.escape(mapping.target_test)}\s*\(', re.MULTILINE)
+        else:
+            definition = re.compile(rf'^([ \t]*)(?:async\s+)?def\s+{re.escape(mapping.target_test)}\s*\(', re.MULTILINE)
+        match = definition.search(code)
+        if match is None:
+            continue
+        indent = match.group(1) if match.groups() else ""
+        code = code[:match.start()] + f"{indent}{trace}\n" + code[match.start():]
+    
+    code = code.rstrip("\n") + "\n"
+    return _gofmt(code) if language == Language.GO else code
+
+
+def _framework(language: Language) -> str:
+    return get_language_config(language).default_framework
+
+
+def _outcome_for(outcomes: Dict[str, str], name: str, language: Language) -> Optional[str]:
+    """A test's outcome; pytest keys are `<module>.<Class>.<test>`."""
+    if language == Language.GO:
+        return outcomes.get(name)
+    suffix = "." + name.replace("::", ".")
+    return next((status for key, status in outcomes.items() if key == name or key.endswith(suffix)), None)
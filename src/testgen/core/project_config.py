@@ -0,0 +1,208 @@
+"""
+Project Configuration for TestGen AI.
+
+Declarative, per-project settings that live next to the code under test
+(as opposed to `testgen.config.Config`, which holds user/environment
+settings such as API keys).
+
+Settings are read from `testgen.toml`, or from the `[tool.testgen]` table of
+`pyproject.toml`, found by searching upward from the project directory.
+
+Example `testgen.toml`:
+
+    [[standins.sqlite]]
+    name = "app_db"
+    fixtures = ["tests/fixtures/schema.sql", "tests/fixtures/users.json"]
+    env = "DATABASE_URL"
+    
+    [[standins.http]]
+    name = "payments"
+    recordings = "tests/recordings/payments.json"
+    env = "PAYMENTS_URL"
+    
+    [[standins.object_storage]]
+    name = "uploads"
+    seed = "tests/fixtures/uploads"
+    buckets = ["avatars"]
+
+    [[standins.openapi]]
+    name = "billing"
+    spec = "api/billing.yaml"
+    
+    [standins.env]
+    APP_ENV = "test"
+    
+    [[contracts]]
+    consumer = "web"
+    consumer_dir = "services/web"
+    provider = "users"
+    provider_dir = "services/users"
+    base_url = "usersURL|USERS_URL"
+    
+    [[alloc_budgets]]
+    package = "internal/codec"
+    function = "Encode"
+    max_allocs = 0
+    
+    [retention]
+    keep_last = 20
+    keep_failing_days = 14
+    max_size_mb = 500
+"""
+
+from pathlib import Path
+from typing import Any, Dict, List, Optional
+
+from pydantic import BaseModel, Field
+
+try:
+    import tomllib  # Python 3.11+
+except ImportError:  # pragma: no cover - Python 3.10
+    try:
+        import tomli as tomllib
+    except ImportError:
+        tomllib = None
+
+
+PROJECT_CONFIG_FILE = "testgen.toml"
+
+
+class SQLiteStandIn(BaseModel):
+    """SQLite database file seeded from fixtures (.sql, .json or .csv)."""
+    name: str
+    fixtures: List[str] = []
+    env: str = "DATABASE_URL"  # Receives sqlite:///<path>
+
+
+class HTTPStandIn(BaseModel):
+    """Fake HTTP service replaying recorded responses."""
+    name: str
+    recordings: str  # JSON file or directory of JSON files
+    env: Optional[str] = None  # Receives http://127.0.0.1:<port> (default: <NAME>_URL)
+    port: int = 0  # 0 = pick a free port
+
+
+class ObjectStorageStandIn(BaseModel):
+    """Temp directory acting as object storage (one subdirectory per bucket)."""
+    name: str
+    seed: Optional[str] = None  # Directory copied into the store
+    buckets: List[str] = []
+    env: Optional[str] = None  # Receives the directory path (default: <NAME>_DIR)
+
+
+class OpenAPIStandIn(BaseModel):
+    """Mock server generated from an upstream service's OpenAPI spec."""
+    name: str
+    spec: str  # OpenAPI spec file (JSON or YAML)
+    env: Optional[str] = None  # Receives http://127.0.0.1:<port> (default: <NAME>_URL)
+    port: int = 0  # 0 = pick a free port
+
+
+class StandInsConfig(BaseModel):
+    """Local dependency stand-ins for integration tests."""
+    sqlite: List[SQLiteStandIn] = []
+    http: List[HTTPStandIn] = []
+    object_storage: List[ObjectStorageStandIn] = []
+    openapi: List[OpenAPIStandIn] = []
+    env: Dict[str, str] = {}  # Extra variables injected as-is
+    
+    @property
+    def enabled(self) -> bool:
+        return bool(self.sqlite or self.http or self.object_storage or self.openapi or self.env)
+
+
+class ContractConfig(BaseModel):
+    """Consumer-driven contract between two services in the repository."""
+    consumer: str
+    consumer_dir: str
+    provider: str
+    provider_dir: str
+    base_url: Optional[str] = None  # Regex matching the consumer's URL expressions for this provider
+    contracts_dir: str = "contracts"  # Where the Pact-style JSON files are kept
+
+
+class AllocBudgetConfig(BaseModel):
+    """Allocation budget of a Go function (checked by `testgen allocs`)."""
+    package: str  # Package directory, relative to the project root
+    function: str  # Name, or Type.Method
+    max_allocs: float = 0  # Allocations per call
+
+
+class RetentionConfig(BaseModel):
+    """How long run artifacts are kept (enforced by `testgen gc`)."""
+    keep_last: int = 20  # The most recent runs always keep their artifacts
+    keep_failing_days: int = 14  # Failing runs keep theirs this long
+    max_size_mb: float = 500  # Cap on all artifacts, oldest removed first (0 = no cap)
+
+
+class ProjectConfig(BaseModel):
+    """Per-project TestGen settings."""
+    root: Path = Field(default_factory=Path.cwd)
+    standins: StandInsConfig = Field(default_factory=StandInsConfig)
+    contracts: List[ContractConfig] = []
+    alloc_budgets: List[AllocBudgetConfig] = []
+    retention: RetentionConfig = Field(default_factory=RetentionConfig)
+    
+    def resolve(self, path: str) -> Path:
+        """Resolve a config-relative path against the project root."""
+        candidate = Path(path)
+        return candidate if candidate.is_absolute() else self.root / candidate
+
+
+def find_project_config(start_dir: str = ".") -> Optional[Path]:
+    """
+    Find the project config file by searching upward.
+    
+    Args:
+        start_dir: Directory (or file) to start from
+        
+    Returns:
+        Path to testgen.toml or pyproject.toml with [tool.testgen], or None
+    """
+    current = Path(start_dir).resolve()
+    if current.is_file():
+        current = current.parent
+    
+    for directory in [current, *current.parents]:
+        candidate = directory / PROJECT_CONFIG_FILE
+        if candidate.exists():
+            return candidate
+        
+        pyproject = directory / "pyproject.toml"
+        if pyproject.exists() and "[tool.testgen" in pyproject.read_text(encoding='utf-8', errors='ignore'):
+            return pyproject
+    
+    return None
+
+
+def load_project_config(start_dir: str = ".") -> ProjectConfig:
+    """
+    Load project config, falling back to defaults when none exists.
+    
+    Args:
+        start_dir: Directory (or file) inside the project
+        
+    Returns:
+        ProjectConfig with `root` set to the directory holding the config file
+    """
+    config_file = find_project_config(start_dir)
+    if config_file is None:
+        start = Path(start_dir).resolve()
+        return ProjectConfig(root=start.parent if start.is_file() else start)
+    
+    data = _read_toml(config_file)
+    if config_file.name == "pyproject.toml":
+        data = data.get("tool", {}).get("testgen", {})
+    
+    return ProjectConfig(root=config_file.parent, **data)
+
+
+def _read_toml(path: Path) -> Dict[str, Any]:
+    """Read a TOML file."""
+    if tomllib is None:
+        raise RuntimeError(
+            f"Cannot read {path}: TOML support requires Python 3.11+ or the 'tomli' package"
+        )
+    
+    with open(path, 'rb') as f:
+        return tomllib.load(f)
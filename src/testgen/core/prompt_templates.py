@@ -4,8 +4,15 @@ Multi-Language Prompt Templates for Test Generation.
 Provides language-specific prompts for generating tests in different languages.
 """
 
-from typing import Dict, Any
+import re
+from typing import Dict, Any, List, Optional, Tuple
 from .language_config import Language, get_language_config
+from .go_cgo import documented_thread_safe, uses_cgo
+from .python_async import PYTHON_ASYNC_USAGE
+from .security_tests import PAYLOADS, SECURITY_TEST_NAMING, normalize_language
+
+
+KOTLIN_COROUTINE_USAGE = re.compile(r'\bsuspend\s+fun\b|\bFlow<|kotlinx\.coroutines|\bDispatchers\.|\blaunch\s*\{|\basync\s*\{')
 
 
 class PromptTemplates:
@@ -35,6 +42,42 @@ Requirements:
 
 Generate ONLY the test code, no explanations."""
 
+    # Added to Python prompts when the code defines coroutines or async context managers
+    PYTHON_ASYNC = """Async code (coroutines / async context managers):
+- Test coroutines with `async def test_...` and await every call; never call a coroutine from a sync test (it never runs and the test passes vacuously)
+{runner}
+- Replace awaited dependencies with unittest.mock.AsyncMock and check them with assert_awaited_once_with() / await_count
+- Guard every test against hangs with a timeout ({timeout}) so a deadlock fails instead of blocking the suite
+- Add cancellation cases: start the coroutine as a task, cancel it, assert the cancellation propagates and resources are released
+- For async context managers use `async with`, and check cleanup (__aexit__) also runs when the body raises
+- Exhaust async generators with `async for` or an async comprehension"""
+
+    # Per-plugin instructions for PYTHON_ASYNC
+    PYTHON_ASYNC_RUNNERS = {
+        "asyncio": (
+            "- Use pytest-asyncio: mark async tests with @pytest.mark.asyncio and async fixtures with "
+            "@pytest_asyncio.fixture; let the plugin's event loop fixture run them (no asyncio.run or "
+            "new_event_loop in tests)",
+            "asyncio.timeout() or asyncio.wait_for(..., timeout=...)"
+        ),
+        "asyncio-auto": (
+            "- Use pytest-asyncio in auto mode: plain `async def` tests and fixtures need no marker; "
+            "don't create event loops in tests",
+            "asyncio.timeout() or asyncio.wait_for(..., timeout=...)"
+        ),
+        "anyio": (
+            "- Use anyio's pytest plugin: mark async tests with @pytest.mark.anyio and provide an "
+            "`anyio_backend` fixture (return \"asyncio\"); use anyio primitives (create_task_group, "
+            "CancelScope) rather than asyncio ones",
+            "anyio.fail_after()"
+        ),
+        "trio": (
+            "- Use pytest-trio: mark async tests with @pytest.mark.trio; use nurseries for concurrency "
+            "and nursery.cancel_scope.cancel() for cancellation; trio.testing.MockClock for time",
+            "trio.fail_after()"
+        ),
+    }
+    
     # JavaScript (Jest) template
     JAVASCRIPT_JEST = """You are an expert JavaScript developer writing comprehensive Jest tests.
 
@@ -76,6 +119,20 @@ Requirements:
 
 Generate ONLY the test code, no explanations."""
 
+    # Added to TypeScript prompts when the compiler's type information is available
+    TYPESCRIPT_TYPES = """Type information from the TypeScript compiler:
+
+```typescript
+{types}
+```
+
+Types:
+- Build test inputs and expected values that satisfy these types exactly; no `any`, `as` casts or @ts-ignore to silence the compiler
+- Cover every variant of each discriminated union (one case per tag value)
+- Test optional parameters and fields both provided and omitted
+- Import exported interfaces and types with `import type` where the tests need them
+- The tests must compile with `tsc --noEmit`"""
+
     # Java (JUnit) template
     JAVA_JUNIT = """You are an expert Java developer writing comprehensive JUnit tests.
 
@@ -217,6 +274,26 @@ Requirements:
 - Use XCTAssert methods (XCTAssertEqual, XCTAssertTrue, etc.)
 - Use setUp() and tearDown() if needed
 
+Generate ONLY the test code, no explanations."""
+
+    # Swift (swift-testing) template
+    SWIFT_TESTING = """You are an expert Swift developer writing comprehensive swift-testing tests.
+
+Generate swift-testing unit tests for the following Swift code:
+
+```swift
+{code}
+```
+
+Requirements:
+- Use the swift-testing framework (import Testing), not XCTest
+- Mark test functions with @Test("descriptive name") and group them in a @Suite struct
+- Use #expect(...) for checks and #require(...) to unwrap optionals or stop on failure
+- Check thrown errors with #expect(throws: ErrorType.self) {{ ... }}
+- Use parameterized tests (@Test(arguments: [...])) instead of loops over inputs
+- Write async tests as `async` functions and await the code directly
+- Cover edge cases and error conditions
+
 Generate ONLY the test code, no explanations."""
 
     # Kotlin (JUnit) template
@@ -239,6 +316,34 @@ Requirements:
 
 Generate ONLY the test code, no explanations."""
 
+    # Kotlin (Kotest) template
+    KOTLIN_KOTEST = """You are an expert Kotlin developer writing comprehensive Kotest tests.
+
+Generate Kotest unit tests for the following Kotlin code:
+
+```kotlin
+{code}
+```
+
+Requirements:
+- Use Kotest framework
+- Extend a spec style (FunSpec or StringSpec)
+- Write descriptive test names
+- Cover edge cases and error conditions
+- Use Kotest matchers (shouldBe, shouldThrow<T>, etc.)
+- Leverage Kotlin features
+
+Generate ONLY the test code, no explanations."""
+
+    # Added to Kotlin prompts when the code uses coroutines
+    KOTLIN_COROUTINES = """Coroutines (the code uses suspend functions or coroutines):
+- Call suspend functions inside `runTest { }` from kotlinx-coroutines-test; never use runBlocking or Thread.sleep
+- `delay()` is skipped on virtual time: use advanceTimeBy(), advanceUntilIdle() and currentTime instead of real waits
+- Inject dispatchers: pass StandardTestDispatcher(testScheduler) wherever the code takes a CoroutineDispatcher; don't let tests hit Dispatchers.IO or Dispatchers.Default
+- For code on Dispatchers.Main, call Dispatchers.setMain(testDispatcher) before each test and Dispatchers.resetMain() after
+- Collect Flows inside runTest (toList(), first(), take(n)) so collection can't hang
+- Kotest: enable `coroutineTestScope = true` and use `testCoroutineScheduler` for virtual time"""
+
     # C++ (Google Test) template
     CPP_GTEST = """You are an expert C++ developer writing comprehensive Google Test tests.
 
@@ -293,6 +398,142 @@ Requirements:
 
 Generate ONLY the test code, no explanations."""
 
+    # Shell (bats-core) template
+    SHELL_BATS = """You are an expert shell developer writing comprehensive bats-core tests.
+
+Generate bats tests for the following shell script:
+
+```bash
+{code}
+```
+
+Requirements:
+- Use bats-core: `@test "description" {{ ... }}` blocks, `run` to capture `$status`, `$output` and `${{lines[@]}}`
+- Work in a temp directory: in setup() create one under "$BATS_TEST_TMPDIR" and cd into it, so tests never touch the real filesystem
+- Stub external commands (curl, kubectl, docker, aws, ssh, git, ...) as executable scripts in "$BATS_TEST_TMPDIR/bin" and prepend it to PATH; have stubs record their arguments to a file and assert on them
+- Never let tests reach the network or real infrastructure
+- Source the script to test individual functions only if sourcing doesn't run it (main guarded by `[[ "${{BASH_SOURCE[0]}}" == "$0" ]]`); otherwise run it as `run bash "$SCRIPT" args`
+- Locate the script relative to the test file: "$BATS_TEST_DIRNAME/..."
+- Set required environment variables per test and test the missing-variable and bad-argument paths (non-zero exit codes, messages on stderr)
+- Check exit codes explicitly: `[ "$status" -eq 0 ]`; use `run -N` or `run !` where the bats version supports it
+- Use plain `[ ... ]` assertions unless the project already loads bats-assert
+
+Generate ONLY the test code, no explanations."""
+
+    # Security test mode: added to any template
+    SECURITY_TESTS = """Security tests for the functions that handle external input:
+
+{targets}
+
+Security tests:
+- Write one test per function and risk, with the payloads above as table/parametrized cases
+- Assert the expected safe behavior for every payload, not just "no crash": check the resolved path, the SQL text and bound arguments, the response headers/status, the escaped output
+- Exercise the real input path (HTTP request through the handler, arguments to the builder); stub only databases and the filesystem outside a temp directory
+- If the code is vulnerable, the test must fail: never weaken an assertion to match unsafe behavior
+- Name security tests {naming}, where <risk> is one of {categories}; the comment is how their failures are reported as security findings"""
+
+    # Go fault injection: added to the Go template
+    GO_FAULT_INJECTION = """Fault-injection tests for the I/O and dependency seams:
+
+{targets}
+
+Fault injection:
+- Make every seam fail in turn: readers that fail immediately and midway (`iotest.ErrReader`, `io.MultiReader` of partial input and `iotest.ErrReader`, `iotest.OneByteReader`, `iotest.TimeoutReader`), writers that fail or short-write, contexts cancelled mid-operation, dependencies failing on the 1st, 2nd and last call
+- Use the helpers in testgen_fault_test.go (same package) instead of redefining them: `errInjected`, `testgenFaultWriter{limit, short}`, `testgenCloser`, `testgenCancelReader`, `testgenCancelWriter` and the `fault<Interface>` wrappers listed above
+- Assert propagation with `errors.Is(err, errInjected)` (or `context.Canceled`, `io.ErrShortWrite`), never just `err != nil`
+- Assert cleanup after each failure: closers passed in are closed, files and temp directories opened by the code are closed/removed, goroutines have returned, partial output is not committed
+- If the code swallows or replaces the error, the test must fail: don't weaken the assertion"""
+
+    # Go hot paths: added to the Go template
+    GO_BENCHMARKS = """Benchmarks for the hot paths:
+
+{targets}
+
+Benchmarks:
+- Write one benchmark per function above with the name given, calling `b.ReportAllocs()`
+- Run sub-benchmarks across input sizes: `for _, n := range testgenBenchSizes { b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) { ... }) }` (`testgenBenchSizes` and the `testgenBench*` input helpers are in testgen_bench_test.go, same package)
+- Build receivers and inputs first, then call `b.ResetTimer()`; inside the loop only reset what the call consumes (`reader.Reset(data)`), never rebuild inputs
+- Assign every result to a package-level sink variable (`var testgenSinkGet string`) so the compiler can't eliminate the call; don't assert inside the loop
+- Check one call before `b.ResetTimer()` and `b.Fatal` if it fails: benchmark the work, not the error path"""
+
+    # Added to Go prompts when the code imports "C"
+    GO_CGO = """Cgo (the code calls into C through `import "C"`):
+- Test through the Go API only: test files can't use cgo, so never `import "C"` or pass C types in the tests
+- {parallel}
+- Release what the code allocates in C (call its Close/Free functions, with `t.Cleanup`) so tests don't leak between runs
+- Tests of this package are skipped where no C compiler is installed: don't guard them with build tags or runtime checks"""
+    
+    GO_CGO_SERIAL = "The code isn't documented thread-safe: never call it from parallel tests or subtests (no `t.Parallel()`) or from goroutines the test starts"
+    GO_CGO_PARALLEL = "The code is documented thread-safe, so parallel subtests (`t.Parallel()`) may call it"
+    
+    # Go allocation budgets: added to the Go template
+    GO_ALLOC_BUDGETS = """Allocation budgets to test:
+
+{targets}
+
+Allocation budgets:
+- Write one test per function above with the name given, checking the budget with `testing.AllocsPerRun(100, func() { ... })` and `t.Errorf` when it's exceeded
+- Run it across input sizes with `t.Run(fmt.Sprintf("n=%d", n), ...)`: a zero-allocation path must stay at zero for large inputs too
+- Build receivers and inputs outside the measured function; inside it only reset what the call consumes (`reader.Reset(data)`)
+- Assign results to package-level sink variables (`var testgenAllocSinkGet string`) so the call isn't optimized away, and don't convert values to interfaces (fmt, any) inside the measured function
+- Never raise the budget in the test to make it pass"""
+
+    # Code port: the original implementation's tests, to translate to the port
+    PORTED_TESTS = """The code above is a port. Translate these tests of the original implementation to it:
+
+{tests}
+
+Translation:
+- Give each translated test exactly the name after its `->` and put `{comment} Ported from: <original test id>` on the line above it
+- Call the port's counterparts listed under `calls` with the same inputs; keep every assertion and expected value, translating idioms (exceptions to returned errors, None to nil, dicts to maps) without changing what is checked
+- Don't add, merge or drop tests: one translated test per original test
+- If the port behaves differently from the original, the translated test must fail: never adapt an expected value to the port"""
+
+    # Finishing a framework migration: the lines the deterministic rewrite couldn't translate
+    MIGRATION = """You are an expert {language} developer migrating tests from {source} to {target}.
+
+This test file has already been partly migrated:
+
+```{fence}
+{code}
+```
+
+Still to migrate:
+{leftovers}
+
+Requirements:
+{requirements}
+- Keep every test's name and what it checks: each test must pass or fail exactly as before
+- Leave lines that are already migrated as they are
+
+Return ONLY the complete migrated file, no explanations."""
+
+    # Fixing generated Go tests that failed their trial run (`go test -overlay`)
+    GO_TRIAL_REPAIR = """You are an expert Go developer fixing generated tests.
+
+These tests were generated for the Go code below but didn't build or pass, so they weren't written:
+
+```go
+{tests}
+```
+
+`go test` reported:
+{problems}
+
+Code under test:
+
+```go
+{code}
+```
+
+Requirements:
+- Fix every problem above and keep the tests that already pass as they are
+- Fix the test, not the expectation: correct wrong API use, setup and expected values that misread the code, but never delete an assertion or a test to make it pass
+- Only use identifiers the code under test and the standard library declare
+- Keep the package clause and imports consistent with the code (same package, no unused imports)
+
+Return ONLY the complete fixed test file, no explanations."""
+
     # Template mapping
     TEMPLATES = {
         (Language.PYTHON, "pytest"): PYTHON_PYTEST,
@@ -305,10 +546,13 @@ Generate ONLY the test code, no explanations."""
         (Language.RUST, "cargo"): RUST_CARGO,
         (Language.PHP, "phpunit"): PHP_PHPUNIT,
         (Language.SWIFT, "xctest"): SWIFT_XCTEST,
+        (Language.SWIFT, "swift-testing"): SWIFT_TESTING,
         (Language.KOTLIN, "junit"): KOTLIN_JUNIT,
+        (Language.KOTLIN, "kotest"): KOTLIN_KOTEST,
         (Language.CPP, "gtest"): CPP_GTEST,
         (Language.HTML, "playwright"): HTML_PLAYWRIGHT,
         (Language.CSS, "stylelint"): CSS_VISUAL,
+        (Language.SHELL, "bats"): SHELL_BATS,
     }
     
     @classmethod
@@ -316,7 +560,14 @@ Generate ONLY the test code, no explanations."""
         cls,
         language: Language,
         code: str,
-        framework: str = None
+        framework: str = None,
+        type_declarations: Optional[str] = None,
+        async_framework: Optional[str] = None,
+        security_targets: Optional[str] = None,
+        fault_targets: Optional[str] = None,
+        benchmark_targets: Optional[str] = None,
+        alloc_budgets: Optional[str] = None,
+        ported_tests: Optional[str] = None
     ) -> str:
         """
         Get prompt template for language and framework.
@@ -325,7 +576,21 @@ Generate ONLY the test code, no explanations."""
             language: Programming language
             code: Code to generate tests for
             framework: Test framework (optional, uses default)
-            
+            type_declarations: Compiler-extracted declarations for TypeScript
+                (see `typescript_types.TypeInfo.to_declarations`)
+            async_framework: Async test plugin for Python ("asyncio",
+                "asyncio-auto", "anyio" or "trio"; see `python_async`)
+            security_targets: Input-handling functions to write security
+                tests for (see `security_tests.describe_targets`)
+            fault_targets: Go seams and dependency wrappers to write
+                fault-injection tests for (see `fault_injection.describe_fault_seams`)
+            benchmark_targets: Go hot paths to write benchmarks for
+                (see `go_benchmarks.describe_hot_paths`)
+            alloc_budgets: Go functions with allocation budgets to test
+                (see `alloc_budgets.describe_alloc_budgets`)
+            ported_tests: Tests of the original implementation to translate
+                to this code (see `port.describe_ported_tests`)
+                
         Returns:
             Formatted prompt string
         """
@@ -343,7 +608,94 @@ Generate ONLY the test code, no explanations."""
         )
         
         # Format with code
-        return template.format(code=code)
+        prompt = template.format(code=code)
+        
+        # Coroutine guidance so tests of suspend functions don't block or flake
+        if language == Language.KOTLIN and KOTLIN_COROUTINE_USAGE.search(code):
+            prompt = prompt.replace(
+                "\n\nGenerate ONLY the test code",
+                "\n\n" + cls.KOTLIN_COROUTINES + "\n\nGenerate ONLY the test code",
+                1
+            )
+        
+        # Cgo code: no C in tests, and no parallel calls into C unless documented thread-safe
+        if language == Language.GO and uses_cgo(code):
+            parallel = cls.GO_CGO_PARALLEL if documented_thread_safe(code) else cls.GO_CGO_SERIAL
+            prompt = prompt.replace(
+                "\n\nGenerate ONLY the test code",
+                "\n\n" + cls.GO_CGO.replace("{parallel}", parallel) + "\n\nGenerate ONLY the test code",
+                1
+            )
+        
+        # Async guidance so coroutines are awaited under the project's plugin
+        if language == Language.PYTHON and PYTHON_ASYNC_USAGE.search(code):
+            runner, timeout = cls.PYTHON_ASYNC_RUNNERS.get(
+                async_framework or "asyncio", cls.PYTHON_ASYNC_RUNNERS["asyncio"]
+            )
+            prompt = prompt.replace(
+                "\n\nGenerate ONLY the test code",
+                "\n\n" + cls.PYTHON_ASYNC.format(runner=runner, timeout=timeout) + "\n\nGenerate ONLY the test code",
+                1
+            )
+        
+        # Precise types so inputs type-check and every union variant is covered
+        if language == Language.TYPESCRIPT and type_declarations:
+            prompt = prompt.replace(
+                "\n\nGenerate ONLY the test code",
+                # Not str.format: declarations are full of braces
+                "\n\n" + cls.TYPESCRIPT_TYPES.replace("{types}", type_declarations) + "\n\nGenerate ONLY the test code",
+                1
+            )
+        
+        # Hostile inputs against input-handling code, with findings kept apart from failures
+        if security_targets:
+            naming = SECURITY_TEST_NAMING.get(
+                normalize_language(language.value),
+                "with \"security\" in their names, each right below a `testgen:security <risk>` comment"
+            )
+            security = cls.SECURITY_TESTS.replace("{naming}", naming).replace(
+                "{categories}", ", ".join(PAYLOADS)
+            ).replace("{targets}", security_targets)
+            prompt = prompt.replace(
+                "\n\nGenerate ONLY the test code",
+                "\n\n" + security + "\n\nGenerate ONLY the test code",
+                1
+            )
+        
+        # Failing readers, writers, contexts and dependencies for Go error paths
+        if language == Language.GO and fault_targets:
+            prompt = prompt.replace(
+                "\n\nGenerate ONLY the test code",
+                "\n\n" + cls.GO_FAULT_INJECTION.replace("{targets}", fault_targets) + "\n\nGenerate ONLY the test code",
+                1
+            )
+        
+        # Benchmarks with sinks and sized inputs for Go hot paths
+        if language == Language.GO and benchmark_targets:
+            prompt = prompt.replace(
+                "\n\nGenerate ONLY the test code",
+                "\n\n" + cls.GO_BENCHMARKS.replace("{targets}", benchmark_targets) + "\n\nGenerate ONLY the test code",
+                1
+            )
+        
+        # AllocsPerRun checks for functions with an allocation budget
+        if language == Language.GO and alloc_budgets:
+            prompt = prompt.replace(
+                "\n\nGenerate ONLY the test code",
+                "\n\n" + cls.GO_ALLOC_BUDGETS.replace("{targets}", alloc_budgets) + "\n\nGenerate ONLY the test code",
+                1
+            )
+        
+        # Tests carried over from the implementation this code was ported from
+        if ported_tests:
+            ported = cls.PORTED_TESTS.replace("{comment}", config.comment_style).replace("{tests}", ported_tests)
+            prompt = prompt.replace(
+                "\n\nGenerate ONLY the test code",
+                "\n\n" + ported + "\n\nGenerate ONLY the test code",
+                1
+            )
+        
+        return prompt
     
     @classmethod
     def get_prompt_for_function(
@@ -398,3 +750,69 @@ Generate ONLY the test code, no explanations."""
         additional_context = f"\n\nFocus on testing all methods of the `{class_name}` class."
         
         return base_prompt + additional_context
+    
+    @classmethod
+    def get_trial_repair_prompt(cls, code: str, tests: str, problems: List[str]) -> str:
+        """
+        Get prompt for fixing generated Go tests after a failed trial run.
+        
+        Args:
+            code: Code under test
+            tests: The generated test file
+            problems: Build errors and failures (see `go_trial.TrialRun.problems`)
+            
+        Returns:
+            Formatted prompt
+        """
+        # One pass: braces and placeholders inside the Go sources are left as they are
+        return cls.GO_TRIAL_REPAIR.format(
+            problems="\n".join(f"- {p}" for p in problems),
+            tests=tests.rstrip("\n"),
+            code=code.rstrip("\n"),
+        )
+    
+    @classmethod
+    def get_migration_prompt(
+        cls,
+        language: Language,
+        code: str,
+        source: str,
+        target: str,
+        leftovers: str,
+        template: Optional[Tuple[Language, str]] = None,
+        notes: Optional[List[str]] = None
+    ) -> str:
+        """
+        Get prompt for finishing the migration of a test file to another framework.
+        
+        Args:
+            language: Programming language
+            code: Partly migrated test file
+            source: Framework migrated from
+            target: Framework migrated to
+            leftovers: Lines still to migrate (see `migration.find_leftovers`)
+            template: (language, framework) of the target's generation
+                template, whose framework and import requirements are reused
+            notes: Extra guidance about the target framework
+            
+        Returns:
+            Formatted prompt
+        """
+        requirements = []
+        if template in cls.TEMPLATES:
+            requirements = re.findall(
+                r'^- (?:Use .*(?:framework|package)|Include .*imports.*)$', cls.TEMPLATES[template], re.MULTILINE
+            )
+        requirements += [f"- {note}" for note in notes or []]
+        
+        # Not str.format: test code is full of braces
+        return (
+            cls.MIGRATION
+            .replace("{language}", get_language_config(language).name)
+            .replace("{source}", source)
+            .replace("{target}", target)
+            .replace("{fence}", language.value)
+            .replace("{leftovers}", leftovers)
+            .replace("{requirements}", "\n".join(requirements))
+            .replace("{code}", code)
+        )
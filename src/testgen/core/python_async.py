@@ -0,0 +1,293 @@
+"""
+Async Python Test Support.
+
+Helps generate tests for coroutine functions and async context managers:
+- detects whether a project runs async tests with pytest-asyncio, anyio
+  or trio (pytest-trio)
+- finds the async code under test and the awaited dependencies that need
+  `AsyncMock`
+- finds generated tests that call coroutines without awaiting them, or
+  async tests the plugin won't run: both pass vacuously
+"""
+
+import ast
+import re
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import List, Optional, Set
+
+
+ASYNCIO = "asyncio"  # pytest-asyncio
+ANYIO = "anyio"      # anyio's pytest plugin
+TRIO = "trio"        # pytest-trio
+
+# Markers that make each plugin run an `async def test_*`
+ASYNC_MARKERS = {
+    ASYNCIO: "asyncio",
+    ANYIO: "anyio",
+    TRIO: "trio",
+}
+
+# Calls that run a coroutine to completion from sync code
+RUNNER_CALLS = ("asyncio.run", "anyio.run", "trio.run")
+RUNNER_METHODS = (".run_until_complete", "portal.call")  # loop.run_until_complete(), portal.call()
+
+# Files declaring dependencies or pytest settings, checked in this order
+PROJECT_FILES = (
+    "pyproject.toml", "setup.cfg", "pytest.ini", "tox.ini", "setup.py",
+    "requirements-dev.txt", "requirements-test.txt", "requirements.txt",
+    "Pipfile", "conftest.py", "tests/conftest.py",
+)
+
+PYTHON_ASYNC_USAGE = re.compile(r'\basync\s+(?:def|with|for)\b|\b__aenter__\b|@\w*\.?asynccontextmanager\b')
+
+
+@dataclass
+class AsyncTestConfig:
+    """How a project runs async tests."""
+    
+    framework: str = ASYNCIO
+    detected: bool = False     # False: defaulted to pytest-asyncio
+    auto_mode: bool = False    # asyncio_mode = auto / trio_mode = true: no markers needed
+    
+    @property
+    def marker(self) -> str:
+        return f"@pytest.mark.{ASYNC_MARKERS[self.framework]}"
+    
+    @property
+    def prompt_framework(self) -> str:
+        """Key for `PromptTemplates.PYTHON_ASYNC_RUNNERS`."""
+        if self.framework == ASYNCIO and self.auto_mode:
+            return "asyncio-auto"
+        return self.framework
+
+
+@dataclass
+class AsyncTargets:
+    """Async code in a module under test."""
+    
+    coroutines: List[str] = field(default_factory=list)        # async def (incl. Class.method)
+    async_generators: List[str] = field(default_factory=list)  # async def with yield
+    context_managers: List[str] = field(default_factory=list)  # __aenter__/__aexit__ or @asynccontextmanager
+    awaited_dependencies: List[str] = field(default_factory=list)  # e.g. self.client.fetch
+    
+    @property
+    def names(self) -> Set[str]:
+        """Bare names callable from tests (function and method names)."""
+        return {n.split(".")[-1] for n in self.coroutines + self.async_generators}
+    
+    @property
+    def is_empty(self) -> bool:
+        return not (self.coroutines or self.async_generators or self.context_managers)
+
+
+def uses_async(code: str) -> bool:
+    """Whether the code defines coroutines or async context managers."""
+    return bool(PYTHON_ASYNC_USAGE.search(code))
+
+
+def detect_async_framework(project_dir: str) -> AsyncTestConfig:
+    """
+    Detect the async test plugin a project uses.
+    
+    Looks at dependency and pytest configuration files and conftest.py.
+    anyio's plugin ships with anyio itself, so it only counts when tests
+    are marked or configured for it (or nothing else is installed).
+    
+    Args:
+        project_dir: Project root
+        
+    Returns:
+        AsyncTestConfig (pytest-asyncio when nothing is found)
+    """
+    root = Path(project_dir)
+    content = ""
+    for name in PROJECT_FILES:
+        path = root / name
+        if path.is_file():
+            try:
+                content += path.read_text(encoding='utf-8', errors='ignore') + "\n"
+            except OSError:
+                continue
+    
+    lowered = content.lower()
+    auto_asyncio = re.search(r'asyncio_mode\s*=\s*["\']?auto', lowered) is not None
+    auto_trio = re.search(r'trio_mode\s*=\s*["\']?true', lowered) is not None
+    
+    if "pytest-trio" in lowered or "pytest_trio" in lowered or auto_trio:
+        return AsyncTestConfig(framework=TRIO, detected=True, auto_mode=auto_trio)
+    if "pytest-asyncio" in lowered or "pytest_asyncio" in lowered or auto_asyncio:
+        return AsyncTestConfig(framework=ASYNCIO, detected=True, auto_mode=auto_asyncio)
+    if "anyio_backend" in lowered or "pytest.mark.anyio" in lowered or re.search(r'\banyio\b', lowered):
+        return AsyncTestConfig(framework=ANYIO, detected=True)
+    if re.search(r'\btrio\b', lowered):
+        return AsyncTestConfig(framework=TRIO, detected=True)
+    
+    return AsyncTestConfig()
+
+
+def find_async_targets(code: str) -> AsyncTargets:
+    """
+    Find coroutine functions, async generators, async context managers
+    and awaited dependencies in a module.
+    
+    Args:
+        code: Python source
+        
+    Returns:
+        AsyncTargets (empty on syntax errors)
+    """
+    targets = AsyncTargets()
+    try:
+        tree = ast.parse(code)
+    except SyntaxError:
+        return targets
+    
+    def visit_function(node: ast.AST, prefix: str = "") -> None:
+        if not isinstance(node, ast.AsyncFunctionDef):
+            return
+        name = prefix + node.name
+        decorators = {ast.unparse(d).split(".")[-1] for d in node.decorator_list}
+        if "asynccontextmanager" in decorators:
+            targets.context_managers.append(name)
+        elif any(isinstance(n, (ast.Yield, ast.YieldFrom)) for n in ast.walk(node)):
+            targets.async_generators.append(name)
+        elif node.name not in ("__aenter__", "__aexit__"):
+            targets.coroutines.append(name)
+        
+        for awaited in ast.walk(node):
+            if isinstance(awaited, ast.Await) and isinstance(awaited.value, ast.Call):
+                dependency = ast.unparse(awaited.value.func)
+                # Collaborators (self.repo.save, client.get), not sleeps or locals
+                if "." in dependency and not dependency.startswith(("asyncio.", "anyio.", "trio.")):
+                    if dependency not in targets.awaited_dependencies:
+                        targets.awaited_dependencies.append(dependency)
+    
+    for node in tree.body:
+        if isinstance(node, ast.ClassDef):
+            methods = {item.name for item in node.body if isinstance(item, ast.AsyncFunctionDef)}
+            if {"__aenter__", "__aexit__"} <= methods:
+                targets.context_managers.append(node.name)
+            for item in node.body:
+                visit_function(item, prefix=f"{node.name}.")
+        else:
+            visit_function(node)
+    
+    return targets
+
+
+def find_vacuous_async_tests(
+    test_code: str,
+    coroutine_names: Set[str],
+    config: Optional[AsyncTestConfig] = None
+) -> List[str]:
+    """
+    Find tests that would pass without running the async code.
+    
+    - sync tests calling a coroutine function without awaiting it (the
+      coroutine object is created and dropped, nothing runs)
+    - `async def` tests without the plugin's marker (the plugin doesn't
+      run them unless it is in auto mode)
+      
+    Args:
+        test_code: Generated test code
+        coroutine_names: Names of coroutine functions/methods under test
+        config: The project's async test configuration
+        
+    Returns:
+        Issues, one per offending test
+    """
+    try:
+        tree = ast.parse(test_code)
+    except SyntaxError:
+        return []
+    
+    config = config or AsyncTestConfig()
+    issues = []
+    module_marked = _module_marked(tree, config)
+    
+    for node, class_marked in _test_functions(tree, config):
+        if isinstance(node, ast.AsyncFunctionDef):
+            marked = module_marked or class_marked or _has_marker(node.decorator_list, config)
+            if not (marked or config.auto_mode):
+                issues.append(
+                    f"{node.name}: async test without {config.marker}; it won't be run"
+                )
+            continue
+        
+        for call in _unawaited_calls(node):
+            name = _call_name(call.func).split(".")[-1]
+            if name in coroutine_names:
+                issues.append(
+                    f"{node.name}: calls coroutine {name}() without awaiting it; the test passes without running it"
+                )
+                break
+    
+    return issues
+
+
+def _test_functions(tree: ast.Module, config: AsyncTestConfig):
+    """(test function, marked by its class) pairs."""
+    for node in tree.body:
+        if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)) and node.name.startswith("test"):
+            yield node, False
+        elif isinstance(node, ast.ClassDef) and node.name.startswith("Test"):
+            class_marked = _has_marker(node.decorator_list, config) or _module_marked(node, config)
+            for item in node.body:
+                if isinstance(item, (ast.FunctionDef, ast.AsyncFunctionDef)) and item.name.startswith("test"):
+                    yield item, class_marked
+
+
+def _has_marker(decorators: List[ast.expr], config: AsyncTestConfig) -> bool:
+    marker = ASYNC_MARKERS[config.framework]
+    for decorator in decorators:
+        text = ast.unparse(decorator)
+        if text.startswith(f"pytest.mark.{marker}") or text == marker:
+            return True
+    return False
+
+
+def _module_marked(scope: ast.AST, config: AsyncTestConfig) -> bool:
+    """`pytestmark = pytest.mark.<plugin>` (or a list including it) in the scope."""
+    marker = f"pytest.mark.{ASYNC_MARKERS[config.framework]}"
+    for node in getattr(scope, "body", []):
+        if isinstance(node, ast.Assign) and any(
+            isinstance(t, ast.Name) and t.id == "pytestmark" for t in node.targets
+        ):
+            if marker in ast.unparse(node.value):
+                return True
+    return False
+
+
+def _unawaited_calls(function: ast.AST) -> List[ast.Call]:
+    """Calls in a sync test that aren't awaited or handed to an event loop runner."""
+    handled: Set[int] = set()
+    run_names: Set[str] = set()  # coro = fetch(); asyncio.run(coro)
+    for node in ast.walk(function):
+        if isinstance(node, ast.Await):
+            handled.add(id(node.value))
+        elif isinstance(node, ast.Call) and _is_runner(_call_name(node.func)):
+            handled.update(id(arg) for arg in node.args)
+            run_names.update(arg.id for arg in node.args if isinstance(arg, ast.Name))
+    
+    for node in ast.walk(function):
+        if isinstance(node, ast.Assign) and isinstance(node.value, ast.Call):
+            if any(isinstance(t, ast.Name) and t.id in run_names for t in node.targets):
+                handled.add(id(node.value))
+    
+    return [
+        node for node in ast.walk(function)
+        if isinstance(node, ast.Call) and id(node) not in handled
+    ]
+
+
+def _is_runner(name: str) -> bool:
+    """asyncio.run(...), loop.run_until_complete(...), portal.call(...)."""
+    return name in RUNNER_CALLS or name.endswith(RUNNER_METHODS)
+
+
+def _call_name(func: ast.expr) -> str:
+    try:
+        return ast.unparse(func)
+    except Exception:
+        return ""
@@ -156,8 +156,12 @@ class PythonTestRunner(BaseTestRunner):
         """
         cmd = ["python", "-m", "pytest"]
         
-        # Add test directory
-        cmd.append(test_dir)
+        # Add test directory, or explicit node ids (e.g. retries of failed tests)
+        node_ids = kwargs.pop("node_ids", None)
+        if node_ids:
+            cmd.extend(node_ids)
+        else:
+            cmd.append(test_dir)
         
         # Add pattern
         if pattern and pattern != "test_*.py":
@@ -178,6 +182,9 @@ class PythonTestRunner(BaseTestRunner):
         else:
             cmd.append("-q")
         
+        # Add pre-built arguments (e.g. from TestExecutionConfig)
+        cmd.extend(kwargs.pop("extra_args", None) or [])
+        
         # Add custom arguments
         for key, value in kwargs.items():
             if key in ['json_report', 'json_report_file']:
@@ -242,6 +249,11 @@ class PythonTestRunner(BaseTestRunner):
                 timeout=300
             )
             
+            if self.artifacts is not None:
+                self.artifacts.add_output("pytest", result.stdout, result.stderr)
+                if json_report and json_report_file:
+                    self.artifacts.add_file("json", Path(json_report_file))
+            
             # Try to parse JSON report if available
             if json_report and json_report_file:
                 json_path = Path(json_report_file)
@@ -257,7 +269,7 @@ class PythonTestRunner(BaseTestRunner):
             
             # Parse text output
             return self._parse_text_output(result)
-            
+        
         except subprocess.TimeoutExpired:
             return TestResults(
                 total=0,
@@ -283,6 +295,33 @@ class PythonTestRunner(BaseTestRunner):
                 )]
             )
     
+    def rerun_tests(
+        self,
+        test_dir: str,
+        tests: List[TestResult],
+        pattern: Optional[str] = None,
+        **kwargs
+    ) -> TestResults:
+        """
+        Re-run only the given tests by passing their pytest node ids.
+        
+        A JSON report is always requested so every retried test gets its
+        own outcome.
+        """
+        import tempfile
+        
+        node_ids = [t.name for t in tests if "::" in t.name]
+        if not node_ids:
+            return super().rerun_tests(test_dir, tests, pattern, **kwargs)
+        
+        with tempfile.TemporaryDirectory() as tmp_dir:
+            report_file = str(Path(tmp_dir) / "rerun.json")
+            # Ordered runs already pass their own report settings: these win
+            return self.run_tests(
+                test_dir,
+                **{**kwargs, "json_report": True, "json_report_file": report_file, "node_ids": node_ids}
+            )
+    
     def _parse_json_report(self, json_data: Dict[str, Any]) -> TestResults:
         """Parse pytest JSON report."""
         results = TestResults(
@@ -0,0 +1,275 @@
+"""
+Resource Usage Accounting for Test Subprocesses.
+
+Records CPU time, peak RSS and bytes written for runner subprocesses from
+the child's rusage:
+- Go: every package's test binary runs through an exec wrapper
+  (`go test -exec`), which reaps the binary with `wait4` and writes its
+  usage to a directory, keyed by package
+- Other runners: usage of the whole runner subprocess, from the
+  difference in `RUSAGE_CHILDREN` around the call
+
+The exec wrapper is also the module's entry point:
+
+    go test -exec "python -m testgen.core.resource_usage --out /tmp/usage" ./...
+
+Only available where `resource`/`wait4` exist (Linux, macOS).
+"""
+
+import json
+import os
+import subprocess
+import sys
+import time
+import uuid
+from dataclasses import dataclass, asdict
+from pathlib import Path
+from typing import Dict, List, Optional
+
+try:
+    import resource
+except ImportError:  # Windows
+    resource = None
+
+
+SUPPORTED = resource is not None and hasattr(os, "wait4")
+
+
+@dataclass
+class ResourceUsage:
+    """
+    Resources used by one test process (or several, when merged).
+    
+    Attributes:
+        cpu_user: User CPU time in seconds
+        cpu_system: System CPU time in seconds
+        peak_rss: Peak resident set size in bytes
+        bytes_written: Bytes written to storage (None if the platform can't tell)
+        fs_writes: File system output operations (rusage `ru_oublock`)
+        wall_time: Wall-clock time in seconds
+        processes: Number of processes measured
+    """
+    
+    cpu_user: float = 0.0
+    cpu_system: float = 0.0
+    peak_rss: int = 0
+    bytes_written: Optional[int] = None
+    fs_writes: int = 0
+    wall_time: float = 0.0
+    processes: int = 1
+    
+    @property
+    def cpu_time(self) -> float:
+        return self.cpu_user + self.cpu_system
+    
+    @property
+    def peak_rss_mb(self) -> float:
+        return self.peak_rss / (1024 * 1024)
+    
+    def merge(self, other: "ResourceUsage") -> "ResourceUsage":
+        """Combine usage of two runs: times and writes add up, peak RSS is the max."""
+        bytes_written = None
+        if self.bytes_written is not None or other.bytes_written is not None:
+            bytes_written = (self.bytes_written or 0) + (other.bytes_written or 0)
+        
+        return ResourceUsage(
+            cpu_user=self.cpu_user + other.cpu_user,
+            cpu_system=self.cpu_system + other.cpu_system,
+            peak_rss=max(self.peak_rss, other.peak_rss),
+            bytes_written=bytes_written,
+            fs_writes=self.fs_writes + other.fs_writes,
+            wall_time=self.wall_time + other.wall_time,
+            processes=self.processes + other.processes
+        )
+    
+    def to_dict(self) -> Dict:
+        return asdict(self)
+    
+    @classmethod
+    def from_rusage(cls, usage, wall_time: float = 0.0, bytes_written: Optional[int] = None) -> "ResourceUsage":
+        """Build from a `resource.struct_rusage`."""
+        return cls(
+            cpu_user=usage.ru_utime,
+            cpu_system=usage.ru_stime,
+            peak_rss=maxrss_bytes(usage.ru_maxrss),
+            bytes_written=bytes_written,
+            fs_writes=usage.ru_oublock,
+            wall_time=wall_time
+        )
+
+
+def maxrss_bytes(maxrss: int) -> int:
+    """`ru_maxrss` is in kilobytes on Linux and in bytes on macOS."""
+    return maxrss if sys.platform == "darwin" else maxrss * 1024
+
+
+def merge_usage(target: Dict[str, ResourceUsage], source: Dict[str, ResourceUsage]) -> None:
+    """Merge per-suite usage from `source` into `target`."""
+    for key, usage in source.items():
+        target[key] = target[key].merge(usage) if key in target else usage
+
+
+class ChildUsageMeter:
+    """
+    Measure the runner subprocesses started inside a `with` block.
+    
+    Uses the growth of `RUSAGE_CHILDREN`, so it covers every child reaped
+    in the block, including the children's own waited-for children. Peak
+    RSS is only known when the block's children exceed every earlier child
+    (otherwise it's the earlier peak, an upper bound).
+    
+    Example:
+        >>> with ChildUsageMeter() as meter:
+        ...     subprocess.run(cmd)
+        >>> meter.usage.cpu_time
+    """
+    
+    def __init__(self):
+        self.usage: Optional[ResourceUsage] = None
+    
+    def __enter__(self) -> "ChildUsageMeter":
+        self._start = resource.getrusage(resource.RUSAGE_CHILDREN) if SUPPORTED else None
+        self._started_at = time.perf_counter()
+        return self
+    
+    def __exit__(self, exc_type, exc, tb) -> None:
+        if self._start is None:
+            return
+        
+        end = resource.getrusage(resource.RUSAGE_CHILDREN)
+        self.usage = ResourceUsage(
+            cpu_user=end.ru_utime - self._start.ru_utime,
+            cpu_system=end.ru_stime - self._start.ru_stime,
+            peak_rss=maxrss_bytes(end.ru_maxrss),
+            fs_writes=end.ru_oublock - self._start.ru_oublock,
+            wall_time=time.perf_counter() - self._started_at
+        )
+
+
+# ===== Go exec wrapper =====
+
+def exec_wrapper_command(out_dir: str) -> str:
+    """
+    Value for `go test -exec` that records every test binary's usage.
+    
+    Args:
+        out_dir: Directory the wrapper writes one JSON file per binary to
+        
+    Returns:
+        Command string (quoted for go's argument splitting)
+    """
+    parts = [sys.executable, "-m", "testgen.core.resource_usage", "--out", out_dir]
+    return " ".join(f"'{p}'" if " " in p else p for p in parts)
+
+
+def exec_wrapper_env() -> Dict[str, str]:
+    """Environment for `go test` so the wrapper can import testgen from a source checkout."""
+    env = os.environ.copy()
+    package_root = str(Path(__file__).resolve().parents[2])
+    env["PYTHONPATH"] = os.pathsep.join(filter(None, [package_root, env.get("PYTHONPATH")]))
+    return env
+
+
+def collect_usage(out_dir: str) -> Dict[str, ResourceUsage]:
+    """
+    Read the usage files written by the exec wrapper.
+    
+    Args:
+        out_dir: Directory passed to `exec_wrapper_command`
+        
+    Returns:
+        Usage keyed by package import path (merged if a package ran twice)
+    """
+    usage: Dict[str, ResourceUsage] = {}
+    
+    for usage_file in sorted(Path(out_dir).glob("*.json")):
+        try:
+            data = json.loads(usage_file.read_text(encoding='utf-8'))
+        except (json.JSONDecodeError, OSError):
+            continue
+        package = data.pop("package", None) or data.pop("dir", "unknown")
+        data.pop("dir", None)
+        merge_usage(usage, {package: ResourceUsage(**data)})
+    
+    return usage
+
+
+def go_package_path(directory: Path) -> Optional[str]:
+    """Import path of the package in `directory`, from the enclosing go.mod."""
+    for parent in [directory, *directory.parents]:
+        go_mod = parent / "go.mod"
+        if not go_mod.exists():
+            continue
+        for line in go_mod.read_text(encoding='utf-8', errors='ignore').splitlines():
+            if line.startswith("module "):
+                module = line.split()[1].strip('"')
+                relative = directory.relative_to(parent).as_posix()
+                return module if relative == "." else f"{module}/{relative}"
+        return None
+    return None
+
+
+def _read_write_bytes(pid: int) -> Optional[int]:
+    """Storage bytes written by a process (Linux /proc, before it is reaped)."""
+    try:
+        for line in Path(f"/proc/{pid}/io").read_text().splitlines():
+            if line.startswith("write_bytes:"):
+                return int(line.split()[1])
+    except (OSError, ValueError):
+        pass
+    return None
+
+
+def run_measured(cmd: List[str]) -> tuple:
+    """
+    Run a command and reap it with `wait4` to get its own rusage.
+    
+    Returns:
+        (exit code, ResourceUsage)
+    """
+    started_at = time.perf_counter()
+    process = subprocess.Popen(cmd)
+    
+    # Wait without reaping so /proc/<pid>/io is still readable
+    bytes_written = None
+    if hasattr(os, "waitid"):
+        while True:
+            try:
+                os.waitid(os.P_PID, process.pid, os.WEXITED | os.WNOWAIT)
+                break
+            except InterruptedError:
+                continue
+        bytes_written = _read_write_bytes(process.pid)
+    
+    _, status, usage = os.wait4(process.pid, 0)
+    process.returncode = os.waitstatus_to_exitcode(status)
+    
+    return process.returncode, ResourceUsage.from_rusage(
+        usage,
+        wall_time=time.perf_counter() - started_at,
+        bytes_written=bytes_written
+    )
+
+
+def main(argv: Optional[List[str]] = None) -> None:
+    """Exec wrapper: `--out DIR <test binary> [args...]`."""
+    argv = list(sys.argv[1:] if argv is None else argv)
+    if len(argv) < 3 or argv[0] != "--out":
+        print("usage: python -m testgen.core.resource_usage --out DIR BINARY [ARGS...]", file=sys.stderr)
+        sys.exit(2)
+    
+    out_dir, cmd = Path(argv[1]), argv[2:]
+    exit_code, usage = run_measured(cmd)
+    
+    # go test runs the binary in the package directory
+    directory = Path.cwd()
+    record = dict(usage.to_dict(), dir=str(directory), package=go_package_path(directory))
+    out_dir.mkdir(parents=True, exist_ok=True)
+    (out_dir / f"{uuid.uuid4().hex}.json").write_text(json.dumps(record), encoding='utf-8')
+    
+    # Signals become 128 + signal, like a shell
+    sys.exit(exit_code if exit_code >= 0 else 128 - exit_code)
+
+
+if __name__ == "__main__":
+    main()
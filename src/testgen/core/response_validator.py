@@ -7,9 +7,11 @@ valid Python code and extracting code from markdown blocks.
 
 import re
 import ast
-from typing import Optional, List, Tuple
+from typing import Optional, List, Set, Tuple
 from pydantic import BaseModel, Field, field_validator
 
+from .python_async import AsyncTestConfig, find_vacuous_async_tests
+
 
 class TestCodeValidation(BaseModel):
     """
@@ -44,14 +46,14 @@ class TestCodeValidation(BaseModel):
             count = 0
             
             for node in ast.walk(tree):
-                if isinstance(node, ast.FunctionDef):
+                if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
                     if node.name.startswith('test_'):
                         count += 1
                 elif isinstance(node, ast.ClassDef):
                     if node.name.startswith('Test'):
                         # Count methods in test class
                         for item in node.body:
-                            if isinstance(item, ast.FunctionDef) and item.name.startswith('test_'):
+                            if isinstance(item, (ast.FunctionDef, ast.AsyncFunctionDef)) and item.name.startswith('test_'):
                                 count += 1
             
             return count
@@ -96,7 +98,9 @@ class ResponseValidator:
         self,
         require_tests: bool = True,
         require_imports: bool = False,
-        allow_warnings: bool = True
+        allow_warnings: bool = True,
+        coroutine_names: Optional[Set[str]] = None,
+        async_config: Optional[AsyncTestConfig] = None
     ):
         """
         Initialize response validator.
@@ -105,10 +109,15 @@ class ResponseValidator:
             require_tests: Whether to require test functions
             require_imports: Whether to require import statements
             allow_warnings: Whether to allow warnings (non-critical issues)
+            coroutine_names: Coroutine functions of the code under test; tests
+                calling them without awaiting are rejected (see `python_async`)
+            async_config: The project's async test plugin (pytest-asyncio by default)
         """
         self.require_tests = require_tests
         self.require_imports = require_imports
         self.allow_warnings = allow_warnings
+        self.coroutine_names = coroutine_names or set()
+        self.async_config = async_config
     
     def validate_response(self, response: str) -> TestCodeValidation:
         """
@@ -158,10 +167,16 @@ class ResponseValidator:
         code_warnings = self._check_code_quality(code)
         warnings.extend(code_warnings)
         
+        # Step 7: Async tests that would pass without running anything
+        vacuous = find_vacuous_async_tests(code, self.coroutine_names, self.async_config) if syntax_valid else []
+        issues.extend(f"Vacuous async test: {v}" for v in vacuous)
+        
         # Determine overall validity
         is_valid = syntax_valid and (not issues or (self.allow_warnings and not any("Invalid" in i for i in issues)))
         if self.require_tests:
             is_valid = is_valid and has_tests
+        if vacuous:
+            is_valid = False
         
         return TestCodeValidation(
             is_valid=is_valid,
@@ -268,7 +283,7 @@ class ResponseValidator:
             
             for node in ast.walk(tree):
                 # Check for test functions
-                if isinstance(node, ast.FunctionDef):
+                if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
                     if node.name.startswith('test_'):
                         return True
                 
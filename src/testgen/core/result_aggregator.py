@@ -10,9 +10,10 @@ from pathlib import Path
 from datetime import datetime, timedelta
 
 from .result_models import (
-    TestResult, TestSuite, ExecutionSummary,
+    TestResult, TestSuite, ExecutionSummary, SecurityFinding,
     TestStatus, Language, TestFramework
 )
+from .security_tests import security_category
 
 
 class ResultAggregator:
@@ -22,16 +23,24 @@ class ResultAggregator:
     Works for ALL 14 languages with consistent behavior.
     """
     
-    def __init__(self, language: Language = Language.UNKNOWN, framework: TestFramework = TestFramework.UNKNOWN):
+    def __init__(
+        self,
+        language: Language = Language.UNKNOWN,
+        framework: TestFramework = TestFramework.UNKNOWN,
+        security_tests: Optional[Dict[str, str]] = None
+    ):
         """
         Initialize aggregator.
         
         Args:
             language: Programming language
             framework: Test framework
+            security_tests: Marked security tests, name -> category
+                (see `security_tests.find_security_tests`)
         """
         self.language = language
         self.framework = framework
+        self.security_tests = security_tests or {}
         self.suites: List[TestSuite] = []
     
     def add_suite(self, suite: TestSuite) -> None:
@@ -60,6 +69,10 @@ class ResultAggregator:
         passed = sum(s.passed_tests for s in self.suites)
         failed = sum(s.failed_tests for s in self.suites)
         skipped = sum(s.skipped_tests for s in self.suites)
+        passed_on_retry = sum(s.passed_on_retry_tests for s in self.suites)
+        
+        # Failed security tests are reported as findings too (they still count as failed)
+        findings = self.get_security_findings()
         
         # Calculate total duration
         total_duration = self.calculate_total_duration()
@@ -70,10 +83,12 @@ class ResultAggregator:
             passed=passed,
             failed=failed,
             skipped=skipped,
+            passed_on_retry=passed_on_retry,
             duration=total_duration,
             language=self.language,
             framework=self.framework,
-            suites=self.suites
+            suites=self.suites,
+            security_findings=findings
         )
         
         return summary
@@ -138,7 +153,7 @@ class ResultAggregator:
     
     def get_failed_tests(self) -> List[Tuple[TestResult, str]]:
         """
-        Get all failed tests across all suites.
+        Get all failed tests across all suites (security tests included).
         
         Returns:
             List of tuples (failed_test, suite_name)
@@ -152,6 +167,31 @@ class ResultAggregator:
         
         return failed_tests
     
+    def get_security_findings(self) -> List[SecurityFinding]:
+        """
+        Get failed security tests as findings.
+        
+        Only tests marked with a `testgen:security` comment count (see
+        `security_tests`); without marked tests there are no findings.
+        
+        Returns:
+            List of SecurityFinding
+        """
+        findings = []
+        
+        for suite in self.suites:
+            for test in suite.tests:
+                category = security_category(test.name, self.security_tests) if test.failed else None
+                if category:
+                    findings.append(SecurityFinding(
+                        test_name=test.name,
+                        suite=suite.name,
+                        category=category,
+                        message=test.error.message if test.error else None
+                    ))
+        
+        return findings
+    
     def get_slowest_suites(self, n: int = 5) -> List[TestSuite]:
         """
         Identify slowest test suites.
@@ -275,6 +315,14 @@ class ResultAggregator:
                 report.append(f"  {i}. {test.name} ({suite}): {test.duration:.3f}s")
             report.append("")
         
+        if summary.security_findings:
+            report.append("Security Findings:")
+            for finding in summary.security_findings:
+                report.append(f"  [{finding.category}] {finding.test_name} ({finding.suite})")
+                if finding.message:
+                    report.append(f"      {finding.message.splitlines()[0]}")
+            report.append("")
+        
         report.append("=" * 70)
         
         return "\n".join(report)
@@ -300,6 +348,7 @@ class ResultAggregator:
                 {"name": test.name, "suite": suite, "error": test.error.message if test.error else None}
                 for test, suite in self.get_failed_tests()
             ],
+            "security_findings": [finding.to_dict() for finding in self.get_security_findings()],
             "suites": [
                 {
                     "name": suite.name,
@@ -320,8 +369,14 @@ class MultiLanguageAggregator:
     Useful for polyglot projects with tests in multiple languages.
     """
     
-    def __init__(self):
-        """Initialize multi-language aggregator."""
+    def __init__(self, security_tests: Optional[Dict[str, str]] = None):
+        """
+        Initialize multi-language aggregator.
+        
+        Args:
+            security_tests: Marked security tests, name -> category
+        """
+        self.security_tests = security_tests or {}
         self.aggregators: Dict[Language, ResultAggregator] = {}
     
     def add_suite(self, suite: TestSuite) -> None:
@@ -329,7 +384,8 @@ class MultiLanguageAggregator:
         if suite.language not in self.aggregators:
             self.aggregators[suite.language] = ResultAggregator(
                 language=suite.language,
-                framework=suite.framework
+                framework=suite.framework,
+                security_tests=self.security_tests
             )
         
         self.aggregators[suite.language].add_suite(suite)
@@ -365,16 +421,20 @@ class MultiLanguageAggregator:
         passed = sum(s.passed_tests for s in all_suites)
         failed = sum(s.failed_tests for s in all_suites)
         skipped = sum(s.skipped_tests for s in all_suites)
+        passed_on_retry = sum(s.passed_on_retry_tests for s in all_suites)
         duration = sum(s.total_duration for s in all_suites)
+        findings = [f for aggregator in self.aggregators.values() for f in aggregator.get_security_findings()]
         
         return ExecutionSummary(
             total=total,
             passed=passed,
             failed=failed,
             skipped=skipped,
+            passed_on_retry=passed_on_retry,
             duration=duration,
             suites=all_suites,
-            language=Language.UNKNOWN  # Multiple languages
+            language=Language.UNKNOWN,  # Multiple languages
+            security_findings=findings
         )
     
     def get_multi_language_report(self) -> str:
@@ -402,6 +462,8 @@ class MultiLanguageAggregator:
         report.append(f"  Total Tests: {overall.total}")
         report.append(f"  Passed: {overall.passed}")
         report.append(f"  Failed: {overall.failed}")
+        if overall.security_findings:
+            report.append(f"  Security Findings: {len(overall.security_findings)}")
         report.append(f"  Duration: {overall.duration:.2f}s")
         report.append(f"  Pass Rate: {overall.pass_rate:.1f}%")
         report.append("")
@@ -458,6 +520,7 @@ def merge_execution_summaries(summaries: List[ExecutionSummary]) -> ExecutionSum
     failed = sum(s.failed for s in summaries)
     skipped = sum(s.skipped for s in summaries)
     duration = sum(s.duration for s in summaries)
+    findings = [f for s in summaries for f in s.security_findings]
     
     return ExecutionSummary(
         total=total,
@@ -465,5 +528,6 @@ def merge_execution_summaries(summaries: List[ExecutionSummary]) -> ExecutionSum
         failed=failed,
         skipped=skipped,
         duration=duration,
-        suites=all_suites
+        suites=all_suites,
+        security_findings=findings
     )
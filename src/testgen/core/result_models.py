@@ -16,6 +16,7 @@ class TestStatus(str, Enum):
     FAILED = "failed"
     SKIPPED = "skipped"
     ERROR = "error"
+    PASSED_ON_RETRY = "passed_on_retry"  # Failed first, passed when retried (flaky)
 
 
 class TestType(str, Enum):
@@ -46,6 +47,7 @@ class Language(str, Enum):
     CPP = "cpp"
     HTML = "html"
     CSS = "css"
+    SHELL = "shell"
     UNKNOWN = "unknown"
 
 
@@ -54,14 +56,17 @@ class TestFramework(str, Enum):
     PYTEST = "pytest"
     JEST = "jest"
     JUNIT = "junit"
+    KOTEST = "kotest"
     GO_TESTING = "testing"
     NUNIT = "nunit"
     RSPEC = "rspec"
     CARGO = "cargo"
     PHPUNIT = "phpunit"
     XCTEST = "xctest"
+    SWIFT_TESTING = "swift-testing"
     GTEST = "gtest"
     PLAYWRIGHT = "playwright"
+    BATS = "bats"
     UNKNOWN = "unknown"
 
 
@@ -74,6 +79,35 @@ class ErrorInfo(BaseModel):
     model_config = ConfigDict(use_enum_values=True)
 
 
+class TestAttempt(BaseModel):
+    """Outcome of one execution attempt of a test (1 = initial run)."""
+    attempt: int
+    status: TestStatus
+    duration: float = 0.0
+    message: Optional[str] = None
+    
+    model_config = ConfigDict(use_enum_values=True)
+
+
+class ResourceUsage(BaseModel):
+    """Resources used by the process(es) that ran a suite (from rusage)."""
+    cpu_user: float = 0.0  # seconds
+    cpu_system: float = 0.0  # seconds
+    peak_rss: int = 0  # bytes
+    bytes_written: Optional[int] = None  # None = not available on this platform
+    fs_writes: int = 0  # file system output operations
+    wall_time: float = 0.0  # seconds
+    processes: int = 1
+    
+    @property
+    def cpu_time(self) -> float:
+        return self.cpu_user + self.cpu_system
+    
+    @property
+    def peak_rss_mb(self) -> float:
+        return self.peak_rss / (1024 * 1024)
+
+
 class TestResult(BaseModel):
     """Individual test result."""
     name: str
@@ -82,11 +116,16 @@ class TestResult(BaseModel):
     error: Optional[ErrorInfo] = None
     language: Language = Language.UNKNOWN
     framework: TestFramework = TestFramework.UNKNOWN
+    attempts: List[TestAttempt] = []
     
     @property
     def passed(self) -> bool:
         return self.status == TestStatus.PASSED
     
+    @property
+    def passed_on_retry(self) -> bool:
+        return self.status == TestStatus.PASSED_ON_RETRY
+    
     @property
     def failed(self) -> bool:
         return self.status == TestStatus.FAILED
@@ -109,6 +148,7 @@ class TestSuite(BaseModel):
     total_duration: float = 0.0
     language: Language = Language.UNKNOWN
     framework: TestFramework = TestFramework.UNKNOWN
+    resource_usage: Optional[ResourceUsage] = None
     
     @property
     def total_tests(self) -> int:
@@ -126,6 +166,10 @@ class TestSuite(BaseModel):
     def skipped_tests(self) -> int:
         return sum(1 for t in self.tests if t.skipped)
     
+    @property
+    def passed_on_retry_tests(self) -> int:
+        return sum(1 for t in self.tests if t.passed_on_retry)
+    
     @property
     def pass_rate(self) -> float:
         if self.total_tests == 0:
@@ -135,6 +179,17 @@ class TestSuite(BaseModel):
     model_config = ConfigDict(use_enum_values=True)
 
 
+class SecurityFinding(BaseModel):
+    """A failed security test: the code didn't handle a hostile input safely."""
+    test_name: str
+    suite: str = ""
+    category: str = "security"  # see security_tests (path_traversal, sql_injection, ...)
+    message: Optional[str] = None
+    
+    def to_dict(self) -> Dict[str, Any]:
+        return self.model_dump(exclude_none=True)
+
+
 class ExecutionSummary(BaseModel):
     """Summary of test execution."""
     total: int = 0
@@ -142,14 +197,18 @@ class ExecutionSummary(BaseModel):
     failed: int = 0
     skipped: int = 0
     errors: int = 0
+    passed_on_retry: int = 0
     duration: float = 0.0
     language: Language = Language.UNKNOWN
     framework: TestFramework = TestFramework.UNKNOWN
     suites: List[TestSuite] = []
+    security_findings: List[SecurityFinding] = []  # failed marked security tests (also counted in `failed`)
+    stale_generated: Dict[str, List[str]] = {}  # Go package -> stale `go generate` output
+    stale_symbols: Dict[str, List[str]] = {}    # Go package -> what that output declares
     
     @property
     def success(self) -> bool:
-        return self.failed == 0 and self.errors == 0
+        return self.failed == 0 and self.errors == 0 and not self.security_findings
     
     @property
     def pass_rate(self) -> float:
@@ -162,9 +221,60 @@ class ExecutionSummary(BaseModel):
         return (
             f"{status} - {self.language}/{self.framework}\n"
             f"  Total: {self.total}, Passed: {self.passed}, "
-            f"Failed: {self.failed}, Skipped: {self.skipped}\n"
+            f"Failed: {self.failed}, Skipped: {self.skipped}, "
+            f"Passed on retry: {self.passed_on_retry}\n"
             f"  Duration: {self.duration:.2f}s, Pass Rate: {self.pass_rate:.1f}%"
-        )
+        ) + (f"\n  Security findings: {len(self.security_findings)}" if self.security_findings else "")
+    
+    model_config = ConfigDict(use_enum_values=True)
+
+
+class BenchmarkResult(BaseModel):
+    """
+    Timing statistics for one benchmark (same shape for every language).
+    
+    All times are in seconds per operation. Python/JS results come from the
+    warmup + measured-iterations harness; Go results from native benchmarks,
+    where each `-count` run contributes one sample.
+    Load tests store one sample per request latency, with `iterations` as
+    the request count.
+    """
+    name: str
+    file_path: str = ""
+    language: Language = Language.UNKNOWN
+    framework: TestFramework = TestFramework.UNKNOWN
+    iterations: int = 0
+    warmup_iterations: int = 0
+    samples: List[float] = []
+    mean: float = 0.0
+    p50: float = 0.0
+    p95: float = 0.0
+    p99: float = 0.0
+    stddev: float = 0.0
+    min: float = 0.0
+    max: float = 0.0
+    outliers: List[float] = []
+    bytes_per_op: Optional[float] = None
+    allocs_per_op: Optional[float] = None
+    profile_path: Optional[str] = None
+    concurrency: Optional[int] = None  # load tests: concurrent clients
+    throughput: Optional[float] = None  # load tests: requests per second
+    error_rate: Optional[float] = None  # load tests: failed / total requests
+    client_error_rate: Optional[float] = None  # load tests: 4xx / total requests
+    error: Optional[str] = None
+    timestamp: datetime = Field(default_factory=datetime.now)
+    
+    @property
+    def succeeded(self) -> bool:
+        return self.error is None and bool(self.samples)
+    
+    @property
+    def key(self) -> str:
+        """Stable identity used to compare runs."""
+        return f"{self.language}:{self.file_path}::{self.name}"
+    
+    def to_dict(self) -> Dict[str, Any]:
+        return self.model_dump(mode="json", exclude_none=True)
     
     model_config = ConfigDict(use_enum_values=True)
 
@@ -199,6 +309,7 @@ def create_execution_summary_from_suites(
     passed = sum(s.passed_tests for s in suites)
     failed = sum(s.failed_tests for s in suites)
     skipped = sum(s.skipped_tests for s in suites)
+    passed_on_retry = sum(s.passed_on_retry_tests for s in suites)
     duration = sum(s.total_duration for s in suites)
     
     return ExecutionSummary(
@@ -206,8 +317,85 @@ def create_execution_summary_from_suites(
         passed=passed,
         failed=failed,
         skipped=skipped,
+        passed_on_retry=passed_on_retry,
         duration=duration,
         language=language,
         framework=framework,
         suites=suites
     )
+
+
+def create_execution_summary_from_runner_results(results: Any) -> ExecutionSummary:
+    """
+    Convert runner output (`base_runner.TestResults`) into an ExecutionSummary.
+    
+    Tests are grouped into suites by their `suite` (package/module) or,
+    failing that, by their file path. Resource usage recorded for a suite
+    (e.g. a Go package's test binary) is attached to it.
+    """
+    def _enum(enum_cls, value, default):
+        try:
+            return enum_cls(value)
+        except ValueError:
+            return default
+    
+    language = _enum(Language, results.language, Language.UNKNOWN)
+    framework = _enum(TestFramework, results.framework, TestFramework.UNKNOWN)
+    
+    grouped: Dict[str, List[TestResult]] = {}
+    for test in results.tests:
+        suite_name = test.suite or test.file_path or results.language
+        error = None
+        if test.message or test.traceback:
+            error = ErrorInfo(message=test.message or "", traceback=test.traceback)
+        grouped.setdefault(suite_name, []).append(TestResult(
+            name=test.name,
+            status=_enum(TestStatus, test.status, TestStatus.ERROR),
+            duration=test.duration,
+            error=error,
+            language=language,
+            framework=framework,
+            attempts=[
+                TestAttempt(
+                    attempt=a.attempt,
+                    status=_enum(TestStatus, a.status, TestStatus.ERROR),
+                    duration=a.duration,
+                    message=a.message
+                )
+                for a in test.attempts
+            ]
+        ))
+    
+    usage_by_suite = getattr(results, "resource_usage", None) or {}
+    if len(grouped) == 1 and len(usage_by_suite) == 1:
+        # One runner subprocess for one suite (e.g. a single test file)
+        usage_by_suite = {next(iter(grouped)): next(iter(usage_by_suite.values()))}
+    
+    suites = [
+        TestSuite(
+            name=name,
+            file_path=name,
+            tests=tests,
+            total_duration=sum(t.duration for t in tests),
+            language=language,
+            framework=framework,
+            resource_usage=(
+                ResourceUsage(**usage_by_suite[name].to_dict()) if name in usage_by_suite else None
+            )
+        )
+        for name, tests in grouped.items()
+    ]
+    
+    summary = create_execution_summary_from_suites(suites, language, framework)
+    if not suites:
+        # Runner only reported counts
+        summary.total = results.total
+        summary.passed = results.passed
+        summary.failed = results.failed
+        summary.skipped = results.skipped
+        summary.passed_on_retry = results.passed_on_retry
+    summary.errors = results.errors
+    summary.duration = results.duration or summary.duration
+    summary.stale_generated = dict(getattr(results, "stale_generated", None) or {})
+    summary.stale_symbols = dict(getattr(results, "stale_symbols", None) or {})
+    return summary
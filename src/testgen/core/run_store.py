@@ -0,0 +1,255 @@
+"""
+Run Store for TestGen AI.
+
+Keeps the per-test outcome of every test run so later runs can use the
+history: failure-first ordering, durations for scheduling, flakiness.
+
+Layout (under the cache directory):
+    runs/index.json        Summary of every stored run, newest last
+    runs/<run_id>.json     Full results of one run
+    artifacts/<run_id>/    Runner output, reports, profiles... (see `artifacts`)
+"""
+
+import json
+import shutil
+from dataclasses import dataclass, field, asdict
+from datetime import datetime
+from pathlib import Path
+from typing import Any, Dict, List, Optional
+
+from .artifacts import RunArtifacts
+from .base_runner import TestResults, TestResult
+
+
+def test_key(test: TestResult) -> str:
+    """
+    Stable identity of a test across runs.
+    
+    Go tests are scoped by package; pytest node ids already contain the file.
+    """
+    if test.suite:
+        return f"{test.suite}::{test.name}"
+    if test.file_path and test.file_path not in test.name:
+        return f"{test.file_path}::{test.name}"
+    return test.name
+
+
+@dataclass
+class TestHistory:
+    """Outcome history of one test across stored runs."""
+    
+    key: str
+    name: str
+    suite: Optional[str] = None
+    file_path: Optional[str] = None
+    runs: int = 0
+    failures: int = 0
+    last_status: str = "unknown"
+    last_run: Optional[str] = None
+    durations: List[float] = field(default_factory=list)
+    
+    @property
+    def last_failed(self) -> bool:
+        return self.last_status in ("failed", "error")
+    
+    @property
+    def average_duration(self) -> Optional[float]:
+        if not self.durations:
+            return None
+        return sum(self.durations) / len(self.durations)
+    
+    @property
+    def failure_rate(self) -> float:
+        return self.failures / self.runs if self.runs else 0.0
+
+
+class RunStore:
+    """
+    Store of test runs.
+    
+    Example:
+        >>> store = RunStore()
+        >>> run_id = store.record_run(results, test_dir="tests")
+        >>> history = store.test_history(language="go")
+    """
+    
+    def __init__(self, cache_dir: str = ".testgen-cache", max_runs: int = 100):
+        """
+        Initialize run store.
+        
+        Args:
+            cache_dir: TestGen cache directory (runs go into `runs/`)
+            max_runs: Runs kept before the oldest are pruned
+        """
+        self.runs_dir = Path(cache_dir) / "runs"
+        self.artifacts_dir = Path(cache_dir) / "artifacts"
+        self.index_file = self.runs_dir / "index.json"
+        self.max_runs = max_runs
+    
+    def new_artifacts(self) -> RunArtifacts:
+        """Artifact directory for a run about to start (pass it to `record_run`)."""
+        run_id = datetime.now().strftime("%Y%m%d-%H%M%S-%f")
+        return RunArtifacts(run_id, self.artifacts_dir / run_id)
+    
+    def record_run(
+        self,
+        results: TestResults,
+        test_dir: Optional[str] = None,
+        metadata: Optional[Dict[str, Any]] = None,
+        artifacts: Optional[RunArtifacts] = None
+    ) -> str:
+        """
+        Store the results of a run.
+        
+        Args:
+            results: Runner results (per-test results are what history uses)
+            test_dir: Directory the run was started for
+            metadata: Extra information to keep with the run
+            artifacts: The run's artifacts (see `new_artifacts`); the run
+                is stored under their ID
+                
+        Returns:
+            ID of the stored run
+        """
+        timestamp = datetime.now()
+        run_id = artifacts.run_id if artifacts else timestamp.strftime("%Y%m%d-%H%M%S-%f")
+        
+        summary = {
+            "id": run_id,
+            "timestamp": timestamp.isoformat(),
+            "language": results.language,
+            "framework": results.framework,
+            "test_dir": str(test_dir) if test_dir else None,
+            "total": results.total,
+            "passed": results.passed,
+            "failed": results.failed,
+            "skipped": results.skipped,
+            "errors": results.errors,
+            "passed_on_retry": results.passed_on_retry,
+            "duration": results.duration,
+            "metadata": metadata or {},
+            "artifacts_size": artifacts.size if artifacts else 0,
+        }
+        
+        self.runs_dir.mkdir(parents=True, exist_ok=True)
+        run_data = dict(
+            summary,
+            tests=[asdict(t) for t in results.tests],
+            artifacts_dir=str(artifacts.directory) if artifacts and artifacts.artifacts else None,
+            artifacts=artifacts.to_list() if artifacts else [],
+        )
+        (self.runs_dir / f"{run_id}.json").write_text(json.dumps(run_data, indent=2), encoding='utf-8')
+        
+        index = self.list_runs()
+        index.append(summary)
+        for old in index[:-self.max_runs]:
+            (self.runs_dir / f"{old['id']}.json").unlink(missing_ok=True)
+            shutil.rmtree(self.artifacts_dir / old["id"], ignore_errors=True)
+        self._write_index(index[-self.max_runs:])
+        
+        return run_id
+    
+    def list_runs(self, language: Optional[str] = None) -> List[Dict[str, Any]]:
+        """
+        Summaries of stored runs, oldest first.
+        
+        Args:
+            language: Only runs of this language
+            
+        Returns:
+            Run summaries
+        """
+        if not self.index_file.exists():
+            return []
+        
+        try:
+            runs = json.loads(self.index_file.read_text(encoding='utf-8'))
+        except (json.JSONDecodeError, OSError):
+            return []
+        
+        if language:
+            runs = [r for r in runs if r.get("language") == language]
+        return runs
+    
+    def load_run(self, run_id: str) -> Optional[Dict[str, Any]]:
+        """Load the full data of one run."""
+        run_file = self.runs_dir / f"{run_id}.json"
+        if not run_file.exists():
+            return None
+        
+        try:
+            return json.loads(run_file.read_text(encoding='utf-8'))
+        except (json.JSONDecodeError, OSError):
+            return None
+    
+    def drop_artifacts(self, run_id: str) -> None:
+        """Delete a run's artifacts, keeping its results (retention, see `artifacts.collect_garbage`)."""
+        shutil.rmtree(self.artifacts_dir / run_id, ignore_errors=True)
+        
+        index = self.list_runs()
+        for summary in index:
+            if summary["id"] == run_id:
+                summary["artifacts_size"] = 0
+        self._write_index(index)
+        
+        run = self.load_run(run_id)
+        if run is not None:
+            run.update(artifacts_size=0, artifacts_dir=None, artifacts=[], artifacts_dropped=datetime.now().isoformat())
+            (self.runs_dir / f"{run_id}.json").write_text(json.dumps(run, indent=2), encoding='utf-8')
+    
+    def test_history(
+        self,
+        language: Optional[str] = None,
+        last_runs: Optional[int] = None,
+        max_durations: int = 10
+    ) -> Dict[str, TestHistory]:
+        """
+        Aggregate per-test history over stored runs.
+        
+        Args:
+            language: Only runs of this language
+            last_runs: Only the most recent N runs
+            max_durations: Durations kept per test (most recent)
+            
+        Returns:
+            History keyed by `test_key`
+        """
+        runs = self.list_runs(language)
+        if last_runs:
+            runs = runs[-last_runs:]
+        
+        history: Dict[str, TestHistory] = {}
+        
+        for summary in runs:
+            run = self.load_run(summary["id"])
+            if not run:
+                continue
+            
+            for data in run.get("tests", []):
+                test = TestResult(
+                    name=data.get("name", "unknown"),
+                    status=data.get("status", "unknown"),
+                    duration=data.get("duration", 0.0),
+                    file_path=data.get("file_path"),
+                    suite=data.get("suite"),
+                )
+                key = test_key(test)
+                entry = history.setdefault(key, TestHistory(
+                    key=key,
+                    name=test.name,
+                    suite=test.suite,
+                    file_path=test.file_path,
+                ))
+                
+                entry.runs += 1
+                if test.status in ("failed", "error", "passed_on_retry"):
+                    entry.failures += 1
+                entry.last_status = test.status
+                entry.last_run = summary["id"]
+                if test.status != "skipped":
+                    entry.durations = (entry.durations + [test.duration])[-max_durations:]
+        
+        return history
+    
+    def _write_index(self, runs: List[Dict[str, Any]]) -> None:
+        self.index_file.write_text(json.dumps(runs, indent=2), encoding='utf-8')
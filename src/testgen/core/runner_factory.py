@@ -10,6 +10,7 @@ from typing import Optional
 from .base_runner import BaseTestRunner
 from .language_config import Language
 from .language_detector import LanguageDetector, TestFramework
+from .language_detector import Language as DetectedLanguage
 from .python_runner import PythonTestRunner
 from .javascript_runner import JavaScriptTestRunner
 from .java_runner import JavaTestRunner
@@ -23,6 +24,7 @@ from .kotlin_runner import KotlinTestRunner
 from .cpp_runner import CppTestRunner
 from .html_runner import HTMLTestRunner
 from .css_runner import CSSTestRunner
+from .shell_runner import ShellTestRunner
 
 
 
@@ -69,6 +71,10 @@ class TestRunnerFactory:
         if language is None:
             language = self.detector.detect_language(project_dir)
         
+        # The detector has its own Language enum
+        if isinstance(language, DetectedLanguage):
+            language = Language(language.value)
+        
         # Create appropriate runner
         if language == Language.PYTHON:
             return PythonTestRunner(verbose=verbose)
@@ -95,10 +101,12 @@ class TestRunnerFactory:
             return PHPTestRunner(verbose=verbose)
         
         elif language == Language.SWIFT:
-            return SwiftTestRunner(verbose=verbose)
+            framework = self.detector.detect_test_framework(project_dir, DetectedLanguage.SWIFT)
+            return SwiftTestRunner(verbose=verbose, framework=framework.value)
         
         elif language == Language.KOTLIN:
-            return KotlinTestRunner(verbose=verbose)
+            framework = self.detector.detect_test_framework(project_dir, DetectedLanguage.KOTLIN)
+            return KotlinTestRunner(verbose=verbose, framework=framework.value)
         
         elif language == Language.CPP:
             return CppTestRunner(verbose=verbose)
@@ -109,6 +117,9 @@ class TestRunnerFactory:
         elif language == Language.CSS:
             return CSSTestRunner(verbose=verbose)
         
+        elif language == Language.SHELL:
+            return ShellTestRunner(verbose=verbose)
+        
         else:
             # Default to Python for unknown languages
             print(f"Warning: Unknown language '{language}', defaulting to Python runner")
@@ -136,6 +147,7 @@ class TestRunnerFactory:
             Language.CPP.value,
             Language.HTML.value,
             Language.CSS.value,
+            Language.SHELL.value,
         ]
     
     def get_project_info(self, project_dir: str) -> dict:
@@ -7,6 +7,7 @@ optimized for LLM consumption.
 """
 
 import ast
+import re
 from pathlib import Path
 from typing import Dict, List, Optional, Set
 from enum import Enum
@@ -15,6 +16,17 @@ from pydantic import BaseModel, Field
 from testgen.config import config
 
 
+# Shell keywords and builtins (not external commands)
+SHELL_BUILTINS = {
+    "if", "then", "else", "elif", "fi", "for", "while", "until", "do", "done", "case", "esac",
+    "in", "function", "return", "exit", "local", "export", "readonly", "declare", "typeset",
+    "set", "unset", "shift", "source", "echo", "printf", "read", "cd", "pwd", "test", "true",
+    "false", "trap", "eval", "exec", "wait", "break", "continue", "builtin", "command", "type",
+    "hash", "getopts", "let", "shopt", "alias", "unalias", "pushd", "popd", "dirs", "mapfile",
+    "readarray", "kill", "jobs", "fg", "bg", "umask", "ulimit", "times", "select", "time",
+}
+
+
 class FileType(str, Enum):
     """Supported file types for scanning."""
     # Programming Languages
@@ -32,6 +44,8 @@ class FileType(str, Enum):
     GO = ".go"
     RUST = ".rs"
     PHP = ".php"
+    SHELL = ".sh"
+    BASH = ".bash"
     
     # Web Languages
     HTML = ".html"
@@ -384,18 +398,26 @@ class CodeScanner:
     - Smart context optimization for LLMs
     """
     
-    def __init__(self, ignore_patterns: Optional[List[str]] = None, include_config_files: bool = False):
+    def __init__(
+        self,
+        ignore_patterns: Optional[List[str]] = None,
+        include_config_files: bool = False,
+        typescript_types: bool = True
+    ):
         """
         Initialize the scanner.
         
         Args:
             ignore_patterns: Custom patterns to ignore (uses config defaults if None)
             include_config_files: Whether to include configuration files in scan
+            typescript_types: Use the project's TypeScript compiler (when installed)
+                for typed signatures of .ts/.tsx files
         """
         self.ignore_patterns = ignore_patterns or config.ignore_patterns
         self.supported_extensions = config.supported_extensions
         self.max_file_size_lines = config.max_file_size_lines
         self.include_config_files = include_config_files
+        self.typescript_types = typescript_types
         
         # Smart context reduction threshold
         self.CONTEXT_THRESHOLD = 500  # lines
@@ -537,6 +559,12 @@ class CodeScanner:
                         code_file.classes = classes
                         code_file.imports = ids  # Store IDs as "imports"
                     
+                    elif file_type in (FileType.SHELL, FileType.BASH):
+                        functions, commands, sources = self._extract_shell_info(content)
+                        code_file.functions = functions
+                        code_file.classes = commands  # Store external commands as "classes"
+                        code_file.imports = sources  # Store sourced files as "imports"
+                    
                     elif file_type == FileType.SQL:
                         tables, functions, procedures = self._extract_sql_info(content)
                         code_file.functions = functions + procedures  # Combine functions and procedures
@@ -585,6 +613,10 @@ class CodeScanner:
                     result.errors.append(error_msg)
                     continue
             
+            # Compiler type information for TypeScript (one program for all files)
+            if self.typescript_types:
+                self._apply_typescript_types(result)
+            
             # Update totals
             result.total_files = len(result.files)
         
@@ -594,6 +626,45 @@ class CodeScanner:
         
         return result
     
+    def _apply_typescript_types(self, result: ScanResult) -> None:
+        """
+        Replace the regex-extracted signatures of TypeScript files with the
+        compiler's typed ones (parameter/return types, interfaces, unions).
+        
+        Files are left as they are when Node or the project's `typescript`
+        package isn't available.
+        """
+        from testgen.core.typescript_types import extract_types, find_project_dir
+        
+        ts_files = [f for f in result.files if f.file_type in (FileType.TYPESCRIPT, FileType.TSX)]
+        if not ts_files:
+            return
+        
+        # One compiler run per project (tsconfig), e.g. per package of a monorepo
+        projects: Dict[Path, List[CodeFile]] = {}
+        for code_file in ts_files:
+            projects.setdefault(find_project_dir(str(code_file.path)), []).append(code_file)
+        
+        types = {}
+        for project_dir, files in projects.items():
+            types.update(extract_types([str(f.path) for f in files], str(project_dir)))
+        
+        for code_file in ts_files:
+            info = types.get(str(code_file.path.resolve()))
+            if info is None or info.is_empty:
+                continue
+            
+            code_file.functions = info.function_signatures()
+            code_file.classes = info.class_summaries()
+            
+            if code_file.content is None:
+                # Signatures-only context: token estimate follows the new signatures
+                result.total_tokens -= code_file.token_count
+                code_file.token_count = self._estimate_tokens(
+                    "\n".join(code_file.functions + code_file.classes + code_file.imports)
+                )
+                result.total_tokens += code_file.token_count
+    
     def _should_ignore(self, path: Path, root: Path) -> bool:
         """
         Check if a path should be ignored based on patterns.
@@ -778,6 +849,13 @@ class CodeScanner:
             return FileType.RUST
         elif ext == ".php":
             return FileType.PHP
+        elif ext == ".sh":
+            return FileType.SHELL
+        elif ext == ".bash":
+            return FileType.BASH
+        elif ext == "" and self._has_shell_shebang(path):
+            # Extensionless scripts (bin/deploy, scripts/release)
+            return FileType.SHELL
         
         # Web Languages
         elif ext == ".html":
@@ -826,7 +904,7 @@ class CodeScanner:
             tree = ast.parse(content)
             
             for node in ast.walk(tree):
-                if isinstance(node, ast.FunctionDef):
+                if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
                     # Build function signature
                     params = []
                     for arg in node.args.args:
@@ -838,6 +916,8 @@ class CodeScanner:
                     
                     # Build signature string
                     sig = f"{node.name}({', '.join(params)})"
+                    if isinstance(node, ast.AsyncFunctionDef):
+                        sig = f"async {sig}"
                     
                     # Add return type if present
                     if node.returns:
@@ -878,8 +958,10 @@ class CodeScanner:
                     # Extract class methods
                     methods = []
                     for item in node.body:
-                        if isinstance(item, ast.FunctionDef):
+                        if isinstance(item, (ast.FunctionDef, ast.AsyncFunctionDef)):
                             method_info = item.name
+                            if isinstance(item, ast.AsyncFunctionDef):
+                                method_info = f"async {method_info}"
                             # Add method decorators
                             if item.decorator_list:
                                 method_decorators = [ast.unparse(d) for d in item.decorator_list]
@@ -1176,6 +1258,59 @@ class CodeScanner:
         
         return list(set(functions)), list(set(classes)), list(set(imports))
     
+    def _has_shell_shebang(self, path: Path) -> bool:
+        """Check for a sh/bash/zsh/ksh shebang on the first line."""
+        try:
+            with open(path, 'rb') as f:
+                first_line = f.readline(128)
+        except OSError:
+            return False
+        return re.match(rb'#!\s*\S*/(?:env\s+)?(?:ba|z|k|da)?sh\b', first_line) is not None
+    
+    def _extract_shell_info(self, content: str) -> tuple[List[str], List[str], List[str]]:
+        """
+        Extract functions, external commands, and sourced files from shell scripts.
+        
+        External commands are the ones tests usually stub on PATH (curl,
+        kubectl, docker, ...).
+        
+        Returns:
+            Tuple of (functions, external_commands, sourced_files)
+        """
+        functions = []
+        sources = []
+        
+        # name() {   |   function name {   |   function name() {
+        function_pattern = r'^\s*(?:function\s+([\w:.-]+)\s*(?:\(\s*\))?|([\w:.-]+)\s*\(\s*\))\s*\{?'
+        for match in re.finditer(function_pattern, content, re.MULTILINE):
+            name = match.group(1) or match.group(2)
+            if name not in functions:
+                functions.append(name)
+        
+        # source lib.sh  |  . "$DIR/lib.sh"
+        for match in re.finditer(r'^\s*(?:source|\.)\s+(?:"([^"]+)"|\'([^\']+)\'|([^\s;]+))', content, re.MULTILINE):
+            sources.append(match.group(1) or match.group(2) or match.group(3))
+        
+        # Commands at the start of a pipeline/statement that aren't shell builtins or functions
+        builtins = SHELL_BUILTINS | set(functions)
+        commands = []
+        for line in content.splitlines():
+            stripped = line.strip()
+            if not stripped or stripped.startswith('#'):
+                continue
+            for segment in re.split(r'\|\||&&|[|;]|\$\(', stripped):
+                words = segment.strip().split()
+                # Skip leading VAR=value assignments
+                while words and re.match(r'^[A-Za-z_]\w*=', words[0]):
+                    words = words[1:]
+                if not words:
+                    continue
+                command = words[0]
+                if re.match(r'^[a-z][\w.-]*$', command) and command not in builtins and command not in commands:
+                    commands.append(command)
+        
+        return functions, commands, sources
+    
     def _extract_html_info(self, content: str) -> tuple[List[str], List[str], List[str]]:
         """
         Extract IDs, classes, and script sources from HTML.
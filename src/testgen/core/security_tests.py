@@ -0,0 +1,378 @@
+"""
+Security Test Generation.
+
+Finds functions that handle external input (HTTP handlers, SQL builders,
+path joins, template rendering, deserialization) and describes what
+security tests should throw at them:
+- path traversal (`../`), SQL injection, header injection (CR/LF)
+- oversized inputs and malformed encodings
+- template injection and unsafe deserialization
+
+Generated security tests carry a `testgen:security <category>` comment on
+the line above them, so their failures can be reported as security
+findings (on top of being failures). Names alone don't mark them: a
+hand-written `TestSecurityHeaders` is an ordinary test.
+"""
+
+import re
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Dict, List, Optional, Set
+
+
+PATH_TRAVERSAL = "path_traversal"
+SQL_INJECTION = "sql_injection"
+HEADER_INJECTION = "header_injection"
+OVERSIZED_INPUT = "oversized_input"
+MALFORMED_ENCODING = "malformed_encoding"
+TEMPLATE_INJECTION = "template_injection"
+UNSAFE_DESERIALIZATION = "unsafe_deserialization"
+
+# Payloads suggested to the LLM per category (as written in test source)
+PAYLOADS: Dict[str, List[str]] = {
+    PATH_TRAVERSAL: ["../../etc/passwd", "..%2f..%2fetc%2fpasswd", "/etc/passwd", "..\\\\..\\\\windows\\\\win.ini", "a/../../b"],
+    SQL_INJECTION: ["' OR '1'='1", "1; DROP TABLE users--", "\" OR \"\"=\"", "1 UNION SELECT NULL--", "name') --"],
+    HEADER_INJECTION: ["value\\r\\nSet-Cookie: injected=1", "value\\nLocation: //evil.example", "%0d%0aX-Injected: 1"],
+    OVERSIZED_INPUT: ["a string of 10 MB", "a deeply nested JSON document (10000 levels)", "100000 repeated parameters"],
+    MALFORMED_ENCODING: ["\\xff\\xfe (invalid UTF-8)", "\\xc0\\xaf (overlong '/')", "%zz (bad percent-encoding)", "{\"a\": (truncated JSON)", "\\x00 (NUL byte)"],
+    TEMPLATE_INJECTION: ["<script>alert(1)</script>", "{{7*7}}", "\"><img src=x onerror=alert(1)>"],
+    UNSAFE_DESERIALIZATION: ["a payload naming an arbitrary type/class", "!!python/object/apply:os.system ['id']"],
+}
+
+# What a safe implementation does with the payloads
+EXPECTED_BEHAVIOR: Dict[str, str] = {
+    PATH_TRAVERSAL: "the resolved path stays inside the base directory, or the input is rejected (error, 400/403)",
+    SQL_INJECTION: "the input is passed as a bound parameter or rejected; it never appears verbatim in the SQL text",
+    HEADER_INJECTION: "no extra header or response splitting; CR/LF values are rejected or encoded",
+    OVERSIZED_INPUT: "rejected with an error (413) at a size limit, without reading everything into memory",
+    MALFORMED_ENCODING: "a clean error (400 / error value / exception type), no panic, crash or leaked internals",
+    TEMPLATE_INJECTION: "the output is escaped; template syntax in input is not evaluated",
+    UNSAFE_DESERIALIZATION: "untrusted data is never turned into arbitrary objects; the input is rejected",
+}
+
+# Risks per kind of input-handling code
+KIND_CATEGORIES: Dict[str, List[str]] = {
+    "http_handler": [HEADER_INJECTION, OVERSIZED_INPUT, MALFORMED_ENCODING],
+    "path_join": [PATH_TRAVERSAL],
+    "sql_builder": [SQL_INJECTION],
+    "header": [HEADER_INJECTION],
+    "template": [TEMPLATE_INJECTION],
+    "deserialization": [UNSAFE_DESERIALIZATION, OVERSIZED_INPUT, MALFORMED_ENCODING],
+}
+
+# Request accessors: values that come straight from the client
+REQUEST_INPUT = re.compile(
+    r'\br\.(?:URL|Form|PostForm|Header|Body|PathValue|FormValue|PostFormValue)\b|\bmux\.Vars\('
+    r'|\bc\.(?:Param|Query|PostForm|FormValue|Params)\(|\bchi\.URLParam\('
+    r'|\brequest\.(?:args|form|json|data|files|headers|cookies|values|query_params|path_params|GET|POST|body)\b'
+    r'|\breq\.(?:params|query|body|headers|cookies)\b'
+)
+
+SQL_KEYWORDS = r'(?:SELECT|INSERT|UPDATE|DELETE|WHERE|ORDER\s+BY|VALUES)'
+# A single- or double-quoted string literal starting with SQL (quotes of the other kind inside)
+SQL_STRING = rf'(?:"\s*{SQL_KEYWORDS}\b[^"\n]*"|\'\s*{SQL_KEYWORDS}\b[^\'\n]*\')'
+
+# Function starts: (decorators)(name)(params)
+FUNCTION_PATTERNS = {
+    "python": re.compile(r'^((?:[ \t]*@[^\n]*\n)*)[ \t]*(?:async\s+)?def\s+(\w+)\s*\(([^)]*)\)', re.MULTILINE),
+    "go": re.compile(r'^()func\s+(?:\([^)]*\)\s*)?(\w+)\s*\(([^)]*)\)', re.MULTILINE),
+    "javascript": re.compile(
+        r'^()[ \t]*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*(\w+)\s*\(([^)]*)\)'
+        r'|^()[ \t]*(?:export\s+)?(?:const|let|var)\s+(\w+)\s*=\s*(?:async\s*)?\(([^)]*)\)\s*(?::[^=]+)?=>'
+        r'|^()[ \t]*(?:app|router)\.((?:get|post|put|patch|delete|all|use)\(\s*[\'"`][^\'"`]*)[\'"`]\s*,[^(]*\(([^)]*)\)',
+        re.MULTILINE
+    ),
+}
+
+# (kind, pattern, needs input): sinks only count when their arguments use client input
+SINKS = {
+    "python": [
+        ("path_join", re.compile(r'\bos\.path\.join\(|\bPath\([^)]*\)\s*/|\bsend_file\(|\bsend_from_directory\(|\bopen\('), True),
+        ("sql_builder", re.compile(rf'\bf(?:"\s*{SQL_KEYWORDS}\b[^"\n]*\{{|\'\s*{SQL_KEYWORDS}\b[^\'\n]*\{{)', re.IGNORECASE), False),
+        ("sql_builder", re.compile(SQL_STRING + r'\s*(?:%\s*[\w(]|\+\s*\w|\.format\()', re.IGNORECASE), False),
+        ("header", re.compile(r'\bheaders\[[^\]]+\]\s*=|\bredirect\('), True),
+        ("template", re.compile(r'\brender_template_string\(|\bjinja2\.Template\(|\bMarkup\(|\bmark_safe\('), False),
+        ("deserialization", re.compile(r'\bpickle\.loads?\(|\byaml\.load\((?![^)]*SafeLoader)|\byaml\.unsafe_load\(|\bmarshal\.loads\(|\bjsonpickle\.decode\('), False),
+    ],
+    "go": [
+        ("path_join", re.compile(r'\b(?:filepath|path)\.Join\(|\bos\.(?:Open|OpenFile|ReadFile|Create|Remove|RemoveAll)\(|\bhttp\.ServeFile\('), True),
+        ("sql_builder", re.compile(r'\bfmt\.Sprintf\(\s*["`][^"`]*\b' + SQL_KEYWORDS + r'\b[^"`]*%[svq]', re.IGNORECASE), False),
+        ("sql_builder", re.compile(r'\.(?:Query|QueryRow|Exec)(?:Context)?\([^\n]*["`]\s*\+\s*\w'), False),
+        ("header", re.compile(r'\.Header\(\)\.(?:Set|Add)\(|\bhttp\.Redirect\('), True),
+        ("template", re.compile(r'\btemplate\.(?:HTML|JS|URL)\('), True),
+        ("deserialization", re.compile(r'\b(?:json|xml|gob)\.NewDecoder\(|\b(?:json|xml|yaml)\.Unmarshal\('), True),
+    ],
+    "javascript": [
+        ("path_join", re.compile(r'\bpath\.(?:join|resolve)\(|\bfs\.\w+\(|\bres\.sendFile\('), True),
+        ("sql_builder", re.compile(r'`\s*' + SQL_KEYWORDS + r'\b[^`]*\$\{', re.IGNORECASE), False),
+        ("sql_builder", re.compile(SQL_STRING + r'\s*\+\s*\w', re.IGNORECASE), False),
+        ("header", re.compile(r'\bres\.(?:setHeader|set|header|redirect|location)\('), True),
+        ("template", re.compile(r'\.innerHTML\s*=|\bdangerouslySetInnerHTML\b|\bejs\.render\(|\bHandlebars\.compile\('), False),
+        ("deserialization", re.compile(r'\bJSON\.parse\(|\bunserialize\(|\byaml\.load\('), True),
+    ],
+}
+
+# Parameters that carry client input in handler signatures
+HANDLER_PARAMS = {
+    "python": re.compile(r'\brequest\b|:\s*Request\b'),
+    "go": re.compile(r'\*http\.Request\b|\bhttp\.ResponseWriter\b|\*gin\.Context\b|\becho\.Context\b|\*fiber\.Ctx\b'),
+    "javascript": re.compile(r'^\s*req\b|\brequest\b'),
+}
+ROUTE_DECORATOR = re.compile(r'@\w+\.(?:route|get|post|put|patch|delete|api_route|websocket)\(')
+
+# Receivers and framework objects that aren't input themselves
+NON_INPUT_PARAMS = {"self", "cls", "w", "ctx", "context", "res", "response", "next", "t", "b"}
+
+# Comment marking a generated security test: "// testgen:security path_traversal"
+SECURITY_MARKER = "testgen:security"
+
+# The marker, then (decorators and) the test it marks: def test_*, func Test*, describe/it/test('...')
+MARKED_TEST = re.compile(
+    r'^[ \t]*(?://|#)[ \t]*' + re.escape(SECURITY_MARKER) + r'(?:[ \t]+(\w+))?[ \t]*\n'
+    r'(?:[ \t]*(?:@|//|#)[^\n]*\n)*'
+    r'[ \t]*(?:(?:async[ \t]+)?def[ \t]+(test\w*)|func[ \t]+(Test\w*)|(?:describe|it|test)\(\s*([\'"`])(.*?)\4)',
+    re.MULTILINE
+)
+
+TEST_FILE_SUFFIXES = (".py", ".go", ".js", ".ts", ".jsx", ".tsx", ".mjs", ".cjs")
+SKIPPED_DIRS = {"node_modules", "vendor", "venv", ".venv", "__pycache__", "dist", "build"}
+
+# Test name keywords -> finding category
+CATEGORY_KEYWORDS = [
+    (PATH_TRAVERSAL, ("traversal", "path", "dotdot", "directory")),
+    (SQL_INJECTION, ("sql", "query")),
+    (HEADER_INJECTION, ("header", "crlf", "redirect", "splitting")),
+    (OVERSIZED_INPUT, ("oversize", "large", "huge", "long", "size", "limit")),
+    (MALFORMED_ENCODING, ("encoding", "utf", "malformed", "invalid_bytes", "percent", "unicode")),
+    (TEMPLATE_INJECTION, ("template", "xss", "escape", "html", "script")),
+    (UNSAFE_DESERIALIZATION, ("deserializ", "pickle", "yaml", "gob", "unmarshal")),
+]
+
+SECURITY_TEST_NAMING = {
+    "python": "test_security_<function>_<risk> (e.g. test_security_download_path_traversal), "
+              "each right below a `# testgen:security <risk>` comment",
+    "go": "TestSecurity<Function><Risk> (e.g. TestSecurityDownloadPathTraversal) with one subtest per payload, "
+          "each right below a `// testgen:security <risk>` comment",
+    "javascript": "inside describe('security: <function>', ...), "
+                  "each describe right below a `// testgen:security <risk>` comment",
+}
+
+
+@dataclass
+class InputHandler:
+    """A function handling external input, and the security risks to test."""
+    
+    name: str
+    line: int
+    kinds: List[str] = field(default_factory=list)       # http_handler, path_join, sql_builder, ...
+    evidence: List[str] = field(default_factory=list)    # source lines that triggered each kind
+    
+    @property
+    def categories(self) -> List[str]:
+        categories = []
+        for kind in self.kinds:
+            for category in KIND_CATEGORIES[kind]:
+                if category not in categories:
+                    categories.append(category)
+        return categories
+
+
+def normalize_language(language: str) -> str:
+    """Map a language name to the pattern set used for it."""
+    language = str(getattr(language, "value", language)).lower()
+    if language in ("typescript", "js", "ts"):
+        return "javascript"
+    return language
+
+
+def find_input_handlers(code: str, language: str) -> List[InputHandler]:
+    """
+    Find functions that handle external input.
+    
+    A function counts when it is an HTTP handler (by signature or route
+    decorator), or passes client input into a path join, SQL text, a
+    response header, a template or a deserializer. Input is a parameter
+    of the function, a request accessor (`r.URL`, `request.args`,
+    `req.params`, ...) or a local assigned from either.
+    
+    Args:
+        code: Source code
+        language: "python", "go", "javascript" or "typescript"
+        
+    Returns:
+        Input handlers in source order (empty for other languages)
+    """
+    language = normalize_language(language)
+    pattern = FUNCTION_PATTERNS.get(language)
+    if pattern is None:
+        return []
+    
+    matches = list(pattern.finditer(code))
+    handlers = []
+    for index, match in enumerate(matches):
+        groups = match.groups()
+        # JavaScript alternatives: take the (decorators, name, params) triple that matched
+        for offset in range(0, len(groups), 3):
+            if groups[offset + 1] is not None:
+                decorators, name, params = groups[offset:offset + 3]
+                break
+        end = matches[index + 1].start() if index + 1 < len(matches) else len(code)
+        body = code[match.end():end]
+        line = code.count("\n", 0, match.start() + len(decorators or "")) + 1
+        
+        handler = InputHandler(name=_route_name(name), line=line)
+        if HANDLER_PARAMS[language].search(params) or ROUTE_DECORATOR.search(decorators or "") or "(" in name:
+            handler.kinds.append("http_handler")
+            handler.evidence.append(code[match.start():match.end()].strip().splitlines()[-1])
+        
+        tainted = _tainted_names(params, body)
+        for kind, sink, needs_input in SINKS[language]:
+            for sink_match in sink.finditer(body):
+                statement = _line_at(body, sink_match.start())
+                if needs_input and not _uses_input(statement[statement.find(sink_match.group(0)):], tainted):
+                    continue
+                if kind not in handler.kinds:
+                    handler.kinds.append(kind)
+                    handler.evidence.append(statement.strip())
+                break
+        
+        if handler.kinds:
+            handlers.append(handler)
+    
+    return handlers
+
+
+def describe_targets(handlers: List[InputHandler]) -> str:
+    """
+    Render input handlers, their risks, payloads and expected-safe
+    behavior for `PromptTemplates.SECURITY_TESTS`.
+    """
+    lines = []
+    for handler in handlers:
+        lines.append(f"- {handler.name} (line {handler.line}): " + "; ".join(handler.evidence))
+        for category in handler.categories:
+            payloads = ", ".join(f"`{p}`" for p in PAYLOADS[category])
+            lines.append(f"  - {category.replace('_', ' ')}: {payloads}")
+            lines.append(f"    expect: {EXPECTED_BEHAVIOR[category]}")
+    return "\n".join(lines)
+
+
+def marked_security_tests(source: str) -> Dict[str, str]:
+    """
+    Security tests marked in test source.
+    
+    Returns:
+        Test name (Python/Go function, JavaScript describe/it title) -> category
+        (the marker's, or from the name when the marker has none)
+    """
+    marked = {}
+    for match in MARKED_TEST.finditer(source):
+        category, python, go, _, title = match.groups()
+        name = python or go or title
+        marked[name] = category or classify_security_test(name)
+    return marked
+
+
+def find_security_tests(test_dir: str) -> Dict[str, str]:
+    """
+    Marked security tests in the test files under a directory.
+    
+    Args:
+        test_dir: Directory to search (a single file works too)
+    
+    Returns:
+        Test name -> category, for `ResultAggregator`
+    """
+    root = Path(test_dir)
+    paths = [root] if root.is_file() else sorted(root.rglob("*"))
+    marked: Dict[str, str] = {}
+    for path in paths:
+        if path.suffix not in TEST_FILE_SUFFIXES or not path.is_file():
+            continue
+        if path != root and SKIPPED_DIRS & set(path.relative_to(root).parts[:-1]):
+            continue
+        try:
+            source = path.read_text(encoding='utf-8', errors='ignore')
+        except OSError:
+            continue
+        if SECURITY_MARKER in source:
+            marked.update(marked_security_tests(source))
+    return marked
+
+
+def security_category(name: str, marked: Dict[str, str]) -> Optional[str]:
+    """
+    Category of a reported test if it is (a subtest/case of) a marked security test.
+    
+    Args:
+        name: Test name as the runner reports it ("file::Class::test[case]",
+            "TestX/payload", "describe title > it title")
+        marked: Result of `find_security_tests`
+    
+    Returns:
+        The category, or None for tests that aren't marked
+    """
+    if not marked:
+        return None
+    base = re.sub(r'\[.*\]$', "", (name or "").split("::")[-1])
+    for test, category in marked.items():
+        if base == test or any(base.startswith(test + separator) for separator in ("/", " > ", " ")):
+            return category
+        if base.endswith((" > " + test, " " + test)):
+            return category
+    return None
+
+
+def classify_security_test(name: str) -> str:
+    """Category of a security test from the keywords in its name ("security" if none match)."""
+    lowered = re.sub(r'([a-z])([A-Z])', r'\1_\2', name or "").lower()
+    for category, keywords in CATEGORY_KEYWORDS:
+        if any(keyword in lowered for keyword in keywords):
+            return category
+    return "security"
+
+
+def _route_name(name: str) -> str:
+    """`get('/files/:name` -> `GET /files/:name` for Express routes."""
+    if "(" not in name:
+        return name
+    method, _, route = name.partition("(")
+    return f"{method.upper()} {route.strip().strip(chr(39) + chr(34) + '`')}"
+
+
+def _parameter_names(params: str) -> Set[str]:
+    names = set()
+    for part in params.split(","):
+        part = part.strip().lstrip("*.").strip()
+        if not part or part[0] in "{[":
+            continue
+        name = re.split(r'[\s:=?]', part, maxsplit=1)[0]
+        if re.match(r'^\w+$', name) and name not in NON_INPUT_PARAMS:
+            names.add(name)
+    return names
+
+
+def _tainted_names(params: str, body: str) -> Set[str]:
+    """Parameters plus locals assigned from them or from request accessors, in source order."""
+    tainted = _parameter_names(params)
+    assignment = re.compile(r'^\s*(?:(?:var|let|const)\s+)?([\w, ]+?)\s*(?::=|=(?!=))\s*(.+)$')
+    for line in body.splitlines():
+        match = assignment.match(line)
+        if match and _uses_input(match.group(2), tainted):
+            for name in match.group(1).split(","):
+                name = name.strip()
+                if name and name != "_" and re.match(r'^\w+$', name):
+                    tainted.add(name)
+    return tainted
+
+
+def _uses_input(text: str, tainted: Set[str]) -> bool:
+    if REQUEST_INPUT.search(text):
+        return True
+    return any(re.search(rf'\b{re.escape(name)}\b', text) for name in tainted)
+
+
+def _line_at(text: str, position: int) -> str:
+    start = text.rfind("\n", 0, position) + 1
+    end = text.find("\n", position)
+    return text[start:end if end != -1 else len(text)]
@@ -0,0 +1,238 @@
+"""
+Shell Test Runner (bats-core).
+
+Runs `.bats` files with `bats --tap` and turns the TAP stream into
+per-test results:
+
+    1..3
+    ok 1 deploy uploads the artifact in 41ms
+    not ok 2 deploy fails without a token in 12ms
+    # (in test file test/deploy.bats, line 14)
+    #   `[ "$status" -eq 1 ]' failed
+    ok 3 rollback # skip not implemented yet
+"""
+
+import re
+import shutil
+import subprocess
+from pathlib import Path
+from typing import List, Optional
+
+from .base_runner import BaseTestRunner, TestResults, TestResult
+
+
+TAP_PLAN = re.compile(r'^1\.\.(\d+)')
+TAP_RESULT = re.compile(
+    r'^(not ok|ok)\s+(\d+)\s+(.*?)'
+    r'(?:\s+in\s+(\d+)ms)?'                # --timing
+    r'(?:\s+#\s*(skip|SKIP|todo|TODO)\b\s*(.*))?$'
+)
+TAP_FAILURE_LOCATION = re.compile(r'^#\s+\(in test file (.+?), line (\d+)\)')
+
+# Where projects usually vendor bats-core (git submodules, npm)
+BATS_LOCATIONS = ("test/bats/bin/bats", "test/libs/bats/bin/bats", "tests/bats/bin/bats", "node_modules/.bin/bats")
+
+
+class ShellTestRunner(BaseTestRunner):
+    """Test runner for shell scripts using bats-core."""
+    
+    def __init__(self, verbose: bool = False):
+        super().__init__(verbose)
+    
+    def get_language(self) -> str:
+        return "shell"
+    
+    def get_framework(self) -> str:
+        return "bats"
+    
+    def get_test_patterns(self) -> List[str]:
+        return ["*.bats"]
+    
+    def supports_coverage(self) -> bool:
+        return False
+    
+    def supports_parallel(self) -> bool:
+        return True  # --jobs (needs GNU parallel)
+    
+    def discover_tests(self, test_dir: str, pattern: Optional[str] = None) -> List[Path]:
+        if pattern is None:
+            pattern = "*.bats"
+        test_path = Path(test_dir)
+        if not test_path.exists():
+            return []
+        if test_path.is_file():
+            return [test_path]
+        return sorted(f for f in test_path.rglob(pattern) if "bats" not in f.relative_to(test_path).parts[:-1])
+    
+    def count_tests(self, test_dir: str, pattern: Optional[str] = None) -> int:
+        total = 0
+        for test_file in self.discover_tests(test_dir, pattern):
+            try:
+                total += len(re.findall(r'^\s*@test\s', test_file.read_text(), re.MULTILINE))
+            except:
+                pass
+        return total
+    
+    def find_bats(self, test_dir: str) -> str:
+        """
+        Find the bats executable: a vendored copy near the tests, else `bats` on PATH.
+        
+        Args:
+            test_dir: Test directory or file
+            
+        Returns:
+            Path to bats (or "bats" when only the PATH lookup is left)
+        """
+        start = Path(test_dir).resolve()
+        if start.is_file():
+            start = start.parent
+        for directory in [start, *start.parents]:
+            for location in BATS_LOCATIONS:
+                candidate = directory / location
+                if candidate.is_file():
+                    return str(candidate)
+        return shutil.which("bats") or "bats"
+    
+    def build_command(self, test_dir: str, pattern: Optional[str] = None, **kwargs) -> List[str]:
+        cmd = [self.find_bats(test_dir), "--tap", "--timing"]
+        
+        test_filter = kwargs.get("filter")
+        if test_filter:
+            cmd.extend(["--filter", test_filter])
+        
+        if kwargs.get("jobs"):
+            cmd.extend(["--jobs", str(kwargs["jobs"])])
+        
+        cmd.extend(kwargs.get("extra_args") or [])
+        
+        # A single .bats file, or the files matching the pattern
+        if pattern and pattern.endswith(".bats") and not any(c in pattern for c in "*?["):
+            cmd.append(str(Path(test_dir) / pattern))
+        elif pattern and pattern != "*.bats":
+            cmd.extend(str(f) for f in self.discover_tests(test_dir, pattern))
+        else:
+            cmd.extend(["--recursive", str(test_dir)])
+        
+        return cmd
+    
+    def run_tests(self, test_dir: str, pattern: Optional[str] = None, **kwargs) -> TestResults:
+        cmd = self.build_command(test_dir, pattern, **kwargs)
+        
+        if self.verbose:
+            print(f"Running: {' '.join(cmd)}")
+        
+        try:
+            result = subprocess.run(
+                cmd,
+                capture_output=True,
+                text=True,
+                timeout=kwargs.get("timeout", 300)
+            )
+        except subprocess.TimeoutExpired:
+            return TestResults(total=0, errors=1, language=self.get_language(), framework=self.get_framework())
+        except Exception:
+            return TestResults(total=0, errors=1, language=self.get_language(), framework=self.get_framework())
+        
+        return self._parse_output(result)
+    
+    def rerun_tests(
+        self,
+        test_dir: str,
+        tests: List[TestResult],
+        pattern: Optional[str] = None,
+        **kwargs
+    ) -> TestResults:
+        """Re-run only the given tests with an anchored bats `--filter` (a bash regex)."""
+        names = []
+        for test in tests:
+            if test.name not in names:
+                names.append(test.name)
+        
+        if not names:
+            return super().rerun_tests(test_dir, tests, pattern, **kwargs)
+        
+        test_filter = "^(" + "|".join(_ere_escape(n) for n in names) + ")$"
+        return self.run_tests(test_dir, pattern, **{**kwargs, "filter": test_filter})
+    
+    def _parse_output(self, result: subprocess.CompletedProcess) -> TestResults:
+        """Parse bats TAP output into per-test results."""
+        results = TestResults(language=self.get_language(), framework=self.get_framework())
+        planned = None
+        current: Optional[TestResult] = None
+        diagnostics: List[str] = []
+        
+        def finish():
+            if current is not None and current.status == "failed" and diagnostics:
+                current.message = next((d for d in diagnostics if not d.startswith("(in test file")), diagnostics[0])
+                current.traceback = "\n".join(diagnostics)
+        
+        for line in (result.stdout or "").splitlines():
+            plan = TAP_PLAN.match(line)
+            if plan:
+                planned = int(plan.group(1))
+                continue
+            
+            match = TAP_RESULT.match(line)
+            if match:
+                finish()
+                outcome, _, name, millis, directive, reason = match.groups()
+                if directive:
+                    status = "skipped"
+                else:
+                    status = "passed" if outcome == "ok" else "failed"
+                current = TestResult(
+                    name=name.strip(),
+                    status=status,
+                    duration=int(millis) / 1000 if millis else 0.0,
+                    message=(reason or None) if directive else None
+                )
+                diagnostics = []
+                results.tests.append(current)
+                continue
+            
+            if line.startswith("#") and current is not None:
+                diagnostic = line[1:].strip()
+                location = TAP_FAILURE_LOCATION.match(line)
+                if location:
+                    current.file_path = location.group(1)
+                    current.line_number = int(location.group(2))
+                    current.suite = Path(location.group(1)).stem
+                if diagnostic:
+                    diagnostics.append(diagnostic)
+        finish()
+        
+        for test in results.tests:
+            if test.status == "passed":
+                results.passed += 1
+            elif test.status == "failed":
+                results.failed += 1
+            else:
+                results.skipped += 1
+        results.total = len(results.tests)
+        results.duration = sum(t.duration for t in results.tests)
+        
+        # Tests that never reported (bats crashed mid-run, syntax error in a file)
+        if planned is not None and planned > results.total:
+            results.errors += planned - results.total
+            results.total = planned
+        elif results.total == 0 and result.returncode != 0:
+            results.tests.append(TestResult(
+                name="bats",
+                status="error",
+                message=((result.stderr or "") + (result.stdout or "")).strip()[-2000:] or None
+            ))
+            results.errors = results.total = 1
+        
+        return results
+    
+    def validate_test_file(self, test_file: str) -> bool:
+        try:
+            content = Path(test_file).read_text()
+            return re.search(r'^\s*@test\s', content, re.MULTILINE) is not None
+        except:
+            return False
+
+
+def _ere_escape(text: str) -> str:
+    """Escape a test name for a POSIX extended regex (bash `=~`)."""
+    return re.sub(r'([.\[\]()*+?{}|^$\\])', r'\\\1', text)
@@ -0,0 +1,436 @@
+"""
+Local Dependency Stand-ins for Integration Tests.
+
+Starts offline replacements for external dependencies before integration
+tests run and tears them down afterwards (no containers, no network):
+- SQLite: a database file seeded from .sql/.json/.csv fixtures
+- HTTP: a local process replaying recorded responses
+- Object storage: a temp directory with one subdirectory per bucket
+- OpenAPI: a local mock server for an upstream service's spec
+
+Connection details are injected into the environment, which every test
+runner's subprocess inherits.
+
+The HTTP stand-in also runs standalone:
+
+    python -m testgen.core.standins http --recordings recordings.json --port 8080
+"""
+
+import argparse
+import csv
+import json
+import os
+import queue
+import shutil
+import sqlite3
+import subprocess
+import sys
+import tempfile
+import threading
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+from pathlib import Path
+from typing import Dict, List, Optional, Tuple
+from urllib.parse import urlsplit, parse_qsl
+
+from .project_config import ProjectConfig, StandInsConfig
+
+
+# Seconds a stand-in server process gets to announce its port
+START_TIMEOUT = 30
+
+
+class StandInError(Exception):
+    """Raised when a stand-in cannot be started."""
+    pass
+
+
+class StandInManager:
+    """
+    Start, reset and stop the stand-ins declared in project config.
+    
+    Example:
+        >>> manager = StandInManager(load_project_config("."))
+        >>> with manager:
+        ...     runner.run_tests("tests/integration")  # sees DATABASE_URL etc.
+    """
+    
+    def __init__(self, project_config: ProjectConfig):
+        """
+        Initialize manager.
+        
+        Args:
+            project_config: Project config holding the `standins` section
+        """
+        self.project_config = project_config
+        self.config: StandInsConfig = project_config.standins
+        self.work_dir: Optional[Path] = None
+        self.env: Dict[str, str] = {}
+        self._previous_env: Dict[str, Optional[str]] = {}
+        self._processes: List[subprocess.Popen] = []
+        self._db_snapshots: List[Tuple[Path, Path]] = []  # (database, seeded copy)
+        self._stores: List[Tuple[Path, Optional[Path], List[str]]] = []  # (dir, seed, buckets)
+    
+    @property
+    def running(self) -> bool:
+        return self.work_dir is not None
+    
+    def start(self) -> Dict[str, str]:
+        """
+        Start all stand-ins and inject their connection details.
+        
+        Returns:
+            Environment variables that were set
+        """
+        if self.running:
+            return self.env
+        
+        self.work_dir = Path(tempfile.mkdtemp(prefix="testgen-standins-"))
+        
+        try:
+            for db in self.config.sqlite:
+                self.env[db.env] = self._start_sqlite(db.name, db.fixtures)
+            
+            for store in self.config.object_storage:
+                env_name = store.env or f"{_env_name(store.name)}_DIR"
+                self.env[env_name] = self._start_object_storage(store.name, store.seed, store.buckets)
+            
+            for service in self.config.http:
+                env_name = service.env or f"{_env_name(service.name)}_URL"
+                self.env[env_name] = self._start_http(service.name, service.recordings, service.port)
+            
+            for api in self.config.openapi:
+                env_name = api.env or f"{_env_name(api.name)}_URL"
+                self.env[env_name] = self._start_openapi(api.name, api.spec, api.port)
+            
+            self.env.update(self.config.env)
+        except Exception:
+            self.stop()
+            raise
+        
+        for key, value in self.env.items():
+            self._previous_env[key] = os.environ.get(key)
+            os.environ[key] = value
+        
+        return self.env
+    
+    def reset(self) -> None:
+        """
+        Roll stand-ins back to their seeded state.
+        
+        SQLite files are restored from the snapshot taken right after
+        seeding, and object storage is emptied and re-seeded.
+        """
+        for database, snapshot in self._db_snapshots:
+            shutil.copyfile(snapshot, database)
+        
+        for store_dir, seed, buckets in self._stores:
+            shutil.rmtree(store_dir, ignore_errors=True)
+            self._populate_store(store_dir, seed, buckets)
+    
+    def stop(self) -> None:
+        """Stop processes, restore the environment and remove temp files."""
+        for process in self._processes:
+            process.terminate()
+            try:
+                process.wait(timeout=5)
+            except subprocess.TimeoutExpired:
+                process.kill()
+        self._processes = []
+        
+        for key, value in self._previous_env.items():
+            if value is None:
+                os.environ.pop(key, None)
+            else:
+                os.environ[key] = value
+        self._previous_env = {}
+        
+        if self.work_dir is not None:
+            shutil.rmtree(self.work_dir, ignore_errors=True)
+        self.work_dir = None
+        self.env = {}
+        self._db_snapshots = []
+        self._stores = []
+    
+    def __enter__(self) -> "StandInManager":
+        self.start()
+        return self
+    
+    def __exit__(self, exc_type, exc, tb) -> None:
+        self.stop()
+    
+    def _start_sqlite(self, name: str, fixtures: List[str]) -> str:
+        """Create and seed a SQLite file; returns its URL."""
+        database = self.work_dir / f"{name}.sqlite3"
+        connection = sqlite3.connect(database)
+        
+        try:
+            for fixture in fixtures:
+                seed_sqlite(connection, self.project_config.resolve(fixture))
+            connection.commit()
+        finally:
+            connection.close()
+        
+        snapshot = self.work_dir / f"{name}.seeded.sqlite3"
+        shutil.copyfile(database, snapshot)
+        self._db_snapshots.append((database, snapshot))
+        
+        return f"sqlite:///{database}"
+    
+    def _start_object_storage(self, name: str, seed: Optional[str], buckets: List[str]) -> str:
+        """Create the object storage directory; returns its path."""
+        store_dir = self.work_dir / "object-storage" / name
+        seed_dir = self.project_config.resolve(seed) if seed else None
+        
+        if seed_dir is not None and not seed_dir.is_dir():
+            raise StandInError(f"Object storage seed directory not found: {seed_dir}")
+        
+        self._populate_store(store_dir, seed_dir, buckets)
+        self._stores.append((store_dir, seed_dir, buckets))
+        
+        return str(store_dir)
+    
+    def _populate_store(self, store_dir: Path, seed: Optional[Path], buckets: List[str]) -> None:
+        """Copy seed data and create bucket directories."""
+        if seed is not None:
+            shutil.copytree(seed, store_dir, dirs_exist_ok=True)
+        store_dir.mkdir(parents=True, exist_ok=True)
+        for bucket in buckets:
+            (store_dir / bucket).mkdir(exist_ok=True)
+    
+    def _start_http(self, name: str, recordings: str, port: int) -> str:
+        """Start the replay server process; returns its base URL."""
+        recordings_path = self.project_config.resolve(recordings)
+        if not recordings_path.exists():
+            raise StandInError(f"HTTP recordings not found for '{name}': {recordings_path}")
+        
+        return self._start_server_process(
+            name,
+            ["testgen.core.standins", "http", "--recordings", str(recordings_path), "--port", str(port)]
+        )
+    
+    def _start_openapi(self, name: str, spec: str, port: int) -> str:
+        """Start an OpenAPI mock server process; returns its base URL."""
+        spec_path = self.project_config.resolve(spec)
+        if not spec_path.exists():
+            raise StandInError(f"OpenAPI spec not found for '{name}': {spec_path}")
+        
+        return self._start_server_process(
+            name,
+            ["testgen.core.openapi_mock", "serve", str(spec_path), "--port", str(port)]
+        )
+    
+    def _start_server_process(self, name: str, module_args: List[str]) -> str:
+        """Run `python -m <module_args>` and wait for it to announce its port."""
+        # Make sure the child can import testgen even when running from a source checkout
+        env = os.environ.copy()
+        package_root = str(Path(__file__).resolve().parents[2])
+        env["PYTHONPATH"] = os.pathsep.join(filter(None, [package_root, env.get("PYTHONPATH")]))
+        
+        # stderr (request logs) goes to a file so a full pipe never blocks the server
+        log_path = self.work_dir / f"{name}.log"
+        with open(log_path, "w", encoding='utf-8') as log:
+            process = subprocess.Popen(
+                [sys.executable, "-m", *module_args],
+                stdout=subprocess.PIPE,
+                stderr=log,
+                text=True,
+                env=env
+            )
+        self._processes.append(process)
+        
+        # The server announces its port on the first line; the rest of stdout is drained
+        lines: queue.Queue = queue.Queue()
+        
+        def _drain() -> None:
+            for output in process.stdout:
+                lines.put(output)
+            lines.put("")
+        
+        threading.Thread(target=_drain, daemon=True).start()
+        try:
+            line = lines.get(timeout=START_TIMEOUT).strip()
+        except queue.Empty:
+            line = f"no port announced within {START_TIMEOUT}s"
+        
+        if not line.isdigit():
+            process.kill()
+            process.wait()
+            stderr = log_path.read_text(encoding='utf-8', errors='replace')
+            raise StandInError(f"Stand-in '{name}' failed to start: {stderr.strip() or line}")
+        
+        return f"http://127.0.0.1:{line}"
+
+
+def seed_sqlite(connection: sqlite3.Connection, fixture: Path) -> None:
+    """
+    Load a fixture file into a SQLite database.
+    
+    Supported formats:
+    - .sql: executed as a script
+    - .json: {"table": [{"column": value, ...}, ...], ...}
+    - .csv: rows for the table named after the file (header = columns)
+    
+    Tables missing for .json/.csv fixtures are created from the columns.
+    
+    Args:
+        connection: Open SQLite connection
+        fixture: Fixture file
+    """
+    if not fixture.exists():
+        raise StandInError(f"SQLite fixture not found: {fixture}")
+    
+    suffix = fixture.suffix.lower()
+    
+    if suffix == ".sql":
+        connection.executescript(fixture.read_text(encoding='utf-8'))
+    elif suffix == ".json":
+        data = json.loads(fixture.read_text(encoding='utf-8'))
+        for table, rows in data.items():
+            _insert_rows(connection, table, rows)
+    elif suffix == ".csv":
+        with open(fixture, newline='', encoding='utf-8') as f:
+            _insert_rows(connection, fixture.stem, list(csv.DictReader(f)))
+    else:
+        raise StandInError(f"Unsupported SQLite fixture format: {fixture}")
+
+
+def _insert_rows(connection: sqlite3.Connection, table: str, rows: List[Dict]) -> None:
+    """Insert rows, creating the table from the row keys if needed."""
+    if not rows:
+        return
+    
+    columns = list(rows[0].keys())
+    quoted_table = _quote_identifier(table)
+    quoted_columns = ", ".join(_quote_identifier(c) for c in columns)
+    
+    connection.execute(f"CREATE TABLE IF NOT EXISTS {quoted_table} ({quoted_columns})")
+    connection.executemany(
+        f"INSERT INTO {quoted_table} ({quoted_columns}) VALUES ({', '.join('?' for _ in columns)})",
+        [[row.get(c) for c in columns] for row in rows]
+    )
+
+
+def _quote_identifier(name: str) -> str:
+    return '"' + name.replace('"', '""') + '"'
+
+
+def _env_name(name: str) -> str:
+    return "".join(c if c.isalnum() else "_" for c in name).upper()
+
+
+# ===== HTTP replay service =====
+
+def load_recordings(path: Path) -> List[Dict]:
+    """
+    Load recorded exchanges from a JSON file or a directory of JSON files.
+    
+    Each recording looks like:
+    
+        {"request": {"method": "GET", "path": "/users/1", "query": {"verbose": "1"}},
+         "response": {"status": 200, "headers": {...}, "body": {...}}}
+         
+    `query` is optional; when present every listed parameter must match.
+    """
+    files = sorted(path.glob("*.json")) if path.is_dir() else [path]
+    recordings = []
+    
+    for file in files:
+        data = json.loads(file.read_text(encoding='utf-8'))
+        recordings.extend(data if isinstance(data, list) else data.get("recordings", []))
+    
+    return recordings
+
+
+class ReplayState:
+    """Recordings plus per-request replay position."""
+    
+    def __init__(self, recordings: List[Dict]):
+        self.recordings = recordings
+        self.calls: Dict[Tuple[str, str], int] = {}
+    
+    def match(self, method: str, raw_path: str) -> Optional[Dict]:
+        """
+        Find the response for a request.
+        
+        Repeated requests with several recordings replay them in order and
+        then keep returning the last one.
+        """
+        parts = urlsplit(raw_path)
+        query = dict(parse_qsl(parts.query))
+        candidates = [
+            r for r in self.recordings
+            if r.get("request", {}).get("method", "GET").upper() == method
+            and r.get("request", {}).get("path") == parts.path
+            and all(query.get(k) == str(v) for k, v in r.get("request", {}).get("query", {}).items())
+        ]
+        if not candidates:
+            return None
+        
+        key = (method, raw_path)
+        index = self.calls.get(key, 0)
+        self.calls[key] = index + 1
+        return candidates[min(index, len(candidates) - 1)]["response"]
+
+
+def create_replay_server(recordings: List[Dict], port: int = 0, host: str = "127.0.0.1") -> ThreadingHTTPServer:
+    """Create (but don't start) an HTTP server replaying recordings."""
+    state = ReplayState(recordings)
+    
+    class ReplayHandler(BaseHTTPRequestHandler):
+        def _replay(self):
+            length = int(self.headers.get("Content-Length") or 0)
+            if length:
+                self.rfile.read(length)
+            
+            response = state.match(self.command, self.path)
+            if response is None:
+                response = {
+                    "status": 404,
+                    "body": {"error": f"No recording for {self.command} {self.path}"}
+                }
+            
+            body = response.get("body", "")
+            headers = dict(response.get("headers", {}))
+            if not isinstance(body, str):
+                body = json.dumps(body)
+                headers.setdefault("Content-Type", "application/json")
+            payload = body.encode("utf-8")
+            
+            self.send_response(int(response.get("status", 200)))
+            for name, value in headers.items():
+                self.send_header(name, value)
+            self.send_header("Content-Length", str(len(payload)))
+            self.end_headers()
+            self.wfile.write(payload)
+        
+        do_GET = do_POST = do_PUT = do_PATCH = do_DELETE = do_HEAD = do_OPTIONS = _replay
+        
+        def log_message(self, format, *args):
+            pass
+    
+    return ThreadingHTTPServer((host, port), ReplayHandler)
+
+
+def main(argv: Optional[List[str]] = None) -> None:
+    """Entry point for running a stand-in as its own process."""
+    parser = argparse.ArgumentParser(description="TestGen local dependency stand-ins")
+    subparsers = parser.add_subparsers(dest="kind", required=True)
+    
+    http_parser = subparsers.add_parser("http", help="Replay recorded HTTP responses")
+    http_parser.add_argument("--recordings", required=True, help="JSON file or directory")
+    http_parser.add_argument("--port", type=int, default=0, help="Port (0 = any free port)")
+    
+    args = parser.parse_args(argv)
+    
+    server = create_replay_server(load_recordings(Path(args.recordings)), args.port)
+    print(server.server_address[1], flush=True)
+    try:
+        server.serve_forever()
+    except KeyboardInterrupt:
+        pass
+    finally:
+        server.server_close()
+
+
+if __name__ == "__main__":
+    main()
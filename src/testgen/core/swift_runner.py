@@ -1,22 +1,131 @@
-"""Swift Test Runner (XCTest)."""
+"""
+Swift Test Runner (XCTest / swift-testing).
 
+Runs `swift test --parallel --xunit-output` from the package root, which
+works on Linux toolchains as well as on macOS, and reads per-test results
+from the xUnit reports: XCTest results go to the given file, swift-testing
+results (Swift 6+) to `<name>-swift-testing.xml` next to it. Console output
+of both frameworks is parsed as a fallback.
+
+Targets are discovered from `Package.swift`.
+"""
+
+import re
 import subprocess
+import tempfile
+from dataclasses import dataclass, field
 from pathlib import Path
 from typing import List, Optional
+
 from .base_runner import BaseTestRunner, TestResults, TestResult
+from .junit_xml import parse_junit_xml_files
+
+
+# .target(name: "Core", dependencies: ["Models"], path: "Sources/Core")
+TARGET_DECLARATION = re.compile(
+    r'\.(target|testTarget|executableTarget|macro|plugin)\s*\(\s*name\s*:\s*"([^"]+)"'
+)
+
+# XCTest: Test Case 'MathTests.testAdd' passed (0.001 seconds).
+#         Test Case '-[AppTests.MathTests testAdd]' failed (0.002 seconds).
+XCTEST_CASE_LINE = re.compile(
+    r"Test Case '(?:-\[)?([\w.]+)[ .](\w+)\]?' (passed|failed|skipped) \((\d+(?:\.\d+)?) seconds\)"
+)
+
+# swift-testing: ✔ Test add() passed after 0.001 seconds.
+#                ✘ Test "Adds numbers" failed after 0.002 seconds with 1 issue.
+SWIFT_TESTING_LINE = re.compile(
+    r'^\S*\s*Test (?!run with)(.+?) '
+    r'(?:(passed|failed)(?: after (\d+(?:\.\d+)?) seconds)?(?= with|\.?$)|(skipped)\b)'
+)
+
+
+@dataclass
+class SwiftTarget:
+    """A target declared in Package.swift."""
+    
+    name: str
+    kind: str  # target, testTarget, executableTarget, macro, plugin
+    path: Path
+    dependencies: List[str] = field(default_factory=list)
+    
+    @property
+    def is_test(self) -> bool:
+        return self.kind == "testTarget"
+
+
+def find_package_root(start: str) -> Optional[Path]:
+    """Directory containing Package.swift, searching upward from `start`."""
+    path = Path(start).resolve()
+    for directory in [path, *path.parents]:
+        if (directory / "Package.swift").exists():
+            return directory
+    return None
+
+
+def discover_package_targets(package_dir: str) -> List[SwiftTarget]:
+    """
+    Read the targets declared in Package.swift.
+    
+    Handles the usual literal declarations; targets built dynamically in
+    the manifest are not seen.
+    
+    Args:
+        package_dir: Package root
+        
+    Returns:
+        Declared targets, with their source directory
+    """
+    root = Path(package_dir)
+    manifest = root / "Package.swift"
+    if not manifest.exists():
+        return []
+    
+    content = manifest.read_text(encoding='utf-8', errors='ignore')
+    matches = list(TARGET_DECLARATION.finditer(content))
+    targets = []
+    
+    for index, match in enumerate(matches):
+        kind, name = match.group(1), match.group(2)
+        # Arguments of this declaration run until the next one
+        end = matches[index + 1].start() if index + 1 < len(matches) else len(content)
+        arguments = content[match.end():end]
+        
+        path_match = re.search(r'\bpath\s*:\s*"([^"]+)"', arguments)
+        if path_match:
+            path = root / path_match.group(1)
+        else:
+            path = root / ("Tests" if kind == "testTarget" else "Plugins" if kind == "plugin" else "Sources") / name
+        
+        dependencies = []
+        deps_match = re.search(r'\bdependencies\s*:\s*\[(.*?)\]', arguments, re.DOTALL)
+        if deps_match:
+            dependencies = re.findall(r'"([^"]+)"', deps_match.group(1))
+        
+        targets.append(SwiftTarget(name=name, kind=kind, path=path, dependencies=dependencies))
+    
+    return targets
 
 
 class SwiftTestRunner(BaseTestRunner):
-    """Test runner for Swift projects using swift test."""
+    """Test runner for Swift packages using swift test (XCTest and swift-testing)."""
     
-    def __init__(self, verbose: bool = False):
+    def __init__(self, verbose: bool = False, framework: str = "xctest"):
+        """
+        Initialize Swift runner.
+        
+        Args:
+            verbose: Enable verbose output
+            framework: "xctest" or "swift-testing" (`swift test` runs both)
+        """
         super().__init__(verbose)
+        self.framework = framework
     
     def get_language(self) -> str:
         return "swift"
     
     def get_framework(self) -> str:
-        return "xctest"
+        return self.framework
     
     def get_test_patterns(self) -> List[str]:
         return ["*Tests.swift"]
@@ -33,6 +142,13 @@ class SwiftTestRunner(BaseTestRunner):
         test_path = Path(test_dir)
         if not test_path.exists():
             return []
+        
+        # A package root: only look inside its test targets
+        if (test_path / "Package.swift").exists():
+            test_targets = [t for t in discover_package_targets(str(test_path)) if t.is_test]
+            if test_targets:
+                return sorted(f for t in test_targets if t.path.exists() for f in t.path.rglob(pattern))
+        
         return sorted(list(test_path.rglob(pattern)))
     
     def count_tests(self, test_dir: str, pattern: Optional[str] = None) -> int:
@@ -42,51 +158,183 @@ class SwiftTestRunner(BaseTestRunner):
             try:
                 content = test_file.read_text()
                 for line in content.split('\n'):
-                    if 'func test' in line:
+                    stripped = line.strip()
+                    if 'func test' in line or stripped.startswith('@Test'):
                         total += 1
             except:
                 pass
         return total
     
+    def test_target_for(self, source_file: str) -> Optional[SwiftTarget]:
+        """
+        Test target that should hold tests for a source file.
+        
+        The file's target is found by path; the test target is the one
+        depending on it (or named `<Target>Tests`).
+        
+        Args:
+            source_file: Swift source file inside a package
+            
+        Returns:
+            Test target, or None if the package has none for this file
+        """
+        package_root = find_package_root(str(Path(source_file).parent))
+        if package_root is None:
+            return None
+        
+        targets = discover_package_targets(str(package_root))
+        source = Path(source_file).resolve()
+        owner = next(
+            (t for t in targets if not t.is_test and t.path.resolve() in source.parents),
+            None
+        )
+        if owner is None:
+            return None
+        
+        test_targets = [t for t in targets if t.is_test]
+        return next(
+            (t for t in test_targets if owner.name in t.dependencies),
+            next((t for t in test_targets if t.name == f"{owner.name}Tests"), None)
+        )
+    
     def build_command(self, test_dir: str, pattern: Optional[str] = None, **kwargs) -> List[str]:
         cmd = ["swift", "test"]
+        
+        # --parallel is what produces xUnit output for XCTest on Linux
+        if kwargs.get("parallel", True):
+            cmd.append("--parallel")
+            if kwargs.get("workers"):
+                cmd.extend(["--num-workers", str(kwargs["workers"])])
+        
+        xunit_output = kwargs.get("xunit_output")
+        if xunit_output:
+            cmd.extend(["--xunit-output", str(xunit_output)])
+        
+        # Test filter: a target, `Target.Class`, `Target.Class/test` or a regex
+        test_filter = kwargs.get("filter") or (pattern if pattern and not pattern.endswith(".swift") else None)
+        if test_filter:
+            cmd.extend(["--filter", test_filter])
+        
         if self.verbose:
             cmd.append("--verbose")
+        
+        cmd.extend(kwargs.get("extra_args") or [])
         return cmd
     
     def run_tests(self, test_dir: str, pattern: Optional[str] = None, **kwargs) -> TestResults:
-        cmd = self.build_command(test_dir, pattern, **kwargs)
-        try:
-            result = subprocess.run(cmd, capture_output=True, text=True, cwd=test_dir, timeout=300)
+        package_root = find_package_root(test_dir) or Path(test_dir)
+        
+        with tempfile.TemporaryDirectory(prefix="testgen-swift-") as tmp_dir:
+            xunit_output = Path(tmp_dir) / "results.xml"
+            cmd = self.build_command(test_dir, pattern, xunit_output=xunit_output, **kwargs)
+            
+            try:
+                result = subprocess.run(
+                    cmd,
+                    capture_output=True,
+                    text=True,
+                    cwd=package_root,
+                    timeout=kwargs.get("timeout", 600)
+                )
+            except subprocess.TimeoutExpired:
+                return TestResults(total=0, errors=1, language=self.get_language(), framework=self.get_framework())
+            except Exception:
+                return TestResults(total=0, errors=1, language=self.get_language(), framework=self.get_framework())
+            
+            # XCTest report plus the swift-testing one (Swift 6+)
+            reports = [
+                r for r in (xunit_output, xunit_output.with_name("results-swift-testing.xml"))
+                if r.exists()
+            ]
+            results = None
+            if reports:
+                results = parse_junit_xml_files(reports, self.get_language(), self.get_framework())
+        
+        if results is None or results.total == 0:
             return self._parse_output(result)
-        except:
-            return TestResults(total=0, errors=1, language=self.get_language(), framework=self.get_framework())
+        
+        if result.returncode != 0 and results.success:
+            # Build failure or crash without failing tests
+            results.tests.append(TestResult(
+                name="swift test",
+                status="error",
+                message=(result.stderr or result.stdout or "").strip()[-2000:] or None
+            ))
+            results.errors += 1
+            results.total += 1
+        
+        return results
+    
+    def rerun_tests(
+        self,
+        test_dir: str,
+        tests: List[TestResult],
+        pattern: Optional[str] = None,
+        **kwargs
+    ) -> TestResults:
+        """Re-run only the given tests with an anchored `--filter` of `Suite/test` ids."""
+        ids = []
+        for test in tests:
+            if not test.suite:
+                continue
+            test_id = f"{test.suite}/{test.name}"
+            if test_id not in ids:
+                ids.append(test_id)
+        
+        if not ids:
+            return super().rerun_tests(test_dir, tests, pattern, **kwargs)
+        
+        test_filter = "^(" + "|".join(re.escape(i) for i in ids) + ")$"
+        return self.run_tests(test_dir, pattern, **{**kwargs, "filter": test_filter})
     
     def _parse_output(self, result: subprocess.CompletedProcess) -> TestResults:
+        """Parse XCTest and swift-testing console output (fallback when there's no report)."""
         results = TestResults(language=self.get_language(), framework=self.get_framework())
-        output = result.stdout
+        output = (result.stdout or "") + "\n" + (result.stderr or "")
+        statuses = {"passed": "passed", "failed": "failed", "skipped": "skipped"}
+        
         for line in output.split('\n'):
-            if 'Test Suite' in line and 'passed' in line:
-                parts = line.split()
-                for i, part in enumerate(parts):
-                    if 'passed' in part.lower() and i > 0:
-                        try:
-                            results.passed = int(parts[i-1])
-                        except:
-                            pass
-                    elif 'failed' in part.lower() and i > 0:
-                        try:
-                            results.failed = int(parts[i-1])
-                        except:
-                            pass
-        results.total = results.passed + results.failed
-        if results.total == 0 and result.returncode == 0:
-            results.passed = results.total = 1
+            xctest = XCTEST_CASE_LINE.search(line)
+            if xctest:
+                suite, name, status, seconds = xctest.groups()
+                results.tests.append(TestResult(
+                    name=name,
+                    status=statuses[status],
+                    duration=float(seconds),
+                    suite=suite
+                ))
+                continue
+            
+            swift_testing = SWIFT_TESTING_LINE.search(line.strip())
+            if swift_testing:
+                name, status, seconds, skipped = swift_testing.groups()
+                results.tests.append(TestResult(
+                    name=name.strip('"'),
+                    status=statuses[status or skipped],
+                    duration=float(seconds or 0.0)
+                ))
+        
+        for test in results.tests:
+            if test.status == "passed":
+                results.passed += 1
+            elif test.status == "failed":
+                results.failed += 1
+            else:
+                results.skipped += 1
+        results.total = len(results.tests)
+        
+        if results.total == 0:
+            if result.returncode == 0:
+                results.passed = results.total = 1
+            else:
+                results.errors = 1
         return results
     
     def validate_test_file(self, test_file: str) -> bool:
         try:
             content = Path(test_file).read_text()
+            if 'import Testing' in content and '@Test' in content:
+                return True
             return 'XCTest' in content and 'func test' in content
         except:
             return False
@@ -0,0 +1,529 @@
+"""
+Table-Driven Refactoring of Go Tests.
+
+Finds clusters of structurally similar Go test functions, ones that
+differ only in literal values such as `2 + 2` vs `10 - 5` or `4` vs `5`,
+and rewrites each cluster as one table-driven test with a `t.Run`
+subtest per original function:
+
+    func TestAdditionPass(t *testing.T) { ... 2 + 2 ... }
+    func TestSubtractionPass(t *testing.T) { ... 10 - 5 ... }
+
+    ->  func TestPass(t *testing.T) {
+            tests := []struct{ name string; result int; ... }{
+                {name: "Addition", result: 2 + 2, ...},
+                {name: "Subtraction", result: 10 - 5, ...},
+            }
+            for _, tc := range tests {
+                t.Run(tc.name, func(t *testing.T) { ... tc.result ... })
+            }
+        }
+
+Behavior is verified before anything is proposed: the original tests and
+the rewrite (swapped in with `go test -overlay`, the tree isn't touched)
+are run and their per-case outcomes compared. Clusters whose outcomes
+differ, or that don't compile, are left alone. The result is a unified
+diff for review.
+"""
+
+import difflib
+import json
+import re
+import shutil
+import subprocess
+import tempfile
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Dict, List, Optional, Tuple
+
+from .go_runner import GoTestRunner
+
+
+# func TestXxx(t *testing.T) {
+GO_TEST_FUNC = re.compile(r'^func\s+(Test[A-Z0-9_]\w*)\s*\(\s*(\w+)\s+\*testing\.T\s*\)\s*\{[ \t]*$', re.MULTILINE)
+
+GO_TOKEN = re.compile(
+    r'(?P<comment>//[^\n]*|/\*.*?\*/)'
+    r'|(?P<string>`[^`]*`|"(?:[^"\\\n]|\\.)*")'
+    r'|(?P<rune>\'(?:[^\'\\\n]|\\.)+\')'
+    r'|(?P<number>0[xX][0-9a-fA-F_]+|0[bB][01_]+|0[oO][0-7_]+|(?:\d[\d_]*(?:\.\d*)?|\.\d+)(?:[eE][+-]?\d+)?i?)'
+    r'|(?P<ident>[A-Za-z_]\w*)'
+    r'|(?P<newline>\n)'
+    r'|(?P<space>[ \t\r]+)'
+    r'|(?P<op><<=|>>=|&\^=|\.\.\.|&&|\|\||<-|<<|>>|&\^|[-+*/%&|^<>=!:]=|\+\+|--|[-+*/%&|^<>=!~.,;:()\[\]{}])',
+    re.DOTALL
+)
+
+# Operators that bind a literal to its neighbours (a span next to one can't be lifted whole)
+ARITHMETIC = {"+", "-", "*", "/", "%", "<<", ">>", "&", "|", "^", "&^", "."}
+
+FIELD_TYPES = {
+    frozenset({"int"}): "int",
+    frozenset({"float"}): "float64",
+    frozenset({"int", "float"}): "float64",
+    frozenset({"string"}): "string",
+    frozenset({"rune"}): "rune",
+    frozenset({"bool"}): "bool",
+}
+
+LOOP_NAMES = [("tests", "tc"), ("cases", "c"), ("table", "row")]
+
+
+@dataclass
+class Token:
+    """A Go token (literals spanning a constant expression are one token)."""
+    
+    kind: str       # "const", "ident", "op", "comment", "newline"
+    text: str
+    start: int      # offsets into the function body
+    end: int
+    literal: Optional[str] = None   # for consts: "int", "float", "string", "rune", "bool"
+
+
+@dataclass
+class GoTestFunc:
+    """A top-level Go test function."""
+    
+    name: str
+    param: str
+    start: int          # offset of the doc comment (or `func`)
+    end: int            # offset after the closing brace
+    body: str           # between the braces
+    doc: List[str]      # doc comment lines
+    tokens: List[Token] = field(default_factory=list)
+    
+    @property
+    def shape(self) -> Tuple:
+        """Structure with literal values left out."""
+        return tuple((t.kind, None if t.kind == "const" else t.text) for t in self.tokens)
+
+
+@dataclass
+class TestCluster:
+    """Structurally similar tests that can become one table-driven test."""
+    
+    functions: List[GoTestFunc]
+    name: str = ""
+    case_names: List[str] = field(default_factory=list)
+    fields: List[Tuple[str, str, int]] = field(default_factory=list)  # (name, Go type, token index)
+    source: str = ""                 # the table-driven test
+    verified: bool = False
+    outcomes: Dict[str, Tuple[str, str]] = field(default_factory=dict)  # original -> (before, after)
+    problem: Optional[str] = None
+    
+    @property
+    def original_names(self) -> List[str]:
+        return [f.name for f in self.functions]
+    
+    def subtest(self, original: str) -> str:
+        """The subtest an original test became (as `go test` names it)."""
+        return f"{self.name}/{self.case_names[self.original_names.index(original)]}"
+
+
+@dataclass
+class RefactorResult:
+    """Outcome of refactoring one Go test file."""
+    
+    path: Path
+    original: str
+    rewritten: str
+    clusters: List[TestCluster]
+    
+    @property
+    def applied(self) -> List[TestCluster]:
+        return [c for c in self.clusters if c.verified]
+    
+    @property
+    def rejected(self) -> List[TestCluster]:
+        return [c for c in self.clusters if not c.verified]
+    
+    @property
+    def patch(self) -> str:
+        return make_patch(self.original, self.rewritten, self.path)
+
+
+def find_test_functions(code: str) -> List[GoTestFunc]:
+    """
+    Find top-level `func TestXxx(t *testing.T)` functions.
+    
+    Args:
+        code: Go test file source
+        
+    Returns:
+        Test functions with tokenized bodies, in source order
+    """
+    functions = []
+    for match in GO_TEST_FUNC.finditer(code):
+        body_start = match.end()
+        closing = re.compile(r'^\}[ \t]*$', re.MULTILINE).search(code, body_start)  # gofmt'd closing brace
+        if closing is None:
+            continue
+        
+        # Doc comment lines directly above
+        start = match.start()
+        doc: List[str] = []
+        lines = code[:start].split("\n")[:-1]
+        while lines and lines[-1].startswith("//"):
+            doc.insert(0, lines.pop())
+            start -= len(doc[0]) + 1
+        
+        body = code[body_start:closing.start()]
+        functions.append(GoTestFunc(
+            name=match.group(1),
+            param=match.group(2),
+            start=start,
+            end=closing.end(),
+            body=body,
+            doc=doc,
+            tokens=tokenize(body),
+        ))
+    return functions
+
+
+def tokenize(body: str) -> List[Token]:
+    """
+    Tokenize a Go function body, joining constant expressions.
+    
+    Literals joined by arithmetic operators (`2 + 2`, `-1`, `1 << 10`)
+    become one `const` token when nothing outside binds tighter, so
+    `result := 2 + 2` and `result := 10 - 5` have the same shape.
+    """
+    raw: List[Token] = []
+    for match in GO_TOKEN.finditer(body):
+        kind = match.lastgroup
+        text = match.group()
+        if kind == "space":
+            continue
+        if kind in ("string", "rune", "number") or (kind == "ident" and text in ("true", "false")):
+            literal = {"string": "string", "rune": "rune"}.get(kind) or (
+                "bool" if kind == "ident" else "float" if re.search(r'[.eE]', text) and not text.startswith(("0x", "0X")) else "int"
+            )
+            raw.append(Token("const", text, match.start(), match.end(), literal))
+        elif kind == "newline":
+            if raw and raw[-1].kind != "newline":
+                raw.append(Token("newline", "\n", match.start(), match.end()))
+        else:
+            raw.append(Token(kind, text, match.start(), match.end()))
+    
+    tokens: List[Token] = []
+    index = 0
+    while index < len(raw):
+        token = raw[index]
+        unary = (
+            token.kind == "op" and token.text == "-"
+            and index + 1 < len(raw) and raw[index + 1].kind == "const"
+            and (not tokens or tokens[-1].kind in ("op", "newline") and tokens[-1].text not in (")", "]", "}")
+                 or tokens[-1].text == "return")
+        )
+        if token.kind != "const" and not unary:
+            tokens.append(token)
+            index += 1
+            continue
+        
+        # Extend over `literal (op literal)*`
+        end = index + 2 if unary else index + 1
+        kinds = {raw[end - 1].literal}
+        while (
+            end + 1 < len(raw) and raw[end].kind == "op" and raw[end].text in ARITHMETIC - {"."}
+            and raw[end + 1].kind == "const"
+        ):
+            kinds.add(raw[end + 1].literal)
+            end += 2
+        
+        before = tokens[-1].text if tokens else ""
+        after = raw[end].text if end < len(raw) else ""
+        operands = (end - index + (0 if unary else 1)) // 2
+        bound = before in ARITHMETIC or after in ARITHMETIC | {"(", "["}
+        if operands > 1 and bound or len(kinds) > 1 and frozenset(kinds) not in FIELD_TYPES:
+            end = index + 2 if unary else index + 1  # Only the first operand
+            kinds = {raw[end - 1].literal}
+        
+        literal = FIELD_TYPES.get(frozenset(kinds))
+        tokens.append(Token(
+            "const", body[raw[index].start:raw[end - 1].end], raw[index].start, raw[end - 1].end,
+            {"float64": "float"}.get(literal, literal)
+        ))
+        index = end
+    return tokens
+
+
+def find_test_clusters(code: str) -> List[TestCluster]:
+    """
+    Group test functions with the same shape.
+    
+    Args:
+        code: Go test file source
+        
+    Returns:
+        Clusters of two or more functions, with their table-driven rewrite
+    """
+    functions = find_test_functions(code)
+    groups: Dict[Tuple, List[GoTestFunc]] = {}
+    for function in functions:
+        if any(t.kind == "const" and t.literal == "string" and t.text.startswith("`") and "\n" in t.text
+               for t in function.tokens):
+            continue  # Re-indenting would change multi-line raw strings
+        groups.setdefault((function.param, function.shape), []).append(function)
+    
+    existing = {f.name for f in functions} | set(re.findall(r'^func\s+(\w+)', code, re.MULTILINE))
+    clusters = []
+    for members in groups.values():
+        if len(members) < 2:
+            continue
+        cluster = TestCluster(functions=members)
+        if _plan(cluster, existing):
+            existing.add(cluster.name)
+            clusters.append(cluster)
+    return clusters
+
+
+def rewrite(code: str, clusters: List[TestCluster]) -> str:
+    """
+    Replace each cluster's functions with its table-driven test.
+    
+    The table-driven test takes the place of the cluster's first function.
+    
+    Args:
+        code: Go test file source
+        clusters: Clusters to apply
+        
+    Returns:
+        Rewritten source
+    """
+    edits: List[Tuple[int, int, str]] = []
+    for cluster in clusters:
+        for position, function in enumerate(cluster.functions):
+            end = function.end
+            # Take the blank line after removed functions with them
+            if position > 0 and code[end:end + 2] == "\n\n":
+                end += 2
+            edits.append((function.start, end, cluster.source if position == 0 else ""))
+    
+    for start, end, replacement in sorted(edits, reverse=True):
+        code = code[:start] + replacement + code[end:]
+    return code
+
+
+def verify_cluster(test_file: Path, original: str, cluster: TestCluster) -> bool:
+    """
+    Run a cluster's tests before and after the rewrite and compare per-case outcomes.
+    
+    The rewrite is swapped in with `go test -overlay`, so the file on
+    disk is never modified. Sets `cluster.verified`, `outcomes` and
+    `problem`.
+    
+    Args:
+        test_file: The Go test file
+        original: Its current source
+        cluster: Cluster to verify
+        
+    Returns:
+        Whether every case has the same outcome
+    """
+    runner = GoTestRunner()
+    package_dir = str(test_file.parent)
+    names = "|".join(cluster.original_names)
+    
+    before = runner.run_tests(
+        package_dir, run=f"^({names})$", packages=["."], extra_args=["-count=1"], measure_usage=False
+    )
+    before_outcomes = {t.name: t.status for t in before.tests}
+    if not all(name in before_outcomes for name in cluster.original_names):
+        details = next((t.message for t in before.tests if t.status == "error" and t.message), None) or "no results"
+        cluster.problem = "original tests didn't run: " + details.strip().splitlines()[-1]
+        return False
+    
+    with tempfile.TemporaryDirectory(prefix="testgen-refactor-") as scratch:
+        rewritten = Path(scratch) / test_file.name
+        rewritten.write_text(rewrite(original, [cluster]), encoding='utf-8')
+        overlay = Path(scratch) / "overlay.json"
+        overlay.write_text(json.dumps({"Replace": {str(test_file.resolve()): str(rewritten)}}), encoding='utf-8')
+        after = runner.run_tests(
+            package_dir, run=f"^{cluster.name}$", packages=["."],
+            extra_args=["-count=1", f"-overlay={overlay}"], measure_usage=False
+        )
+        if any(t.status == "error" for t in after.tests):
+            # The compiler's message goes to stderr, not the -json stream
+            build = subprocess.run(
+                ["go", "vet", f"-overlay={overlay}", "."],
+                capture_output=True, text=True, cwd=package_dir, timeout=300
+            )
+            compiler = [
+                re.sub(rf'^(?:vet:\s*)?\S*{re.escape(test_file.name)}', test_file.name, line.strip())
+                for line in build.stderr.splitlines() if re.search(r'\.go:\d+', line)
+            ]
+            cluster.problem = "rewrite doesn't build: " + (compiler or ["build failed"])[0]
+            return False
+    after_outcomes = {t.name: t.status for t in after.tests}
+    
+    cluster.outcomes = {
+        name: (before_outcomes.get(name, "not run"), after_outcomes.get(cluster.subtest(name), "not run"))
+        for name in cluster.original_names
+    }
+    changed = [f"{name}: {b} -> {a}" for name, (b, a) in cluster.outcomes.items() if b != a]
+    if changed:
+        cluster.problem = "outcomes differ: " + ", ".join(changed)
+        return False
+    
+    cluster.verified = True
+    return True
+
+
+def refactor_file(test_file: str, verify: bool = True) -> RefactorResult:
+    """
+    Rewrite repetitive tests in a Go test file as table-driven tests.
+    
+    Args:
+        test_file: Path of a `_test.go` file
+        verify: Run the tests before and after (needs the Go toolchain);
+            without it every cluster is applied unverified
+            
+    Returns:
+        RefactorResult with the rewritten source and a patch (the file isn't modified)
+    """
+    path = Path(test_file)
+    original = path.read_text(encoding='utf-8')
+    clusters = find_test_clusters(original)
+    
+    for cluster in clusters:
+        if verify:
+            verify_cluster(path, original, cluster)
+        else:
+            cluster.verified = True
+    
+    rewritten = rewrite(original, [c for c in clusters if c.verified])
+    return RefactorResult(path=path, original=original, rewritten=_gofmt(rewritten), clusters=clusters)
+
+
+def make_patch(original: str, rewritten: str, path: Path) -> str:
+    """Unified diff (git style paths) of a rewrite."""
+    name = str(path).lstrip("/")
+    return "".join(difflib.unified_diff(
+        original.splitlines(keepends=True),
+        rewritten.splitlines(keepends=True),
+        fromfile=f"a/{name}",
+        tofile=f"b/{name}",
+    ))
+
+
+def _plan(cluster: TestCluster, existing: set) -> bool:
+    """Work out the table (names, fields) and source of a cluster; False if it can't be expressed."""
+    functions = cluster.functions
+    tokens = [f.tokens for f in functions]
+    
+    # Test and case names from the common CamelCase prefix/suffix words
+    words = [re.findall(r'[A-Z][a-z0-9]*|[a-z0-9]+|_+', f.name[len("Test"):]) for f in functions]
+    prefix = 0
+    while all(len(w) > prefix + 1 for w in words) and len({tuple(w[:prefix + 1]) for w in words}) == 1:
+        prefix += 1
+    suffix = 0
+    while all(len(w) > prefix + suffix + 1 for w in words) and len({tuple(w[len(w) - suffix - 1:]) for w in words}) == 1:
+        suffix += 1
+    cluster.case_names = ["".join(w[prefix:len(w) - suffix]).strip("_") or f.name for w, f in zip(words, functions)]
+    if len(set(cluster.case_names)) < len(functions):
+        return False
+    name = "Test" + "".join(words[0][:prefix]) + "".join(words[0][len(words[0]) - suffix:]) if suffix else "Test" + "".join(words[0][:prefix])
+    if name in ("Test", *existing) or not re.match(r'^Test[A-Z0-9_]', name):
+        name = functions[0].name + "Table"
+    if name in existing:
+        return False
+    cluster.name = name
+    
+    # Fields: const positions whose value differs between functions
+    identifiers = {t.text for f in functions for t in f.tokens if t.kind == "ident"}
+    table, row = next(((a, b) for a, b in LOOP_NAMES if a not in identifiers and b not in identifiers), (None, None))
+    if table is None:
+        return False
+    
+    fields: List[Tuple[str, str, int]] = []
+    used = {"name"}
+    for index, token in enumerate(tokens[0]):
+        if token.kind != "const" or len({t[index].text for t in tokens}) == 1:
+            continue
+        go_type = FIELD_TYPES.get(frozenset(t[index].literal for t in tokens))
+        if go_type is None:
+            return False
+        field_name = _field_name(tokens[0], index, used)
+        used.add(field_name)
+        fields.append((field_name, go_type, index))
+    cluster.fields = fields
+    
+    cluster.source = _table_source(cluster, table, row)
+    return True
+
+
+def _field_name(tokens: List[Token], index: int, used: set) -> str:
+    """Name a table field after the literal's context."""
+    previous = [t for t in tokens[:index] if t.kind != "newline"]
+    base = "value"
+    if len(previous) >= 2 and previous[-1].text in (":=", "=") and previous[-2].kind == "ident":
+        base = previous[-2].text
+    elif previous and previous[-1].text in ("==", "!=", "<", ">", "<=", ">="):
+        base = "want"
+    elif len(previous) >= 3 and previous[-1].text == "(" and previous[-2].kind == "ident" and previous[-3].text == "." \
+            and re.match(r'^(?:Errorf|Fatalf|Logf|Skipf)$', previous[-2].text):
+        base = "format"
+    elif len(previous) >= 2 and previous[-1].text in ("(", ",") and previous[-2].kind == "ident":
+        base = "arg"
+    elif previous and previous[-1].text == "return":
+        base = "result"
+    base = re.sub(r'^[A-Z]', lambda m: m.group().lower(), base)
+    
+    candidate = base
+    number = 2
+    while candidate in used or candidate in ("tc", "tests"):
+        candidate = f"{base}{number}"
+        number += 1
+    return candidate
+
+
+def _table_source(cluster: TestCluster, table: str, row: str) -> str:
+    """Source of the table-driven test."""
+    first = cluster.functions[0]
+    body = first.body
+    for field_name, _, index in sorted(cluster.fields, key=lambda f: -f[2]):
+        token = first.tokens[index]
+        body = body[:token.start] + f"{row}.{field_name}" + body[token.end:]
+    
+    width = max(len(n) for n in ["name"] + [f[0] for f in cluster.fields])
+    struct_fields = "".join(
+        f"\t\t{n.ljust(width)} {t}\n" for n, t in [("name", "string")] + [(f[0], f[1]) for f in cluster.fields]
+    )
+    
+    cases = []
+    for function, case_name in zip(cluster.functions, cluster.case_names):
+        values = [f'name: "{case_name}"'] + [f"{n}: {function.tokens[i].text}" for n, _, i in cluster.fields]
+        doc = "".join(f"\t\t{line.strip()}\n" for line in function.doc)
+        cases.append(f"{doc}\t\t{{{', '.join(values)}}},\n")
+    
+    indented = "".join(
+        ("\t\t" + line if line.strip() else "") + "\n"
+        for line in body.strip("\n").split("\n")
+    )
+    parallel = f"\t\t{row} := {row}\n" if re.search(rf'\b{first.param}\.Parallel\(\)', body) else ""
+    
+    return (
+        f"func {cluster.name}({first.param} *testing.T) {{\n"
+        f"\t{table} := []struct {{\n{struct_fields}\t}}{{\n"
+        + "".join(cases)
+        + f"\t}}\n"
+        f"\tfor _, {row} := range {table} {{\n"
+        + parallel
+        + f"\t\t{first.param}.Run({row}.name, func({first.param} *testing.T) {{\n"
+        + indented
+        + "\t\t})\n"
+        "\t}\n"
+        "}"
+    )
+
+
+def _gofmt(code: str) -> str:
+    """Format with gofmt when it's available (keeps the patch minimal)."""
+    gofmt = shutil.which("gofmt")
+    if gofmt is None:
+        return code
+    try:
+        result = subprocess.run([gofmt], input=code, capture_output=True, text=True, timeout=30)
+    except (OSError, subprocess.TimeoutExpired):
+        return code
+    return result.stdout if result.returncode == 0 else code
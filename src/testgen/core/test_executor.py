@@ -10,12 +10,21 @@ Runs tests with appropriate settings based on test type and language:
 """
 
 from pathlib import Path
-from typing import Dict, List, Optional, Any
-from dataclasses import dataclass, field
+from typing import Dict, List, Optional, Any, Tuple
+from dataclasses import dataclass, field, replace
 from enum import Enum
 
 from .test_detector import UniversalTestTypeDetector, TestType
-from .base_runner import BaseTestRunner, TestResults
+from .base_runner import BaseTestRunner, TestResults, TestResult, TestAttempt
+from .benchmark import BenchmarkHarness
+from .load_test import LOAD_TEST_FILE, LoadTester
+from .result_models import BenchmarkResult
+from .project_config import ProjectConfig, load_project_config
+from .standins import StandInManager
+from .run_store import RunStore
+from .resource_usage import ChildUsageMeter, merge_usage
+from .test_ordering import TestOrderer, get_changed_files
+from .go_generate import GeneratedPackage, check_generated, find_module_root, stale_packages, stale_symbols
 
 
 @dataclass
@@ -43,20 +52,35 @@ class TestExecutionConfig:
     viewport_width: int = 1280
     viewport_height: int = 720
     
-    # Integration test specific
-    setup_fixtures: bool = True
-    teardown_after: bool = True
-    database_rollback: bool = True
+    # Integration test specific (stand-ins declared in project config)
+    setup_fixtures: bool = True  # Start stand-ins before integration tests
+    teardown_after: bool = True  # Stop stand-ins afterwards
+    database_rollback: bool = True  # Reset stand-in state after each test file
     
     # Performance test specific
     iterations: int = 100
     warmup_iterations: int = 10
     profile: bool = False
+    go_benchtime: Optional[str] = None  # Go -benchtime ("2s", "1000x"); default lets go test calibrate b.N
     
-    # Retry settings
+    # Go: per-package CPU, peak RSS and writes through a `go test -exec` wrapper (slower, opt-in)
+    measure_usage: bool = False
+    
+    # Load test specific (generated Go httptest load tests)
+    load_concurrency: int = 10  # concurrent clients
+    load_duration: float = 10.0  # seconds of requests per load test
+    
+    # Retry settings (applied by UniversalTestExecutor for every runner)
     retry_failed: bool = False
     max_retries: int = 2
     
+    # Ordered runs (failing tests first): stop after this many failures (0 = run everything)
+    max_failures: int = 0
+    
+    # Go: check //go:generate output before running (regenerate: run go generate in a scratch copy)
+    check_generated: bool = True
+    regenerate: bool = False
+    
     # Output settings
     capture_output: bool = True
     json_report: bool = True
@@ -116,9 +140,6 @@ class TestExecutionConfig:
             
             args.extend(["--browser", self.browser])
         
-        if self.retry_failed:
-            args.extend(["--reruns", str(self.max_retries)])
-        
         if self.json_report:
             args.append("--json-report")
         
@@ -229,7 +250,8 @@ class UniversalTestExecutor:
     def __init__(
         self,
         runner: BaseTestRunner,
-        detector: Optional[UniversalTestTypeDetector] = None
+        detector: Optional[UniversalTestTypeDetector] = None,
+        project_config: Optional[ProjectConfig] = None
     ):
         """
         Initialize executor.
@@ -237,12 +259,15 @@ class UniversalTestExecutor:
         Args:
             runner: Test runner instance
             detector: Test type detector (optional)
+            project_config: Project config (default: loaded from the test location)
         """
         self.runner = runner
         self.detector = detector or UniversalTestTypeDetector(
             runner.get_language(),
             runner.get_framework()
         )
+        self.project_config = project_config
+        self.standins: Optional[StandInManager] = None
         self.default_configs = self._create_default_configs()
     
     def _create_default_configs(self) -> Dict[TestType, TestExecutionConfig]:
@@ -328,12 +353,21 @@ class UniversalTestExecutor:
             test_type
         )
         
+        # Integration tests get their local dependency stand-ins
+        standins, started_here = None, False
+        if test_type == TestType.INTEGRATION:
+            standins, started_here = self._start_standins(test_file, config)
+        
         # Execute
-        return self.runner.run_tests(
-            str(Path(test_file).parent),
-            pattern=Path(test_file).name,
-            **{"extra_args": extra_args}
-        )
+        try:
+            return self.run_with_retries(
+                str(Path(test_file).parent),
+                config,
+                pattern=Path(test_file).name,
+                extra_args=extra_args
+            )
+        finally:
+            self._finish_standins(standins, started_here, config)
     
     def execute_ui_tests(
         self,
@@ -411,18 +445,95 @@ class UniversalTestExecutor:
             headless=True
         )
         
-        return self.runner.run_tests(test_dir, **{
-            "extra_args": config.to_command_args(
+        return self.run_with_retries(
+            test_dir,
+            config,
+            extra_args=config.to_command_args(
                 self.runner.get_language(),
                 self.runner.get_framework(),
                 TestType.UNIT
             )
+        )
+    
+    def execute_performance_tests(
+        self,
+        test_path: str,
+        config: Optional[TestExecutionConfig] = None
+    ) -> List[BenchmarkResult]:
+        """
+        Benchmark performance tests with warmup and measured iterations.
+        
+        Python/JS performance-classified test files are timed by the
+        benchmark harness; Go packages run their native benchmarks.
+        
+        Args:
+            test_path: Test file or directory
+            config: Override default performance config (iterations, warmup, profile)
+            
+        Returns:
+            BenchmarkResult per benchmarked test
+        """
+        config = config or self.default_configs[TestType.PERFORMANCE]
+        language = self.runner.get_language()
+        harness = BenchmarkHarness.from_config(config, language, self.runner.get_framework())
+        path = Path(test_path)
+        
+        if language == "go":
+            package_dirs = [path.parent] if path.is_file() else sorted({
+                f.parent for f in path.rglob("*_test.go")
+                if "vendor" not in f.parts
+            })
+            results = []
+            for package_dir in package_dirs:
+                results.extend(harness.run_go(str(package_dir)))
+            return results
+        
+        if path.is_file():
+            test_files = [path]
+        else:
+            test_files = self.detector.classify_directory(str(path)).get(TestType.PERFORMANCE, [])
+        
+        results = []
+        for test_file in test_files:
+            results.extend(harness.run(str(test_file)))
+        return results
+    
+    def execute_load_tests(
+        self,
+        test_path: str,
+        config: Optional[TestExecutionConfig] = None
+    ) -> List[BenchmarkResult]:
+        """
+        Run generated Go load tests (`testgen_load_test.go`) in-process
+        against httptest servers.
+        
+        Args:
+            test_path: Package directory, or a directory tree of packages
+            config: Override default performance config (load_concurrency, load_duration)
+            
+        Returns:
+            BenchmarkResult per load test, with throughput and error rate
+        """
+        config = config or self.default_configs[TestType.PERFORMANCE]
+        tester = LoadTester(concurrency=config.load_concurrency, duration=config.load_duration)
+        path = Path(test_path)
+        
+        package_dirs = sorted({
+            f.parent for f in path.rglob(LOAD_TEST_FILE)
+            if "vendor" not in f.parts
         })
+        results = []
+        for package_dir in package_dirs:
+            results.extend(tester.run_go(str(package_dir)))
+        return results
     
     def execute_all_with_optimization(self, test_dir: str) -> Dict[TestType, TestResults]:
         """
         Execute all tests with optimized configuration per type.
         
+        Go runs check the module's `go generate` output once, before the
+        first test file; every type's results report what is stale.
+        
         Args:
             test_dir: Directory containing all tests
             
@@ -434,22 +545,318 @@ class UniversalTestExecutor:
         
         results_by_type = {}
         
+        # Checked once for the whole run, not per test file
+        generated = self._check_generated(test_dir, self.default_configs[TestType.UNIT])
+        
         # Execute each type with appropriate config
         for test_type, test_files in classifications.items():
             if not test_files or test_type == TestType.UNKNOWN:
                 continue
             
-            config = self.default_configs.get(test_type)
+            config = replace(
+                self.default_configs.get(test_type, self.default_configs[TestType.UNIT]),
+                check_generated=False
+            )
             type_results = []
             
-            for test_file in test_files:
-                result = self.execute_with_auto_config(str(test_file), config)
-                type_results.append(result)
+            # Start stand-ins once for all integration files
+            standins, started_here = None, False
+            if test_type == TestType.INTEGRATION:
+                standins, started_here = self._start_standins(test_dir, config)
+            
+            try:
+                for test_file in test_files:
+                    result = self.execute_with_auto_config(str(test_file), config)
+                    type_results.append(result)
+            finally:
+                if started_here and config.teardown_after:
+                    standins.stop()
             
             results_by_type[test_type] = self._aggregate_results(type_results)
+            results_by_type[test_type].stale_generated = stale_packages(generated)
+            results_by_type[test_type].stale_symbols = stale_symbols(generated)
         
         return results_by_type
     
+    def execute_ordered(
+        self,
+        test_dir: str,
+        config: Optional[TestExecutionConfig] = None,
+        run_store: Optional[RunStore] = None,
+        changed_files: Optional[List[Path]] = None
+    ) -> TestResults:
+        """
+        Run tests failure-first for the fastest useful signal.
+        
+        Order: tests that failed in the last run, then tests impacted by
+        local changes, then everything else by ascending duration. With
+        `config.max_failures` set, the run stops once that many tests have
+        failed (`TestResults.stopped_early`). Go packages whose `go generate`
+        output is stale are reported in `TestResults.stale_generated`, so
+        their failures aren't taken for logic failures. The run is recorded
+        in the run store so the next run can be ordered the same way, with
+        its artifacts: runner output and reports, golden diffs and a
+        snapshot of the generated tests it ran.
+        
+        Args:
+            test_dir: Directory containing tests (Go: module root)
+            config: Execution config (max_failures, retries, check_generated)
+            run_store: Run history (default: `.testgen-cache/runs`)
+            changed_files: Locally changed files (default: from git)
+            
+        Returns:
+            Aggregated TestResults of all executed steps
+        """
+        import tempfile
+        
+        config = config or TestExecutionConfig()
+        run_store = run_store or RunStore()
+        language = self.runner.get_language()
+        framework = self.runner.get_framework()
+        
+        if changed_files is None:
+            changed_files = get_changed_files(test_dir)
+        
+        # Checked once for the whole run, not per step
+        generated = self._check_generated(test_dir, config)
+        step_config = replace(config, check_generated=False)
+        
+        orderer = TestOrderer(run_store.test_history(language), changed_files)
+        test_files = [] if framework == "testing" else self.runner.discover_tests(test_dir)
+        steps = orderer.plan(framework, test_dir, test_files)
+        
+        step_results = []
+        failures = 0
+        stopped_early = False
+        
+        artifacts = run_store.new_artifacts()
+        # Go steps select by package and name: the run covers every test file
+        artifacts.add_snapshot(Path(test_dir), test_files or Path(test_dir).rglob("*_test.go"))
+        self.runner.artifacts = artifacts
+        
+        try:
+            with tempfile.TemporaryDirectory() as tmp_dir:
+                for index, step in enumerate(steps):
+                    if config.max_failures and failures >= config.max_failures:
+                        stopped_early = True
+                        break
+                    
+                    remaining = config.max_failures - failures if config.max_failures else 0
+                    kwargs = step.runner_kwargs(framework, test_dir, remaining)
+                    if framework == "pytest":
+                        # Per-test outcomes and durations feed the next ordering
+                        kwargs["json_report"] = True
+                        kwargs["json_report_file"] = str(Path(tmp_dir) / f"step-{index}.json")
+                    
+                    if config.verbose:
+                        print(f"Running {step.label}")
+                    
+                    result = self.run_with_retries(config=step_config, **kwargs)
+                    step_results.append(result)
+                    failures += result.failed + result.errors
+        finally:
+            self.runner.artifacts = None
+        
+        results = self._aggregate_results(step_results)
+        results.stopped_early = stopped_early
+        results.stale_generated = stale_packages(generated)
+        results.stale_symbols = stale_symbols(generated)
+        artifacts.add_golden_diffs(results)
+        
+        run_store.record_run(results, test_dir=test_dir, metadata={
+            "ordered": True,
+            "max_failures": config.max_failures,
+            "stopped_early": results.stopped_early,
+            "stale_generated": results.stale_generated,
+        }, artifacts=artifacts)
+        
+        return results
+    
+    def run_with_retries(
+        self,
+        test_dir: str,
+        config: TestExecutionConfig,
+        pattern: Optional[str] = None,
+        **kwargs
+    ) -> TestResults:
+        """
+        Run tests and retry only the failed ones, for any runner.
+        
+        Every attempt is recorded on the test (`TestResult.attempts`). Tests
+        that fail first and pass on a later attempt get the status
+        "passed_on_retry" instead of "passed", so they remain visible as
+        flaky rather than being hidden by the retry. Tests are matched to the
+        retry's results by suite and name; a test the retry doesn't report
+        stays failed. Package-level errors (a Go build failure) aren't retried.
+        Go runs first check the module's `go generate` output and report
+        stale packages in `TestResults.stale_generated` (see `execute_ordered`).
+        
+        Args:
+            test_dir: Directory containing tests
+            config: Execution config (retry_failed, max_retries, check_generated)
+            pattern: File/test pattern
+            **kwargs: Runner arguments (e.g. extra_args)
+            
+        Returns:
+            TestResults with final statuses and per-attempt records
+        """
+        if config.measure_usage and self.runner.get_framework() == "testing":
+            kwargs = {**kwargs, "measure_usage": True}
+        
+        generated = self._check_generated(test_dir, config)
+        with ChildUsageMeter() as meter:
+            results = self.runner.run_tests(test_dir, pattern, **kwargs)
+        results.stale_generated = stale_packages(generated)
+        results.stale_symbols = stale_symbols(generated)
+        
+        # Runners without per-suite accounting: usage of the whole runner subprocess
+        if not results.resource_usage and meter.usage is not None:
+            results.resource_usage[pattern or str(test_dir)] = meter.usage
+        
+        if not config.retry_failed or config.max_retries <= 0 or results.success:
+            return results
+        
+        for test in results.tests:
+            test.attempts.append(TestAttempt(
+                attempt=1,
+                status=test.status,
+                duration=test.duration,
+                message=test.message
+            ))
+        
+        # Package-level errors (build failures, panics in init) aren't tests: a retry can't select them
+        failing = [
+            t for t in results.tests
+            if t.status in ("failed", "error") and not (t.suite and t.name == t.suite)
+        ]
+        
+        if not failing and not any(t.status in ("failed", "error") for t in results.tests):
+            # Runner reported failures without per-test details: retry the whole selection
+            return self._retry_whole_run(test_dir, config, results, pattern, **kwargs)
+        
+        for attempt in range(2, config.max_retries + 2):
+            if not failing:
+                break
+            
+            rerun = self.runner.rerun_tests(test_dir, failing, pattern, **kwargs)
+            results.duration += rerun.duration
+            # The same test name can exist in several suites (Go packages, test files)
+            rerun_by_key = {(t.suite, t.name): t for t in rerun.tests}
+            
+            still_failing = []
+            for test in failing:
+                retried = rerun_by_key.get((test.suite, test.name))
+                if retried is not None:
+                    status, duration, message = retried.status, retried.duration, retried.message
+                else:
+                    # Not reported by the rerun: never counts as passing
+                    status, duration, message = "failed", 0.0, "not reported by the retry"
+                
+                test.attempts.append(TestAttempt(
+                    attempt=attempt,
+                    status=status,
+                    duration=duration,
+                    message=message
+                ))
+                
+                if status == "passed":
+                    if test.status == "error":
+                        results.errors -= 1
+                    else:
+                        results.failed -= 1
+                    test.status = "passed_on_retry"
+                    results.passed_on_retry += 1
+                else:
+                    still_failing.append(test)
+            
+            failing = still_failing
+        
+        return results
+    
+    def _check_generated(self, test_dir: str, config: TestExecutionConfig) -> List[GeneratedPackage]:
+        """`go generate` output of the module a Go run is in (nothing for other runners)."""
+        if self.runner.get_framework() != "testing" or not config.check_generated:
+            return []
+        # A stale mock may live in another package than the tests it breaks
+        return check_generated(find_module_root(test_dir), regenerate=config.regenerate)
+    
+    def _retry_whole_run(
+        self,
+        test_dir: str,
+        config: TestExecutionConfig,
+        results: TestResults,
+        pattern: Optional[str] = None,
+        **kwargs
+    ) -> TestResults:
+        """Retry a run whose failures can't be attributed to individual tests."""
+        summary = TestResult(
+            name=pattern or str(test_dir),
+            status="failed",
+            duration=results.duration
+        )
+        summary.attempts.append(TestAttempt(attempt=1, status="failed", duration=results.duration))
+        
+        for attempt in range(2, config.max_retries + 2):
+            rerun = self.runner.run_tests(test_dir, pattern, **kwargs)
+            results.duration += rerun.duration
+            status = "passed" if rerun.success else "failed"
+            summary.attempts.append(TestAttempt(attempt=attempt, status=status, duration=rerun.duration))
+            
+            if rerun.success:
+                summary.status = "passed_on_retry"
+                results.passed_on_retry += results.failed + results.errors
+                results.failed = 0
+                results.errors = 0
+                break
+        
+        results.tests.append(summary)
+        return results
+    
+    def _start_standins(
+        self,
+        test_path: str,
+        config: TestExecutionConfig
+    ) -> Tuple[Optional[StandInManager], bool]:
+        """
+        Start the project's stand-ins unless they are already running.
+        
+        Returns:
+            (manager or None if nothing is declared, whether this call started it)
+        """
+        if not config.setup_fixtures:
+            return None, False
+        
+        if self.standins is not None and self.standins.running:
+            return self.standins, False
+        
+        project_config = self.project_config or load_project_config(test_path)
+        if not project_config.standins.enabled:
+            return None, False
+        
+        self.standins = StandInManager(project_config)
+        self.standins.start()
+        return self.standins, True
+    
+    def _finish_standins(
+        self,
+        standins: Optional[StandInManager],
+        started_here: bool,
+        config: TestExecutionConfig
+    ) -> None:
+        """Tear down stand-ins started for one file, or roll back shared ones."""
+        if standins is None:
+            return
+        
+        if started_here and config.teardown_after:
+            standins.stop()
+        elif config.database_rollback:
+            standins.reset()
+    
+    def stop_standins(self) -> None:
+        """Stop stand-ins left running (e.g. with `teardown_after=False`)."""
+        if self.standins is not None:
+            self.standins.stop()
+    
     def _aggregate_results(self, results: List[TestResults]) -> TestResults:
         """Aggregate multiple test results into one."""
         if not results:
@@ -470,6 +877,11 @@ class UniversalTestExecutor:
             aggregated.failed += result.failed
             aggregated.skipped += result.skipped
             aggregated.errors += result.errors
+            aggregated.passed_on_retry += result.passed_on_retry
             aggregated.duration += result.duration
+            aggregated.tests.extend(result.tests)
+            merge_usage(aggregated.resource_usage, result.resource_usage)
+            aggregated.stale_generated.update(result.stale_generated)
+            aggregated.stale_symbols.update(result.stale_symbols)
         
         return aggregated
@@ -0,0 +1,447 @@
+"""
+Failure-First Test Ordering for TestGen AI.
+
+Orders test execution for the fastest useful signal, using the run store:
+1. Tests that failed in the previous run
+2. Tests impacted by local changes (git)
+3. Everything else, shortest first
+
+Python runs failing tests by node id and deselects them afterwards; Go
+runs the packages of each tier in one `go test` (so they still build and
+run in parallel) with `-run`/`-skip` groups; other runners are ordered per
+test file.
+"""
+
+import re
+import subprocess
+from dataclasses import dataclass, field
+from functools import lru_cache
+from pathlib import Path
+from typing import Dict, List, Optional, Set
+
+from .run_store import TestHistory
+
+
+TIER_FAILING = "failing"
+TIER_IMPACTED = "impacted"
+TIER_REST = "rest"
+TIER_ORDER = {TIER_FAILING: 0, TIER_IMPACTED: 1, TIER_REST: 2}
+
+
+@dataclass
+class ExecutionStep:
+    """
+    One runner invocation of an ordered run.
+    
+    Attributes:
+        tier: failing, impacted or rest
+        target: Test file (file-based runners) or Go package import path
+        packages: Go import paths run together in one `go test` (instead of target)
+        tests: Only run these tests (pytest node ids / Go top-level test names)
+        skip_tests: Don't run these tests (already run in an earlier step)
+        estimated_duration: Expected duration from history (None = unknown)
+    """
+    
+    tier: str
+    target: Optional[str] = None
+    packages: List[str] = field(default_factory=list)
+    tests: List[str] = field(default_factory=list)
+    skip_tests: List[str] = field(default_factory=list)
+    estimated_duration: Optional[float] = None
+    
+    @property
+    def label(self) -> str:
+        scope = f"{len(self.tests)} test(s) in " if self.tests else ""
+        target = self.target or (
+            self.packages[0] if len(self.packages) == 1
+            else f"{len(self.packages)} packages" if self.packages
+            else "previously failing tests"
+        )
+        return f"[{self.tier}] {scope}{target}"
+    
+    def runner_kwargs(self, framework: str, test_dir: str, remaining_failures: int = 0) -> Dict:
+        """
+        Arguments for `BaseTestRunner.run_tests` to execute this step.
+        
+        Args:
+            framework: Runner framework (pytest, testing, jest, ...)
+            test_dir: Directory of the whole run
+            remaining_failures: Failures left before fail-fast stops (0 = off)
+            
+        Returns:
+            Keyword arguments including `test_dir` and `pattern`
+        """
+        extra_args: List[str] = []
+        
+        if framework == "pytest":
+            kwargs = {"test_dir": self.target or test_dir, "pattern": None}
+            if self.tests:
+                kwargs["node_ids"] = list(self.tests)
+            for node_id in self.skip_tests:
+                extra_args.extend(["--deselect", node_id])
+            if remaining_failures:
+                extra_args.append(f"--maxfail={remaining_failures}")
+        elif framework == "testing":  # Go
+            kwargs = {"test_dir": test_dir, "pattern": None, "packages": list(self.packages) or [self.target]}
+            if self.tests:
+                kwargs["run"] = go_name_pattern(self.tests)
+            if self.skip_tests:
+                extra_args.extend(["-skip", go_name_pattern(self.skip_tests)])
+            if remaining_failures == 1:
+                extra_args.append("-failfast")
+        else:
+            kwargs = {"test_dir": test_dir, "pattern": self.target}
+            if framework == "jest" and remaining_failures:
+                extra_args.append(f"--bail={remaining_failures}")
+        
+        if extra_args:
+            kwargs["extra_args"] = extra_args
+        return kwargs
+
+
+def go_name_pattern(names: List[str]) -> str:
+    """Anchored `-run`/`-skip` pattern matching exactly these top-level tests."""
+    top_level = sorted({n.split("/")[0] for n in names})
+    return "^(" + "|".join(re.escape(n) for n in top_level) + ")$"
+
+
+@lru_cache(maxsize=None)
+def go_supports_skip() -> bool:
+    """Whether the installed `go test` has `-skip` (Go 1.20+)."""
+    try:
+        output = subprocess.run(["go", "version"], capture_output=True, text=True, timeout=30).stdout
+    except (OSError, subprocess.TimeoutExpired):
+        return False
+    match = re.search(r'\bgo(\d+)\.(\d+)', output)
+    return bool(match) and (int(match.group(1)), int(match.group(2))) >= (1, 20)
+
+
+def get_changed_files(root: str) -> List[Path]:
+    """
+    Files changed locally: uncommitted changes against HEAD plus untracked files.
+    
+    Args:
+        root: Any directory inside the git work tree
+        
+    Returns:
+        Absolute paths (empty when git isn't available)
+    """
+    try:
+        top = subprocess.run(
+            ["git", "rev-parse", "--show-toplevel"],
+            cwd=root, capture_output=True, text=True, timeout=30
+        )
+        if top.returncode != 0:
+            return []
+        repo_root = Path(top.stdout.strip())
+        
+        changed: Set[str] = set()
+        for cmd in (
+            ["git", "diff", "--name-only", "HEAD"],
+            ["git", "ls-files", "--others", "--exclude-standard"],
+        ):
+            result = subprocess.run(cmd, cwd=repo_root, capture_output=True, text=True, timeout=30)
+            if result.returncode == 0:
+                changed.update(line.strip() for line in result.stdout.splitlines() if line.strip())
+    except (OSError, subprocess.TimeoutExpired):
+        return []
+    
+    return sorted(repo_root / name for name in changed)
+
+
+class TestOrderer:
+    """
+    Build an ordered execution plan from run history and local changes.
+    
+    Example:
+        >>> orderer = TestOrderer(RunStore().test_history("python"), get_changed_files("."))
+        >>> for step in orderer.plan_files(runner.discover_tests("tests")):
+        ...     print(step.label)
+    """
+    
+    def __init__(self, history: Dict[str, TestHistory], changed_files: Optional[List[Path]] = None):
+        """
+        Initialize orderer.
+        
+        Args:
+            history: Per-test history from `RunStore.test_history`
+            changed_files: Locally changed files (absolute paths)
+        """
+        self.history = history
+        self.changed_files = [Path(f).resolve() for f in (changed_files or [])]
+    
+    def plan(self, framework: str, test_dir: str, test_files: Optional[List[Path]] = None) -> List[ExecutionStep]:
+        """
+        Plan for a runner.
+        
+        Args:
+            framework: Runner framework
+            test_dir: Test directory (Go: module/package root)
+            test_files: Discovered test files (not needed for Go)
+            
+        Returns:
+            Ordered execution steps
+        """
+        if framework == "testing":
+            return self.plan_go(test_dir)
+        return self.plan_files(test_files or [], node_ids=(framework == "pytest"))
+    
+    # ===== File-based runners =====
+    
+    def plan_files(self, test_files: List[Path], node_ids: bool = False) -> List[ExecutionStep]:
+        """
+        Order test files.
+        
+        Args:
+            test_files: Test files to run
+            node_ids: Runner accepts test ids like `file::test` (pytest)
+            
+        Returns:
+            Ordered steps; with node ids, previously failing tests form a
+            first step of their own and are skipped in their file's step
+        """
+        files = [Path(f).resolve() for f in test_files]
+        failing = [h for h in self.history.values() if h.last_failed]
+        steps: List[ExecutionStep] = []
+        failing_by_file: Dict[Path, List[str]] = {}
+        
+        if node_ids:
+            for entry in failing:
+                test_file = self._file_of(entry, files)
+                if test_file is not None and "::" in entry.name:
+                    failing_by_file.setdefault(test_file, []).append(entry.name)
+            ids = [node_id for ids in failing_by_file.values() for node_id in ids]
+            if ids:
+                steps.append(ExecutionStep(
+                    tier=TIER_FAILING,
+                    tests=ids,
+                    estimated_duration=self._sum_durations(
+                        [h for h in failing if h.name in ids]
+                    )
+                ))
+        
+        failing_files = {self._file_of(h, files) for h in failing} - {None}
+        file_steps = []
+        for test_file in files:
+            if test_file in failing_files and not failing_by_file.get(test_file):
+                tier = TIER_FAILING
+            elif self._file_impacted(test_file):
+                tier = TIER_IMPACTED
+            else:
+                tier = TIER_REST
+            file_steps.append(ExecutionStep(
+                tier=tier,
+                target=str(test_file),
+                skip_tests=failing_by_file.get(test_file, []),
+                estimated_duration=self._sum_durations(
+                    [h for h in self.history.values() if self._file_of(h, [test_file]) == test_file]
+                )
+            ))
+        
+        return steps + self._sorted(file_steps)
+    
+    def _file_of(self, entry: TestHistory, files: List[Path]) -> Optional[Path]:
+        """Test file a history entry belongs to, if it is one of `files`."""
+        candidates = [entry.file_path, entry.name.split("::")[0], entry.suite]
+        for candidate in filter(None, candidates):
+            path = Path(candidate)
+            for test_file in files:
+                if test_file == path.resolve() or test_file.as_posix().endswith("/" + path.as_posix().lstrip("./")):
+                    return test_file
+        return None
+    
+    def _file_impacted(self, test_file: Path) -> bool:
+        """
+        A test file is impacted when it changed, or a changed source file in
+        the same directory tree is referenced by name from the test.
+        """
+        if not self.changed_files:
+            return False
+        if test_file in self.changed_files:
+            return True
+        
+        try:
+            content = test_file.read_text(encoding='utf-8', errors='ignore')
+        except OSError:
+            return False
+        
+        for changed in self.changed_files:
+            if changed.suffix == test_file.suffix or changed.stem in ("__init__", "index"):
+                stem = changed.parent.name if changed.stem in ("__init__", "index") else changed.stem
+                if re.search(rf'\b{re.escape(stem)}\b', content):
+                    return True
+        return False
+    
+    # ===== Go =====
+    
+    def plan_go(self, module_dir: str) -> List[ExecutionStep]:
+        """
+        Order Go packages: failing tests (`-run` groups) first, then
+        impacted packages, then the rest; tests already run in a failing
+        group are excluded later with `-skip`.
+        
+        Each tier runs as one `go test` over its packages, so packages keep
+        building and running in parallel. A package that failed to build
+        last time, or every failing package when `go test` has no `-skip`
+        (before Go 1.20), runs whole in the failing tier.
+        
+        Args:
+            module_dir: Directory to list packages from (`./...`)
+            
+        Returns:
+            Ordered steps, at most two per tier
+        """
+        packages = list_go_packages(module_dir)
+        impacted = self._impacted_go_packages(packages)
+        can_skip = go_supports_skip()
+        
+        failing_tests: Dict[str, List[str]] = {}   # package -> failing top-level tests
+        failing_packages: List[str] = []           # packages run whole, first
+        durations: Dict[str, Optional[float]] = {}
+        
+        for package in packages.values():
+            if not package.has_tests:
+                continue
+            
+            path = package.import_path
+            entries = [h for h in self.history.values() if h.suite == path]
+            # A build failure is recorded under the package itself, not a test -run can select
+            broken = any(h.last_failed and h.name == path for h in entries)
+            failing = sorted({h.name.split("/")[0] for h in entries if h.last_failed and h.name != path})
+            
+            if broken or (failing and not can_skip):
+                failing_packages.append(path)
+            elif failing:
+                failing_tests[path] = failing
+            durations[path] = self._sum_durations(
+                [h for h in entries if "/" not in h.name and h.name not in failing]
+            )
+        
+        steps: List[ExecutionStep] = []
+        if failing_tests:
+            tests = sorted({name for names in failing_tests.values() for name in names})
+            steps.append(ExecutionStep(
+                tier=TIER_FAILING,
+                packages=self._by_duration(list(failing_tests), durations),
+                tests=tests,
+                estimated_duration=self._sum_durations([
+                    h for h in self.history.values()
+                    if h.suite in failing_tests and h.name in failing_tests[h.suite]
+                ])
+            ))
+        if failing_packages:
+            steps.append(self._go_step(TIER_FAILING, failing_packages, durations))
+        
+        for tier in (TIER_IMPACTED, TIER_REST):
+            in_tier = [
+                path for path in durations
+                if path not in failing_packages and (path in impacted) == (tier == TIER_IMPACTED)
+            ]
+            # -skip applies to every package of a go test: packages with failing tests get their own
+            with_skip = [path for path in in_tier if path in failing_tests]
+            without_skip = [path for path in in_tier if path not in failing_tests]
+            if without_skip:
+                steps.append(self._go_step(tier, without_skip, durations))
+            if with_skip:
+                step = self._go_step(tier, with_skip, durations)
+                step.skip_tests = sorted({name for path in with_skip for name in failing_tests[path]})
+                steps.append(step)
+        
+        return steps
+    
+    def _go_step(self, tier: str, packages: List[str], durations: Dict[str, Optional[float]]) -> ExecutionStep:
+        known = [durations[p] for p in packages if durations.get(p) is not None]
+        return ExecutionStep(
+            tier=tier,
+            packages=self._by_duration(packages, durations),
+            estimated_duration=sum(known) if known else None
+        )
+    
+    def _by_duration(self, packages: List[str], durations: Dict[str, Optional[float]]) -> List[str]:
+        """Shortest first (go test starts packages in the order given)."""
+        known = [d for d in durations.values() if d is not None]
+        default = sum(known) / len(known) if known else 0.0
+        return sorted(packages, key=lambda p: durations.get(p) if durations.get(p) is not None else default)
+    
+    def _impacted_go_packages(self, packages: Dict[str, "GoPackage"]) -> Set[str]:
+        """Packages containing changed files, plus everything importing them."""
+        impacted = {
+            p.import_path for p in packages.values()
+            if any(f.parent == p.dir and f.suffix == ".go" for f in self.changed_files)
+        }
+        
+        # Transitive reverse dependencies within the listed packages
+        changed = True
+        while changed:
+            changed = False
+            for package in packages.values():
+                if package.import_path not in impacted and impacted.intersection(package.imports):
+                    impacted.add(package.import_path)
+                    changed = True
+        
+        return impacted
+    
+    # ===== Helpers =====
+    
+    def _sum_durations(self, entries: List[TestHistory]) -> Optional[float]:
+        known = [h.average_duration for h in entries if h.average_duration is not None]
+        return sum(known) if known else None
+    
+    def _sorted(self, steps: List[ExecutionStep]) -> List[ExecutionStep]:
+        """Sort by tier, then ascending duration (unknown durations count as average)."""
+        known = [s.estimated_duration for s in steps if s.estimated_duration is not None]
+        default = sum(known) / len(known) if known else 0.0
+        return sorted(
+            steps,
+            key=lambda s: (
+                TIER_ORDER[s.tier],
+                s.estimated_duration if s.estimated_duration is not None else default
+            )
+        )
+
+
+@dataclass
+class GoPackage:
+    """A Go package as reported by `go list`."""
+    
+    import_path: str
+    dir: Path
+    imports: Set[str]
+    has_tests: bool
+
+
+def list_go_packages(module_dir: str) -> Dict[str, GoPackage]:
+    """
+    List packages below a directory with `go list`.
+    
+    Args:
+        module_dir: Directory inside a Go module
+        
+    Returns:
+        Packages keyed by import path (empty if `go list` fails)
+    """
+    template = (
+        '{{.ImportPath}}\t{{.Dir}}\t{{join .Imports " "}} {{join .TestImports " "}} '
+        '{{join .XTestImports " "}}\t{{len .TestGoFiles}}\t{{len .XTestGoFiles}}'
+    )
+    try:
+        result = subprocess.run(
+            ["go", "list", "-e", "-f", template, "./..."],
+            cwd=module_dir, capture_output=True, text=True, timeout=120
+        )
+    except (OSError, subprocess.TimeoutExpired):
+        return {}
+    
+    packages = {}
+    for line in result.stdout.splitlines():
+        parts = line.split("\t")
+        if len(parts) != 5:
+            continue
+        import_path, directory, imports, tests, xtests = parts
+        packages[import_path] = GoPackage(
+            import_path=import_path,
+            dir=Path(directory).resolve(),
+            imports=set(imports.split()),
+            has_tests=int(tests or 0) + int(xtests or 0) > 0
+        )
+    return packages
@@ -0,0 +1,210 @@
+#!/usr/bin/env node
+/**
+ * Type extraction helper for TestGen AI.
+ *
+ * Usage: node extract_types.js [--project tsconfig.json] file.ts [file.ts ...]
+ *
+ * Loads the project's own `typescript` package (resolved from the project
+ * directory, never bundled) and prints the declarations of each file as
+ * JSON on stdout: functions with parameter/return types, interfaces and
+ * object types with optional fields, discriminated unions, enums and
+ * classes. Errors are printed as {"error": "..."} with exit code 1.
+ */
+'use strict';
+
+const fs = require('fs');
+const path = require('path');
+
+function fail(message) {
+  process.stdout.write(JSON.stringify({ error: message }));
+  process.exit(1);
+}
+
+function parseArgs(argv) {
+  const args = { project: null, files: [] };
+  for (let i = 0; i < argv.length; i++) {
+    if (argv[i] === '--project') {
+      args.project = argv[++i];
+    } else {
+      args.files.push(path.resolve(argv[i]));
+    }
+  }
+  return args;
+}
+
+function loadTypeScript(fromDir) {
+  try {
+    return require(require.resolve('typescript', { paths: [fromDir] }));
+  } catch (e) {
+    fail(`typescript is not installed in ${fromDir}: ${e.message}`);
+  }
+}
+
+function compilerOptions(ts, project) {
+  if (!project) {
+    return { strict: true, target: ts.ScriptTarget.ES2020, module: ts.ModuleKind.CommonJS, esModuleInterop: true };
+  }
+  const config = ts.readConfigFile(project, ts.sys.readFile);
+  if (config.error) {
+    fail(ts.flattenDiagnosticMessageText(config.error.messageText, '\n'));
+  }
+  return ts.parseJsonConfigFileContent(config.config, ts.sys, path.dirname(project)).options;
+}
+
+function main() {
+  const args = parseArgs(process.argv.slice(2));
+  if (args.files.length === 0) {
+    fail('no files given');
+  }
+
+  const baseDir = args.project ? path.dirname(path.resolve(args.project)) : path.dirname(args.files[0]);
+  const ts = loadTypeScript(baseDir);
+  const program = ts.createProgram(args.files, { ...compilerOptions(ts, args.project), noEmit: true });
+  const checker = program.getTypeChecker();
+  const flags = ts.TypeFormatFlags.NoTruncation | ts.TypeFormatFlags.UseAliasDefinedOutsideCurrentScope;
+
+  const typeText = (type, node) => checker.typeToString(type, node, flags);
+  // Expands the aliased type instead of printing the alias name
+  const aliasText = (type, node) => checker.typeToString(type, node, flags | ts.TypeFormatFlags.InTypeAlias);
+  const hasModifier = (node, kind) => (ts.getModifiers ? ts.getModifiers(node) || [] : node.modifiers || [])
+    .some((m) => m.kind === kind);
+
+  function describeSignature(name, signature, node) {
+    const declaration = signature.getDeclaration();
+    return {
+      name,
+      parameters: signature.getParameters().map((symbol) => {
+        const param = symbol.valueDeclaration;
+        return {
+          name: symbol.getName(),
+          type: typeText(checker.getTypeOfSymbolAtLocation(symbol, param || node), node),
+          optional: !!(param && (param.questionToken || param.initializer)),
+          rest: !!(param && param.dotDotDotToken)
+        };
+      }),
+      return_type: typeText(checker.getReturnTypeOfSignature(signature), node),
+      type_parameters: (signature.getTypeParameters() || []).map((t) => typeText(t, node)),
+      is_async: !!(declaration && hasModifier(declaration, ts.SyntaxKind.AsyncKeyword))
+    };
+  }
+
+  function describeProperties(type, node) {
+    return checker.getPropertiesOfType(type).map((symbol) => {
+      const declaration = symbol.valueDeclaration || (symbol.declarations || [])[0];
+      return {
+        name: symbol.getName(),
+        type: typeText(checker.getTypeOfSymbolAtLocation(symbol, node), node),
+        optional: (symbol.getFlags() & ts.SymbolFlags.Optional) !== 0,
+        readonly: !!(declaration && hasModifier(declaration, ts.SyntaxKind.ReadonlyKeyword))
+      };
+    });
+  }
+
+  // A union of object types sharing a property with a literal type in every member
+  function describeUnion(name, type, node) {
+    const literal = ts.TypeFlags.Literal | ts.TypeFlags.EnumLiteral | ts.TypeFlags.Undefined | ts.TypeFlags.Null;
+    const members = type.types;
+    const objects = members.filter((m) => m.getFlags() & ts.TypeFlags.Object);
+    let discriminant = null;
+
+    if (objects.length === members.length) {
+      for (const candidate of checker.getPropertiesOfType(objects[0])) {
+        const tagged = objects.every((m) => {
+          const prop = checker.getPropertyOfType(m, candidate.getName());
+          return prop && (checker.getTypeOfSymbolAtLocation(prop, node).getFlags() & literal);
+        });
+        if (tagged) {
+          discriminant = candidate.getName();
+          break;
+        }
+      }
+    }
+
+    return {
+      name,
+      type: aliasText(type, node),
+      discriminant,
+      variants: members.map((m) => ({
+        tag: discriminant ? typeText(checker.getTypeOfSymbolAtLocation(checker.getPropertyOfType(m, discriminant), node), node) : null,
+        type: typeText(m, node)
+      }))
+    };
+  }
+
+  function describeFile(sourceFile) {
+    const info = { functions: [], interfaces: [], unions: [], aliases: [], enums: [], classes: [] };
+    const moduleSymbol = checker.getSymbolAtLocation(sourceFile);
+    const exported = new Set(moduleSymbol ? checker.getExportsOfModule(moduleSymbol).map((s) => s.getName()) : []);
+    const isExported = (name) => exported.has(name);
+
+    ts.forEachChild(sourceFile, (node) => {
+      if (ts.isFunctionDeclaration(node) && node.name) {
+        const signature = checker.getSignatureFromDeclaration(node);
+        if (signature) {
+          info.functions.push({ ...describeSignature(node.name.text, signature, node), exported: isExported(node.name.text) });
+        }
+      } else if (ts.isVariableStatement(node)) {
+        for (const declaration of node.declarationList.declarations) {
+          if (!ts.isIdentifier(declaration.name) || !declaration.initializer) continue;
+          if (!ts.isArrowFunction(declaration.initializer) && !ts.isFunctionExpression(declaration.initializer)) continue;
+          const signature = checker.getSignatureFromDeclaration(declaration.initializer);
+          if (signature) {
+            const name = declaration.name.text;
+            info.functions.push({ ...describeSignature(name, signature, declaration), exported: isExported(name) });
+          }
+        }
+      } else if (ts.isInterfaceDeclaration(node)) {
+        const type = checker.getTypeAtLocation(node.name);
+        info.interfaces.push({ name: node.name.text, properties: describeProperties(type, node), exported: isExported(node.name.text) });
+      } else if (ts.isTypeAliasDeclaration(node)) {
+        const name = node.name.text;
+        const type = checker.getTypeAtLocation(node.name);
+        if (type.isUnion() && !(type.getFlags() & ts.TypeFlags.Boolean)) {
+          info.unions.push({ ...describeUnion(name, type, node), exported: isExported(name) });
+        } else if (ts.isTypeLiteralNode(node.type)) {
+          info.interfaces.push({ name, properties: describeProperties(type, node), exported: isExported(name) });
+        } else {
+          info.aliases.push({ name, type: aliasText(type, node), exported: isExported(name) });
+        }
+      } else if (ts.isEnumDeclaration(node)) {
+        info.enums.push({ name: node.name.text, members: node.members.map((m) => m.name.getText(sourceFile)), exported: isExported(node.name.text) });
+      } else if (ts.isClassDeclaration(node) && node.name) {
+        const methods = [];
+        const properties = [];
+        for (const member of node.members) {
+          if (hasModifier(member, ts.SyntaxKind.PrivateKeyword) || (member.name && ts.isPrivateIdentifier(member.name))) continue;
+          if (ts.isConstructorDeclaration(member) || ts.isMethodDeclaration(member)) {
+            const signature = checker.getSignatureFromDeclaration(member);
+            const memberName = ts.isConstructorDeclaration(member) ? 'constructor' : member.name.getText(sourceFile);
+            if (signature) {
+              methods.push({ ...describeSignature(memberName, signature, member), static: hasModifier(member, ts.SyntaxKind.StaticKeyword) });
+            }
+          } else if (ts.isPropertyDeclaration(member) && member.name) {
+            const symbol = checker.getSymbolAtLocation(member.name);
+            properties.push({
+              name: member.name.getText(sourceFile),
+              type: typeText(symbol ? checker.getTypeOfSymbolAtLocation(symbol, member) : checker.getTypeAtLocation(member), member),
+              optional: !!member.questionToken,
+              readonly: hasModifier(member, ts.SyntaxKind.ReadonlyKeyword),
+              static: hasModifier(member, ts.SyntaxKind.StaticKeyword)
+            });
+          }
+        }
+        info.classes.push({ name: node.name.text, methods, properties, exported: isExported(node.name.text) });
+      }
+    });
+
+    return info;
+  }
+
+  const files = {};
+  for (const file of args.files) {
+    const sourceFile = program.getSourceFile(file);
+    if (!sourceFile || !fs.existsSync(file)) continue;
+    files[file] = describeFile(sourceFile);
+  }
+
+  process.stdout.write(JSON.stringify({ typescript: ts.version, files }));
+}
+
+main();
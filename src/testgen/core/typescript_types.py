@@ -0,0 +1,424 @@
+"""
+TypeScript Type Information.
+
+Uses the project's own TypeScript compiler (never a bundled one) to:
+- extract precise declarations from source files with the bundled
+  `ts_helpers/extract_types.js` helper run under the local Node: parameter
+  and return types, optional fields, discriminated unions, exported
+  interfaces and classes
+- type-check generated tests with `tsc --noEmit`, so tests that don't
+  compile can be rejected before they are written
+
+Everything degrades to "no information" when Node or the `typescript`
+package isn't available.
+"""
+
+import json
+import re
+import shutil
+import subprocess
+import uuid
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Dict, List, Optional
+
+
+HELPER_SCRIPT = Path(__file__).parent / "ts_helpers" / "extract_types.js"
+
+TS_EXTENSIONS = (".ts", ".tsx", ".mts", ".cts")
+
+# src/cart.test.ts(12,5): error TS2345: Argument of type 'string' is not assignable ...
+TSC_DIAGNOSTIC = re.compile(r'^(.+?)\((\d+),(\d+)\): error (TS\d+): (.*)$')
+
+# Used when the project has no tsconfig.json
+DEFAULT_COMPILER_OPTIONS = {
+    "strict": True,
+    "target": "ES2020",
+    "module": "commonjs",
+    "moduleResolution": "node",
+    "esModuleInterop": True,
+    "skipLibCheck": True,
+}
+
+
+@dataclass
+class TsParameter:
+    """A function parameter."""
+    
+    name: str
+    type: str
+    optional: bool = False
+    rest: bool = False
+    
+    def __str__(self) -> str:
+        prefix = "..." if self.rest else ""
+        return f"{prefix}{self.name}{'?' if self.optional and not self.rest else ''}: {self.type}"
+
+
+@dataclass
+class TsFunction:
+    """A function, arrow function or method with its checked signature."""
+    
+    name: str
+    parameters: List[TsParameter] = field(default_factory=list)
+    return_type: str = "void"
+    type_parameters: List[str] = field(default_factory=list)
+    is_async: bool = False
+    exported: bool = False
+    static: bool = False
+    
+    @property
+    def signature(self) -> str:
+        generics = f"<{', '.join(self.type_parameters)}>" if self.type_parameters else ""
+        params = ", ".join(str(p) for p in self.parameters)
+        return f"{self.name}{generics}({params}): {self.return_type}"
+    
+    @classmethod
+    def from_dict(cls, data: dict) -> "TsFunction":
+        return cls(
+            name=data["name"],
+            parameters=[TsParameter(**p) for p in data.get("parameters", [])],
+            return_type=data.get("return_type", "void"),
+            type_parameters=data.get("type_parameters", []),
+            is_async=data.get("is_async", False),
+            exported=data.get("exported", False),
+            static=data.get("static", False)
+        )
+
+
+@dataclass
+class TsProperty:
+    """A field of an interface, object type or class."""
+    
+    name: str
+    type: str
+    optional: bool = False
+    readonly: bool = False
+    static: bool = False
+    
+    def __str__(self) -> str:
+        readonly = "readonly " if self.readonly else ""
+        return f"{readonly}{self.name}{'?' if self.optional else ''}: {self.type};"
+
+
+@dataclass
+class TsInterface:
+    """An interface or object type alias."""
+    
+    name: str
+    properties: List[TsProperty] = field(default_factory=list)
+    exported: bool = False
+
+
+@dataclass
+class TsUnion:
+    """A union type alias; `discriminant` is set for discriminated unions."""
+    
+    name: str
+    type: str
+    discriminant: Optional[str] = None
+    variants: List[Dict[str, Optional[str]]] = field(default_factory=list)
+    exported: bool = False
+    
+    @property
+    def tags(self) -> List[str]:
+        """Discriminant values, one per variant."""
+        return [v["tag"] for v in self.variants if v.get("tag")]
+
+
+@dataclass
+class TsClass:
+    """A class with its public methods and fields."""
+    
+    name: str
+    methods: List[TsFunction] = field(default_factory=list)
+    properties: List[TsProperty] = field(default_factory=list)
+    exported: bool = False
+
+
+@dataclass
+class TypeInfo:
+    """Declarations of one TypeScript file as seen by the compiler."""
+    
+    file: str
+    functions: List[TsFunction] = field(default_factory=list)
+    interfaces: List[TsInterface] = field(default_factory=list)
+    unions: List[TsUnion] = field(default_factory=list)
+    aliases: Dict[str, str] = field(default_factory=dict)
+    enums: Dict[str, List[str]] = field(default_factory=dict)
+    classes: List[TsClass] = field(default_factory=list)
+    
+    @classmethod
+    def from_dict(cls, file: str, data: dict) -> "TypeInfo":
+        return cls(
+            file=file,
+            functions=[TsFunction.from_dict(f) for f in data.get("functions", [])],
+            interfaces=[
+                TsInterface(
+                    name=i["name"],
+                    properties=[TsProperty(**p) for p in i.get("properties", [])],
+                    exported=i.get("exported", False)
+                )
+                for i in data.get("interfaces", [])
+            ],
+            unions=[TsUnion(**u) for u in data.get("unions", [])],
+            aliases={a["name"]: a["type"] for a in data.get("aliases", [])},
+            enums={e["name"]: e.get("members", []) for e in data.get("enums", [])},
+            classes=[
+                TsClass(
+                    name=c["name"],
+                    methods=[TsFunction.from_dict(m) for m in c.get("methods", [])],
+                    properties=[TsProperty(**p) for p in c.get("properties", [])],
+                    exported=c.get("exported", False)
+                )
+                for c in data.get("classes", [])
+            ]
+        )
+    
+    @property
+    def is_empty(self) -> bool:
+        return not (self.functions or self.interfaces or self.unions or self.aliases or self.enums or self.classes)
+    
+    def function_signatures(self) -> List[str]:
+        """Typed function signatures (for the scanner)."""
+        return [
+            ("async " if f.is_async else "") + f.signature
+            for f in self.functions
+        ]
+    
+    def class_summaries(self) -> List[str]:
+        """Classes with typed methods, plus interfaces and unions (for the scanner)."""
+        summaries = []
+        for cls in self.classes:
+            methods = [m.signature for m in cls.methods]
+            summaries.append(f"{cls.name} [methods: {', '.join(methods)}]" if methods else cls.name)
+        for interface in self.interfaces:
+            summaries.append(f"interface {interface.name} {{ {' '.join(str(p) for p in interface.properties)} }}")
+        for union in self.unions:
+            summaries.append(f"type {union.name} = {union.type}")
+        return summaries
+    
+    def to_declarations(self) -> str:
+        """
+        Render the declarations as TypeScript, for the prompt.
+        
+        Discriminated unions are annotated with their discriminant and
+        tag values so every variant can be covered.
+        """
+        lines = []
+        
+        for interface in self.interfaces:
+            export = "export " if interface.exported else ""
+            lines.append(f"{export}interface {interface.name} {{")
+            lines.extend(f"  {p}" for p in interface.properties)
+            lines.append("}")
+        
+        for union in self.unions:
+            export = "export " if union.exported else ""
+            if union.discriminant:
+                lines.append(
+                    f"// discriminated union on `{union.discriminant}`: {' | '.join(union.tags)}"
+                )
+            lines.append(f"{export}type {union.name} = {union.type};")
+        
+        for name, type_text in self.aliases.items():
+            lines.append(f"type {name} = {type_text};")
+        
+        for name, members in self.enums.items():
+            lines.append(f"enum {name} {{ {', '.join(members)} }}")
+        
+        for function in self.functions:
+            export = "export " if function.exported else ""
+            asynchronous = "async " if function.is_async else ""
+            lines.append(f"{export}{asynchronous}function {function.signature};")
+        
+        for cls in self.classes:
+            export = "export " if cls.exported else ""
+            lines.append(f"{export}class {cls.name} {{")
+            for prop in cls.properties:
+                lines.append(f"  {'static ' if prop.static else ''}{prop}")
+            for method in cls.methods:
+                modifiers = ("static " if method.static else "") + ("async " if method.is_async else "")
+                lines.append(f"  {modifiers}{method.signature};")
+            lines.append("}")
+        
+        return "\n".join(lines)
+
+
+@dataclass
+class TypeCheckResult:
+    """Outcome of `tsc --noEmit` for a generated test file."""
+    
+    checked: bool
+    diagnostics: List[str] = field(default_factory=list)
+    reason: Optional[str] = None  # Why the check was skipped
+    
+    @property
+    def ok(self) -> bool:
+        """True unless the compiler reported errors (skipped checks pass)."""
+        return not self.diagnostics
+
+
+def find_project_dir(start: str) -> Path:
+    """Nearest directory with a tsconfig.json or package.json, searching upward."""
+    path = Path(start).resolve()
+    if path.is_file():
+        path = path.parent
+    for directory in [path, *path.parents]:
+        if (directory / "tsconfig.json").exists() or (directory / "package.json").exists():
+            return directory
+    return path
+
+
+def find_tsconfig(project_dir: Path) -> Optional[Path]:
+    """tsconfig.json of the project, if any."""
+    tsconfig = Path(project_dir) / "tsconfig.json"
+    return tsconfig if tsconfig.exists() else None
+
+
+def find_typescript(project_dir: Path) -> Optional[Path]:
+    """The project's installed `typescript` package (node_modules lookup, upward)."""
+    start = Path(project_dir).resolve()
+    for directory in [start, *start.parents]:
+        package = directory / "node_modules" / "typescript"
+        if (package / "package.json").exists():
+            return package
+    return None
+
+
+def find_node() -> Optional[str]:
+    return shutil.which("node")
+
+
+def extract_types(
+    files: List[str],
+    project_dir: Optional[str] = None,
+    timeout: int = 120
+) -> Dict[str, TypeInfo]:
+    """
+    Extract declarations from TypeScript files with the local compiler.
+    
+    All files are analysed by one compiler program, so files from the same
+    project should be passed together.
+    
+    Args:
+        files: TypeScript source files
+        project_dir: Project directory (found from the first file if None)
+        timeout: Timeout in seconds
+        
+    Returns:
+        TypeInfo per resolved file path; empty if Node, the `typescript`
+        package or the helper is unavailable or fails
+    """
+    files = [str(Path(f).resolve()) for f in files if str(f).endswith(TS_EXTENSIONS)]
+    if not files:
+        return {}
+    
+    project = Path(project_dir) if project_dir else find_project_dir(files[0])
+    node = find_node()
+    if node is None or find_typescript(project) is None or not HELPER_SCRIPT.exists():
+        return {}
+    
+    cmd = [node, str(HELPER_SCRIPT)]
+    tsconfig = find_tsconfig(project)
+    if tsconfig:
+        cmd.extend(["--project", str(tsconfig)])
+    cmd.extend(files)
+    
+    try:
+        result = subprocess.run(cmd, capture_output=True, text=True, cwd=project, timeout=timeout)
+        data = json.loads(result.stdout or "{}")
+    except (subprocess.TimeoutExpired, OSError, json.JSONDecodeError):
+        return {}
+    
+    if result.returncode != 0 or "error" in data:
+        return {}
+    
+    return {
+        path: TypeInfo.from_dict(path, info)
+        for path, info in data.get("files", {}).items()
+    }
+
+
+def extract_file_types(source_file: str, project_dir: Optional[str] = None) -> Optional[TypeInfo]:
+    """Declarations of a single file, or None if unavailable."""
+    types = extract_types([source_file], project_dir)
+    return types.get(str(Path(source_file).resolve()))
+
+
+def typecheck_test_file(
+    test_file: str,
+    project_dir: Optional[str] = None,
+    timeout: int = 120
+) -> TypeCheckResult:
+    """
+    Type-check a test file with the project's `tsc --noEmit`.
+    
+    The file is checked with the project's compiler options (a temporary
+    tsconfig extending the project's one, so type roots and path aliases
+    resolve as usual). Only errors located in the test file count: errors
+    in the code under test aren't the test's fault.
+    
+    Args:
+        test_file: Test file (on disk, where it will live)
+        project_dir: Project directory (found from the test file if None)
+        timeout: Timeout in seconds
+        
+    Returns:
+        TypeCheckResult; `checked` is False when no local tsc is available
+    """
+    test_path = Path(test_file).resolve()
+    if not test_path.name.endswith(TS_EXTENSIONS):
+        return TypeCheckResult(checked=False, reason="not a TypeScript file")
+    
+    project = Path(project_dir).resolve() if project_dir else find_project_dir(str(test_path))
+    node = find_node()
+    typescript = find_typescript(project)
+    if node is None:
+        return TypeCheckResult(checked=False, reason="node not found")
+    if typescript is None:
+        return TypeCheckResult(checked=False, reason="typescript is not installed in the project")
+    
+    config = {"compilerOptions": {"noEmit": True}, "files": [str(test_path)], "include": []}
+    tsconfig = find_tsconfig(project)
+    if tsconfig:
+        config["extends"] = "./" + tsconfig.name
+    else:
+        config["compilerOptions"].update(DEFAULT_COMPILER_OPTIONS)
+    
+    # Next to the project's tsconfig so relative settings resolve the same way
+    temp_config = project / f"tsconfig.testgen-{uuid.uuid4().hex[:8]}.json"
+    try:
+        temp_config.write_text(json.dumps(config, indent=2), encoding='utf-8')
+        result = subprocess.run(
+            [node, str(typescript / "bin" / "tsc"), "--project", str(temp_config), "--pretty", "false"],
+            capture_output=True,
+            text=True,
+            cwd=project,
+            timeout=timeout
+        )
+    except subprocess.TimeoutExpired:
+        return TypeCheckResult(checked=False, reason=f"tsc timed out after {timeout}s")
+    except OSError as e:
+        return TypeCheckResult(checked=False, reason=str(e))
+    finally:
+        temp_config.unlink(missing_ok=True)
+    
+    return TypeCheckResult(checked=True, diagnostics=_test_file_diagnostics(result.stdout, test_path, project))
+
+
+def _test_file_diagnostics(output: str, test_path: Path, project: Path) -> List[str]:
+    """tsc errors reported for the test file (or for no file at all)."""
+    diagnostics = []
+    for line in output.splitlines():
+        match = TSC_DIAGNOSTIC.match(line.strip())
+        if match:
+            reported = Path(match.group(1))
+            if not reported.is_absolute():
+                reported = project / reported
+            if reported.resolve() == test_path:
+                diagnostics.append(f"{test_path.name}:{match.group(2)}:{match.group(3)} {match.group(4)}: {match.group(5)}")
+        elif line.startswith("error TS"):
+            # Global errors (bad compiler options, missing type definitions)
+            diagnostics.append(line.strip())
+    return diagnostics
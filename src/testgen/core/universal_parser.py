@@ -6,7 +6,7 @@ Parses code in ANY supported language using Tree-sitter.
 
 from pathlib import Path
 from typing import List, Dict, Any, Optional
-from dataclasses import dataclass
+from dataclasses import dataclass, field
 
 try:
     from tree_sitter import Language, Parser
@@ -30,6 +30,12 @@ class Function:
     line_end: int
     is_test: bool = False
     is_async: bool = False
+    decorators: List[str] = field(default_factory=list)
+    
+    @property
+    def is_async_context_manager(self) -> bool:
+        """Decorated with @asynccontextmanager."""
+        return any(d.split('.')[-1] == 'asynccontextmanager' for d in self.decorators)
 
 
 @dataclass
@@ -42,6 +48,12 @@ class Class:
     line_start: int
     line_end: int
     is_test_class: bool = False
+    
+    @property
+    def is_async_context_manager(self) -> bool:
+        """Implements `async with` (__aenter__ and __aexit__)."""
+        names = {m.name for m in self.methods}
+        return '__aenter__' in names and '__aexit__' in names
 
 
 class UniversalCodeParser:
@@ -189,7 +201,7 @@ class UniversalCodeParser:
         lines = code.split('\n')
         
         for node in ast.walk(tree):
-            if isinstance(node, ast.FunctionDef):
+            if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef)):
                 # Get docstring
                 docstring = ast.get_docstring(node)
                 
@@ -217,7 +229,8 @@ class UniversalCodeParser:
                     line_start=start_line + 1,
                     line_end=end_line,
                     is_test=is_test,
-                    is_async=isinstance(node, ast.AsyncFunctionDef)
+                    is_async=isinstance(node, ast.AsyncFunctionDef),
+                    decorators=[ast.unparse(d) for d in node.decorator_list]
                 ))
         
         return functions
@@ -238,7 +251,7 @@ class UniversalCodeParser:
                 # Get methods
                 methods = []
                 for item in node.body:
-                    if isinstance(item, ast.FunctionDef):
+                    if isinstance(item, (ast.FunctionDef, ast.AsyncFunctionDef)):
                         methods.append(Function(
                             name=item.name,
                             parameters=[arg.arg for arg in item.args.args],
@@ -247,7 +260,9 @@ class UniversalCodeParser:
                             body="",
                             line_start=item.lineno,
                             line_end=item.end_lineno or item.lineno,
-                            is_test=item.name.startswith('test_')
+                            is_test=item.name.startswith('test_'),
+                            is_async=isinstance(item, ast.AsyncFunctionDef),
+                            decorators=[ast.unparse(d) for d in item.decorator_list]
                         ))
                 
                 classes.append(Class(
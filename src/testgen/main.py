@@ -2,12 +2,13 @@
 TestGen AI - Main CLI Entry Point
 
 This module provides the command-line interface for TestGen AI using Typer.
-All commands are defined here: generate, test, report, auto, and version.
+All commands are defined here: generate, test, report, auto, mock, load, contracts,
+faults, refactor, migrate, port, allure, gc, bench, allocs, generated, and version.
 """
 
 import sys
 from pathlib import Path
-from typing import Optional
+from typing import List, Optional
 
 import typer
 from rich.console import Console
@@ -130,6 +131,11 @@ def generate(
         "-w",
         help="Enable watch mode for real-time test generation",
     ),
+    security: bool = typer.Option(
+        False,
+        "--security",
+        help="Also generate security tests for input-handling code (path traversal, SQL/header injection, ...)",
+    ),
 ):
     """
     Generate test files for your code using AI.
@@ -140,6 +146,7 @@ def generate(
         testgen generate ./src
         testgen generate ./src --output ./tests
         testgen generate ./src --watch
+        testgen generate ./src --security
     """
     try:
         # Set output directory
@@ -158,7 +165,8 @@ def generate(
             f"[bold cyan]Test Generation Started[/bold cyan]\n\n"
             f"📁 Source: [green]{target_directory}[/green]\n"
             f"📝 Output: [green]{output_dir}[/green]\n"
-            f"👀 Watch Mode: [yellow]{'Enabled' if watch else 'Disabled'}[/yellow]",
+            f"👀 Watch Mode: [yellow]{'Enabled' if watch else 'Disabled'}[/yellow]\n"
+            f"🔒 Security Tests: [yellow]{'Enabled' if security else 'Disabled'}[/yellow]",
             title="🚀 TestGen AI",
             border_style="cyan"
         ))
@@ -171,6 +179,9 @@ def generate(
         console.print("[yellow]📊 Analyzing code...[/yellow]")
         console.print("[dim]⚠️  Scanner module not yet implemented (Task 22+)[/dim]")
         
+        if security:
+            _print_security_targets(target_directory)
+        
         # TODO: Module 3 - Call LLM module
         console.print("[yellow]🤖 Generating tests with AI...[/yellow]")
         console.print("[dim]⚠️  LLM module not yet implemented (Task 33+)[/dim]")
@@ -191,6 +202,32 @@ def generate(
         raise typer.Exit(1)
 
 
+def _print_security_targets(target_directory: Path) -> None:
+    """List the input-handling functions security tests will target."""
+    from testgen.core.security_tests import find_input_handlers
+    
+    extensions = {".py": "python", ".go": "go", ".js": "javascript", ".ts": "typescript"}
+    console.print("[yellow]🔒 Finding input-handling code...[/yellow]")
+    found = 0
+    for path in sorted(target_directory.rglob("*")):
+        language = extensions.get(path.suffix)
+        if language is None or not path.is_file() or any(
+            part.startswith(".") or part in ("node_modules", "vendor", "venv", "__pycache__")
+            for part in path.relative_to(target_directory).parts
+        ):
+            continue
+        try:
+            handlers = find_input_handlers(path.read_text(encoding='utf-8', errors='ignore'), language)
+        except OSError:
+            continue
+        for handler in handlers:
+            found += 1
+            risks = ", ".join(c.replace("_", " ") for c in handler.categories)
+            console.print(f"  {path.relative_to(target_directory)}:{handler.line} [cyan]{handler.name}[/cyan] - {risks}")
+    if not found:
+        console.print("[dim]  No input-handling code found[/dim]")
+
+
 @app.command()
 def test(
     test_directory: Optional[Path] = typer.Argument(
@@ -454,6 +491,1156 @@ def auto(
         raise typer.Exit(1)
 
 
+@app.command()
+def mock(
+    spec: Path = typer.Argument(
+        ...,
+        help="OpenAPI spec of the upstream service (JSON or YAML)",
+        exists=True,
+        file_okay=True,
+        dir_okay=False,
+        resolve_path=True,
+    ),
+    go_package: Optional[str] = typer.Option(
+        None,
+        "--go-package",
+        help="Generate an in-process Go mock (httptest) for this package instead of serving",
+    ),
+    output: Optional[Path] = typer.Option(
+        None,
+        "--output",
+        "-o",
+        help="Output file for the Go mock (default: <spec>_mock_test.go in the directory of --go-package)",
+    ),
+    name: Optional[str] = typer.Option(
+        None,
+        "--name",
+        help="Name prefix of the generated Go mock type (default: spec title)",
+    ),
+    port: int = typer.Option(
+        8080,
+        "--port",
+        help="Port for the standalone mock server",
+    ),
+):
+    """
+    Mock an upstream HTTP API from its OpenAPI spec.
+    
+    Serves schema-valid example responses and reports requests that don't
+    match the spec. Standalone servers accept overrides and injected errors
+    under /__mock/.
+    
+    Examples:
+        testgen mock api/payments.yaml --port 9000
+        testgen mock api/payments.yaml --go-package payments
+        testgen mock api/payments.yaml --go-package payments -o internal/payments/payments_mock_test.go
+    """
+    from testgen.core.openapi_mock import create_mock_server, generate_go_mock, go_mock_path, load_spec
+    
+    try:
+        if go_package:
+            output_file = output or go_mock_path(str(spec), go_package)
+            output_file.write_text(generate_go_mock(str(spec), go_package, name), encoding='utf-8')
+            console.print(f"[green]✅ Go mock written to {output_file}[/green]")
+            return
+        
+        server, mock_state = create_mock_server(load_spec(str(spec)), port)
+        console.print(Panel.fit(
+            f"[bold cyan]Mock server running[/bold cyan]\n\n"
+            f"📄 Spec: [green]{spec}[/green]\n"
+            f"🌐 URL: [green]http://127.0.0.1:{server.server_address[1]}[/green]\n"
+            f"🔧 Control: [yellow]/__mock/overrides, /__mock/errors, /__mock/violations, /__mock/reset[/yellow]",
+            title="🎭 TestGen AI",
+            border_style="cyan"
+        ))
+        
+        try:
+            server.serve_forever()
+        except KeyboardInterrupt:
+            pass
+        finally:
+            server.server_close()
+            if mock_state.violations:
+                console.print(f"\n[yellow]⚠️  {len(mock_state.violations)} request(s) did not match the spec:[/yellow]")
+                for violation in mock_state.violations:
+                    console.print(f"  - {violation}")
+        
+    except Exception as e:
+        console.print(f"[red]❌ Error running mock: {e}[/red]")
+        if state.debug:
+            console.print_exception()
+        raise typer.Exit(1)
+
+
+@app.command()
+def load(
+    target: Path = typer.Argument(
+        Path("."),
+        help="Go package directory (or tree of packages) with HTTP handlers",
+        exists=True,
+        file_okay=False,
+        dir_okay=True,
+        resolve_path=True,
+    ),
+    concurrency: int = typer.Option(
+        10,
+        "--concurrency",
+        "-c",
+        help="Concurrent clients",
+    ),
+    duration: float = typer.Option(
+        10.0,
+        "--duration",
+        "-d",
+        help="Seconds of requests per load test",
+    ),
+    url: Optional[str] = typer.Option(
+        None,
+        "--url",
+        help="Load a locally running service at this URL instead of Go handlers",
+    ),
+    start: Optional[str] = typer.Option(
+        None,
+        "--start",
+        help="Command that starts the service for --url (stopped afterwards)",
+    ),
+    routes: Optional[List[str]] = typer.Option(
+        None,
+        "--route",
+        "-r",
+        help="Route of a handler the package doesn't register: Name='METHOD /path'; repeatable",
+    ),
+):
+    """
+    Load test HTTP handlers locally.
+    
+    Generates testgen_load_test.go for Go packages with exported handlers
+    (if missing), drives concurrent requests against httptest servers on
+    the routes the handlers are registered on, and records throughput,
+    latency percentiles and error rates with the benchmark history.
+    
+    Examples:
+        testgen load ./internal/api
+        testgen load ./internal/api -c 50 -d 30
+        testgen load ./internal/api --route Health='GET /healthz'
+        testgen load --url http://localhost:8000/health --start "python app.py"
+    """
+    from testgen.core.benchmark import BenchmarkStore
+    from testgen.core.load_test import LOAD_TEST_FILE, LoadTester, format_load_report, write_go_load_test
+    
+    try:
+        tester = LoadTester(concurrency=concurrency, duration=duration)
+        
+        if url:
+            results = [tester.run_url(url, command=start, cwd=str(target))]
+        else:
+            if not list(target.rglob(LOAD_TEST_FILE)):
+                given = dict(route.split("=", 1) for route in routes or [] if "=" in route)
+                written = write_go_load_test(str(target), given)
+                if written is None:
+                    console.print(
+                        f"[red]❌ Error: No exported HTTP handlers with a known route in {target} "
+                        f"(register them in the package or pass --route Name='METHOD /path')[/red]"
+                    )
+                    raise typer.Exit(1)
+                console.print(f"[green]✓[/green] Load tests written to {written}")
+            results = []
+            for package_dir in sorted({f.parent for f in target.rglob(LOAD_TEST_FILE) if "vendor" not in f.parts}):
+                results.extend(tester.run_go(str(package_dir)))
+        
+        console.print(format_load_report(results))
+        
+        store = BenchmarkStore()
+        regressions = store.find_regressions(results)
+        store.record(results)
+        for regression in regressions:
+            console.print(f"[yellow]⚠️  {regression.key}: p50 {regression.change:+.0%} vs. recorded runs[/yellow]")
+        
+        if any(r.error for r in results):
+            raise typer.Exit(1)
+    
+    except typer.Exit:
+        raise
+    except Exception as e:
+        console.print(f"[red]❌ Error running load tests: {e}[/red]")
+        if state.debug:
+            console.print_exception()
+        raise typer.Exit(1)
+
+
+@app.command()
+def contracts(
+    project_dir: Path = typer.Argument(
+        Path("."),
+        help="Directory inside the project (testgen.toml declares the contracts)",
+        exists=True,
+        file_okay=False,
+        dir_okay=True,
+        resolve_path=True,
+    ),
+    check: bool = typer.Option(
+        False,
+        "--check",
+        help="Fail if a stored contract no longer matches the consumer's code (don't rewrite it)",
+    ),
+    verify: bool = typer.Option(
+        True,
+        "--verify/--no-verify",
+        help="Run the provider verification tests",
+    ),
+):
+    """
+    Consumer-driven contract tests between services.
+    
+    For each [[contracts]] entry in testgen.toml, builds the consumer's
+    Pact-style contract from its client code and tests, writes a provider
+    verification test (if missing) and replays the contract against the
+    provider's handler in-process. Reports breaks on either side.
+    
+    Examples:
+        testgen contracts
+        testgen contracts --check
+        testgen contracts --no-verify
+    """
+    from testgen.core.contracts import (
+        Contract, breaks_from_results, build_contract, check_provider_routes,
+        diff_contracts, generate_provider_test,
+    )
+    from testgen.core.go_runner import GoTestRunner
+    from testgen.core.project_config import load_project_config
+    from testgen.core.python_runner import PythonTestRunner
+    
+    try:
+        project = load_project_config(str(project_dir))
+        if not project.contracts:
+            console.print("[red]❌ Error: No [[contracts]] declared in testgen.toml[/red]")
+            raise typer.Exit(1)
+        
+        breaks = []
+        for declared in project.contracts:
+            console.print(f"[cyan]📜 {declared.consumer} → {declared.provider}[/cyan]")
+            contract = build_contract(declared, project.root)
+            if not contract.interactions:
+                console.print(f"[yellow]⚠️  No calls to {declared.provider} found in {declared.consumer_dir}[/yellow]")
+                continue
+            
+            contract_path = project.resolve(declared.contracts_dir) / contract.file_name
+            stored = Contract.load(contract_path)
+            changes = diff_contracts(stored, contract) if stored else []
+            for change in changes:
+                console.print(f"  [yellow]~ {change.interaction}: {change.reason}[/yellow]")
+            if check:
+                if stored is None:
+                    console.print(f"  [red]✗ {contract_path} is missing[/red]")
+                breaks.extend(changes)
+            elif stored is None or changes or stored.to_dict() != contract.to_dict():
+                contract.save(contract_path)
+                console.print(f"  [green]✓[/green] Contract written to {contract_path} ({len(contract.interactions)} interactions)")
+            
+            breaks.extend(check_provider_routes(contract, project.resolve(declared.provider_dir)))
+            
+            if not verify:
+                continue
+            generated = generate_provider_test(contract, declared, project.root)
+            if generated is None:
+                console.print(f"  [yellow]⚠️  No handler constructor or app found in {declared.provider_dir}; skipping verification[/yellow]")
+                continue
+            test_file, source = generated
+            if not test_file.exists():
+                test_file.parent.mkdir(parents=True, exist_ok=True)
+                test_file.write_text(source, encoding='utf-8')
+                console.print(f"  [green]✓[/green] Provider verification written to {test_file}")
+            
+            if test_file.suffix == ".go":
+                results = GoTestRunner().run_tests(str(test_file.parent), run="^TestContract", packages=["."])
+            else:
+                results = PythonTestRunner().run_tests(str(test_file.parent), node_ids=[str(test_file)])
+            breaks.extend(breaks_from_results(contract, results.tests))
+        
+        if breaks:
+            console.print(f"\n[red]❌ {len(breaks)} contract break(s):[/red]")
+            for found in breaks:
+                console.print(f"  [red]✗[/red] {found.consumer} → {found.provider} \\[{found.side}] {found.interaction}: {found.reason}")
+            raise typer.Exit(1)
+        console.print("\n[green]✓ All contracts hold[/green]")
+    
+    except typer.Exit:
+        raise
+    except Exception as e:
+        console.print(f"[red]❌ Error checking contracts: {e}[/red]")
+        if state.debug:
+            console.print_exception()
+        raise typer.Exit(1)
+
+
+@app.command()
+def faults(
+    target: Path = typer.Argument(
+        Path("."),
+        help="Go package directory (or tree of packages)",
+        exists=True,
+        file_okay=False,
+        dir_okay=True,
+        resolve_path=True,
+    ),
+    regenerate: bool = typer.Option(
+        False,
+        "--regenerate",
+        help="Rewrite existing testgen_fault_test.go files",
+    ),
+):
+    """
+    Fault-injection tests for Go I/O and dependencies.
+    
+    Generates testgen_fault_test.go for Go packages (if missing): readers
+    that fail midway, writers that fail or short-write, contexts cancelled
+    mid-operation and fault<Interface> wrappers that fail a dependency's
+    Nth call. Runs them and reports errors that aren't propagated or
+    closers left open.
+    
+    Examples:
+        testgen faults ./internal/stream
+        testgen faults ./internal --regenerate
+    """
+    from testgen.core.fault_injection import (
+        FAULT_TEST_FILE, describe_fault_seams, find_fault_seams, find_go_interfaces, write_go_fault_test,
+    )
+    from testgen.core.go_runner import GoTestRunner
+    
+    try:
+        package_dirs = sorted({
+            f.parent for f in target.rglob("*.go")
+            if not any(part in ("vendor", "testdata") or part.startswith(".") for part in f.relative_to(target).parts)
+        })
+        
+        failed = []
+        for package_dir in package_dirs:
+            relative = package_dir.relative_to(target) if package_dir != target else Path(".")
+            if regenerate or not (package_dir / FAULT_TEST_FILE).exists():
+                if write_go_fault_test(str(package_dir)) is None:
+                    continue
+                console.print(f"[green]✓[/green] Fault-injection tests written to {relative / FAULT_TEST_FILE}")
+            
+            # Seams that need hand-written (or LLM-generated) tests with the wrappers
+            sources = [
+                f.read_text(encoding='utf-8', errors='ignore')
+                for f in sorted(package_dir.glob("*.go")) if not f.name.endswith("_test.go")
+            ]
+            interfaces = [i for code in sources for i in find_go_interfaces(code)]
+            seams = [s for code in sources for s in find_fault_seams(code, [i.name for i in interfaces])]
+            remaining = describe_fault_seams([s for s in seams if not s.generatable], [])
+            if remaining and state.verbose:
+                console.print(f"[dim]  Seams without generated tests in {relative}:\n{remaining}[/dim]")
+            
+            results = GoTestRunner().run_tests(str(package_dir), run="^TestFault", packages=["."])
+            failed.extend(t for t in results.tests if t.status in ("failed", "error") and "/" in t.name)
+            if results.total == 0 and results.errors:
+                failed.extend(results.tests)
+        
+        if failed:
+            console.print(f"\n[red]❌ {len(failed)} fault(s) not handled:[/red]")
+            for test in failed:
+                message = (test.message or "").strip().splitlines()
+                console.print(f"  [red]✗[/red] {test.name}" + (f": {message[0]}" if message else ""))
+            raise typer.Exit(1)
+        console.print("\n[green]✓ Injected faults are propagated and cleaned up[/green]")
+    
+    except typer.Exit:
+        raise
+    except Exception as e:
+        console.print(f"[red]❌ Error running fault-injection tests: {e}[/red]")
+        if state.debug:
+            console.print_exception()
+        raise typer.Exit(1)
+
+
+@app.command()
+def refactor(
+    target: Path = typer.Argument(
+        Path("."),
+        help="Go test file, or directory of Go packages",
+        exists=True,
+        resolve_path=True,
+    ),
+    output: Optional[Path] = typer.Option(
+        None,
+        "--output",
+        "-o",
+        help="Write the patch to this file instead of printing it",
+    ),
+    apply: bool = typer.Option(
+        False,
+        "--apply",
+        help="Write the rewritten test files (after review, prefer `git apply`)",
+    ),
+    verify: bool = typer.Option(
+        True,
+        "--verify/--no-verify",
+        help="Run the tests before and after and keep only rewrites with the same per-case outcomes",
+    ),
+):
+    """
+    Rewrite repetitive Go tests as table-driven tests.
+    
+    Finds test functions that differ only in literal values and turns each
+    group into one table-driven test with t.Run subtests. Every rewrite is
+    run before and after (via go test -overlay, nothing is modified) and
+    dropped if any case's outcome changes. Prints a patch for review.
+    
+    Examples:
+        testgen refactor ./internal/calc
+        testgen refactor calc_test.go -o table.patch
+        git apply table.patch
+    """
+    from testgen.core.table_tests import make_patch, refactor_file
+    
+    try:
+        files = [target] if target.is_file() else sorted(
+            f for f in target.rglob("*_test.go")
+            if not any(part in ("vendor", "testdata") or part.startswith(".") for part in f.relative_to(target).parts)
+        )
+        if not files:
+            console.print(f"[red]❌ Error: No Go test files found in {target}[/red]")
+            raise typer.Exit(1)
+        
+        patches = []
+        for test_file in files:
+            result = refactor_file(str(test_file), verify=verify)
+            for cluster in result.applied:
+                console.print(
+                    f"[green]✓[/green] {test_file.name}: {', '.join(cluster.original_names)} → "
+                    f"{cluster.name} ({len(cluster.functions)} cases)"
+                )
+            for cluster in result.rejected:
+                console.print(f"[yellow]⚠️  {test_file.name}: {', '.join(cluster.original_names)} left as is: {cluster.problem}[/yellow]")
+            if not result.applied:
+                continue
+            
+            relative = test_file.relative_to(Path.cwd()) if test_file.is_relative_to(Path.cwd()) else test_file
+            patches.append(make_patch(result.original, result.rewritten, relative))
+            if apply:
+                test_file.write_text(result.rewritten, encoding='utf-8')
+        
+        if not patches:
+            console.print("[dim]No repetitive tests to rewrite[/dim]")
+            return
+        
+        patch = "".join(patches)
+        if output:
+            output.write_text(patch, encoding='utf-8')
+            console.print(f"[green]✓[/green] Patch written to {output}")
+        elif not apply:
+            sys.stdout.write(patch)
+        if apply:
+            console.print(f"[green]✓[/green] Rewrote {len(patches)} file(s)")
+    
+    except typer.Exit:
+        raise
+    except Exception as e:
+        console.print(f"[red]❌ Error refactoring tests: {e}[/red]")
+        if state.debug:
+            console.print_exception()
+        raise typer.Exit(1)
+
+
+@app.command()
+def migrate(
+    target: Path = typer.Argument(
+        Path("."),
+        help="Test file, or directory of test files",
+        exists=True,
+        resolve_path=True,
+    ),
+    source: str = typer.Option(
+        ...,
+        "--from",
+        help="Framework to migrate from: unittest, stdlib, testify, jest or junit4",
+    ),
+    to: str = typer.Option(
+        ...,
+        "--to",
+        help="Framework to migrate to: pytest, testify, stdlib, vitest or junit5",
+    ),
+    output: Optional[Path] = typer.Option(
+        None,
+        "--output",
+        "-o",
+        help="Write the patch to this file instead of printing it",
+    ),
+    apply: bool = typer.Option(
+        False,
+        "--apply",
+        help="Write the migrated test files (after review, prefer `git apply`)",
+    ),
+    verify: bool = typer.Option(
+        True,
+        "--verify/--no-verify",
+        help="Run the tests before and after and keep only migrations with the same per-test outcomes",
+    ),
+    llm: bool = typer.Option(
+        False,
+        "--llm",
+        help="Ask the LLM to migrate what the deterministic rewrite left over",
+    ),
+):
+    """
+    Migrate test files to another framework.
+    
+    Rewrites the common API deterministically (unittest → pytest,
+    Go stdlib ↔ testify, Jest → Vitest, JUnit 4 → 5), lists what it
+    couldn't translate, optionally has the LLM finish it, and runs every
+    file before and after so only migrations with identical outcomes are
+    proposed. Prints a patch per file for review.
+    
+    Examples:
+        testgen migrate tests/ --from unittest --to pytest
+        testgen migrate ./internal --from stdlib --to testify -o testify.patch
+        testgen migrate src/__tests__ --from jest --to vitest --llm --apply
+    """
+    from testgen.core.migration import find_test_files, get_migration, migrate_file
+    from testgen.core.table_tests import make_patch
+    
+    try:
+        try:
+            get_migration(source, to)
+        except ValueError as e:
+            console.print(f"[red]❌ Error: {e}[/red]")
+            raise typer.Exit(1)
+        
+        files = [target] if target.is_file() else find_test_files(target, source.lower())
+        if not files:
+            console.print(f"[red]❌ Error: No {source} test files found in {target}[/red]")
+            raise typer.Exit(1)
+        
+        llm_client = None
+        if llm:
+            from testgen.core.llm import LLMClient
+            llm_client = LLMClient()
+        
+        patches = []
+        for test_file in files:
+            result = migrate_file(str(test_file), source, to, verify=verify, llm_client=llm_client)
+            relative = test_file.relative_to(Path.cwd()) if test_file.is_relative_to(Path.cwd()) else test_file
+            
+            if not result.changed:
+                console.print(f"[dim]{relative}: nothing to migrate[/dim]")
+                continue
+            if not result.verified:
+                console.print(f"[yellow]⚠️  {relative} left as is: {result.problem}[/yellow]")
+                continue
+            
+            checked = f"{len(result.outcomes)} tests, same outcomes" if result.outcomes else "unverified"
+            via = ", with LLM help" if result.llm_used else ""
+            console.print(f"[green]✓[/green] {relative}: {source} → {to} ({checked}{via})")
+            for leftover in result.leftovers:
+                escaped = str(leftover).replace("[", "\\[")
+                console.print(f"   [yellow]left over[/yellow] {escaped}")
+            
+            patches.append(make_patch(result.original, result.migrated, relative))
+            if apply:
+                test_file.write_text(result.migrated, encoding='utf-8')
+        
+        if not patches:
+            console.print("[dim]No files migrated[/dim]")
+            return
+        
+        patch = "".join(patches)
+        if output:
+            output.write_text(patch, encoding='utf-8')
+            console.print(f"[green]✓[/green] Patch written to {output}")
+        elif not apply:
+            sys.stdout.write(patch)
+        if apply:
+            console.print(f"[green]✓[/green] Migrated {len(patches)} file(s)")
+    
+    except typer.Exit:
+        raise
+    except Exception as e:
+        console.print(f"[red]❌ Error migrating tests: {e}[/red]")
+        if state.debug:
+            console.print_exception()
+        raise typer.Exit(1)
+
+
+@app.command()
+def port(
+    source_dir: Path = typer.Argument(
+        ...,
+        help="Original implementation, with its tests",
+        exists=True,
+        file_okay=False,
+        resolve_path=True,
+    ),
+    target_dir: Path = typer.Argument(
+        ...,
+        help="The port (tests are translated into it)",
+        exists=True,
+        file_okay=False,
+        resolve_path=True,
+    ),
+    source: str = typer.Option(
+        "python",
+        "--from",
+        help="Language ported from: python or go",
+    ),
+    to: str = typer.Option(
+        "go",
+        "--to",
+        help="Language ported to: go or python",
+    ),
+    report: Optional[Path] = typer.Option(
+        None,
+        "--report",
+        "-r",
+        help="Write the test mapping and outcomes as JSON",
+    ),
+    dry_run: bool = typer.Option(
+        False,
+        "--dry-run",
+        help="Only map the tests; don't translate them",
+    ),
+    run: bool = typer.Option(
+        True,
+        "--run/--no-run",
+        help="Run the original and translated tests and compare outcomes",
+    ),
+):
+    """
+    Translate a test suite to the language code was ported to.
+    
+    Indexes the symbols of both implementations, maps each original test
+    to the port's counterparts of what it calls (reporting the tests that
+    can't be mapped), translates the mapped tests with the LLM and runs
+    both suites to show which tests have the same outcome on the port.
+    
+    Examples:
+        testgen port services/cart ./cart --from python --to go
+        testgen port services/cart ./cart --dry-run
+        testgen port ./cart services/cart --from go --to python -r parity.json
+    """
+    from testgen.core.language_config import Language
+    from testgen.core.port import port_tests, save_report
+    
+    try:
+        try:
+            source_language, target_language = Language(source.lower()), Language(to.lower())
+        except ValueError:
+            console.print(f"[red]❌ Error: Unsupported port {source} → {to} (supported: python → go, go → python)[/red]")
+            raise typer.Exit(1)
+        
+        llm_client = None
+        if not dry_run:
+            from testgen.core.llm import LLMClient
+            llm_client = LLMClient()
+        
+        try:
+            result = port_tests(
+                str(source_dir), str(target_dir), source_language, target_language,
+                llm_client=llm_client, run=run
+            )
+        except ValueError as e:
+            console.print(f"[red]❌ Error: {e}[/red]")
+            raise typer.Exit(1)
+        
+        if not result.mappings:
+            console.print(f"[red]❌ Error: No {source_language.value} tests found in {source_dir}[/red]")
+            raise typer.Exit(1)
+        
+        for mapping in result.mapped:
+            targets = ", ".join(t.name for t in mapping.symbols.values())
+            line = f"{mapping.test.id} → {mapping.target_test} ({targets})".replace("[", "\\[")
+            if mapping.parity is None:
+                console.print(f"  {line}")
+            elif mapping.parity:
+                console.print(f"[green]✓[/green] {line} [dim]{mapping.target_outcome}[/dim]")
+            else:
+                console.print(f"[red]✗[/red] {line}: {mapping.source_outcome} → {mapping.target_outcome}")
+        for mapping in result.unmapped:
+            console.print(f"[yellow]⚠️  {mapping.test.id} not mapped: {mapping.problem}[/yellow]")
+        for problem in result.problems:
+            console.print(f"[yellow]⚠️  {problem}[/yellow]")
+        
+        for path in result.files:
+            console.print(f"[green]✓[/green] Wrote {path}")
+        summary = f"{len(result.mapped)} of {len(result.mappings)} tests mapped"
+        if any(m.parity is not None for m in result.mapped):
+            summary += f", {len(result.diverging)} with different outcomes on the port"
+        console.print(f"\n[bold]{summary}[/bold]")
+        
+        if report:
+            save_report(result, report)
+            console.print(f"[green]✓[/green] Report written to {report}")
+        if result.diverging:
+            raise typer.Exit(1)
+    
+    except typer.Exit:
+        raise
+    except Exception as e:
+        console.print(f"[red]❌ Error porting tests: {e}[/red]")
+        if state.debug:
+            console.print_exception()
+        raise typer.Exit(1)
+
+
+@app.command()
+def allure(
+    run_id: Optional[str] = typer.Argument(
+        None,
+        help="Stored run to export (default: the latest)",
+    ),
+    output: Path = typer.Option(
+        Path("allure-results"),
+        "--output",
+        "-o",
+        help="Allure results directory",
+    ),
+    language: Optional[str] = typer.Option(
+        None,
+        "--language",
+        "-l",
+        help="Latest run of this language",
+    ),
+    project_root: Path = typer.Option(
+        Path("."),
+        "--project-root",
+        help="Root the test files and CODEOWNERS are relative to",
+        exists=True,
+        file_okay=False,
+    ),
+    profile_dir: Optional[Path] = typer.Option(
+        None,
+        "--profiles",
+        help="Directory of profiles to attach to their package (e.g. .testgen-cache/profiles)",
+    ),
+    history: Optional[Path] = typer.Option(
+        None,
+        "--history",
+        help="Previous Allure report, whose history/ keeps the trends going",
+    ),
+    clean: bool = typer.Option(
+        False,
+        "--clean",
+        help="Empty the results directory first",
+    ),
+):
+    """
+    Export a stored test run as Allure results.
+    
+    Writes one result per test with Go subtests as nested steps, logs and
+    golden diffs as attachments, language/package/owner/test type labels
+    and stable history IDs. Open it with `allure serve allure-results`.
+    
+    Examples:
+        testgen allure
+        testgen allure --language go --profiles .testgen-cache/profiles
+        testgen allure 20260101-120000-000000 -o build/allure-results --history build/allure-report
+    """
+    import shutil
+    from testgen.core.allure import copy_history, export_run
+    from testgen.core.run_store import RunStore
+    
+    try:
+        store = RunStore(cache_dir=str(config.cache_dir))
+        if run_id is None:
+            runs = store.list_runs(language)
+            if not runs:
+                where = f" for {language}" if language else ""
+                console.print(f"[red]❌ Error: No stored runs{where}[/red]")
+                console.print("[yellow]💡 Hint: Runs are stored when tests are run through TestGen[/yellow]")
+                raise typer.Exit(1)
+            run_id = runs[-1]["id"]
+        
+        run = store.load_run(run_id)
+        if run is None:
+            console.print(f"[red]❌ Error: Run '{run_id}' not found[/red]")
+            raise typer.Exit(1)
+        
+        if clean and output.exists():
+            shutil.rmtree(output)
+        written = export_run(run, output, project_root=project_root, profile_dir=profile_dir)
+        console.print(f"[green]✓[/green] Exported run {run_id}: {len(written)} results to {output}")
+        
+        if history:
+            if copy_history(history, output):
+                console.print(f"[green]✓[/green] History copied from {history}")
+            else:
+                console.print(f"[yellow]⚠️  No history/ in {history}; trends start with this run[/yellow]")
+    
+    except typer.Exit:
+        raise
+    except Exception as e:
+        console.print(f"[red]❌ Error exporting Allure results: {e}[/red]")
+        if state.debug:
+            console.print_exception()
+        raise typer.Exit(1)
+
+
+@app.command()
+def gc(
+    keep_last: Optional[int] = typer.Option(
+        None,
+        "--keep-last",
+        help="Most recent runs that keep their artifacts (default: [retention] in testgen.toml, else 20)",
+    ),
+    keep_failing_days: Optional[int] = typer.Option(
+        None,
+        "--keep-failing-days",
+        help="Days failing runs keep their artifacts (default: 14)",
+    ),
+    max_size_mb: Optional[float] = typer.Option(
+        None,
+        "--max-size",
+        help="Cap on all run artifacts in MB, oldest removed first; 0 = no cap (default: 500)",
+    ),
+    dry_run: bool = typer.Option(
+        False,
+        "--dry-run",
+        help="Only show what would be removed",
+    ),
+):
+    """
+    Remove run artifacts and cache entries past their retention.
+    
+    Applies the retention policy from testgen.toml ([retention]), overridden
+    by the options: the last N runs keep their artifacts, failing runs keep
+    them for N days, and a size cap removes the oldest beyond it. Run
+    results stay for the run history; expired scan/LLM cache entries and
+    artifacts of runs no longer stored are removed too.
+    
+    Examples:
+        testgen gc
+        testgen gc --dry-run
+        testgen gc --keep-last 5 --keep-failing-days 30 --max-size 200
+    """
+    from testgen.core.artifacts import collect_garbage
+    from testgen.core.project_config import load_project_config
+    from testgen.core.run_store import RunStore
+    
+    try:
+        retention = load_project_config(".").retention
+        store = RunStore(cache_dir=str(config.cache_dir))
+        report = collect_garbage(
+            store,
+            keep_last=retention.keep_last if keep_last is None else keep_last,
+            keep_failing_days=retention.keep_failing_days if keep_failing_days is None else keep_failing_days,
+            max_size_mb=retention.max_size_mb if max_size_mb is None else max_size_mb,
+            dry_run=dry_run,
+        )
+        
+        verb = "Would remove" if dry_run else "Removed"
+        for run_id in report.removed_runs:
+            console.print(f"[dim]{verb} artifacts of run {run_id}[/dim]")
+        for run_id in report.orphans:
+            console.print(f"[dim]{verb} artifacts of deleted run {run_id}[/dim]")
+        
+        freed = report.freed / (1024 * 1024)
+        console.print(
+            f"[green]✓[/green] {verb} {len(report.removed_runs) + len(report.orphans)} run artifact directories "
+            f"and {report.cache_entries} expired cache entries ({freed:.1f} MB)"
+        )
+        console.print(
+            f"[dim]Kept artifacts of {report.kept_runs} runs ({report.kept_size / (1024 * 1024):.1f} MB)[/dim]"
+        )
+    
+    except Exception as e:
+        console.print(f"[red]❌ Error collecting garbage: {e}[/red]")
+        if state.debug:
+            console.print_exception()
+        raise typer.Exit(1)
+
+
+@app.command()
+def bench(
+    target: Path = typer.Argument(
+        Path("."),
+        help="Go package directory (or tree of packages)",
+        exists=True,
+        file_okay=False,
+        dir_okay=True,
+        resolve_path=True,
+    ),
+    functions: Optional[List[str]] = typer.Option(
+        None,
+        "--func",
+        "-f",
+        help="Function to benchmark (Name or Type.Method); repeatable",
+    ),
+    profile: bool = typer.Option(
+        True,
+        "--profile/--no-profile",
+        help="Find hot paths by CPU-profiling the slow tests",
+    ),
+    slow: float = typer.Option(
+        0.1,
+        "--slow",
+        help="Tests averaging this many seconds in stored runs are profiled (all tests if none are recorded)",
+    ),
+    min_percent: float = typer.Option(
+        5.0,
+        "--min-percent",
+        help="Share of the profiled CPU time that makes a function a hot path",
+    ),
+    run: bool = typer.Option(
+        True,
+        "--run/--no-run",
+        help="Run the generated benchmarks and record them with the benchmark history",
+    ),
+):
+    """
+    Generate Go benchmarks for hot paths.
+    
+    Writes testgen_bench_test.go with BenchmarkXxx functions for functions
+    flagged with --func or a //testgen:benchmark comment, and for hot paths
+    found by CPU-profiling the package's slow tests. Benchmarks report
+    allocations, run sub-benchmarks across input sizes, reset the timer
+    after setup and keep results in sinks. Then runs them and reports
+    regressions against the benchmark history.
+    
+    Examples:
+        testgen bench ./internal/parse
+        testgen bench ./internal --func Parse --func Index.Lookup --no-profile
+        testgen bench . --slow 0.5 --min-percent 10
+    """
+    from testgen.core.benchmark import BenchmarkHarness, BenchmarkStore, format_benchmark_report
+    from testgen.core.go_benchmarks import (
+        BENCH_TEST_FILE, describe_hot_paths, go_import_path, profile_hot_paths,
+        select_benchmark_targets, slow_tests, write_go_benchmarks,
+    )
+    from testgen.core.run_store import RunStore
+    
+    try:
+        package_dirs = sorted({
+            f.parent for f in target.rglob("*.go")
+            if not any(part in ("vendor", "testdata") or part.startswith(".") for part in f.relative_to(target).parts)
+        })
+        history = RunStore(cache_dir=str(config.cache_dir)).test_history(language="go", last_runs=10) if profile else {}
+        
+        results = []
+        selected = set()
+        for package_dir in package_dirs:
+            relative = package_dir.relative_to(target) if package_dir != target else Path(".")
+            hot = {}
+            if profile and any(package_dir.glob("*_test.go")):
+                import_path = go_import_path(str(package_dir))
+                tests = slow_tests(history, import_path, slow) if import_path else []
+                if import_path and (tests or not any(h.suite == import_path for h in history.values())):
+                    hot = profile_hot_paths(str(package_dir), tests=tests or None, min_percent=min_percent)
+            
+            targets = select_benchmark_targets(str(package_dir), functions or [], hot)
+            selected.update(t.qualified_name for t in targets)
+            if not targets:
+                continue
+            for name, percent in hot.items():
+                console.print(f"[cyan]🔥 {relative}: {name} ({percent:.1f}% of CPU in slow tests)[/cyan]")
+            
+            remaining = describe_hot_paths(targets)
+            if remaining:
+                console.print(f"[yellow]⚠️  No generated benchmark (write one, or generate it with the LLM) in {relative}:[/yellow]")
+                console.print(remaining, markup=False, highlight=False)
+            
+            written = write_go_benchmarks(str(package_dir), targets)
+            if written is None:
+                continue
+            names = [t.benchmark_name for t in targets if t.generatable]
+            console.print(f"[green]✓[/green] {', '.join(names)} written to {relative / BENCH_TEST_FILE}")
+            if run:
+                results.extend(BenchmarkHarness("go").run_go(str(package_dir), names))
+        
+        for name in sorted(set(functions or []) - selected):
+            console.print(f"[yellow]⚠️  {name} not found (or already has a benchmark)[/yellow]")
+        
+        if not results:
+            if run:
+                console.print("[dim]No benchmarks generated[/dim]")
+            return
+        
+        console.print(format_benchmark_report(results))
+        
+        store = BenchmarkStore()
+        regressions = store.find_regressions(results)
+        store.record(results)
+        for regression in regressions:
+            console.print(f"[yellow]⚠️  {regression.key}: p50 {regression.change:+.0%} vs. recorded runs[/yellow]")
+        
+        if any(r.error for r in results):
+            raise typer.Exit(1)
+    
+    except typer.Exit:
+        raise
+    except Exception as e:
+        console.print(f"[red]❌ Error generating benchmarks: {e}[/red]")
+        if state.debug:
+            console.print_exception()
+        raise typer.Exit(1)
+
+
+@app.command()
+def allocs(
+    target: Path = typer.Argument(
+        Path("."),
+        help="Go package directory (or tree of packages)",
+        exists=True,
+        file_okay=False,
+        dir_okay=True,
+        resolve_path=True,
+    ),
+    hints: bool = typer.Option(
+        True,
+        "--hints/--no-hints",
+        help="Show escape analysis (go build -gcflags=-m) for functions over budget",
+    ),
+):
+    """
+    Check allocation budgets of Go functions.
+    
+    Budgets come from //testgen:allocs N directives above functions and
+    [[alloc_budgets]] in testgen.toml. Writes testgen_alloc_test.go with
+    testing.AllocsPerRun checks across input sizes, runs them, and shows
+    what escapes to the heap in functions that exceed their budget.
+    
+    Examples:
+        testgen allocs ./internal/codec
+        testgen allocs . --no-hints
+    """
+    from testgen.core.alloc_budgets import (
+        ALLOC_TEST_FILE, configured_budgets, describe_alloc_budgets, escape_hints,
+        find_alloc_budgets, over_budget, unverified_budgets, write_go_alloc_tests,
+    )
+    from rich.markup import escape
+    from testgen.core.go_runner import GoTestRunner
+    from testgen.core.project_config import load_project_config
+    
+    try:
+        project = load_project_config(str(target))
+        package_dirs = sorted({
+            f.parent for f in target.rglob("*.go")
+            if not any(part in ("vendor", "testdata") or part.startswith(".") for part in f.relative_to(target).parts)
+        })
+        
+        checked = 0
+        unchecked = 0
+        failed = False
+        for package_dir in package_dirs:
+            relative = package_dir.relative_to(target) if package_dir != target else Path(".")
+            budgets = find_alloc_budgets(str(package_dir), configured_budgets(project, str(package_dir)))
+            if not budgets:
+                continue
+            
+            remaining = describe_alloc_budgets(budgets)
+            if remaining:
+                console.print(f"[yellow]⚠️  No generated test (write one, or generate it with the LLM) in {relative}:[/yellow]")
+                console.print(remaining, markup=False, highlight=False)
+            
+            if write_go_alloc_tests(str(package_dir), budgets) is None:
+                continue
+            console.print(f"[green]✓[/green] Allocation budget tests written to {relative / ALLOC_TEST_FILE}")
+            
+            results = GoTestRunner().run_tests(str(package_dir), run="^TestAllocs", packages=["."])
+            if results.total == 0 and results.errors:
+                console.print(f"[red]❌ {relative}: allocation tests failed to build or run[/red]")
+                failed = True
+                continue
+            
+            over = over_budget(results, budgets)
+            unverified = unverified_budgets(results, budgets)
+            explained = escape_hints(str(package_dir), [b for b in budgets if b.target.qualified_name in over]) if over and hints else {}
+            for budget in budgets:
+                if not budget.target.generatable:
+                    continue
+                checked += 1
+                name = budget.target.qualified_name
+                if name not in over:
+                    measured = [t for t in results.tests if t.name.startswith(f"{budget.test_name}/") and t.status == "passed"]
+                    if measured:
+                        console.print(f"  [green]✓[/green] {relative}: {name} within {budget.budget} allocations per call")
+                    else:
+                        unchecked += 1
+                        console.print(f"  [yellow]⚠️[/yellow]  {relative}: {name} not verified: no input size was measured")
+                    for message in unverified.get(name, []):
+                        console.print(f"      skipped {message}", markup=False, highlight=False)
+                    continue
+                failed = True
+                console.print(f"  [red]✗[/red] {relative}: {name} over its budget of {budget.budget}")
+                for message in over[name]:
+                    console.print(f"      {message}", markup=False, highlight=False)
+                for hint in explained.get(name, []):
+                    console.print(f"      [dim]{escape(str(hint))}[/dim]", highlight=False)
+                if hints and not explained.get(name):
+                    console.print(f"      [dim]Nothing escapes in {name} itself: the allocations are in the functions it calls[/dim]")
+        
+        if not checked:
+            console.print("[dim]No allocation budgets to check (add //testgen:allocs N or [[alloc_budgets]])[/dim]")
+            return
+        if failed:
+            raise typer.Exit(1)
+        console.print(f"\n[green]✓ {checked - unchecked} allocation budget(s) kept[/green]")
+        if unchecked:
+            console.print(f"[yellow]⚠️  {unchecked} allocation budget(s) not verified (no input size was measured)[/yellow]")
+    
+    except typer.Exit:
+        raise
+    except Exception as e:
+        console.print(f"[red]❌ Error checking allocation budgets: {e}[/red]")
+        if state.debug:
+            console.print_exception()
+        raise typer.Exit(1)
+
+
+@app.command()
+def generated(
+    target: Path = typer.Argument(
+        Path("."),
+        help="Go module or package directory",
+        exists=True,
+        file_okay=False,
+        dir_okay=True,
+        resolve_path=True,
+    ),
+    regenerate: bool = typer.Option(
+        False,
+        "--regenerate",
+        help="Run go generate in a scratch copy and compare its output with the working tree",
+    ),
+):
+    """
+    Find Go packages whose go:generate output is stale.
+    
+    Lists the //go:generate directives of every package and the generated
+    files (mocks, stringer output, ...) that are older than their inputs or,
+    with --regenerate, that differ from what go generate writes now. The
+    working tree is never modified. Exits with 1 when anything is stale.
+    
+    Examples:
+        testgen generated
+        testgen generated ./internal/store --regenerate
+    """
+    from testgen.core.go_generate import check_generated, describe_generated
+    
+    try:
+        packages = check_generated(str(target), regenerate=regenerate)
+        if not packages:
+            console.print("[dim]No //go:generate directives found[/dim]")
+            return
+        
+        console.print(describe_generated(packages, str(target)), markup=False, highlight=False)
+        stale = [p for p in packages if p.is_stale]
+        if stale:
+            console.print(f"\n[red]✗ Stale generated code in {len(stale)} package(s): run go generate[/red]")
+            raise typer.Exit(1)
+        console.print(f"\n[green]✓ Generated code up to date in {len(packages)} package(s)[/green]")
+        unchecked = [p for p in packages if p.error]
+        if unchecked:
+            console.print(f"[yellow]⚠️  {len(unchecked)} package(s) checked by modification time only: go generate couldn't run[/yellow]")
+    
+    except typer.Exit:
+        raise
+    except Exception as e:
+        console.print(f"[red]❌ Error checking generated code: {e}[/red]")
+        if state.debug:
+            console.print_exception()
+        raise typer.Exit(1)
+
+
 # CLI entry point
 def cli():
     """Main entry point for the CLI."""
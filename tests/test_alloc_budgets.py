@@ -0,0 +1,176 @@
+"""
+Unit tests for Go allocation budgets.
+
+This test suite covers:
+- Budgets from //testgen:allocs directives and testgen.toml
+- Generating testing.AllocsPerRun tests across input sizes
+- Mapping failing and skipped subtests and escape analysis back to functions
+- Running the generated tests against zero-allocation and allocating code
+"""
+
+import shutil
+
+import pytest
+from testgen.core.alloc_budgets import (
+    ALLOC_TEST_FILE, EscapeHint, configured_budgets, describe_alloc_budgets, escape_hints,
+    find_alloc_budgets, generate_go_alloc_tests, over_budget, unverified_budgets, write_go_alloc_tests
+)
+from testgen.core.base_runner import TestResult, TestResults
+from testgen.core.go_benchmarks import BENCH_TEST_FILE, select_benchmark_targets, write_go_benchmarks
+from testgen.core.go_runner import GoTestRunner
+from testgen.core.project_config import load_project_config
+
+
+GO_CODEC = """package codec
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// AppendInt appends n in decimal to dst.
+//testgen:allocs 0
+func AppendInt(dst []byte, n int) []byte {
+	return strconv.AppendInt(dst, int64(n), 10)
+}
+
+// Upper returns s upper-cased.
+//testgen:allocs 0
+func Upper(s string) string {
+	buf := []byte(s)
+	for i, c := range buf {
+		if 'a' <= c && c <= 'z' {
+			buf[i] = c - 32
+		}
+	}
+	return string(buf)
+}
+
+//testgen:allocs 2
+func Skip(r io.Reader) (int64, error) {
+	return io.Copy(io.Discard, r)
+}
+
+func Join(xs []string) string {
+	return strings.Join(xs, ",")
+}
+
+type Encoder struct{ buf []byte }
+
+//testgen:allocs 0
+func (e *Encoder) Encode(v int) []byte {
+	e.buf = strconv.AppendInt(e.buf[:0], int64(v), 10)
+	return e.buf
+}
+"""
+
+
+class TestFindBudgets:
+    """Test where budgets come from."""
+    
+    def test_directives_and_config(self, tmp_path):
+        """Test directive budgets, and testgen.toml budgets overriding and adding to them."""
+        (tmp_path / "testgen.toml").write_text(
+            '[[alloc_budgets]]\npackage = "codec"\nfunction = "Join"\nmax_allocs = 1\n\n'
+            '[[alloc_budgets]]\npackage = "codec"\nfunction = "Upper"\nmax_allocs = 3\n\n'
+            '[[alloc_budgets]]\npackage = "other"\nfunction = "AppendInt"\nmax_allocs = 5\n'
+        )
+        (tmp_path / "codec").mkdir()
+        (tmp_path / "codec" / "codec.go").write_text(GO_CODEC)
+        project = load_project_config(str(tmp_path / "codec"))
+        
+        configured = configured_budgets(project, str(tmp_path / "codec"))
+        budgets = find_alloc_budgets(str(tmp_path / "codec"), configured)
+        
+        assert configured == {"Join": 1, "Upper": 3}
+        assert [(b.target.qualified_name, b.budget, b.source) for b in budgets] == [
+            ("AppendInt", "0", "directive"),
+            ("Upper", "3", "config"),
+            ("Skip", "2", "directive"),
+            ("Join", "1", "config"),
+            ("Encoder.Encode", "0", "directive"),
+        ]
+        assert describe_alloc_budgets(budgets) == (
+            "- TestAllocsEncoderEncode: Encoder.Encode(v int) (line 39): at most 0 allocations per call"
+        )
+
+
+class TestGenerateAllocTests:
+    """Test the generated test file and reading its results."""
+    
+    def test_generated_tests(self, tmp_path):
+        """Test AllocsPerRun over sized inputs, with reader resets and sinks."""
+        (tmp_path / "codec.go").write_text(GO_CODEC)
+        budgets = find_alloc_budgets(str(tmp_path))
+        
+        source = generate_go_alloc_tests("codec", budgets)
+        
+        assert "var testgenAllocSizes = []int{10, 100, 1000}" in source
+        assert (
+            '\t\t\tdata := []byte(testgenAllocInput("lines", n))\n'
+            "\t\t\tr := bytes.NewReader(data)\n"
+            "\t\t\tif _, err := Skip(r); err != nil {\n"
+            '\t\t\t\tt.Skipf("sample input rejected: %v", err)\n'
+            "\t\t\t}\n"
+            "\t\t\tallocs := testing.AllocsPerRun(100, func() {\n"
+            "\t\t\t\tr.Reset(data)\n"
+            "\t\t\t\ttestgenAllocSinkSkip, testgenAllocSinkErr = Skip(r)\n"
+            "\t\t\t})\n"
+            "\t\t\tif allocs > 2 {\n"
+            '\t\t\t\tt.Errorf("%v allocations per call, budget 2", allocs)\n'
+        ) in source
+        assert "// TestAllocsUpper checks Upper's budget of 0 allocations per call (//testgen:allocs)." in source
+        assert "func testgenAllocInput(format string, n int) string {" in source
+        assert "TestAllocsEncoderEncode" not in source and "testgenBench" not in source
+    
+    def test_over_budget(self, tmp_path):
+        """Test failing subtests map to their function with the measured allocations."""
+        (tmp_path / "codec.go").write_text(GO_CODEC)
+        budgets = find_alloc_budgets(str(tmp_path))
+        results = TestResults(language="go", framework="testing", tests=[
+            TestResult(name="TestAllocsUpper/n=10", status="failed", message="testgen_alloc_test.go:42: 2 allocations per call, budget 0\n"),
+            TestResult(name="TestAllocsUpper", status="failed"),
+            TestResult(name="TestAllocsAppendInt/n=10", status="passed"),
+            TestResult(name="TestOther/n=10", status="failed", message="2 allocations"),
+        ])
+        
+        assert over_budget(results, budgets) == {"Upper": ["n=10: 2 allocations per call, budget 0"]}
+        assert str(EscapeHint("codec.go", 24, "string(buf) escapes to heap")) == "codec.go:24: string(buf) escapes to heap"
+    
+    def test_unverified(self, tmp_path):
+        """Test subtests skipped on a rejected sample input are unverified, not within budget."""
+        (tmp_path / "codec.go").write_text(GO_CODEC)
+        budgets = find_alloc_budgets(str(tmp_path))
+        results = TestResults(language="go", framework="testing", tests=[
+            TestResult(name="TestAllocsSkip/n=10", status="skipped", message="testgen_alloc_test.go:61: sample input rejected: EOF\n"),
+            TestResult(name="TestAllocsSkip/n=100", status="passed"),
+            TestResult(name="TestAllocsUpper/n=10", status="failed", message="2 allocations per call, budget 0"),
+        ])
+        
+        assert unverified_budgets(results, budgets) == {"Skip": ["n=10: sample input rejected: EOF"]}
+        assert over_budget(results, budgets) == {"Upper": ["n=10: 2 allocations per call, budget 0"]}
+
+
+@pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+class TestRunAllocTests:
+    """Test the generated tests with the Go toolchain."""
+    
+    def test_budgets_kept_and_exceeded(self, tmp_path):
+        """Test zero-allocation code passes, allocating code fails with escape hints, next to generated benchmarks."""
+        (tmp_path / "go.mod").write_text("module example.com/codec\n\ngo 1.22\n")
+        (tmp_path / "codec.go").write_text(GO_CODEC)
+        budgets = find_alloc_budgets(str(tmp_path))
+        write_go_benchmarks(str(tmp_path), select_benchmark_targets(str(tmp_path), ["Upper", "Skip"]))
+        
+        assert write_go_alloc_tests(str(tmp_path), budgets) == tmp_path / ALLOC_TEST_FILE
+        results = GoTestRunner().run_tests(str(tmp_path), run="^TestAllocs", packages=["."], measure_usage=False)
+        over = over_budget(results, budgets)
+        hints = escape_hints(str(tmp_path), [b for b in budgets if b.target.qualified_name in over])
+        
+        assert (tmp_path / BENCH_TEST_FILE).exists()
+        assert results.total > 0
+        assert set(over) == {"Upper"}
+        assert [m.split(":")[0] for m in over["Upper"]] == ["n=10", "n=100", "n=1000"]
+        assert any("escapes to heap" in h.message and h.file == "codec.go" for h in hints["Upper"])
+        assert all(17 <= h.line <= 25 for h in hints["Upper"])
@@ -0,0 +1,189 @@
+"""
+Unit tests for the Allure results export.
+
+This test suite covers:
+- Go subtests nested as steps, with logs and golden diffs attached
+- Language, package, owner (CODEOWNERS) and test type labels
+- Stable history IDs across runs, retries and package containers
+- CODEOWNERS pattern matching
+"""
+
+import json
+
+from testgen.core.allure import CodeOwners, classify_test, copy_history, export_run, golden_diff
+from testgen.core.base_runner import TestAttempt, TestResult, TestResults
+from testgen.core.benchmark import go_profile_name
+from testgen.core.run_store import RunStore
+
+
+GOLDEN_FAILURE = """render_test.go:31: Render() mismatch (-want +got):
+  strings.Join({
+  \t"<h1>",
+- \t"Cart",
++ \t"cart",
+  \t"</h1>",
+  }, "")"""
+
+
+def go_run(tmp_path):
+    """A stored Go run of a package with CODEOWNERS, subtests, a golden diff and a flaky test."""
+    (tmp_path / "go.mod").write_text("module example.com/shop\n\ngo 1.22\n")
+    (tmp_path / "cart").mkdir(exist_ok=True)
+    (tmp_path / "cart" / "render_test.go").write_text(
+        "package cart\n\nfunc TestRender(t *testing.T) {}\n\n// testgen:security path_traversal\n"
+        "func TestSecurityDownloadPathTraversal(t *testing.T) {}\n"
+    )
+    (tmp_path / "cart" / "testgen_fault_test.go").write_text("package cart\n\nfunc TestFaultSave(t *testing.T) {}\n")
+    (tmp_path / ".github").mkdir(exist_ok=True)
+    (tmp_path / ".github" / "CODEOWNERS").write_text("* @platform\n/cart/ @shop-team @qa\n")
+    
+    package = "example.com/shop/cart"
+    results = TestResults(language="go", framework="testing", tests=[
+        TestResult(name="TestRender/empty", status="passed", duration=0.01, suite=package),
+        TestResult(name="TestRender/items/one", status="failed", duration=0.02, message=GOLDEN_FAILURE, suite=package),
+        TestResult(name="TestRender/items", status="failed", duration=0.02, suite=package),
+        TestResult(name="TestRender", status="failed", duration=0.04, suite=package),
+        TestResult(name="TestSecurityDownloadPathTraversal", status="passed", suite=package),
+        TestResult(name="TestFaultSave", status="passed_on_retry", duration=0.5, suite=package, attempts=[
+            TestAttempt(attempt=1, status="failed", duration=0.3, message="timeout"),
+            TestAttempt(attempt=2, status="passed", duration=0.5),
+        ]),
+    ])
+    store = RunStore(cache_dir=str(tmp_path / ".testgen-cache"))
+    return store, store.record_run(results, test_dir=str(tmp_path))
+
+
+def read_results(directory):
+    """Exported results by name (retries are listed after the final result)."""
+    results = {}
+    for path in sorted(directory.glob("*-result.json")):
+        data = json.loads(path.read_text())
+        results.setdefault(data["name"], []).append(data)
+    for attempts in results.values():
+        attempts.sort(key=lambda r: -r["start"])
+    return results
+
+
+def labels(result):
+    return {label["name"]: label["value"] for label in result["labels"]}
+
+
+class TestExport:
+    """Test exporting a stored Go run."""
+    
+    def test_subtests_become_nested_steps(self, tmp_path):
+        """Test `t.Run` subtests nest by path and the golden diff is attached to its step."""
+        store, run_id = go_run(tmp_path)
+        output = tmp_path / "allure-results"
+        
+        export_run(store.load_run(run_id), output, project_root=tmp_path)
+        
+        render = read_results(output)["TestRender"][0]
+        assert render["status"] == "failed"
+        assert [s["name"] for s in render["steps"]] == ["empty", "items"]
+        inner = render["steps"][1]["steps"][0]
+        assert (inner["name"], inner["status"]) == ("one", "failed")
+        assert inner["statusDetails"]["message"] == "render_test.go:31: Render() mismatch (-want +got):"
+        attachments = {a["name"]: (output / a["source"]).read_text() for a in inner["attachments"]}
+        assert attachments["Log"] == GOLDEN_FAILURE
+        assert attachments["Golden diff"].startswith("render_test.go:31: Render() mismatch (-want +got):\n")
+        assert inner["stop"] - inner["start"] == 20
+    
+    def test_labels(self, tmp_path):
+        """Test the language, package, owner and test type labels."""
+        store, run_id = go_run(tmp_path)
+        output = tmp_path / "allure-results"
+        
+        export_run(store.load_run(run_id), output, project_root=tmp_path)
+        
+        results = read_results(output)
+        render = labels(results["TestRender"][0])
+        assert render["language"] == "go"
+        assert render["package"] == "example.com/shop/cart"
+        assert render["owner"] == "@shop-team, @qa"
+        assert render["testType"] == "unit"
+        assert labels(results["TestSecurityDownloadPathTraversal"][0])["testType"] == "security"
+        assert labels(results["TestFaultSave"][0])["testType"] == "fault-injection"
+        assert "language=go\nframework=testing\n" in (output / "environment.properties").read_text()
+    
+    def test_history_ids_and_retries(self, tmp_path):
+        """Test history IDs stay the same across runs and a failed attempt is exported as a retry."""
+        store, first = go_run(tmp_path)
+        _, second = go_run(tmp_path)
+        
+        export_run(store.load_run(first), tmp_path / "first", project_root=tmp_path)
+        export_run(store.load_run(second), tmp_path / "second", project_root=tmp_path)
+        
+        before, after = read_results(tmp_path / "first"), read_results(tmp_path / "second")
+        assert before["TestRender"][0]["historyId"] == after["TestRender"][0]["historyId"]
+        assert before["TestRender"][0]["uuid"] != after["TestRender"][0]["uuid"]
+        final, retry = after["TestFaultSave"]
+        assert (final["status"], final["statusDetails"]["flaky"]) == ("passed", True)
+        assert (retry["status"], retry["statusDetails"]["message"]) == ("failed", "timeout")
+        assert retry["historyId"] == final["historyId"]
+        assert retry["stop"] == final["start"]
+    
+    def test_container_with_profiles(self, tmp_path):
+        """Test results are grouped per package, with the package's profiles attached (not a same-named package's)."""
+        store, run_id = go_run(tmp_path)
+        profiles = tmp_path / "profiles"
+        profiles.mkdir()
+        (profiles / go_profile_name("example.com/shop/cart")).write_bytes(b"\x1f\x8b profile")
+        (profiles / go_profile_name("example.com/shop/cmd/cart")).write_bytes(b"")
+        (profiles / "other.cpu.prof").write_bytes(b"")
+        output = tmp_path / "allure-results"
+        
+        written = export_run(store.load_run(run_id), output, project_root=tmp_path, profile_dir=profiles)
+        
+        containers = [json.loads(p.read_text()) for p in output.glob("*-container.json")]
+        assert [c["name"] for c in containers] == ["example.com/shop/cart"]
+        assert len(containers[0]["children"]) == 3
+        attachment = containers[0]["befores"][0]["attachments"][0]
+        assert attachment["name"] == go_profile_name("example.com/shop/cart")
+        assert attachment["name"].startswith("cart-") and attachment["name"].endswith(".cpu.prof")
+        assert len(containers[0]["befores"][0]["attachments"]) == 1
+        assert (output / attachment["source"]).read_bytes() == b"\x1f\x8b profile"
+        assert len(written) == 4
+    
+    def test_copy_history(self, tmp_path):
+        """Test a previous report's history is copied into the results."""
+        (tmp_path / "report" / "history").mkdir(parents=True)
+        (tmp_path / "report" / "history" / "history-trend.json").write_text("[]")
+        
+        assert copy_history(tmp_path / "report", tmp_path / "results")
+        assert (tmp_path / "results" / "history" / "history-trend.json").read_text() == "[]"
+        assert not copy_history(tmp_path / "missing", tmp_path / "results")
+
+
+class TestHelpers:
+    """Test classification, diffs and CODEOWNERS."""
+    
+    def test_classify(self):
+        """Test security markers and generator naming conventions map to test types."""
+        assert classify_test("TestSecurityHeaders/crlf", None, {"TestSecurityHeaders": "header_injection"}) == "security"
+        assert classify_test("TestSecurityHeaders", None) == "unit"
+        assert classify_test("TestLoadCheckout", None) == "load"
+        assert classify_test("tests/test_contract_web.py::test_contract_web[GET /users/1]", None) == "contract"
+        assert classify_test("TestDiscount", "cart/cart_port_test.go") == "port"
+        assert classify_test("BenchmarkTotal", None) == "benchmark"
+        assert classify_test("tests/test_cart.py::TestCart::test_total", "tests/test_cart.py") == "unit"
+    
+    def test_golden_diff(self):
+        """Test unified diffs are found and plain failures aren't diffs."""
+        unified = "golden mismatch:\n--- testdata/page.golden\n+++ got\n@@ -1 +1 @@\n-a\n+b\n"
+        
+        assert golden_diff(unified) == "--- testdata/page.golden\n+++ got\n@@ -1 +1 @@\n-a\n+b\n"
+        assert golden_diff("expected 2, got 3") is None
+    
+    def test_codeowners(self):
+        """Test anchoring, directories, globs and last-match-wins."""
+        owners = CodeOwners.parse(
+            "# comment\n* @all\n*.go @gophers\ndocs/ @docs\n/build/** @ci\napps/*/api @api\n"
+        )
+        
+        assert owners.owners("README.md") == ["@all"]
+        assert owners.owners("cart/cart.go") == ["@gophers"]
+        assert owners.owners("site/docs/index.md") == ["@docs"]
+        assert owners.owners("build/scripts/x.sh") == ["@ci"]
+        assert owners.owners("src/build/x.sh") == ["@all"]
+        assert owners.owners("apps/web/api/handler.py") == ["@api"]
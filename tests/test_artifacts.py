@@ -0,0 +1,204 @@
+"""
+Unit tests for run artifacts and their retention.
+
+This test suite covers:
+- Keeping output, reports and golden diffs in a run's artifact directory
+- Indexing artifacts in the run store and attaching them to Allure results
+- Snapshots of generated tests from ordered runs
+- Retention (last N runs, failing runs for N days, size cap) and orphans
+- Keeping the go test -json stream and cover profile
+"""
+
+import json
+import os
+import shutil
+from datetime import datetime, timedelta
+from unittest.mock import Mock
+
+import pytest
+from testgen.core.allure import export_run
+from testgen.core.artifacts import collect_garbage, is_generated
+from testgen.core.base_runner import TestResult, TestResults
+from testgen.core.cache import CacheManager
+from testgen.core.go_runner import GoTestRunner
+from testgen.core.run_store import RunStore
+from testgen.core.test_executor import TestExecutionConfig, UniversalTestExecutor
+
+
+def record(store, failed=0, size=0, when=None):
+    """Record a run with `size` bytes of output, as if it ran at `when`."""
+    artifacts = store.new_artifacts()
+    if size:
+        artifacts.add_text("output", "go-test.stdout.txt", "x" * size)
+    results = TestResults(language="go", framework="testing", failed=failed, tests=[
+        TestResult(name="TestA", status="failed" if failed else "passed", suite="example.com/a")
+    ])
+    run_id = store.record_run(results, artifacts=artifacts)
+    if when is not None:
+        index = json.loads(store.index_file.read_text())
+        index[-1]["timestamp"] = when.isoformat()
+        store.index_file.write_text(json.dumps(index))
+    return run_id
+
+
+class TestRunArtifacts:
+    """Test keeping and indexing a run's artifacts."""
+    
+    def test_recorded_with_the_run(self, tmp_path):
+        """Test the run is stored under the artifacts' ID with an index of them."""
+        store = RunStore(str(tmp_path / "cache"))
+        artifacts = store.new_artifacts()
+        artifacts.add_output("go-test", '{"Action":"pass"}\n', "warning\n", stdout_kind="json")
+        artifacts.add_output("go-test", '{"Action":"fail"}\n', None, stdout_kind="json")
+        results = TestResults(language="go", framework="testing", tests=[
+            TestResult(name="TestRender", status="failed", suite="example.com/a", message="--- want\n+++ got\n-a\n+b\n"),
+        ])
+        artifacts.add_golden_diffs(results)
+        
+        run_id = store.record_run(results, artifacts=artifacts)
+        
+        run = store.load_run(run_id)
+        assert run_id == artifacts.run_id
+        assert [(a["kind"], a["path"], a["test"]) for a in run["artifacts"]] == [
+            ("json", "json/go-test.json", None),
+            ("output", "output/go-test.stderr.txt", None),
+            ("json", "json/go-test-2.json", None),
+            ("golden-diff", "golden-diff/TestRender.diff", "example.com/a::TestRender"),
+        ]
+        assert (tmp_path / "cache" / "artifacts" / run_id / "json" / "go-test-2.json").read_text() == '{"Action":"fail"}\n'
+        assert store.list_runs()[0]["artifacts_size"] == artifacts.size > 0
+    
+    def test_unknown_kind(self, tmp_path):
+        """Test only the documented kinds are accepted."""
+        with pytest.raises(ValueError, match="Unknown artifact kind: logs"):
+            RunStore(str(tmp_path)).new_artifacts().add_text("logs", "x.txt", "")
+    
+    def test_allure_attachments(self, tmp_path):
+        """Test per-test artifacts go on their test and the rest on a run container."""
+        store = RunStore(str(tmp_path / "cache"))
+        artifacts = store.new_artifacts()
+        artifacts.add_output("pytest", "1 failed\n", None)
+        results = TestResults(language="python", framework="pytest", tests=[
+            TestResult(name="tests/test_page.py::test_page", status="failed", message="@@ -1 +1 @@\n-a\n+b\n"),
+        ])
+        artifacts.add_golden_diffs(results)
+        run_id = store.record_run(results, artifacts=artifacts)
+        output = tmp_path / "allure-results"
+        
+        export_run(store.load_run(run_id), output, project_root=tmp_path)
+        
+        result = json.loads(next(output.glob("*-result.json")).read_text())
+        assert [a["name"] for a in result["attachments"]] == ["Log", "tests_test_page.py.test_page.diff"]
+        containers = [json.loads(p.read_text()) for p in output.glob("*-container.json")]
+        run_container = next(c for c in containers if c["name"] == f"run {run_id}")
+        attachment = run_container["befores"][0]["attachments"][0]
+        assert (attachment["name"], attachment["type"]) == ("pytest.stdout.txt", "text/plain")
+        assert run_container["children"] == [result["uuid"]]
+    
+    def test_snapshot_of_generated_tests(self, tmp_path):
+        """Test an ordered run keeps the generated test files it ran, and only those."""
+        tests = tmp_path / "tests"
+        tests.mkdir()
+        (tests / "test_gen.py").write_text('"""\nAuto-generated test file.\n\nGenerator: TestGen AI\n"""\n\ndef test_x(): pass\n')
+        (tests / "test_manual.py").write_text("def test_y(): pass\n")
+        runner = Mock()
+        runner.get_language.return_value = "python"
+        runner.get_framework.return_value = "pytest"
+        runner.discover_tests.return_value = sorted(tests.glob("test_*.py"))
+        runner.run_tests.return_value = TestResults(language="python", framework="pytest", total=1, passed=1, tests=[
+            TestResult(name="test_x", status="passed")
+        ])
+        store = RunStore(str(tmp_path / "cache"))
+        
+        UniversalTestExecutor(runner).execute_ordered(str(tests), TestExecutionConfig(), run_store=store, changed_files=[])
+        
+        run = store.load_run(store.list_runs()[0]["id"])
+        assert [a["path"] for a in run["artifacts"]] == ["snapshot/test_gen.py"]
+        assert runner.artifacts is None
+        assert is_generated(tests / "test_gen.py") and not is_generated(tests / "test_manual.py")
+
+
+class TestRetention:
+    """Test `collect_garbage`."""
+    
+    def test_keep_last_and_failing(self, tmp_path):
+        """Test old passing runs lose their artifacts, recent failing ones keep them."""
+        store = RunStore(str(tmp_path / "cache"))
+        now = datetime(2026, 10, 16, 12, 0)
+        old_failing = record(store, failed=1, size=10, when=now - timedelta(days=30))
+        recent_failing = record(store, failed=1, size=10, when=now - timedelta(days=3))
+        old_passing = record(store, size=10, when=now - timedelta(days=2))
+        latest = record(store, size=10, when=now)
+        
+        report = collect_garbage(store, keep_last=1, keep_failing_days=14, max_size_mb=0, now=now)
+        
+        assert report.removed_runs == [old_failing, old_passing]
+        assert (report.kept_runs, report.kept_size, report.freed) == (2, 20, 20)
+        assert not (store.artifacts_dir / old_failing).exists()
+        assert (store.artifacts_dir / recent_failing).exists() and (store.artifacts_dir / latest).exists()
+        dropped = store.load_run(old_failing)
+        assert dropped["artifacts"] == [] and dropped["tests"][0]["name"] == "TestA"
+        assert [r["artifacts_size"] for r in store.list_runs()] == [0, 10, 0, 10]
+    
+    def test_size_cap_orphans_and_cache(self, tmp_path):
+        """Test the cap removes the oldest first but never the latest run, plus old orphans and expired cache."""
+        store = RunStore(str(tmp_path / "cache"))
+        first = record(store, size=600 * 1024)
+        second = record(store, size=600 * 1024)
+        orphan = store.artifacts_dir / "20200101-000000-000000"
+        (orphan / "output").mkdir(parents=True)
+        for path in (orphan / "output", orphan):
+            os.utime(path, (0, (datetime.now() - timedelta(days=2)).timestamp()))
+        # A run still in progress: not recorded yet, written to just now
+        in_progress = store.artifacts_dir / "29990101-000000-000000"
+        (in_progress / "output").mkdir(parents=True)
+        (in_progress / "output" / "go-test.stdout.txt").write_text("=== RUN TestA\n")
+        cache = CacheManager(str(tmp_path / "cache"))
+        cache.set("old", "value", category="llm")
+        entry = tmp_path / "cache" / "llm" / "old.json"
+        data = json.loads(entry.read_text())
+        data["expires_at"] = "2020-01-01T00:00:00"
+        entry.write_text(json.dumps(data))
+        
+        report = collect_garbage(store, keep_last=10, max_size_mb=1, dry_run=True)
+        assert report.removed_runs == [first] and (store.artifacts_dir / first).exists()
+        
+        report = collect_garbage(store, keep_last=10, max_size_mb=1)
+        
+        assert report.removed_runs == [first]
+        assert report.orphans == ["20200101-000000-000000"]
+        assert report.cache_entries == 1 and not entry.exists()
+        assert sorted(p.name for p in store.artifacts_dir.iterdir()) == [second, in_progress.name]
+    
+    def test_record_run_prunes_artifacts(self, tmp_path):
+        """Test runs pruned by max_runs take their artifacts with them."""
+        store = RunStore(str(tmp_path / "cache"), max_runs=1)
+        first = record(store, size=1)
+        second = record(store, size=1)
+        
+        assert [p.name for p in store.artifacts_dir.iterdir()] == [second]
+        assert store.load_run(first) is None
+
+
+@pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+class TestGoRunnerArtifacts:
+    """Test the Go runner keeps its output."""
+    
+    def test_json_stream_and_cover_profile(self, tmp_path):
+        """Test the -json stream and the cover profile are kept."""
+        (tmp_path / "go.mod").write_text("module example.com/calc\n\ngo 1.22\n")
+        (tmp_path / "calc.go").write_text("package calc\n\nfunc Half(n int) int { return n / 2 }\n")
+        (tmp_path / "calc_test.go").write_text(
+            'package calc\n\nimport "testing"\n\nfunc TestHalf(t *testing.T) {\n\tif Half(4) != 2 {\n\t\tt.Fatal("Half(4)")\n\t}\n}\n'
+        )
+        runner = GoTestRunner()
+        runner.artifacts = RunStore(str(tmp_path / "cache")).new_artifacts()
+        
+        results = runner.run_tests(str(tmp_path), packages=["."], extra_args=["-coverprofile=cover.out"], measure_usage=False)
+        
+        assert results.passed == 1
+        kept = {a.path: a for a in runner.artifacts.artifacts}
+        assert set(kept) == {"json/go-test.json", "coverage/cover.out"}
+        stream = (runner.artifacts.directory / "json" / "go-test.json").read_text()
+        assert '"Test":"TestHalf"' in stream
+        assert (runner.artifacts.directory / "coverage" / "cover.out").read_text().startswith("mode: set")
@@ -0,0 +1,146 @@
+"""
+Unit tests for the micro-benchmark harness.
+
+This test suite covers:
+- Statistics (mean, percentiles, stddev, outliers)
+- Go benchmark output parsing
+- Python harness execution
+- Regression tracking
+"""
+
+import subprocess
+
+import pytest
+from testgen.core.benchmark import (
+    BenchmarkHarness,
+    BenchmarkStore,
+    calculate_statistics,
+    go_profile_name,
+)
+
+
+class TestStatistics:
+    """Test benchmark statistics."""
+    
+    def test_percentiles_and_mean(self):
+        """Test percentiles interpolate over sorted samples."""
+        stats = calculate_statistics([float(i) for i in range(1, 101)])
+        
+        assert stats["mean"] == pytest.approx(50.5)
+        assert stats["p50"] == pytest.approx(50.5)
+        assert stats["p95"] == pytest.approx(95.05)
+        assert stats["p99"] == pytest.approx(99.01)
+        assert stats["min"] == 1.0
+        assert stats["max"] == 100.0
+    
+    def test_outliers_detected(self):
+        """Test samples far outside the IQR are reported as outliers."""
+        stats = calculate_statistics([1.0, 1.1, 0.9, 1.0, 1.05, 0.95, 10.0])
+        
+        assert stats["outliers"] == [10.0]
+    
+    def test_empty_samples(self):
+        """Test empty samples produce zeroed statistics."""
+        stats = calculate_statistics([])
+        
+        assert stats["mean"] == 0.0
+        assert stats["outliers"] == []
+
+
+class TestGoBenchmarks:
+    """Test native Go benchmark parsing."""
+    
+    def test_parse_benchmem_output(self):
+        """Test every -count run becomes one sample."""
+        output = "\n".join([
+            "goos: linux",
+            "pkg: example.com/calc",
+            "BenchmarkAdd-8   \t    1000\t       100.0 ns/op\t      16 B/op\t       1 allocs/op",
+            "BenchmarkAdd-8   \t    1000\t       120.0 ns/op\t      16 B/op\t       1 allocs/op",
+            "BenchmarkParse/n=1000-8   \t    1000\t      5000 ns/op",
+            "PASS",
+        ])
+        
+        harness = BenchmarkHarness("go", iterations=1000)
+        results = {r.name: r for r in harness._parse_go_output(output, ".")}
+        
+        add = results["BenchmarkAdd"]
+        assert add.samples == pytest.approx([100e-9, 120e-9])
+        assert add.bytes_per_op == 16.0
+        assert add.allocs_per_op == 1.0
+        assert add.file_path == "example.com/calc"
+        assert results["BenchmarkParse/n=1000"].allocs_per_op is None
+    
+    def test_benchtime_only_when_asked(self, monkeypatch):
+        """Test go test calibrates b.N unless a -benchtime is given."""
+        commands = []
+        monkeypatch.setattr(
+            "subprocess.run",
+            lambda cmd, **kwargs: commands.append(cmd) or subprocess.CompletedProcess(cmd, 0, "", "")
+        )
+        
+        BenchmarkHarness("go", iterations=1000).run_go(".")
+        BenchmarkHarness("go", go_benchtime="500x").run_go(".")
+        
+        assert "-benchtime" not in commands[0]
+        assert commands[1][commands[1].index("-benchtime") + 1] == "500x"
+    
+    def test_profiles_keyed_on_import_path(self, tmp_path, monkeypatch):
+        """Test same-named packages get separate profiles, named after their import paths."""
+        def fake_run(cmd, **kwargs):
+            with open(cmd[cmd.index("-cpuprofile") + 1], "wb") as f:
+                f.write(b"profile")
+            output = f"pkg: {kwargs['cwd']}\nBenchmarkGet-8 \t 100 \t 50.0 ns/op\nok\n"
+            return subprocess.CompletedProcess(cmd, 0, output, "")
+        monkeypatch.setattr("subprocess.run", fake_run)
+        harness = BenchmarkHarness("go", profile=True, profile_dir=str(tmp_path))
+        
+        internal = harness.run_go("example.com/app/internal/store")[0]
+        command = harness.run_go("example.com/app/cmd/store")[0]
+        
+        assert internal.profile_path == str(tmp_path / go_profile_name("example.com/app/internal/store"))
+        assert command.profile_path == str(tmp_path / go_profile_name("example.com/app/cmd/store"))
+        assert internal.profile_path != command.profile_path
+        assert sorted(p.name for p in tmp_path.iterdir()) == sorted(
+            [go_profile_name("example.com/app/internal/store"), go_profile_name("example.com/app/cmd/store")]
+        )
+
+
+class TestPythonHarness:
+    """Test timing Python tests."""
+    
+    def test_warmup_and_iterations(self, tmp_path):
+        """Test measured iterations are recorded and fixtures are reported."""
+        test_file = tmp_path / "test_perf.py"
+        test_file.write_text(
+            "def test_sum():\n"
+            "    sum(range(100))\n"
+            "\n"
+            "def test_needs_fixture(tmp_path):\n"
+            "    pass\n"
+        )
+        
+        harness = BenchmarkHarness("python", iterations=5, warmup_iterations=2)
+        results = {r.name: r for r in harness.run(str(test_file))}
+        
+        assert len(results["test_sum"].samples) == 5
+        assert results["test_sum"].warmup_iterations == 2
+        assert results["test_sum"].p99 >= results["test_sum"].p50
+        assert "tmp_path" in results["test_needs_fixture"].error
+
+
+class TestRegressionTracking:
+    """Test benchmark history and regressions."""
+    
+    def test_slowdown_reported(self, tmp_path):
+        """Test a p50 well above the recorded baseline is a regression."""
+        harness = BenchmarkHarness("go")
+        baseline = harness._build_result("BenchmarkAdd", "pkg", [1.0, 1.0, 1.0])
+        slower = harness._build_result("BenchmarkAdd", "pkg", [1.5, 1.5, 1.5])
+        
+        store = BenchmarkStore(str(tmp_path))
+        store.record([baseline])
+        regressions = store.find_regressions([slower], threshold=0.10)
+        
+        assert len(regressions) == 1
+        assert regressions[0].change == pytest.approx(0.5)
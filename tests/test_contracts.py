@@ -0,0 +1,273 @@
+"""
+Unit tests for consumer-driven contract tests.
+
+This test suite covers:
+- Path templates from client URL expressions (Go, Python, JavaScript)
+- Responses stubbed in consumer tests (httptest, responses/respx, nock)
+- Building and diffing Pact-style contracts
+- Static route checks and generated provider verification
+"""
+
+import shutil
+import subprocess
+
+import pytest
+from testgen.core.base_runner import TestResult
+from testgen.core.contracts import (
+    Contract, breaks_from_results, build_contract, check_provider_routes, diff_contracts,
+    extract_client_calls, extract_routes, extract_test_interactions, generate_provider_test, shape_problems
+)
+from testgen.core.project_config import ContractConfig
+
+
+GO_CLIENT = """package web
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type UsersClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func (c *UsersClient) Get(id string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/users/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.http.Do(req)
+}
+
+func (c *UsersClient) Delete(id int) (*http.Response, error) {
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("%s/users/%d", c.baseURL, id), nil)
+	return c.http.Do(req)
+}
+
+func Ping(statusURL string) {
+	http.Get(statusURL + "/status")
+}
+"""
+
+GO_CLIENT_TEST = """package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetUser(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/42":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id": "42", "name": "Ada", "tags": ["admin"]}`))
+		case "/users/missing":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	c := &UsersClient{baseURL: srv.URL, http: srv.Client()}
+	c.Get("42")
+}
+"""
+
+GO_PROVIDER = """package users
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+func NewRouter() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/users/")
+		if id != "42" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"id": id, "name": "Ada", "tags": []string{"admin"}, "age": 36})
+	})
+	return mux
+}
+"""
+
+
+def make_services(root, provider=GO_PROVIDER):
+    """Write a Go consumer (web) and provider (users) under root."""
+    (root / "web").mkdir()
+    (root / "web" / "client.go").write_text(GO_CLIENT)
+    (root / "web" / "client_test.go").write_text(GO_CLIENT_TEST)
+    (root / "users").mkdir()
+    (root / "users" / "users.go").write_text(provider)
+    (root / "users" / "go.mod").write_text("module example.com/users\n\ngo 1.22\n")
+    return ContractConfig(consumer="web", consumer_dir="web", provider="users", provider_dir="users", base_url="baseURL")
+
+
+class TestClientCalls:
+    """Test finding the endpoints a consumer calls."""
+    
+    def test_go_calls_become_path_templates(self):
+        """Test Go concatenation and Sprintf URLs become templates, other services are skipped."""
+        calls = extract_client_calls(GO_CLIENT, "go", base_url="baseURL", source="client.go")
+        
+        assert [(c.method, c.path) for c in calls] == [("GET", "/users/{id}"), ("DELETE", "/users/{id}")]
+        assert calls[0].source == "client.go:14"
+    
+    def test_python_and_javascript_calls(self):
+        """Test requests f-strings and fetch template literals with a method option."""
+        python = 'def get(self, id):\n    return self.session.get(f"{self.base_url}/users/{id}?expand=1", timeout=5)\n'
+        javascript = "await fetch(`${USERS_URL}/users/${user.id}`, { method: 'PUT', body })"
+        
+        python_calls = extract_client_calls(python, "python")
+        js_calls = extract_client_calls(javascript, "javascript")
+        
+        assert (python_calls[0].method, python_calls[0].path, python_calls[0].query) == ("GET", "/users/{id}", "expand=1")
+        assert (js_calls[0].method, js_calls[0].path) == ("PUT", "/users/{id}")
+
+
+class TestStubbedResponses:
+    """Test finding the responses consumer tests rely on."""
+    
+    def test_go_httptest_stubs(self):
+        """Test paths, status constants and raw-string JSON bodies of httptest handlers."""
+        interactions = extract_test_interactions(GO_CLIENT_TEST, "go")
+        
+        assert [(i.path, i.status) for i in interactions] == [("/users/42", 200), ("/users/missing", 404)]
+        assert interactions[0].response_body == {"id": "42", "name": "Ada", "tags": ["admin"]}
+    
+    def test_python_and_nock_stubs(self):
+        """Test responses/respx calls and nock interceptors."""
+        python = (
+            "import responses\n\n"
+            "@responses.activate\n"
+            "def test_get():\n"
+            "    responses.add(responses.GET, 'http://users/users/7', json={'id': '7'}, status=200)\n"
+            "    respx.post('http://users/users').mock(return_value=httpx.Response(201, json={'id': '8'}))\n"
+        )
+        javascript = "nock(USERS_URL).get('/users/7').reply(200, { id: '7', name: 'Ada' });"
+        
+        python_stubs = extract_test_interactions(python, "python")
+        js_stubs = extract_test_interactions(javascript, "javascript")
+        
+        assert [(i.method, i.path, i.status, i.response_body) for i in python_stubs] == [
+            ("GET", "/users/7", 200, {"id": "7"}),
+            ("POST", "/users", 201, {"id": "8"}),
+        ]
+        assert js_stubs[0].response_body == {"id": "7", "name": "Ada"}
+
+
+class TestContracts:
+    """Test building, storing and diffing contracts."""
+    
+    def test_build_contract_round_trip(self, tmp_path):
+        """Test stubs matching client calls are kept and uncovered calls stay templates."""
+        config = make_services(tmp_path)
+        
+        contract = build_contract(config, tmp_path)
+        contract.save(tmp_path / "contracts" / contract.file_name)
+        loaded = Contract.load(tmp_path / "contracts" / "web-users.json")
+        
+        assert [i.description for i in contract.interactions] == ["GET /users/42", "GET /users/missing", "DELETE /users/{id}"]
+        assert contract.interactions[2].status is None
+        assert loaded.to_dict() == contract.to_dict()
+        assert loaded.to_dict()["metadata"]["pactSpecification"]["version"] == "2.0.0"
+    
+    def test_consumer_changes_are_reported(self, tmp_path):
+        """Test a changed expected status and body shape, and a removed interaction."""
+        old = build_contract(make_services(tmp_path), tmp_path)
+        new = Contract.from_dict(old.to_dict())
+        new.interactions[0].response_body = {"id": 42, "name": "Ada", "tags": ["admin"]}
+        new.interactions[1].status = 410
+        del new.interactions[2]
+        
+        reasons = [(b.interaction, b.reason) for b in diff_contracts(old, new)]
+        
+        assert ("GET /users/42", "expected response changed: $.id: expected number, got string") in reasons
+        assert ("GET /users/missing", "expected status changed from 404 to 410") in reasons
+        assert ("DELETE /users/{id}", "interaction removed") in reasons
+    
+    def test_shape_problems_allow_extra_fields(self):
+        """Test type matching: extra fields pass, missing fields and wrong types don't."""
+        want = {"id": "1", "tags": ["a"]}
+        
+        assert shape_problems(want, {"id": "2", "tags": ["b", "c"], "age": 3}) == []
+        assert shape_problems(want, {"tags": [1]}) == ["$.id: missing", "$.tags[0]: expected string, got number"]
+
+
+class TestProvider:
+    """Test provider-side checks."""
+    
+    def test_routes_and_static_check(self, tmp_path):
+        """Test routes from several frameworks and unmatched interactions."""
+        flask = "@app.route('/users/<int:id>', methods=['GET', 'DELETE'])\ndef user(id): ...\n"
+        go = 'r.HandleFunc("/orders/{id}", h).Methods("POST")\nmux.HandleFunc("GET /users/{id}", get)\n'
+        
+        assert extract_routes(flask, "python") == [("GET", "/users/<int:id>"), ("DELETE", "/users/<int:id>")]
+        assert extract_routes(go, "go") == [("POST", "/orders/{id}"), ("GET", "/users/{id}")]
+        
+        config = make_services(tmp_path, provider=GO_PROVIDER.replace('"/users/"', '"GET /users/{id}"'))
+        contract = build_contract(config, tmp_path)
+        breaks = check_provider_routes(contract, tmp_path / "users")
+        
+        assert [(b.interaction, b.side) for b in breaks] == [("DELETE /users/{id}", "provider")]
+    
+    def test_breaks_from_verification_results(self, tmp_path):
+        """Test failed Go subtests and pytest parameters map back to interactions."""
+        contract = build_contract(make_services(tmp_path), tmp_path)
+        tests = [
+            TestResult(name="TestContractWeb/GET_/users/42", status="failed", message="    $.tags: missing\n"),
+            TestResult(name="TestContractWeb/GET_/users/missing", status="passed"),
+            TestResult(name="tests/test_contract_web.py::test_contract_web[GET /users/42]", status="failed"),
+            TestResult(name="TestOther", status="failed"),
+        ]
+        
+        breaks = breaks_from_results(contract, tests)
+        
+        assert [(b.interaction, b.reason) for b in breaks] == [
+            ("GET /users/42", "$.tags: missing"),
+            ("GET /users/42", "verification failed"),
+        ]
+    
+    def test_generated_python_provider_test(self, tmp_path):
+        """Test a Flask app gets a parametrized test-client verification."""
+        config = make_services(tmp_path)
+        shutil.rmtree(tmp_path / "users")
+        (tmp_path / "users").mkdir()
+        (tmp_path / "users" / "app.py").write_text("from flask import Flask\n\napp = Flask(__name__)\n")
+        
+        test_file, source = generate_provider_test(build_contract(config, tmp_path), config, tmp_path)
+        
+        assert test_file == tmp_path / "users" / "tests" / "test_contract_web.py"
+        assert "from app import app" in source
+        assert 'CONTRACT = Path(__file__).resolve().parent / "../../contracts/web-users.json"' in source
+        assert "client.open(target, method=request[\"method\"], **kwargs)" in source
+        compile(source, str(test_file), "exec")
+    
+    @pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+    def test_go_provider_verification(self, tmp_path):
+        """Test the generated Go test passes, then fails when the provider drops a field."""
+        config = make_services(tmp_path)
+        contract = build_contract(config, tmp_path)
+        contract.save(tmp_path / "contracts" / contract.file_name)
+        test_file, source = generate_provider_test(contract, config, tmp_path)
+        test_file.write_text(source)
+        
+        def run():
+            return subprocess.run(["go", "test", "-run", "^TestContract", "-v", "."], cwd=tmp_path / "users",
+                                  capture_output=True, text=True)
+        
+        passing = run()
+        assert passing.returncode == 0, passing.stdout + passing.stderr
+        assert "--- SKIP: TestContractWeb/DELETE_/users/{id}" in passing.stdout
+        
+        (tmp_path / "users" / "users.go").write_text(GO_PROVIDER.replace('"tags": []string{"admin"}, ', ""))
+        failing = run()
+        assert failing.returncode != 0
+        assert "$.tags: missing" in failing.stdout
@@ -0,0 +1,147 @@
+"""
+Unit tests for Go fault-injection tests.
+
+This test suite covers:
+- Finding reader, writer, context and dependency seams in Go code
+- Generating iotest-based tests and fault<Interface> wrappers
+- Running generated tests against propagating and swallowing code
+"""
+
+import shutil
+import subprocess
+
+import pytest
+from testgen.core.fault_injection import (
+    FAULT_TEST_FILE, describe_fault_seams, find_fault_seams, find_go_interfaces,
+    generate_go_fault_test, write_go_fault_test
+)
+
+
+GO_STREAM = """package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type Store interface {
+	Save(ctx context.Context, key string, value []byte) error
+	List(prefix string, opts ...string) ([]string, time.Time, error)
+	Name() string
+}
+
+// Upper copies r to w upper-cased and closes r.
+func Upper(ctx context.Context, r io.ReadCloser, w io.Writer) error {
+	defer r.Close()
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		if _, err := fmt.Fprintln(w, strings.ToUpper(s.Text())); err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+	}
+	return s.Err()
+}
+
+// Decode loses the cause: %v instead of %w.
+func Decode(r io.Reader) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode: %v", err)
+	}
+	return out, nil
+}
+
+func (s *Syncer) Sync(ctx context.Context, store Store, keys []string) error {
+	return nil
+}
+
+func helper(n int) int {
+	return n
+}
+"""
+
+
+class TestFindSeams:
+    """Test finding the seams to inject faults into."""
+    
+    def test_seams_and_generatable_functions(self):
+        """Test readers, writers, contexts, closers and dependencies are found."""
+        seams = {s.qualified_name: s for s in find_fault_seams(GO_STREAM)}
+        
+        assert list(seams) == ["Upper", "Decode", "Syncer.Sync"]
+        assert seams["Upper"].kinds == ["reader", "writer", "context"]
+        assert seams["Upper"].closes == ["r"]
+        assert seams["Decode"].input_format == "json"
+        assert seams["Decode"].results == 2
+        assert seams["Syncer.Sync"].kinds == ["context", "dependency"]
+        assert [s.generatable for s in seams.values()] == [True, True, False]
+    
+    def test_interfaces_with_error_methods(self):
+        """Test interface methods are parsed with unnamed results and variadics."""
+        interfaces = find_go_interfaces(GO_STREAM)
+        
+        assert [i.wrapper for i in interfaces] == ["faultStore"]
+        name, params, results = interfaces[0].methods[1]
+        assert (name, [p.type for p in params], results) == ("List", ["string", "...string"], ["[]string", "time.Time", "error"])
+    
+    def test_describe_remaining_seams(self):
+        """Test seams without generated tests and the wrappers are described for the prompt."""
+        description = describe_fault_seams(find_fault_seams(GO_STREAM), find_go_interfaces(GO_STREAM))
+        
+        assert "- Syncer.Sync (line 40): ctx context.Context, store Store" in description
+        assert "faultStore{Store: real, failOn: n, err: errInjected} fails call n of Save, List" in description
+        assert "Upper" not in description
+
+
+class TestGenerateFaultTests:
+    """Test the generated Go source."""
+    
+    def test_generated_subtests_and_wrappers(self):
+        """Test iotest readers, failing writers, cancellation, cleanup checks and imports."""
+        source = generate_go_fault_test(
+            "stream", find_fault_seams(GO_STREAM), find_go_interfaces(GO_STREAM), {"time": "time"}
+        )
+        
+        assert "func TestFaultUpper(t *testing.T) {" in source
+        assert 'src := &testgenCloser{Reader: iotest.ErrReader(errInjected)}' in source
+        assert "iotest.TimeoutReader(" in source
+        assert "dst := &testgenFaultWriter{short: true}" in source
+        assert 't.Run("context cancelled mid-read"' in source
+        assert 'if !src.closed {' in source
+        assert "_, err := Decode(src)" in source
+        assert 'strings.NewReader("{\\"id\\": 1, \\"name\\": \\"test\\", \\"ite")' in source
+        assert "return f.Store.List(p0, p1...)" in source
+        assert '\t"testing/iotest"\n\t"time"\n)' in source
+    
+    def test_nothing_to_write(self, tmp_path):
+        """Test packages without seams get no file."""
+        (tmp_path / "util.go").write_text("package util\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n")
+        
+        assert write_go_fault_test(str(tmp_path)) is None
+        assert not (tmp_path / FAULT_TEST_FILE).exists()
+
+
+@pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+class TestRunFaultTests:
+    """Test generated tests with the Go toolchain."""
+    
+    def test_propagating_code_passes_and_swallowing_code_fails(self, tmp_path):
+        """Test Upper handles every fault while Decode's %v wrapping is caught."""
+        (tmp_path / "go.mod").write_text("module example.com/stream\n\ngo 1.22\n")
+        (tmp_path / "stream.go").write_text(GO_STREAM + "\ntype Syncer struct{}\n")
+        
+        path = write_go_fault_test(str(tmp_path))
+        vet = subprocess.run(["go", "vet", "."], cwd=tmp_path, capture_output=True, text=True)
+        result = subprocess.run(["go", "test", "-v", "."], cwd=tmp_path, capture_output=True, text=True)
+        
+        assert path == tmp_path / FAULT_TEST_FILE
+        assert vet.returncode == 0, vet.stderr
+        assert "--- PASS: TestFaultUpper " in result.stdout
+        assert "--- PASS: TestFaultUpper/context_cancelled_mid-read" in result.stdout
+        assert "--- FAIL: TestFaultDecode/reader_fails_midway " in result.stdout
+        assert "error not propagated" in result.stdout
@@ -0,0 +1,204 @@
+"""
+Unit tests for generated Go benchmarks.
+
+This test suite covers:
+- Finding flagged functions and hot paths in pprof output and slow tests
+- Generating sized sub-benchmarks with sinks, ReportAllocs and ResetTimer
+- Profiling slow tests and running the generated benchmarks
+"""
+
+import shutil
+import subprocess
+
+import pytest
+from testgen.core.base_runner import TestResult, TestResults
+from testgen.core.go_benchmarks import (
+    BENCH_TEST_FILE, describe_hot_paths, find_go_functions, generate_go_benchmarks, parse_pprof_top,
+    profile_hot_paths, select_benchmark_targets, slow_tests, write_go_benchmarks
+)
+from testgen.core.run_store import RunStore
+
+
+GO_TEXT = """package text
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+type Record struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Decode parses a JSON array of records.
+func Decode(data []byte) ([]Record, error) {
+	var records []Record
+	err := json.Unmarshal(data, &records)
+	return records, err
+}
+
+// CountLines counts the lines of r.
+//testgen:benchmark
+func CountLines(r io.Reader, limit int) (int, error) {
+	s := bufio.NewScanner(r)
+	n := 0
+	for s.Scan() && n < limit {
+		n++
+	}
+	return n, s.Err()
+}
+
+func Stamp(words []string, upper bool) (string, time.Duration) {
+	if upper {
+		return strings.ToUpper(strings.Join(words, " ")), 0
+	}
+	return strings.Join(words, " "), time.Second
+}
+
+type Index struct{ words map[string]int }
+
+func (ix *Index) Lookup(word string) int {
+	return ix.words[word]
+}
+"""
+
+PPROF_TOP = """File: text.test
+Type: cpu
+Showing nodes accounting for 1.20s, 100% of 1.20s total
+      flat  flat%   sum%        cum   cum%
+         0     0%     0%      1.20s   100%  testing.tRunner
+         0     0%     0%      1.10s 91.67%  example.com/app/text.TestSearch
+     0.05s  4.17%  4.17%      0.90s 75.00%  example.com/app/text.(*Index).Lookup
+     0.40s 33.33% 37.50%      0.60s 50.00%  example.com/app/text.Decode.func1
+     0.10s  8.33% 45.83%      0.30s 25.00%  example.com/app/text.Decode (inline)
+     0.20s 16.67% 62.50%      0.20s 16.67%  example.com/app/textutil.Split
+     0.02s  1.67% 64.17%      0.02s  1.67%  example.com/app/text.Stamp
+"""
+
+
+class TestFindTargets:
+    """Test finding what to benchmark."""
+    
+    def test_functions_and_directive(self):
+        """Test signatures, the //testgen:benchmark flag and which functions are generatable."""
+        functions = {f.qualified_name: f for f in find_go_functions(GO_TEXT)}
+        
+        assert list(functions) == ["Decode", "CountLines", "Stamp", "Index.Lookup"]
+        assert [f.name for f in functions.values() if f.flagged] == ["CountLines"]
+        assert functions["Decode"].input_format == "json"
+        assert [p.type for p in functions["Stamp"].results] == ["string", "time.Duration"]
+        assert [f.generatable for f in functions.values()] == [True, True, True, False]
+        assert functions["Index.Lookup"].benchmark_name == "BenchmarkIndexLookup"
+    
+    def test_hot_paths_in_pprof_output(self):
+        """Test the package's functions above the threshold, with methods and closures, excluding tests."""
+        hot = parse_pprof_top(PPROF_TOP, "example.com/app/text", min_percent=5.0)
+        
+        assert hot == {"Index.Lookup": 75.0, "Decode": 50.0}
+    
+    def test_slow_tests_from_stored_runs(self, tmp_path):
+        """Test slow top-level tests of the package are picked, slowest first."""
+        store = RunStore(str(tmp_path))
+        store.record_run(TestResults(language="go", framework="testing", tests=[
+            TestResult(name="TestSearch", status="passed", duration=0.8, suite="example.com/app/text"),
+            TestResult(name="TestSearch/long", status="passed", duration=0.7, suite="example.com/app/text"),
+            TestResult(name="TestDecode", status="passed", duration=0.2, suite="example.com/app/text"),
+            TestResult(name="TestStamp", status="passed", duration=0.01, suite="example.com/app/text"),
+            TestResult(name="TestSplit", status="passed", duration=2.0, suite="example.com/app/textutil"),
+        ]))
+        
+        history = store.test_history(language="go")
+        
+        assert slow_tests(history, "example.com/app/text", min_duration=0.1) == ["TestSearch", "TestDecode"]
+    
+    def test_selection(self, tmp_path):
+        """Test flagged, hot and previously generated targets, minus hand-written benchmarks."""
+        (tmp_path / "text.go").write_text(GO_TEXT)
+        (tmp_path / "text_test.go").write_text("package text\n\nfunc BenchmarkDecode(b *testing.B) {}\n")
+        (tmp_path / BENCH_TEST_FILE).write_text("package text\n\n// BenchmarkStamp benchmarks Stamp (flagged).\n")
+        
+        targets = select_benchmark_targets(str(tmp_path), ["Index.Lookup"], {"Decode": 50.0, "CountLines": 20.0})
+        
+        assert [t.qualified_name for t in targets] == ["CountLines", "Stamp", "Index.Lookup"]
+        assert targets[0].reason == "20.0% of CPU in slow tests"
+        assert describe_hot_paths(targets) == "- BenchmarkIndexLookup: Index.Lookup(word string) int (line 43, flagged)"
+
+
+class TestGenerateBenchmarks:
+    """Test the generated benchmark file."""
+    
+    def test_generated_benchmarks(self):
+        """Test sized sub-benchmarks, setup before ResetTimer, sinks and reader resets."""
+        targets = [t for t in find_go_functions(GO_TEXT) if t.generatable]
+        
+        source = generate_go_benchmarks("text", targets, {"time": "time", "json": "encoding/json"})
+        
+        assert "var testgenBenchSizes = []int{10, 100, 1000}" in source
+        assert 'b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {' in source
+        assert (
+            '\t\t\tdata := []byte(testgenBenchInput("lines", n))\n'
+            "\t\t\tr := bytes.NewReader(data)\n"
+            "\t\t\tlimit := n\n"
+            "\t\t\tif _, err := CountLines(r, limit); err != nil {\n"
+            '\t\t\t\tb.Skipf("sample input rejected: %v", err)\n'
+            "\t\t\t}\n"
+            "\t\t\tb.ReportAllocs()\n"
+            "\t\t\tb.ResetTimer()\n"
+            "\t\t\tfor i := 0; i < b.N; i++ {\n"
+            "\t\t\t\tr.Reset(data)\n"
+            "\t\t\t\ttestgenSinkCountLines, testgenSinkErr = CountLines(r, limit)\n"
+        ) in source
+        assert "var testgenSinkStamp0 string\n\nvar testgenSinkStamp1 time.Duration\n" in source
+        assert "testgenSinkStamp0, testgenSinkStamp1 = Stamp(words, upper)" in source
+        assert "words := testgenBenchStrings(n)\n\t\t\tupper := true\n" in source
+        assert '\t"bytes"\n\t"fmt"\n\t"strings"\n\t"testing"\n\t"time"\n)' in source
+        assert "testgenBenchMap" not in source
+
+
+@pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+class TestRunBenchmarks:
+    """Test profiling and running generated benchmarks with the Go toolchain."""
+    
+    def test_profile_and_run(self, tmp_path):
+        """Test the slow test's hot path is found and the generated benchmarks run."""
+        (tmp_path / "go.mod").write_text("module example.com/app\n\ngo 1.22\n")
+        package_dir = tmp_path / "text"
+        package_dir.mkdir()
+        (package_dir / "text.go").write_text(GO_TEXT + """
+func quadratic(xs []int) int {
+	pairs := 0
+	for i := range xs {
+		for j := range xs {
+			if xs[i] < xs[j] {
+				pairs++
+			}
+		}
+	}
+	return pairs
+}
+""")
+        (package_dir / "text_test.go").write_text(
+            'package text\n\nimport "testing"\n\nfunc TestQuadratic(t *testing.T) {\n'
+            "\txs := make([]int, 4000)\n\tfor i := range xs {\n\t\txs[i] = i\n\t}\n"
+            "\tfor k := 0; k < 10; k++ {\n\t\tif quadratic(xs) != 4000*3999/2 {\n\t\t\tt.Fatal(\"pairs\")\n\t\t}\n\t}\n}\n"
+        )
+        
+        hot = profile_hot_paths(str(package_dir), tests=["TestQuadratic"])
+        targets = select_benchmark_targets(str(package_dir), hot=hot)
+        written = write_go_benchmarks(str(package_dir), targets)
+        
+        assert list(hot) == ["quadratic"]
+        assert written == package_dir / BENCH_TEST_FILE
+        assert not list(package_dir.glob("*.test"))
+        completed = subprocess.run(
+            ["go", "test", "-run", "^$", "-bench", ".", "-benchtime", "1x", "-benchmem", "."],
+            cwd=package_dir, capture_output=True, text=True, timeout=300
+        )
+        assert completed.returncode == 0, completed.stdout + completed.stderr
+        assert "BenchmarkCountLines/n=1000" in completed.stdout
+        assert "BenchmarkQuadratic/n=10" in completed.stdout
+        assert "allocs/op" in completed.stdout
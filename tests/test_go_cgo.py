@@ -0,0 +1,258 @@
+"""
+Unit tests for cgo-aware Go handling.
+
+This test suite covers:
+- Detecting `import "C"` and documented thread-safety
+- Finding the C toolchain (CC, CGO_ENABLED=0)
+- Skipping cgo packages and their dependents with a reason when there is no C compiler
+- Listing packages only without a compiler, once per directory and run
+- Flagging generated tests that call into C from parallel tests, and the cgo prompt block
+"""
+
+import shutil
+
+import pytest
+from testgen.core import go_cgo
+from testgen.core.go_cgo import (
+    CgoPlanner, cgo_functions, documented_thread_safe, find_c_toolchain, parallel_cgo_problems, uses_cgo
+)
+from testgen.core.go_runner import GoTestRunner
+from testgen.core.go_trial import trial_run
+from testgen.core.language_config import Language
+from testgen.core.prompt_templates import PromptTemplates
+
+
+NATIVE = """package native
+
+// #include <stdlib.h>
+import "C"
+
+// Abs returns |n|, computed by the C library.
+func Abs(n int) int {
+	return int(C.abs(C.int(n)))
+}
+
+func helper() int { return 1 }
+"""
+
+NATIVE_TEST = """package native
+
+import "testing"
+
+func TestAbs(t *testing.T) {
+	if Abs(-2) != 2 {
+		t.Fatal("Abs(-2) != 2")
+	}
+}
+"""
+
+PARALLEL_TEST = """package native
+
+import "testing"
+
+func TestAbs(t *testing.T) {
+	for _, n := range []int{-1, 1} {
+		t.Run("n", func(t *testing.T) {
+			t.Parallel()
+			if Abs(n) != 1 {
+				t.Fatal("want 1")
+			}
+		})
+	}
+}
+
+func TestHelper(t *testing.T) {
+	t.Parallel()
+	_ = helper2()
+}
+"""
+
+
+@pytest.fixture
+def module(tmp_path):
+    """A cgo package, a package using it, one whose tests use it, and a plain one."""
+    (tmp_path / "go.mod").write_text("module example.com/cg\n\ngo 1.22\n")
+    files = {
+        "native/native.go": NATIVE,
+        "native/native_test.go": NATIVE_TEST,
+        "app/app.go": 'package app\n\nimport "example.com/cg/native"\n\nfunc F() int { return native.Abs(-1) }\n',
+        "pure/pure.go": "package pure\n\nfunc G() int { return 1 }\n",
+        "pure/pure_test.go": (
+            'package pure_test\n\nimport (\n\t"testing"\n\n\t"example.com/cg/native"\n)\n\n'
+            'func TestG(t *testing.T) { _ = native.Abs(1) }\n'
+        ),
+        "plain/plain.go": "package plain\n\nfunc H() int { return 1 }\n",
+        "plain/plain_test.go": 'package plain\n\nimport "testing"\n\nfunc TestH(t *testing.T) {\n\tif H() != 1 {\n\t\tt.Fatal()\n\t}\n}\n',
+    }
+    for name, source in files.items():
+        (tmp_path / name).parent.mkdir(exist_ok=True)
+        (tmp_path / name).write_text(source)
+    return tmp_path
+
+
+class TestDetection:
+    """Test what is read from the source."""
+    
+    def test_uses_cgo(self):
+        """Test import "C" alone or in an import block, not in comments or strings."""
+        assert uses_cgo(NATIVE)
+        assert uses_cgo('package x\n\n/*\n#include <stdio.h>\n*/\nimport (\n\t"C"\n\t"unsafe"\n)\n')
+        assert not uses_cgo('package x\n\n// import "C" would need gcc\nimport "fmt"\n')
+    
+    def test_documented_thread_safe(self):
+        """Test thread-safety claims in comments, and their negations."""
+        assert documented_thread_safe("// Codec is safe for concurrent use by multiple goroutines.\n")
+        assert documented_thread_safe("/* The library is thread-safe. */\n")
+        assert not documented_thread_safe("// Handle is not thread-safe: lock around it.\n")
+        assert not documented_thread_safe("// Context isn't safe for concurrent use.\n")
+        assert not documented_thread_safe('var s = "thread-safe"\n')
+    
+    def test_cgo_functions(self, module):
+        """Test only functions of files importing "C" count as calling into C."""
+        assert cgo_functions(str(module / "native")) == ["Abs", "helper"]
+        assert cgo_functions(str(module / "plain")) == []
+
+
+class TestToolchain:
+    """Test finding the C compiler."""
+    
+    def test_missing_compiler(self, tmp_path):
+        """Test a CC that isn't on the PATH."""
+        toolchain = find_c_toolchain({"CC": "testgen-no-cc", "PATH": str(tmp_path)})
+        
+        assert not toolchain.available
+        assert toolchain.reason == "no C compiler (testgen-no-cc not found)"
+    
+    def test_cgo_disabled(self, tmp_path):
+        """Test CGO_ENABLED=0 disables cgo even with a compiler."""
+        (tmp_path / "cc").write_text("#!/bin/sh\n")
+        (tmp_path / "cc").chmod(0o755)
+        
+        enabled = find_c_toolchain({"CC": "cc -m64", "PATH": str(tmp_path)})
+        disabled = find_c_toolchain({"CC": "cc", "PATH": str(tmp_path), "CGO_ENABLED": "0"})
+        
+        assert enabled.available and enabled.path == str(tmp_path / "cc")
+        assert not disabled.available and disabled.reason == "CGO_ENABLED=0"
+    
+    def test_no_listing_with_compiler(self, tmp_path, monkeypatch):
+        """Test packages aren't listed when cgo packages can build."""
+        (tmp_path / "cc").write_text("#!/bin/sh\n")
+        (tmp_path / "cc").chmod(0o755)
+        listed = []
+        monkeypatch.setattr(go_cgo, "_list_packages", lambda *args: listed.append(args))
+        
+        plan = CgoPlanner().plan(str(tmp_path), ["./..."], env={"CC": "cc", "PATH": str(tmp_path)})
+        
+        assert plan.packages == ["./..."] and plan.env == {"CGO_ENABLED": "1"}
+        assert listed == []
+
+
+class TestParallelCalls:
+    """Test generated tests calling into C from parallel tests."""
+    
+    def test_flagged(self, module):
+        """Test parallel (sub)tests calling cgo functions are flagged, others not."""
+        problems = parallel_cgo_problems(PARALLEL_TEST, str(module / "native"))
+        
+        assert problems == [
+            "TestAbs: calls into C (Abs) from a parallel test; "
+            "the package isn't documented thread-safe, so don't use t.Parallel() here"
+        ]
+        assert parallel_cgo_problems(NATIVE_TEST, str(module / "native")) == []
+        assert parallel_cgo_problems(PARALLEL_TEST, str(module / "plain")) == []
+    
+    def test_thread_safe_package(self, module):
+        """Test packages documented thread-safe may be called in parallel."""
+        (module / "native" / "doc.go").write_text("// Package native is safe for concurrent use.\npackage native\n")
+        
+        assert parallel_cgo_problems(PARALLEL_TEST, str(module / "native")) == []
+    
+    def test_prompt_block(self):
+        """Test the cgo block is added to Go prompts of cgo code, serial unless documented thread-safe."""
+        serial = PromptTemplates.get_prompt(Language.GO, NATIVE)
+        parallel = PromptTemplates.get_prompt(Language.GO, "// Safe for concurrent use.\n" + NATIVE)
+        plain = PromptTemplates.get_prompt(Language.GO, "package plain\n\nfunc H() int { return 1 }\n")
+        
+        assert "never `import \"C\"`" in serial
+        assert "no `t.Parallel()`" in serial
+        assert "may call it" in parallel
+        assert serial.endswith("Generate ONLY the test code, no explanations.")
+        assert "Cgo" not in plain
+
+
+@pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+class TestRunWithoutCompiler:
+    """Test runs where cgo packages can't build."""
+    
+    def test_cgo_packages_skipped(self, module, monkeypatch):
+        """Test cgo packages and their dependents are skipped with the reason and the rest runs."""
+        monkeypatch.setenv("CC", "testgen-no-cc")
+        
+        results = GoTestRunner().run_tests(str(module), measure_usage=False)
+        
+        skipped = {t.name: t.message for t in results.tests if t.status == "skipped"}
+        assert skipped == {
+            "example.com/cg/native": "cgo package: no C compiler (testgen-no-cc not found)",
+            "example.com/cg/app": "depends on cgo package example.com/cg/native: no C compiler (testgen-no-cc not found)",
+            "example.com/cg/pure": "depends on cgo package example.com/cg/native: no C compiler (testgen-no-cc not found)",
+        }
+        assert [(t.name, t.status) for t in results.tests if t.status != "skipped"] == [("TestH", "passed")]
+        assert results.success and results.errors == 0 and results.skipped == 3
+    
+    def test_only_cgo_packages(self, module, monkeypatch):
+        """Test a run of cgo packages only reports them skipped without running go test."""
+        monkeypatch.setenv("CGO_ENABLED", "0")
+        
+        results = GoTestRunner().run_tests(str(module), packages=["./native"], measure_usage=False)
+        
+        assert [(t.name, t.status, t.message) for t in results.tests] == [
+            ("example.com/cg/native", "skipped", "cgo package: CGO_ENABLED=0")
+        ]
+    
+    def test_listed_once_per_directory(self, module, monkeypatch):
+        """Test later runs of a runner in the same directory are planned from its first listing."""
+        monkeypatch.setenv("CC", "testgen-no-cc")
+        listed = []
+        list_packages = go_cgo._list_packages
+        monkeypatch.setattr(go_cgo, "_list_packages", lambda *args: listed.append(args[1]) or list_packages(*args))
+        runner = GoTestRunner()
+        
+        first = runner.run_tests(str(module), measure_usage=False)
+        again = runner.run_tests(str(module), packages=["./plain", "example.com/cg/pure"], measure_usage=False)
+        
+        assert listed == [["./..."]]
+        assert first.skipped == 3
+        assert [(t.name, t.status) for t in again.tests] == [("TestH", "passed"), ("example.com/cg/pure", "skipped")]
+    
+    def test_trial_unchecked(self, module, monkeypatch):
+        """Test generated tests of a cgo package aren't trial-run without a compiler."""
+        monkeypatch.setenv("CC", "testgen-no-cc")
+        
+        trial = trial_run({module / "native" / "abs_test.go": NATIVE_TEST.replace("TestAbs", "TestAbsAgain")})
+        
+        assert not trial.checked
+        assert trial.reason == "native: cgo package, no C compiler (testgen-no-cc not found)"
+
+
+@pytest.mark.skipif(
+    shutil.which("go") is None or find_c_toolchain().path is None,
+    reason="Go or C toolchain not installed"
+)
+class TestRunWithCompiler:
+    """Test runs where cgo packages build."""
+    
+    def test_everything_runs(self, module):
+        """Test every package runs, cgo ones included."""
+        results = GoTestRunner().run_tests(str(module), measure_usage=False)
+        
+        assert {t.name: t.status for t in results.tests} == {"TestAbs": "passed", "TestG": "passed", "TestH": "passed"}
+    
+    def test_trial_rejects_parallel_calls(self, module):
+        """Test a passing trial run still reports parallel calls into C."""
+        trial = trial_run({module / "native" / "abs_test.go": PARALLEL_TEST.replace("TestAbs", "TestAbsParallel").replace(
+            "func TestHelper(t *testing.T) {\n\tt.Parallel()\n\t_ = helper2()\n}\n", ""
+        )})
+        
+        assert trial.checked and not trial.build_errors and not trial.failures
+        assert [p.split(":")[0] for p in trial.problems] == ["TestAbsParallel"]
+        assert not trial.ok
@@ -0,0 +1,373 @@
+"""
+Unit tests for go:generate awareness.
+
+This test suite covers:
+- Finding //go:generate directives, their generators, inputs and outputs
+- Stale generated files found by modification time, or by re-running go generate in a scratch copy
+- Stale files and what they declare mapped to the Go packages where tests break, importers included
+- The check running once per executor run, not per test file
+- FailureAnalyzer labeling failures that mention stale generated code instead of counting them as logic failures
+"""
+
+import os
+import shutil
+
+import pytest
+from testgen.core import go_generate, test_executor
+from testgen.core.base_runner import TestResult, TestResults
+from testgen.core.failure_analyzer import FailureAnalyzer, FailureType
+from testgen.core.go_generate import (
+    GenerateDirective, check_generated, describe_generated, find_generate_directives, go_symbols, stale_packages,
+    stale_symbols
+)
+from testgen.core.go_runner import GoTestRunner
+from testgen.core.result_models import create_execution_summary_from_runner_results
+from testgen.core.test_executor import TestExecutionConfig, UniversalTestExecutor
+
+
+STORE = """package store
+
+//go:generate mockgen -source=store.go -destination=../mocks/store_mock.go -package=mocks
+
+// Store keeps users.
+type Store interface {
+	Get(id int) (string, error)
+}
+"""
+
+MOCK = """// Code generated by MockGen. DO NOT EDIT.
+// Source: store.go
+
+package mocks
+
+// MockStore is a mock of Store interface.
+type MockStore struct {
+	ctrl *gomock.Controller
+}
+
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	return &MockStore{ctrl: ctrl}
+}
+
+// Get mocks base method.
+func (m *MockStore) Get(id int) (string, error) {
+	return "", nil
+}
+"""
+
+COLOR = """package color
+
+//go:generate stringer -type=Color
+// go:generate is only a comment with the space
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+)
+"""
+
+COLOR_STRING = """// Code generated by "stringer -type=Color"; DO NOT EDIT.
+
+package color
+"""
+
+VALUES = """package values
+
+//go:generate cp values.tmpl values_gen.go
+"""
+
+VALUES_TMPL = """// Code generated by cp. DO NOT EDIT.
+
+package values
+
+const Answer = {answer}
+"""
+
+LIST_METHOD = """
+// List mocks base method.
+func (m *MockStore) List() []string {
+	return nil
+}
+"""
+
+CONSUMED_MOCK = """// Code generated by MockGen. DO NOT EDIT.
+
+package mocks
+
+// MockStore is a mock of Store interface.
+type MockStore struct{}
+
+// Get mocks base method.
+func (m *MockStore) Get(id int) string {
+	return ""
+}
+""" + LIST_METHOD
+
+
+def _age(path, seconds):
+    """Set a file's modification time `seconds` into the past."""
+    stat = path.stat()
+    os.utime(path, (stat.st_atime, stat.st_mtime - seconds))
+
+
+@pytest.fixture
+def module(tmp_path):
+    (tmp_path / "go.mod").write_text("module example.com/app\n\ngo 1.22\n")
+    for directory, files in {
+        "store": {"store.go": STORE},
+        "mocks": {"store_mock.go": MOCK},
+        "color": {"color.go": COLOR, "color_string.go": COLOR_STRING},
+        "vendor/dep": {"dep.go": "package dep\n\n//go:generate stringer -type=X\n"},
+    }.items():
+        (tmp_path / directory).mkdir(parents=True)
+        for name, source in files.items():
+            (tmp_path / directory / name).write_text(source)
+    return tmp_path
+
+
+class TestFindDirectives:
+    """Test what is found in the packages."""
+    
+    def test_directives(self, module):
+        """Test directives per package with import paths, generators, outputs and generated files."""
+        packages = {p.import_path: p for p in find_generate_directives(str(module))}
+        
+        assert set(packages) == {"example.com/app/store", "example.com/app/color"}
+        store, color = packages["example.com/app/store"], packages["example.com/app/color"]
+        assert [(d.line, d.tool) for d in store.directives] == [(3, "mockgen")]
+        assert store.directives[0].outputs() == [module / "mocks" / "store_mock.go"]
+        assert store.directives[0].inputs() == [module / "store" / "store.go"]
+        assert [p.name for p in store.generated] == ["store_mock.go"]
+        assert [d.command for d in color.directives] == ["stringer -type=Color"]
+        assert color.generated == [module / "color" / "color_string.go"]
+    
+    def test_tool_of_go_run(self, tmp_path):
+        """Test the generator of `go run` directives is what it runs."""
+        directive = GenerateDirective(tmp_path / "a.go", 1, "go run -mod=mod golang.org/x/tools/cmd/stringer -type=T")
+        
+        assert directive.tool == "golang.org/x/tools/cmd/stringer"
+
+
+class TestModificationTimes:
+    """Test the check without running go generate."""
+    
+    def test_fresh(self, module):
+        """Test generated files newer than their inputs aren't stale."""
+        _age(module / "store" / "store.go", 60)
+        _age(module / "color" / "color.go", 60)
+        
+        packages = check_generated(str(module))
+        
+        assert not any(p.is_stale for p in packages)
+        assert stale_packages(packages) == {}
+    
+    def test_stale(self, module):
+        """Test generated files older than their inputs are stale, under the package holding them."""
+        _age(module / "mocks" / "store_mock.go", 60)
+        _age(module / "color" / "color_string.go", 60)
+        
+        packages = check_generated(str(module))
+        
+        assert stale_packages(packages) == {
+            "example.com/app/mocks": ["store_mock.go"],
+            "example.com/app/color": ["color_string.go"],
+        }
+        assert stale_symbols(packages)["example.com/app/mocks"] == ["MockStore", "NewMockStore"]
+        report = describe_generated(packages, str(module))
+        assert "example.com/app/store (mockgen): 1 stale [mtime]" in report
+        assert "  color/color_string.go: older than color.go" in report
+    
+    def test_generators_not_run(self, module, monkeypatch):
+        """Test go generate only runs when asked to."""
+        def regenerate(*args):
+            raise AssertionError("go generate ran")
+        monkeypatch.setattr(go_generate, "_regenerate", regenerate)
+        
+        packages = check_generated(str(module))
+        
+        assert {p.checked_by for p in packages} == {"mtime"}
+        assert not any(p.error for p in packages)
+
+
+class TestExecutorRuns:
+    """Test when executor runs check generated code."""
+    
+    def test_checked_once_per_run(self, module, monkeypatch):
+        """Test a run over several test files checks the module once and reports the result for each."""
+        for package in ("store", "color"):
+            (module / package / f"{package}_test.go").write_text(
+                f'package {package}\n\nimport "testing"\n\nfunc TestUnit(t *testing.T) {{}}\n'
+            )
+        _age(module / "color" / "color_string.go", 60)
+        checks = []
+        
+        def check(root, **kwargs):
+            checks.append(root)
+            return check_generated(root, **kwargs)
+        monkeypatch.setattr(test_executor, "check_generated", check)
+        monkeypatch.setattr(GoTestRunner, "run_tests", lambda self, test_dir, pattern=None, **kwargs: TestResults(
+            language="go", framework="testing", total=1, passed=1, tests=[]
+        ))
+        
+        results = UniversalTestExecutor(GoTestRunner()).execute_all_with_optimization(str(module))
+        
+        assert checks == [str(module)]
+        assert all(r.stale_generated == {"example.com/app/color": ["color_string.go"]} for r in results.values())
+
+
+class TestSymbols:
+    """Test what generated files are recognized by in failure output."""
+    
+    def test_declarations(self):
+        """Test top-level names and receiver types, not method names."""
+        assert go_symbols(MOCK) == ["MockStore", "NewMockStore"]
+        assert go_symbols(
+            'package color\n\nfunc _() {}\n\nconst _Color_name = "RedGreen"\n\n'
+            'var _Color_index = [...]uint8{0, 3, 8}\n\nfunc (i Color) String() string { return "" }\n'
+        ) == ["Color", "_Color_name", "_Color_index"]
+
+
+class TestFailureAnalysis:
+    """Test failures in packages with stale generated code."""
+    
+    def _results(self):
+        return TestResults(language="go", framework="testing", tests=[
+            TestResult(name="TestString", status="failed", message="got Color(2), want Blue", suite="example.com/app/color"),
+            TestResult(name="TestMix", status="failed", message="AssertionError: want 3, got 4", suite="example.com/app/color"),
+            TestResult(name="example.com/app/mocks", status="error", message="undefined: MockStore.List", suite="example.com/app/mocks"),
+            TestResult(name="TestParse", status="failed", message="AssertionError: want 1", suite="example.com/app/parse"),
+            TestResult(name="TestOther", status="error", message="build failed", suite="example.com/app/other"),
+        ], stale_generated={
+            "example.com/app/color": ["color_string.go"],
+            "example.com/app/mocks": ["store_mock.go"],
+        }, stale_symbols={
+            "example.com/app/color": ["Color", "_Color_name", "_Color_index"],
+            "example.com/app/mocks": ["MockStore", "NewMockStore"],
+        })
+    
+    def test_labeled_stale_generated(self):
+        """Test failures mentioning stale generated code are labeled, others classified as before."""
+        analyzer = FailureAnalyzer()
+        analyzer.add_summary(create_execution_summary_from_runner_results(self._results()))
+        
+        analysis = analyzer.analyze()
+        
+        assert analysis.total_failures == 4
+        assert analysis.failure_types[FailureType.STALE_GENERATED] == 2
+        assert analysis.failure_types[FailureType.ASSERTION] == 2
+        assert analysis.stale_generated == [
+            "TestString (example.com/app/color)",
+            "example.com/app/mocks (example.com/app/mocks)",
+        ]
+        assert analysis.logic_failures == 2
+        assert [t.name for t, _ in analyzer.get_failures_by_type(FailureType.STALE_GENERATED)] == [
+            "TestString", "example.com/app/mocks"
+        ]
+    
+    def test_report(self):
+        """Test the report separates logic failures from stale generated code."""
+        analyzer = FailureAnalyzer()
+        analyzer.add_summary(create_execution_summary_from_runner_results(self._results()))
+        
+        report = analyzer.generate_failure_report()
+        
+        assert "Logic Failures: 2" in report
+        assert "  - example.com/app/color: color_string.go" in report
+
+
+@pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+class TestRegenerate:
+    """Test re-running go generate in a scratch copy and comparing what it writes."""
+    
+    @pytest.fixture
+    def values(self, tmp_path):
+        (tmp_path / "go.mod").write_text("module example.com/values\n\ngo 1.22\n")
+        (tmp_path / "values.go").write_text(VALUES)
+        (tmp_path / "values.tmpl").write_text(VALUES_TMPL.format(answer=42))
+        (tmp_path / "values_gen.go").write_text(VALUES_TMPL.format(answer=42))
+        return tmp_path
+    
+    def test_up_to_date(self, values):
+        """Test output identical to go generate's isn't stale, even when older than its input."""
+        _age(values / "values_gen.go", 60)
+        
+        [package] = check_generated(str(values), regenerate=True)
+        
+        assert package.checked_by == "go generate" and package.error is None
+        assert not package.is_stale
+    
+    def test_differs(self, values):
+        """Test output that go generate would change is stale, even when newer, and the working tree is left alone."""
+        (values / "values.tmpl").write_text(VALUES_TMPL.format(answer=43))
+        _age(values / "values.tmpl", 60)
+        
+        [package] = check_generated(str(values), regenerate=True)
+        
+        assert package.stale == {values / "values_gen.go": "differs from go generate output"}
+        assert stale_packages([package]) == {"example.com/values": ["values_gen.go"]}
+        assert stale_symbols([package]) == {"example.com/values": ["Answer"]}
+        assert "const Answer = 42" in (values / "values_gen.go").read_text()
+        assert "example.com/values (cp): 1 stale [go generate]" in describe_generated([package], str(values))
+    
+    def test_generator_missing(self, module, monkeypatch):
+        """Test a generator that isn't installed leaves the modification-time verdict with the error."""
+        _age(module / "color" / "color_string.go", 60)
+        monkeypatch.setenv("PATH", os.path.dirname(shutil.which("go")))
+        
+        [color] = check_generated(str(module / "color"), regenerate=True)
+        
+        assert color.checked_by == "mtime" and color.is_stale
+        assert "stringer" in color.error
+        assert "example.com/app/color (stringer): 1 stale [mtime]" in describe_generated([color], str(module))
+    
+    @pytest.fixture
+    def consumers(self, tmp_path):
+        """A mock one method short, a package whose tests need the method, and one whose tests don't."""
+        (tmp_path / "go.mod").write_text("module example.com/app\n\ngo 1.22\n")
+        files = {
+            "store/store.go": "package store\n\ntype Store interface {\n\tGet(id int) string\n\tList() []string\n}\n",
+            "mocks/doc.go": "package mocks\n\n//go:generate cp store_mock.tmpl store_mock.go\n",
+            "mocks/store_mock.tmpl": CONSUMED_MOCK,
+            "mocks/store_mock.go": CONSUMED_MOCK.replace(LIST_METHOD, ""),
+            "service/service.go": (
+                'package service\n\nimport "example.com/app/store"\n\n'
+                "func Count(s store.Store) int { return len(s.List()) }\n"
+            ),
+            "service/service_test.go": (
+                'package service\n\nimport (\n\t"testing"\n\n\t"example.com/app/mocks"\n)\n\n'
+                "func TestCount(t *testing.T) {\n\tif Count(&mocks.MockStore{}) != 0 {\n\t\tt.Fatal(\"want 0\")\n\t}\n}\n"
+            ),
+            "report/report.go": "package report\n\nfunc Title(name string) string { return name }\n",
+            "report/report_test.go": (
+                'package report\n\nimport (\n\t"testing"\n\n\t"example.com/app/mocks"\n)\n\n'
+                "func TestTitle(t *testing.T) {\n\tif got := Title((&mocks.MockStore{}).Get(1)); got != \"user\" {\n"
+                "\t\tt.Errorf(\"want user, got %q\", got)\n\t}\n}\n"
+            ),
+        }
+        for name, source in files.items():
+            (tmp_path / name).parent.mkdir(exist_ok=True)
+            (tmp_path / name).write_text(source)
+        return tmp_path
+    
+    def test_consumer_fails(self, consumers):
+        """Test a package whose tests break on a stale mock it imports is labeled, a logic failure next to it isn't."""
+        results = UniversalTestExecutor(GoTestRunner()).run_with_retries(
+            str(consumers), TestExecutionConfig(regenerate=True)
+        )
+        
+        assert results.stale_generated == {
+            "example.com/app/mocks": ["store_mock.go"],
+            "example.com/app/report": ["example.com/app/mocks/store_mock.go"],
+            "example.com/app/service": ["example.com/app/mocks/store_mock.go"],
+        }
+        assert results.stale_symbols["example.com/app/service"] == ["MockStore"]
+        
+        analyzer = FailureAnalyzer()
+        analyzer.add_summary(create_execution_summary_from_runner_results(results))
+        analysis = analyzer.analyze()
+        
+        assert analysis.stale_generated == ["example.com/app/service (example.com/app/service)"]
+        assert analysis.total_failures == 2 and analysis.logic_failures == 1
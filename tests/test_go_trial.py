@@ -0,0 +1,167 @@
+"""
+Unit tests for trial runs of generated Go tests.
+
+This test suite covers:
+- Building and running generated tests through go test -overlay without writing them
+- Build errors located in the generated file, failures and tests that didn't run
+- Iterating with the LLM until an attempt passes, then writing it
+- The test file writer rejecting Go tests that fail their trial run
+"""
+
+import shutil
+from types import SimpleNamespace
+
+import pytest
+from testgen.core.base_runner import TestResult, TestResults
+from testgen.core.file_writer import TestFileWriter
+from testgen.core.go_trial import TrialRun, generate_go_tests, trial_run
+from testgen.core.prompt_templates import PromptTemplates
+
+
+GO_CODE = """package calc
+
+// Add returns a + b.
+func Add(a, b int) int {
+	return a + b
+}
+"""
+
+PASSING = """package calc
+
+import "testing"
+
+func TestAdd(t *testing.T) {
+	if got := Add(2, 3); got != 5 {
+		t.Fatalf("Add(2, 3) = %d, want 5", got)
+	}
+}
+"""
+
+FAILING = PASSING.replace("!= 5", "!= 6").replace("want 5", "want 6")
+
+BROKEN = PASSING.replace("Add(2, 3)", "Sum(2, 3)", 1)
+
+
+class FakeLLM:
+    """Returns canned responses in order and keeps the prompts."""
+    
+    def __init__(self, *responses):
+        self.responses = list(responses)
+        self.prompts = []
+    
+    def generate(self, prompt):
+        self.prompts.append(prompt)
+        return SimpleNamespace(content=self.responses.pop(0))
+
+
+@pytest.fixture
+def package(tmp_path):
+    (tmp_path / "go.mod").write_text("module example.com/calc\n\ngo 1.22\n")
+    (tmp_path / "calc.go").write_text(GO_CODE)
+    return tmp_path
+
+
+class TestTrialRunModel:
+    """Test what keeps a trial run from being written."""
+    
+    def test_problems(self, tmp_path):
+        """Test build errors, failures and tests that didn't run all count as problems."""
+        results = TestResults(language="go", framework="testing", tests=[
+            TestResult(name="TestAdd", status="passed"),
+            TestResult(name="TestSub", status="failed", message="calc_test.go:9: Sub(3, 1) = 4, want 2\n"),
+        ])
+        trial = TrialRun(
+            files={tmp_path / "calc_test.go": PASSING},
+            build_errors=["calc_test.go:4:2: \"fmt\" imported and not used"],
+            results=results,
+            not_run=["TestMul"],
+        )
+        
+        assert trial.problems == [
+            "calc_test.go:4:2: \"fmt\" imported and not used",
+            "TestSub: calc_test.go:9: Sub(3, 1) = 4, want 2",
+            "TestMul: didn't run",
+        ]
+        assert not trial.ok
+        assert TrialRun(files={}, results=TestResults(), checked=False).ok is False
+    
+    def test_repair_prompt(self):
+        """Test the repair prompt carries the attempt, the problems and the code under test."""
+        prompt = PromptTemplates.get_trial_repair_prompt(GO_CODE, BROKEN, ["calc_test.go:6:12: undefined: Sum"])
+        
+        assert "- calc_test.go:6:12: undefined: Sum" in prompt
+        assert "if got := Sum(2, 3); got != 5 {" in prompt
+        assert "func Add(a, b int) int {" in prompt
+        assert prompt.endswith("Return ONLY the complete fixed test file, no explanations.")
+    
+    def test_repair_prompt_placeholders_in_sources(self):
+        """Test placeholder-like text in the sources is kept, not substituted."""
+        code = 'package calc\n\nconst Usage = "{tests} {problems}"\n'
+        tests = 'package calc\n\nfunc TestUsage(t *testing.T) { t.Log("{code}") }\n'
+        
+        prompt = PromptTemplates.get_trial_repair_prompt(code, tests, ["{error}"])
+        
+        assert 'const Usage = "{tests} {problems}"' in prompt
+        assert 't.Log("{code}")' in prompt
+        assert "- {error}" in prompt
+
+
+@pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+class TestTrialRuns:
+    """Test trial runs with the Go toolchain."""
+    
+    def test_passing_tests_are_not_written(self, package):
+        """Test passing tests run through the overlay while the tree stays untouched."""
+        trial = trial_run({package / "calc_test.go": PASSING})
+        
+        assert trial.ok
+        assert [(t.name, t.status) for t in trial.results.tests] == [("TestAdd", "passed")]
+        assert sorted(p.name for p in package.iterdir()) == ["calc.go", "go.mod"]
+    
+    def test_build_errors_and_failures(self, package):
+        """Test compiler errors point at the generated file's name, failures carry their message."""
+        (package / "calc_test.go").write_text(PASSING)
+        
+        broken = trial_run({package / "calc_test.go": BROKEN})
+        failing = trial_run({package / "calc_test.go": FAILING})
+        
+        assert broken.build_errors == ["calc_test.go:6:12: undefined: Sum"]
+        assert [p.split(": ", 1)[0] for p in failing.problems] == ["TestAdd"]
+        assert "Add(2, 3) = 5, want 6" in failing.problems[0]
+        assert (package / "calc_test.go").read_text() == PASSING
+    
+    def test_iterates_until_an_attempt_passes(self, package):
+        """Test build errors go back to the LLM and only the passing attempt is written."""
+        llm = FakeLLM(f"```go\n{BROKEN}```", f"Fixed:\n\n```go\n{PASSING}```")
+        
+        trial = generate_go_tests(package / "calc_test.go", GO_CODE, llm)
+        
+        assert trial.ok and trial.written
+        assert trial.attempts == 2
+        assert "- calc_test.go:6:12: undefined: Sum" in llm.prompts[1]
+        assert (package / "calc_test.go").read_text() == PASSING
+    
+    def test_gives_up_without_writing(self, package):
+        """Test nothing is written when no attempt passes."""
+        llm = FakeLLM(BROKEN, FAILING)
+        
+        trial = generate_go_tests(package / "calc_test.go", GO_CODE, llm, max_attempts=2)
+        
+        assert not trial.written
+        assert trial.attempts == 2
+        assert not (package / "calc_test.go").exists()
+    
+    def test_file_writer(self, package):
+        """Test the writer rejects failing Go tests and writes passing ones with a Go header."""
+        writer = TestFileWriter()
+        
+        rejected = writer.save_test_file(FAILING, source_file=str(package / "calc.go"))
+        written = writer.save_test_file(PASSING, source_file=str(package / "calc.go"))
+        again = writer.save_test_file(PASSING, source_file=str(package / "calc.go"))
+        
+        assert not rejected.success and "TestAdd" in rejected.diagnostics[0]
+        assert written.success and written.created_new
+        assert written.file_path == package / "calc_test.go"
+        assert written.file_path.read_text().startswith("// Auto-generated test file.\n//\n// Tests for: ")
+        assert again.success
+        assert [p.suffix for p in package.glob("*.go")] == [".go", ".go"]
@@ -0,0 +1,125 @@
+"""
+Unit tests for Kotlin support.
+
+This test suite covers:
+- Gradle invocation from the project root (./gradlew, subproject tasks)
+- Parsing build/test-results JUnit XML reports
+- Kotest vs JUnit 5 detection
+- Coroutine guidance in Kotlin prompts
+"""
+
+from unittest.mock import Mock, patch
+
+import pytest
+from testgen.core.base_runner import TestResult
+from testgen.core.kotlin_runner import KotlinTestRunner
+from testgen.core.language_config import Language
+from testgen.core.language_detector import Language as DetectedLanguage, LanguageDetector, TestFramework
+from testgen.core.prompt_templates import PromptTemplates
+
+
+REPORT = """<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="com.shop.CartTest" tests="3" skipped="1" failures="1" errors="0" time="0.31">
+  <testcase name="addsItem()" classname="com.shop.CartTest" time="0.1"/>
+  <testcase name="removesItem()" classname="com.shop.CartTest" time="0.2">
+    <failure message="expected: &lt;1&gt; but was: &lt;2&gt;" type="AssertionFailedError">stack</failure>
+  </testcase>
+  <testcase name="clears()" classname="com.shop.CartTest" time="0.0"><skipped/></testcase>
+</testsuite>
+"""
+
+
+@pytest.fixture
+def gradle_project(tmp_path):
+    """Multi-project Gradle build with a wrapper and an `app` module."""
+    (tmp_path / "gradlew").write_text("#!/bin/sh\n")
+    (tmp_path / "settings.gradle.kts").write_text('include("app")\n')
+    (tmp_path / "app" / "src" / "test" / "kotlin").mkdir(parents=True)
+    (tmp_path / "app" / "build.gradle.kts").write_text('plugins { kotlin("jvm") }\n')
+    return tmp_path
+
+
+class TestKotlinRunner:
+    """Test suite for the Gradle-based Kotlin runner."""
+    
+    def test_runs_module_task_with_wrapper_in_root(self, gradle_project):
+        """Test the wrapper is used from the root and the module's task is selected."""
+        runner = KotlinTestRunner()
+        test_dir = gradle_project / "app" / "src" / "test" / "kotlin"
+        
+        assert runner.find_project_root(str(test_dir)) == gradle_project.resolve()
+        assert runner.build_command(str(test_dir), "com.shop.CartTest") == [
+            "./gradlew", ":app:test", "--console=plain", "--tests", "com.shop.CartTest"
+        ]
+    
+    @patch('subprocess.run')
+    def test_parses_test_results_xml(self, mock_run, gradle_project):
+        """Test results come from build/test-results instead of stdout."""
+        def gradle(cmd, **kwargs):
+            reports = gradle_project / "app" / "build" / "test-results" / "test"
+            reports.mkdir(parents=True)
+            (reports / "TEST-com.shop.CartTest.xml").write_text(REPORT)
+            return Mock(returncode=1, stdout="BUILD FAILED", stderr="")
+        
+        mock_run.side_effect = gradle
+        test_dir = gradle_project / "app" / "src" / "test" / "kotlin"
+        
+        results = KotlinTestRunner().run_tests(str(test_dir))
+        
+        assert mock_run.call_args.kwargs["cwd"] == gradle_project.resolve()
+        assert (results.total, results.passed, results.failed, results.skipped) == (3, 1, 1, 1)
+        failed = [t for t in results.tests if t.status == "failed"][0]
+        assert failed.suite == "com.shop.CartTest"
+        assert failed.message == "expected: <1> but was: <2>"
+    
+    @patch('subprocess.run')
+    def test_rerun_filters_by_method_or_spec(self, mock_run, gradle_project):
+        """Test JUnit retries filter by Class.method and Kotest retries by spec."""
+        mock_run.return_value = Mock(returncode=0, stdout="", stderr="")
+        failing = [TestResult(name="adds(int)[1]", status="failed", suite="com.shop.CartTest")]
+        
+        KotlinTestRunner().rerun_tests(str(gradle_project), failing)
+        assert mock_run.call_args[0][0][-2:] == ["--tests", "com.shop.CartTest.adds"]
+        
+        KotlinTestRunner(framework="kotest").rerun_tests(str(gradle_project), failing)
+        assert mock_run.call_args[0][0][-2:] == ["--tests", "com.shop.CartTest"]
+
+
+class TestKotlinDetection:
+    """Test suite for Kotlin language and framework detection."""
+    
+    def test_kotlin_gradle_project_detected(self, gradle_project):
+        """Test a Gradle build applying the Kotlin plugin is a Kotlin project."""
+        (gradle_project / "build.gradle.kts").write_text('plugins { kotlin("jvm") version "2.0.0" }\n')
+        
+        assert LanguageDetector().detect_language(str(gradle_project)) == DetectedLanguage.KOTLIN
+    
+    def test_kotest_detected_from_build_file(self, gradle_project):
+        """Test Kotest dependencies select Kotest over JUnit 5."""
+        detector = LanguageDetector()
+        assert detector.detect_test_framework(str(gradle_project), DetectedLanguage.KOTLIN) == TestFramework.JUNIT
+        
+        (gradle_project / "app" / "build.gradle.kts").write_text(
+            'dependencies { testImplementation("io.kotest:kotest-runner-junit5:5.9.0") }\n'
+        )
+        assert detector.detect_test_framework(str(gradle_project), DetectedLanguage.KOTLIN) == TestFramework.KOTEST
+
+
+class TestKotlinPrompts:
+    """Test suite for Kotlin prompt templates."""
+    
+    def test_coroutine_guidance_for_suspend_functions(self):
+        """Test prompts for suspend functions steer towards runTest and virtual time."""
+        prompt = PromptTemplates.get_prompt(Language.KOTLIN, "suspend fun load(): User = api.get()")
+        
+        assert "runTest" in prompt
+        assert "advanceTimeBy" in prompt
+        assert "StandardTestDispatcher" in prompt
+        assert prompt.endswith("Generate ONLY the test code, no explanations.")
+    
+    def test_no_coroutine_guidance_for_plain_code(self):
+        """Test plain Kotlin code gets the plain prompt."""
+        prompt = PromptTemplates.get_prompt(Language.KOTLIN, "fun add(a: Int, b: Int) = a + b", "kotest")
+        
+        assert "Kotest" in prompt
+        assert "runTest" not in prompt
@@ -0,0 +1,215 @@
+"""
+Unit tests for local load tests.
+
+This test suite covers:
+- Finding Go handlers and the routes they are registered on, and generating httptest load tests
+- Throughput, latency percentiles and error rates in benchmark results
+- Loading a locally started service over HTTP
+- Trend tracking through the benchmark store
+"""
+
+import json
+import shutil
+import threading
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+
+import pytest
+from testgen.core.benchmark import RESULT_MARKER, BenchmarkStore
+from testgen.core.load_test import (
+    LOAD_TEST_FILE, LoadTester, find_go_handlers, find_go_routes, format_load_report, generate_go_load_test,
+    write_go_load_test
+)
+
+
+GO_API = """package api
+
+import "net/http"
+
+func Health(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+func (s *Server) Users(w http.ResponseWriter, r *http.Request) {}
+
+func NewRouter() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", Health)
+	return mux
+}
+"""
+
+
+@pytest.fixture
+def local_server():
+    """HTTP server on localhost answering 200 on /ok, 404 on /missing and 500 elsewhere."""
+    class Handler(BaseHTTPRequestHandler):
+        protocol_version = "HTTP/1.1"
+        
+        def do_GET(self):
+            status = {"/ok": 200, "/missing": 404}.get(self.path, 500)
+            self.send_response(status)
+            self.send_header("Content-Length", "0")
+            self.end_headers()
+        
+        def log_message(self, *args):
+            pass
+    
+    server = ThreadingHTTPServer(("127.0.0.1", 0), Handler)
+    thread = threading.Thread(target=server.serve_forever, daemon=True)
+    thread.start()
+    yield f"http://127.0.0.1:{server.server_address[1]}"
+    server.shutdown()
+    server.server_close()
+
+
+class TestGoLoadTests:
+    """Test suite for generated Go load tests."""
+    
+    def test_handlers_and_constructors_found(self):
+        """Test package-level handlers and constructors are found, methods skipped."""
+        handlers = find_go_handlers(GO_API)
+        
+        assert [(h.name, h.expression) for h in handlers] == [
+            ("Health", "http.HandlerFunc(Health)"),
+            ("NewRouter", "NewRouter()"),
+        ]
+    
+    def test_routes_from_registrations(self):
+        """Test handlers get the method and path they are registered on, with sample path parameters."""
+        code = GO_API + """
+func Routes(r chi.Router) {
+	r.Post("/users/{id}/avatar", UploadAvatar)
+}
+
+func Register(mux *http.ServeMux) {
+	mux.HandleFunc("DELETE /users/{id}", DeleteUser)
+	mux.Handle("/orders/", http.HandlerFunc(Orders)).Methods("PUT")
+	mux.HandleFunc("/{$}", Index)
+}
+"""
+        
+        assert [route[1:] for route in find_go_routes(code)] == [
+            ("Health", "GET", "/health"),
+            ("UploadAvatar", "POST", "/users/1/avatar"),
+            ("DeleteUser", "DELETE", "/users/1"),
+            ("Orders", "PUT", "/orders/"),
+            ("Index", "GET", "/"),
+        ]
+    
+    def test_unregistered_handlers_need_a_route(self, tmp_path):
+        """Test handlers without a registration are left out unless their route is given."""
+        (tmp_path / "api.go").write_text(
+            "package api\n\nimport \"net/http\"\n\n"
+            "func Health(w http.ResponseWriter, r *http.Request) {}\n\n"
+            "func CreateUser(w http.ResponseWriter, r *http.Request) {}\n"
+        )
+        (tmp_path / "router.go").write_text(
+            'package api\n\nfunc init() {\n\thttp.HandleFunc("GET /healthz", Health)\n}\n'
+        )
+        
+        write_go_load_test(str(tmp_path))
+        source = (tmp_path / LOAD_TEST_FILE).read_text()
+        assert 'testgenLoad(t, http.HandlerFunc(Health), "GET", "/healthz")' in source
+        assert "CreateUser" not in source
+        
+        write_go_load_test(str(tmp_path), {"CreateUser": "post /users"})
+        source = (tmp_path / LOAD_TEST_FILE).read_text()
+        assert 'testgenLoad(t, http.HandlerFunc(CreateUser), "POST", "/users")' in source
+        
+        (tmp_path / "router.go").unlink()
+        assert write_go_load_test(str(tmp_path)) is None
+    
+    def test_generated_file(self):
+        """Test the load test is build-tagged, uses httptest and reports for testgen."""
+        source = generate_go_load_test("api", find_go_handlers(GO_API))
+        
+        assert source.startswith("//go:build testgen_load\n")
+        assert "package api\n" in source
+        assert 'testgenLoad(t, http.HandlerFunc(Health), "GET", "/health")' in source
+        assert "httptest.NewServer(handler)" in source
+        assert f'fmt.Println("{RESULT_MARKER}" + string(out))' in source
+    
+    def test_results_report_throughput_and_errors(self):
+        """Test result lines become benchmark results with throughput and error rate."""
+        output = "\n".join([
+            "=== RUN   TestLoadHealth",
+            RESULT_MARKER + json.dumps({
+                "name": "TestLoadHealth", "concurrency": 8, "duration": 2.0,
+                "requests": 400, "errors": 4, "samples": [0.001, 0.002, 0.003, 0.004]
+            }),
+            "--- PASS: TestLoadHealth (2.00s)",
+            "ok  \texample.com/api\t2.013s",
+        ])
+        
+        result = LoadTester()._parse_go_output(output, "/src/api")[0]
+        
+        assert (result.throughput, result.error_rate, result.concurrency) == (200.0, 0.01, 8)
+        assert result.iterations == 400
+        assert result.p50 == pytest.approx(0.0025)
+        assert result.file_path == "example.com/api"
+        assert result.language == "go"
+    
+    def test_only_4xx_is_an_error(self):
+        """Test 4xx responses are counted, and a test where all of them are is reported as an error."""
+        tester = LoadTester()
+        data = {"concurrency": 2, "duration": 1.0, "requests": 100, "samples": [0.001]}
+        
+        partly = tester._build_result("TestLoadUsers", "api", {**data, "client_errors": 10}, "go", "testing")
+        wrong_route = tester._build_result("TestLoadUsers", "api", {**data, "client_errors": 100}, "go", "testing")
+        
+        assert partly.client_error_rate == 0.1 and partly.error is None
+        assert wrong_route.error == "Every response was 4xx (100 requests): check the method and path"
+    
+    @pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+    def test_generated_load_test_runs(self, tmp_path):
+        """Test the generated file compiles and loads the handlers in-process."""
+        (tmp_path / "go.mod").write_text("module example.com/api\n\ngo 1.21\n")
+        (tmp_path / "api.go").write_text(GO_API.replace(
+            "func (s *Server) Users(w http.ResponseWriter, r *http.Request) {}\n", ""
+        ))
+        
+        assert write_go_load_test(str(tmp_path)) == tmp_path / LOAD_TEST_FILE
+        results = LoadTester(concurrency=2, duration=0.2).run_go(str(tmp_path))
+        
+        assert [r.name for r in results] == ["TestLoadHealth", "TestLoadNewRouter"]
+        assert all(r.succeeded and r.error_rate == 0.0 and r.throughput > 0 for r in results)
+
+
+class TestLocalServiceLoad:
+    """Test suite for loading locally running services."""
+    
+    def test_url_load_counts_server_errors(self, local_server):
+        """Test requests are sent concurrently and 5xx responses count as errors."""
+        tester = LoadTester(concurrency=2, duration=0.2)
+        
+        ok = tester.run_url(f"{local_server}/ok")
+        failing = tester.run_url(f"{local_server}/broken")
+        
+        assert ok.name == "GET /ok"
+        assert ok.succeeded and ok.error_rate == 0.0 and ok.iterations > 0
+        assert failing.error_rate == 1.0
+        assert "GET /ok" in format_load_report([ok, failing])
+    
+    def test_url_load_all_4xx(self, local_server):
+        """Test a URL answering 4xx only is reported as an error, not as a fast service."""
+        result = LoadTester(concurrency=1, duration=0.1).run_url(f"{local_server}/missing")
+        
+        assert result.client_error_rate == 1.0 and result.error_rate == 0.0
+        assert result.error.startswith("Every response was 4xx")
+    
+    def test_remote_hosts_refused(self):
+        """Test load tests never target anything but local services."""
+        result = LoadTester(duration=0.1).run_url("https://example.com/")
+        
+        assert "only run against local services" in result.error
+    
+    def test_results_tracked_with_benchmarks(self, tmp_path, local_server):
+        """Test load results are recorded in the benchmark history."""
+        result = LoadTester(concurrency=1, duration=0.1).run_url(f"{local_server}/ok", name="health")
+        store = BenchmarkStore(str(tmp_path))
+        
+        store.record([result])
+        
+        recorded = store.load()[result.key][0]
+        assert recorded.throughput == pytest.approx(result.throughput)
+        assert recorded.concurrency == 1
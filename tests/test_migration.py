@@ -0,0 +1,391 @@
+"""
+Unit tests for test framework migration.
+
+This test suite covers:
+- unittest -> pytest rewrites (asserts, raises, hooks, skips, imports)
+- Go stdlib <-> testify, Jest -> Vitest and JUnit 4 -> 5 rewrites
+- Leftovers the rewrite can't translate, and the LLM prompt for them
+- Verifying per-test outcomes before and after with go test -overlay
+- Restoring files swapped for a run on errors, SIGTERM and after a killed run
+"""
+
+import os
+import shutil
+import signal
+from types import SimpleNamespace
+
+import pytest
+from testgen.core.language_config import Language
+from testgen.core.migration import (
+    MIGRATIONS, SWAP_BACKUP_SUFFIX, _swapped, declared_tests, find_leftovers, get_migration, migrate_file
+)
+from testgen.core.prompt_templates import PromptTemplates
+
+
+UNITTEST = '''import sys
+import unittest
+
+from calc import add, div
+
+
+class TestCalc(unittest.TestCase):
+    @classmethod
+    def setUpClass(cls):
+        cls.values = [1, 2]
+
+    def setUp(self):
+        self.total = 3
+
+    def test_add(self):
+        self.assertEqual(add(1, 2), self.total)
+        self.assertTrue(add(1, 1) == 2, "sum")
+        self.assertFalse(add(1, 1) == 3 or False)
+        self.assertAlmostEqual(0.1 + 0.2, 0.3, places=5)
+
+    def test_div(self):
+        with self.assertRaisesRegex(ZeroDivisionError, "zero") as cm:
+            div(1, 0)
+        self.assertIn("division", str(cm.exception))
+        self.assertRaises(ValueError, int, "x", base=10)
+
+    @unittest.skipUnless(sys.platform == "linux", "linux only")
+    def test_many(self):
+        for n in self.values:
+            with self.subTest(n=n):
+                self.assertCountEqual([n], [n])
+
+
+if __name__ == "__main__":
+    unittest.main()
+'''
+
+GO_STDLIB = """package calc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHalf(t *testing.T) {
+	got, err := Half(4)
+	if err != nil {
+		t.Fatalf("Half: %v", err)
+	}
+	if got != 2 {
+		t.Errorf("Half(4) = %d", got)
+	}
+	if !reflect.DeepEqual(Split(4), []int{2, 2}) {
+		t.Error("split")
+	}
+	if got > 4 {
+		t.Errorf("too big")
+	}
+}
+"""
+
+CALC = """package calc
+
+import "errors"
+
+func Half(n int) (int, error) {
+	if n < 0 {
+		return 0, errors.New("negative")
+	}
+	return n / 2, nil
+}
+
+func Split(n int) []int {
+	return []int{n / 2, n - n/2}
+}
+"""
+
+# Just enough of testify for the migrated tests (the real module isn't needed offline)
+FAKE_ASSERT = """package assert
+
+import (
+	"reflect"
+	"testing"
+)
+
+func EqualValues(t *testing.T, want, got interface{}, msg ...interface{}) bool {
+	if reflect.ValueOf(got).Convert(reflect.TypeOf(want)).Interface() != want {
+		t.Errorf("got %v, want %v", got, want)
+		return false
+	}
+	return true
+}
+
+// Like testify, a typed nil pointer counts as nil
+func Nil(t *testing.T, object interface{}, msg ...interface{}) bool {
+	if object != nil && !(reflect.ValueOf(object).Kind() == reflect.Ptr && reflect.ValueOf(object).IsNil()) {
+		t.Errorf("expected nil, got %v", object)
+		return false
+	}
+	return true
+}
+
+func Equal(t *testing.T, want, got interface{}, msg ...interface{}) bool {
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+		return false
+	}
+	return true
+}
+"""
+
+FAKE_REQUIRE = """package require
+
+import "testing"
+
+func NoError(t *testing.T, err error, msg ...interface{}) {
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+"""
+
+
+class TestUnittestToPytest:
+    """Test the unittest -> pytest rewrite."""
+    
+    def test_rewrite(self):
+        """Test asserts, raises with match and cm.value, hooks, skip decorators and imports."""
+        migrated = MIGRATIONS[("unittest", "pytest")].rewrite(UNITTEST)
+        
+        assert "class TestCalc:\n" in migrated
+        assert "    def setup_class(cls):" in migrated and "    def setup_method(self):" in migrated
+        assert "        assert add(1, 2) == self.total\n" in migrated
+        assert '        assert add(1, 1) == 2, "sum"\n' in migrated
+        assert "        assert not (add(1, 1) == 3 or False)\n" in migrated
+        assert "        assert 0.1 + 0.2 == pytest.approx(0.3, abs=1e-5)\n" in migrated
+        assert 'with pytest.raises(ZeroDivisionError, match="zero") as cm:' in migrated
+        assert 'assert "division" in str(cm.value)' in migrated
+        assert '        with pytest.raises(ValueError):\n            int("x", base=10)\n' in migrated
+        assert '@pytest.mark.skipif(not (sys.platform == "linux"), reason="linux only")' in migrated
+        assert migrated.startswith("import sys\nimport pytest\n\nfrom calc")
+        assert "unittest" not in migrated.replace("self.subTest", "")
+        compile(migrated, "test_calc.py", "exec")
+    
+    def test_leftovers_and_test_names(self):
+        """Test subTest and assertCountEqual are left over and no test is lost."""
+        migration = MIGRATIONS[("unittest", "pytest")]
+        migrated = migration.rewrite(UNITTEST)
+        
+        leftovers = find_leftovers(migrated, migration)
+        
+        assert [(l.text.strip(), l.reason.split(":")[0]) for l in leftovers] == [
+            ("with self.subTest(n=n):", "subTest has no pytest equivalent"),
+            ("self.assertCountEqual([n], [n])", "no direct pytest equivalent"),
+        ]
+        assert declared_tests(migrated, Language.PYTHON) == declared_tests(UNITTEST, Language.PYTHON)
+
+
+class TestOtherRewrites:
+    """Test the Go, JavaScript and Java rewrites."""
+    
+    def test_go_stdlib_and_testify(self):
+        """Test if-checks become assert/require calls and back, with imports fixed both ways."""
+        testify = MIGRATIONS[("stdlib", "testify")].rewrite(GO_STDLIB)
+        stdlib = MIGRATIONS[("testify", "stdlib")].rewrite(testify)
+        
+        assert '\trequire.NoError(t, err, "Half: %v", err)\n' in testify
+        assert '\tassert.EqualValues(t, 2, got, "Half(4) = %d", got)\n' in testify
+        assert '\tassert.Equal(t, []int{2, 2}, Split(4), "split")\n' in testify
+        assert '\t"testing"\n\n\t"github.com/stretchr/testify/assert"\n\t"github.com/stretchr/testify/require"\n)' in testify
+        assert '"reflect"' not in testify
+        assert [l.text.strip() for l in find_leftovers(testify, MIGRATIONS[("stdlib", "testify")])] == ['t.Errorf("too big")']
+        
+        assert '\tif err != nil {\n\t\tt.Fatalf("Half: %v: unexpected error: %v", err, err)\n\t}\n' in stdlib
+        assert "\tif got != 2 {\n" in stdlib
+        assert "\tif !reflect.DeepEqual(Split(4), []int{2, 2}) {\n" in stdlib
+        assert "testify" not in stdlib
+    
+    def test_jest_to_vitest(self):
+        """Test jest.* becomes vi.*, vitest imports are added and requireActual is left over."""
+        jest = (
+            "import { total } from './cart';\n\n"
+            "jest.mock('./prices', () => ({ ...jest.requireActual('./prices'), tax: jest.fn() }));\n\n"
+            "describe('total', () => {\n"
+            "  beforeEach(() => jest.useFakeTimers());\n"
+            "  it('adds', () => {\n"
+            "    const log = jest.spyOn(console, 'log') as jest.SpyInstance;\n"
+            "    expect(total([1, 2])).toBe(3);\n"
+            "  });\n"
+            "});\n"
+        )
+        migration = MIGRATIONS[("jest", "vitest")]
+        
+        migrated = migration.rewrite(jest)
+        
+        assert migrated.startswith("import { describe, it, expect, vi, beforeEach, type MockInstance } from 'vitest';\n")
+        assert "vi.spyOn(console, 'log') as MockInstance" in migrated
+        assert "vi.mock('./prices', () => ({ ...jest.requireActual('./prices'), tax: vi.fn() }));" in migrated
+        assert [l.line for l in find_leftovers(migrated, migration)] == [4]
+    
+    def test_junit4_to_junit5(self):
+        """Test annotations, imports, message order, expected exceptions and timeouts."""
+        junit4 = (
+            "import org.junit.Before;\nimport org.junit.Test;\n\nimport static org.junit.Assert.*;\n\n"
+            "public class CartTest {\n"
+            "    @Before\n    public void setUp() {\n        cart = new Cart();\n    }\n\n"
+            "    @Test\n    public void addsItem() {\n"
+            '        assertEquals("one item", 1, cart.size());\n'
+            "        assertEquals(0.5, cart.weight(), 0.01);\n"
+            "        assertEquals(label, 1, cart.size());\n"
+            "    }\n\n"
+            "    @Test(expected = IllegalArgumentException.class, timeout = 100)\n"
+            "    public void rejectsNull() {\n        cart.add(null);\n    }\n"
+            "}\n"
+        )
+        migration = MIGRATIONS[("junit4", "junit5")]
+        
+        migrated = migration.rewrite(junit4)
+        
+        assert "import org.junit.jupiter.api.BeforeEach;\nimport org.junit.jupiter.api.Test;\n" in migrated
+        assert "import java.util.concurrent.TimeUnit;\n\nimport static org.junit.jupiter.api.Assertions.*;" in migrated
+        assert '    @BeforeEach\n' in migrated
+        assert 'assertEquals(1, cart.size(), "one item");' in migrated
+        assert "assertEquals(0.5, cart.weight(), 0.01);" in migrated
+        assert (
+            "    @Test\n    @Timeout(value = 100, unit = TimeUnit.MILLISECONDS)\n    public void rejectsNull() {\n"
+            "        assertThrows(IllegalArgumentException.class, () -> {\n            cart.add(null);\n        });\n    }\n"
+        ) in migrated
+        assert [l.text.strip() for l in find_leftovers(migrated, migration)] == ["assertEquals(label, 1, cart.size());"]
+    
+    def test_unsupported_pair(self):
+        """Test an unknown pair lists the supported ones."""
+        with pytest.raises(ValueError, match="unittest -> pytest"):
+            get_migration("mocha", "vitest")
+
+
+class TestLLMAssistance:
+    """Test handing leftovers to the LLM."""
+    
+    def test_prompt_reuses_target_template(self):
+        """Test the prompt has the leftovers and the pytest template's framework requirements."""
+        prompt = PromptTemplates.get_migration_prompt(
+            Language.PYTHON, "def test_x(self): {}", source="unittest", target="pytest",
+            leftovers="- line 3: self.subTest(n=n)", template=(Language.PYTHON, "pytest"), notes=["Plain asserts"]
+        )
+        
+        assert prompt.startswith("You are an expert Python developer migrating tests from unittest to pytest.")
+        assert "- line 3: self.subTest(n=n)" in prompt
+        assert "- Use pytest framework\n- Include imports (pytest, unittest.mock if needed)\n- Plain asserts\n" in prompt
+        assert "def test_x(self): {}" in prompt
+    
+    def test_leftovers_sent_to_llm(self, tmp_path):
+        """Test the LLM's completion replaces the rewrite and its leftovers are re-checked."""
+        test_file = tmp_path / "test_calc.py"
+        test_file.write_text(UNITTEST)
+        completed = MIGRATIONS[("unittest", "pytest")].rewrite(UNITTEST).replace(
+            "            with self.subTest(n=n):\n                self.assertCountEqual([n], [n])\n",
+            "            assert sorted([n]) == sorted([n])\n",
+        )
+        
+        class FakeLLM:
+            def __init__(self):
+                self.prompts = []
+            
+            def generate(self, prompt):
+                self.prompts.append(prompt)
+                return SimpleNamespace(content=f"```python\n{completed}```")
+        
+        llm = FakeLLM()
+        result = migrate_file(str(test_file), "unittest", "pytest", verify=False, llm_client=llm)
+        
+        assert "self.assertCountEqual([n], [n])  (no direct pytest equivalent)" in llm.prompts[0]
+        assert result.llm_used and result.verified
+        assert result.leftovers == []
+        assert result.migrated == completed
+        assert test_file.read_text() == UNITTEST
+
+
+class TestSwap:
+    """Test files overwritten in place for a verification run."""
+    
+    def test_restored_on_sigterm(self, tmp_path):
+        """Test SIGTERM during the run puts the original back and removes the backup."""
+        test_file = tmp_path / "test_calc.py"
+        test_file.write_text(UNITTEST)
+        
+        with pytest.raises(SystemExit):
+            with _swapped(test_file, "migrated\n"):
+                assert test_file.read_text() == "migrated\n"
+                assert (tmp_path / ("test_calc.py" + SWAP_BACKUP_SUFFIX)).read_text() == UNITTEST
+                os.kill(os.getpid(), signal.SIGTERM)
+        
+        assert test_file.read_text() == UNITTEST
+        assert not (tmp_path / ("test_calc.py" + SWAP_BACKUP_SUFFIX)).exists()
+    
+    def test_backup_of_killed_run_restored(self, tmp_path):
+        """Test a backup left by a killed run is restored before the next swap."""
+        test_file = tmp_path / "test_calc.py"
+        test_file.write_text("migrated\n")
+        (tmp_path / ("test_calc.py" + SWAP_BACKUP_SUFFIX)).write_text(UNITTEST)
+        
+        with _swapped(test_file, "other\n"):
+            pass
+        
+        assert test_file.read_text() == UNITTEST
+        assert not (tmp_path / ("test_calc.py" + SWAP_BACKUP_SUFFIX)).exists()
+
+
+@pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+class TestVerify:
+    """Test before/after verification with the Go toolchain."""
+    
+    def make_package(self, root, testify=True):
+        """A calc package, with a local stand-in for testify when asked."""
+        go_mod = "module example.com/calc\n\ngo 1.22\n"
+        if testify:
+            go_mod += "\nrequire github.com/stretchr/testify v1.9.0\n\nreplace github.com/stretchr/testify => ./testify\n"
+            (root / "testify" / "assert").mkdir(parents=True)
+            (root / "testify" / "require").mkdir()
+            (root / "testify" / "go.mod").write_text("module github.com/stretchr/testify\n\ngo 1.22\n")
+            (root / "testify" / "assert" / "assert.go").write_text(FAKE_ASSERT)
+            (root / "testify" / "require" / "require.go").write_text(FAKE_REQUIRE)
+        (root / "go.mod").write_text(go_mod)
+        (root / "calc.go").write_text(CALC)
+        test_file = root / "calc_test.go"
+        test_file.write_text(GO_STDLIB)
+        return test_file
+    
+    def test_same_outcomes(self, tmp_path):
+        """Test the testify version is verified per test and the file isn't touched."""
+        test_file = self.make_package(tmp_path)
+        
+        result = migrate_file(str(test_file), "stdlib", "testify")
+        
+        assert result.problem is None
+        assert result.verified
+        assert result.outcomes == {"TestHalf": ("passed", "passed")}
+        assert test_file.read_text() == GO_STDLIB
+        assert result.patch.startswith(f"--- a/{str(test_file).lstrip('/')}")
+    
+    def test_changed_outcome_is_rejected(self, tmp_path):
+        """Test a failing test that the migration would make pass is caught."""
+        test_file = self.make_package(tmp_path)
+        # A typed nil in an interface isn't == nil, but assert.Nil accepts it
+        test_file.write_text(GO_STDLIB + (
+            "\nfunc TestNilInterface(t *testing.T) {\n"
+            "\tvar p *int\n\tvar x interface{} = p\n"
+            "\tif x != nil {\n\t\tt.Errorf(\"x = %v\", x)\n\t}\n}\n"
+        ))
+        
+        result = migrate_file(str(test_file), "stdlib", "testify")
+        
+        assert not result.verified
+        assert result.outcomes == {"TestHalf": ("passed", "passed"), "TestNilInterface": ("failed", "passed")}
+        assert result.problem == "outcomes differ: TestNilInterface: failed -> passed"
+    
+    def test_testify_not_required(self, tmp_path):
+        """Test a module without testify is reported instead of failing to build."""
+        test_file = self.make_package(tmp_path, testify=False)
+        
+        result = migrate_file(str(test_file), "stdlib", "testify")
+        
+        assert not result.verified
+        assert result.problem == (
+            "migrated tests didn't run: github.com/stretchr/testify isn't required in go.mod "
+            "(go get github.com/stretchr/testify)"
+        )
@@ -0,0 +1,233 @@
+"""
+Unit tests for OpenAPI mock servers.
+
+This test suite covers:
+- Schema-valid example generation
+- Request validation against the spec
+- Standalone server overrides, error injection and violations
+- Go in-process mock generation
+"""
+
+import json
+import shutil
+import subprocess
+import urllib.error
+import urllib.request
+
+import pytest
+from testgen.core.openapi_mock import (
+    MockServer,
+    example_for_schema,
+    OpenAPIError,
+    generate_go_mock,
+    go_mock_path,
+    validate_value,
+)
+
+
+SPEC = {
+    "openapi": "3.0.3",
+    "info": {"title": "Billing API", "version": "1"},
+    "paths": {
+        "/charges/{id}": {
+            "get": {
+                "parameters": [
+                    {"name": "id", "in": "path", "required": True, "schema": {"type": "integer", "minimum": 1}},
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Charge"}}},
+                    }
+                },
+            }
+        },
+        "/charges": {
+            "post": {
+                "requestBody": {
+                    "required": True,
+                    "content": {"application/json": {"schema": {
+                        "type": "object",
+                        "required": ["amount"],
+                        "properties": {"amount": {"type": "integer"}},
+                    }}},
+                },
+                "responses": {"201": {"description": "created"}},
+            }
+        },
+    },
+    "components": {
+        "schemas": {
+            "Charge": {
+                "type": "object",
+                "required": ["id", "amount"],
+                "properties": {
+                    "id": {"type": "integer", "minimum": 1},
+                    "amount": {"type": "number"},
+                    "currency": {"type": "string", "enum": ["usd", "eur"]},
+                    "created": {"type": "string", "format": "date-time"},
+                },
+            }
+        }
+    },
+}
+
+
+GO_VALIDATION_TEST = """package billing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidation(t *testing.T) {
+	// Not NewBillingAPIMock: its cleanup would fail this test for the violations it expects
+	m := &BillingAPIMock{overrides: map[string]billingAPIMockResponse{}}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.serve))
+	defer m.Close()
+
+	http.Get(m.URL + "/charges/0")
+	http.Get(m.URL + "/charges/abc")
+	http.Post(m.URL+"/charges", "application/json", strings.NewReader(`{"amount": 1.5}`))
+	http.Post(m.URL+"/charges", "application/json", strings.NewReader(`{"currency": "usd"}`))
+	http.Post(m.URL+"/charges", "application/json", strings.NewReader(`{"amount": 3}`))
+
+	want := []string{
+		"GET /charges/{id}: path.id: below minimum 1",
+		"GET /charges/{id}: path.id: expected integer, got string",
+		"POST /charges: body.amount: expected integer, got float64",
+		"POST /charges: body: missing required property 'amount'",
+	}
+	if got := m.Violations(); !reflect.DeepEqual(got, want) {
+		t.Errorf("violations:\\n got %q\\nwant %q", got, want)
+	}
+}
+"""
+
+
+@pytest.fixture
+def spec_file(tmp_path):
+    """Spec written to disk."""
+    path = tmp_path / "billing.json"
+    path.write_text(json.dumps(SPEC))
+    return path
+
+
+class TestExamples:
+    """Test example generation."""
+    
+    def test_examples_are_schema_valid(self):
+        """Test generated examples validate against their schema."""
+        schema = {"$ref": "#/components/schemas/Charge"}
+        example = example_for_schema(schema, SPEC)
+        
+        assert example["currency"] == "usd"
+        assert validate_value(example, schema, SPEC) == []
+    
+    def test_validation_reports_problems(self):
+        """Test invalid values are reported with their location."""
+        problems = validate_value({"id": 0, "currency": "gbp"}, {"$ref": "#/components/schemas/Charge"}, SPEC)
+        
+        assert any("missing required property 'amount'" in p for p in problems)
+        assert any("$.id: below minimum 1" in p for p in problems)
+        assert any("$.currency" in p for p in problems)
+
+
+class TestMockServer:
+    """Test the standalone mock server."""
+    
+    def test_serves_example_response(self, spec_file):
+        """Test operations respond with schema-valid examples."""
+        with MockServer(str(spec_file)) as mock:
+            body = json.load(urllib.request.urlopen(f"{mock.url}/charges/3"))
+            
+            assert body["id"] == 1
+            assert mock.violations == []
+    
+    def test_override_and_error_injection(self, spec_file):
+        """Test per-test overrides and injected errors."""
+        with MockServer(str(spec_file)) as mock:
+            mock.override("GET", "/charges/{id}", 200, {"id": 42, "amount": 1})
+            assert json.load(urllib.request.urlopen(f"{mock.url}/charges/3"))["id"] == 42
+            
+            mock.inject_error("GET", "/charges/{id}", 503)
+            with pytest.raises(urllib.error.HTTPError) as exc_info:
+                urllib.request.urlopen(f"{mock.url}/charges/3")
+            assert exc_info.value.code == 503
+    
+    def test_requests_not_matching_spec_are_recorded(self, spec_file):
+        """Test invalid requests become violations."""
+        with MockServer(str(spec_file)) as mock:
+            request = urllib.request.Request(
+                f"{mock.url}/charges",
+                data=b"{}",
+                headers={"Content-Type": "application/json"},
+                method="POST",
+            )
+            with pytest.raises(urllib.error.HTTPError):
+                urllib.request.urlopen(request)
+            with pytest.raises(urllib.error.HTTPError):
+                urllib.request.urlopen(f"{mock.url}/refunds")
+            
+            assert len(mock.violations) == 2
+            assert "missing required property 'amount'" in mock.violations[0]
+
+
+class TestGoMock:
+    """Test Go in-process mock generation."""
+    
+    def test_generated_source(self, spec_file):
+        """Test the generated mock names routes and checks requests."""
+        source = generate_go_mock(str(spec_file), "billing")
+        
+        assert "package billing" in source
+        assert "func NewBillingAPIMock(t testing.TB) *BillingAPIMock" in source
+        assert 'regexp.MustCompile("^/charges/([^/]+)$")' in source
+        assert 'pathParams:   []string{"id"}' in source
+        assert '\\"required\\": [\\"amount\\"]' in source
+    
+    def test_non_ascii_strings(self, tmp_path):
+        """Test non-ASCII examples are written as UTF-8, not as JSON surrogate escapes."""
+        spec = json.loads(json.dumps(SPEC))
+        spec["components"]["schemas"]["Charge"]["properties"]["currency"]["enum"] = ["💶", "€"]
+        path = tmp_path / "billing.json"
+        path.write_text(json.dumps(spec))
+        
+        source = generate_go_mock(str(path), "billing")
+        
+        assert "\\ud83d" not in source
+        assert '\\"currency\\": \\"💶\\"' in source
+    
+    def test_default_output_in_package(self, spec_file, tmp_path):
+        """Test the mock goes to the directory of the Go package, not next to the spec."""
+        (tmp_path / "go.mod").write_text("module example.com/shop\n\ngo 1.21\n")
+        (tmp_path / "internal" / "billing").mkdir(parents=True)
+        (tmp_path / "internal" / "billing" / "billing.go").write_text("package billing\n")
+        
+        assert go_mock_path(str(spec_file), "billing") == tmp_path / "internal" / "billing" / "billing_mock_test.go"
+        with pytest.raises(OpenAPIError, match="Cannot tell where Go package 'payments' is"):
+            go_mock_path(str(spec_file), "payments")
+    
+    @pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+    def test_generated_source_compiles(self, spec_file, tmp_path):
+        """Test the generated mock passes go vet."""
+        (tmp_path / "go.mod").write_text("module billing\n\ngo 1.21\n")
+        (tmp_path / "billing_mock_test.go").write_text(generate_go_mock(str(spec_file), "billing"))
+        
+        result = subprocess.run(["go", "vet", "./..."], cwd=tmp_path, capture_output=True, text=True)
+        
+        assert result.returncode == 0, result.stderr
+    
+    @pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+    def test_generated_mock_validates_requests(self, spec_file, tmp_path):
+        """Test the generated mock reports parameters and bodies that break the schema."""
+        (tmp_path / "go.mod").write_text("module billing\n\ngo 1.21\n")
+        (tmp_path / "billing_mock_test.go").write_text(generate_go_mock(str(spec_file), "billing"))
+        (tmp_path / "validate_test.go").write_text(GO_VALIDATION_TEST)
+        
+        result = subprocess.run(["go", "test", "./..."], cwd=tmp_path, capture_output=True, text=True)
+        
+        assert result.returncode == 0, result.stdout + result.stderr
@@ -0,0 +1,230 @@
+"""
+Unit tests for test translation across code ports.
+
+This test suite covers:
+- Symbol indexes of Python and Go implementations
+- Mapping source tests to the port's symbols, and reporting unmappable ones
+- The translation prompt and `Ported from:` trace comments
+- Per-test parity of a Python suite and its Go translation with go test
+"""
+
+import shutil
+from types import SimpleNamespace
+
+import pytest
+from testgen.core import port as port_module
+from testgen.core.language_config import Language
+from testgen.core.port import build_symbol_index, find_source_tests, map_tests, port_tests
+from testgen.core.prompt_templates import PromptTemplates
+
+
+CART_PY = '''class Cart:
+    def __init__(self):
+        self.items = []
+
+    def add_item(self, name, price, quantity=1):
+        self.items.append((name, price, quantity))
+
+    def remove(self, name):
+        self.items = [i for i in self.items if i[0] != name]
+
+    def total(self):
+        return sum(price * quantity for _, price, quantity in self.items)
+
+
+def apply_discount(total, percent):
+    return total * (100 - percent) / 100
+'''
+
+TEST_CART_PY = '''from cart import Cart, apply_discount
+
+
+class TestCart:
+    def test_add_item(self):
+        cart = Cart()
+        cart.add_item("apple", 2.0, 3)
+        assert cart.total() == 6.0
+
+    def test_remove(self):
+        cart = Cart()
+        cart.add_item("apple", 2.0)
+        cart.remove("apple")
+        assert cart.total() == 0
+
+
+def test_discount():
+    assert apply_discount(200, 10) == 180
+
+
+def test_rounding():
+    assert round(2.675, 2) == 2.67
+'''
+
+CART_GO = """package cart
+
+type item struct {
+	name     string
+	price    float64
+	quantity int
+}
+
+type Cart struct {
+	items []item
+}
+
+func NewCart() *Cart {
+	return &Cart{}
+}
+
+func (c *Cart) AddItem(name string, price float64, quantity int) {
+	c.items = append(c.items, item{name, price, quantity})
+}
+
+func (c *Cart) Total() float64 {
+	total := 0.0
+	for _, i := range c.items {
+		total += i.price * float64(i.quantity)
+	}
+	return total
+}
+
+func ApplyDiscount(total, percent float64) float64 {
+	return total * (100 - percent) / 10
+}
+"""
+
+TRANSLATED = """```go
+import "testing"
+
+func TestCart_AddItem(t *testing.T) {
+	cart := NewCart()
+	cart.AddItem("apple", 2.0, 3)
+	if got := cart.Total(); got != 6.0 {
+		t.Errorf("Total() = %v, want 6", got)
+	}
+}
+
+func TestDiscount(t *testing.T) {
+	if got := ApplyDiscount(200, 10); got != 180 {
+		t.Errorf("ApplyDiscount(200, 10) = %v, want 180", got)
+	}
+}
+```"""
+
+
+def make_port(root):
+    """A Python cart service and its Go port."""
+    (root / "py" / "tests").mkdir(parents=True)
+    (root / "py" / "cart.py").write_text(CART_PY)
+    (root / "py" / "tests" / "test_cart.py").write_text(TEST_CART_PY)
+    (root / "go").mkdir()
+    (root / "go" / "go.mod").write_text("module example.com/cart\n\ngo 1.22\n")
+    (root / "go" / "cart.go").write_text(CART_GO)
+    return root / "py", root / "go"
+
+
+class FakeLLM:
+    def __init__(self):
+        self.prompts = []
+    
+    def generate(self, prompt):
+        self.prompts.append(prompt)
+        return SimpleNamespace(content=TRANSLATED)
+
+
+class TestMapping:
+    """Test indexing both sides and mapping the source tests."""
+    
+    def test_symbol_indexes(self, tmp_path):
+        """Test classes, methods, functions and Go types are indexed, tests excluded."""
+        source_dir, target_dir = make_port(tmp_path)
+        
+        python = build_symbol_index(source_dir, Language.PYTHON)
+        go = build_symbol_index(target_dir, Language.GO)
+        
+        assert sorted(python) == ["Cart", "Cart.__init__", "Cart.add_item", "Cart.remove", "Cart.total", "apply_discount"]
+        assert python["Cart.add_item"].signature == "def add_item(self, name, price, quantity=1)"
+        assert sorted(go) == ["ApplyDiscount", "Cart", "Cart.AddItem", "Cart.Total", "NewCart", "item"]
+        assert go["Cart.Total"].kind == "method"
+        assert go["NewCart"].location == "cart.go:13"
+    
+    def test_mapped_and_unmappable_tests(self, tmp_path):
+        """Test each test maps through what it calls; missing counterparts are reported."""
+        source_dir, target_dir = make_port(tmp_path)
+        source_index = build_symbol_index(source_dir, Language.PYTHON)
+        tests = find_source_tests(source_dir, Language.PYTHON, source_index)
+        
+        mappings = map_tests(tests, source_index, build_symbol_index(target_dir, Language.GO), Language.GO)
+        
+        assert [(m.test.id, m.target_test) for m in mappings] == [
+            ("tests/test_cart.py::TestCart::test_add_item", "TestCart_AddItem"),
+            ("tests/test_cart.py::TestCart::test_remove", "TestCart_Remove"),
+            ("tests/test_cart.py::test_discount", "TestDiscount"),
+            ("tests/test_cart.py::test_rounding", "TestRounding"),
+        ]
+        assert {s: t.name for s, t in mappings[0].symbols.items()} == {
+            "Cart": "NewCart", "Cart.add_item": "Cart.AddItem", "Cart.total": "Cart.Total"
+        }
+        assert mappings[1].problem == "no Go counterpart for Cart.remove"
+        assert mappings[2].mappable
+        assert mappings[3].problem == "calls nothing from the ported code"
+
+
+class TestTranslation:
+    """Test the prompt and the translated file."""
+    
+    def test_prompt_block(self):
+        """Test the ported tests go into the target template with the trace comment."""
+        prompt = PromptTemplates.get_prompt(Language.GO, "package cart", ported_tests="- a.py::test_x -> TestX")
+        
+        assert "Translate these tests of the original implementation to it:\n\n- a.py::test_x -> TestX" in prompt
+        assert "`// Ported from: <original test id>`" in prompt
+        assert prompt.endswith("Generate ONLY the test code, no explanations.")
+    
+    def test_translation_without_run(self, tmp_path):
+        """Test only mapped tests are sent, and the file gets its package and trace comments."""
+        source_dir, target_dir = make_port(tmp_path)
+        llm = FakeLLM()
+        
+        report = port_tests(str(source_dir), str(target_dir), Language.PYTHON, Language.GO, llm_client=llm, run=False)
+        
+        assert "- tests/test_cart.py::TestCart::test_add_item -> TestCart_AddItem" in llm.prompts[0]
+        assert "Cart.add_item -> Cart.AddItem (func (c *Cart) AddItem(name string, price float64, quantity int), cart.go:17)" in llm.prompts[0]
+        assert "test_remove" not in llm.prompts[0] and "test_rounding" not in llm.prompts[0]
+        written = (target_dir / "cart_port_test.go").read_text()
+        assert written.startswith("package cart\n\nimport \"testing\"\n")
+        assert "// Ported from: tests/test_cart.py::test_discount\nfunc TestDiscount(" in written
+        assert list(report.files) == [target_dir / "cart_port_test.go"]
+        assert report.mapped[0].parity is None
+    
+    def test_unsupported_port(self, tmp_path):
+        """Test language pairs other than Python <-> Go are refused."""
+        with pytest.raises(ValueError, match="Unsupported port python -> java"):
+            port_tests(str(tmp_path), str(tmp_path), Language.PYTHON, Language.JAVA)
+
+
+@pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+class TestParity:
+    """Test running the translated tests against the Go port."""
+    
+    def test_divergence_is_reported(self, tmp_path, monkeypatch):
+        """Test the Go run finds the port's discount bug that the Python suite passes."""
+        source_dir, target_dir = make_port(tmp_path)
+        run_outcomes = port_module.run_outcomes
+        
+        def fake_python_run(path, code, framework, language):
+            """The original suite passes (pytest isn't run here)."""
+            if language == Language.PYTHON:
+                return {"tests.test_cart.TestCart.test_add_item": "passed", "tests.test_cart.test_discount": "passed"}, None
+            return run_outcomes(path, code, framework, language)
+        
+        monkeypatch.setattr(port_module, "run_outcomes", fake_python_run)
+        report = port_tests(str(source_dir), str(target_dir), Language.PYTHON, Language.GO, llm_client=FakeLLM())
+        
+        assert report.problems == []
+        assert [(m.target_test, m.source_outcome, m.target_outcome) for m in report.mapped] == [
+            ("TestCart_AddItem", "passed", "passed"),
+            ("TestDiscount", "passed", "failed"),
+        ]
+        assert [m.target_test for m in report.diverging] == ["TestDiscount"]
+        assert report.to_dict()["tests"][1]["problem"] == "no Go counterpart for Cart.remove"
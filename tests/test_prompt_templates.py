@@ -0,0 +1,78 @@
+"""
+Unit tests for prompt templates.
+
+This test suite covers:
+- Go-only blocks (fault injection, benchmarks, allocation budgets) added to Go prompts and left out of other languages
+"""
+
+import pytest
+from testgen.core.alloc_budgets import describe_alloc_budgets, find_alloc_budgets
+from testgen.core.fault_injection import describe_fault_seams, find_fault_seams, find_go_interfaces
+from testgen.core.go_benchmarks import describe_hot_paths, find_go_functions
+from testgen.core.language_config import Language
+from testgen.core.prompt_templates import PromptTemplates
+
+
+GO_SYNCER = """package sync
+
+import "context"
+
+type Store interface {
+	Save(ctx context.Context, key string, value []byte) error
+}
+
+func (s *Syncer) Sync(ctx context.Context, store Store, keys []string) error {
+	return nil
+}
+"""
+
+GO_ENCODER = """package codec
+
+import "strconv"
+
+type Encoder struct{ buf []byte }
+
+//testgen:allocs 0
+func (e *Encoder) Encode(v int) []byte {
+	e.buf = strconv.AppendInt(e.buf[:0], int64(v), 10)
+	return e.buf
+}
+"""
+
+
+def fault_targets(tmp_path):
+    return describe_fault_seams(find_fault_seams(GO_SYNCER), find_go_interfaces(GO_SYNCER))
+
+
+def benchmark_targets(tmp_path):
+    return describe_hot_paths(find_go_functions(GO_ENCODER))
+
+
+def alloc_budgets(tmp_path):
+    (tmp_path / "codec.go").write_text(GO_ENCODER)
+    return describe_alloc_budgets(find_alloc_budgets(str(tmp_path)))
+
+
+# (prompt keyword, Go source, targets from it, expected in the Go prompt)
+GO_BLOCKS = [
+    ("fault_targets", GO_SYNCER, fault_targets, ["- Syncer.Sync (line 9)", "errors.Is(err, errInjected)"]),
+    ("benchmark_targets", GO_ENCODER, benchmark_targets, ["- BenchmarkEncoderEncode: Encoder.Encode(v int) []byte", "b.ResetTimer()"]),
+    ("alloc_budgets", GO_ENCODER, alloc_budgets, ["- TestAllocsEncoderEncode: Encoder.Encode(v int)", "testing.AllocsPerRun"]),
+]
+
+
+class TestGoBlocks:
+    """Test blocks only Go prompts get."""
+    
+    @pytest.mark.parametrize("keyword, source, targets, expected", GO_BLOCKS, ids=[b[0] for b in GO_BLOCKS])
+    def test_block_only_for_go(self, tmp_path, keyword, source, targets, expected):
+        """Test the block is added to Go prompts, before the closing line, and not to Python prompts."""
+        described = targets(tmp_path)
+        
+        go_prompt = PromptTemplates.get_prompt(Language.GO, source, **{keyword: described})
+        python_prompt = PromptTemplates.get_prompt(Language.PYTHON, "def f(): pass", **{keyword: described})
+        
+        for text in expected:
+            assert text in go_prompt
+            assert text not in python_prompt
+        assert go_prompt.endswith("Generate ONLY the test code, no explanations.")
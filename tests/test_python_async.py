@@ -0,0 +1,174 @@
+"""
+Unit tests for async Python test generation.
+
+This test suite covers:
+- Coroutine and async context manager discovery (parser and scanner)
+- pytest-asyncio / anyio / trio detection
+- Async guidance in Python prompts
+- Rejecting tests that never run the coroutines they call
+"""
+
+from testgen.core.language_config import Language
+from testgen.core.prompt_templates import PromptTemplates
+from testgen.core.python_async import (
+    ANYIO, ASYNCIO, TRIO, AsyncTestConfig, detect_async_framework, find_async_targets
+)
+from testgen.core.response_validator import ResponseValidator
+from testgen.core.scanner import CodeScanner
+from testgen.core.universal_parser import UniversalCodeParser
+
+
+SERVICE = '''
+from contextlib import asynccontextmanager
+
+
+class UserService:
+    def __init__(self, repo):
+        self.repo = repo
+
+    async def get_user(self, user_id: int):
+        await asyncio.sleep(0)
+        return await self.repo.fetch(user_id)
+
+    async def __aenter__(self):
+        return self
+
+    async def __aexit__(self, *exc):
+        await self.repo.close()
+
+
+@asynccontextmanager
+async def session(url):
+    yield url
+
+
+async def stream(n):
+    for i in range(n):
+        yield i
+'''
+
+
+class TestAsyncDiscovery:
+    """Test suite for finding async code under test."""
+    
+    def test_parser_flags_coroutines(self):
+        """Test async functions and methods are extracted with is_async set."""
+        parser = UniversalCodeParser(language="python")
+        functions = {f.name: f for f in parser._extract_functions_python(SERVICE)}
+        classes = parser._extract_classes_python(SERVICE)
+        
+        assert functions["get_user"].is_async
+        assert not functions["__init__"].is_async
+        assert functions["session"].is_async_context_manager
+        assert classes[0].is_async_context_manager
+    
+    def test_targets_and_awaited_dependencies(self):
+        """Test coroutines, generators, context managers and awaited collaborators are found."""
+        targets = find_async_targets(SERVICE)
+        
+        assert targets.coroutines == ["UserService.get_user"]
+        assert targets.async_generators == ["stream"]
+        assert targets.context_managers == ["UserService", "session"]
+        assert targets.awaited_dependencies == ["self.repo.fetch", "self.repo.close"]
+    
+    def test_scanner_keeps_async_signatures(self):
+        """Test the scanner marks async functions and methods."""
+        functions, classes, _ = CodeScanner()._extract_python_info(SERVICE)
+        
+        assert "async get_user(self, user_id: int)" in functions
+        assert "async get_user" in classes[0]
+
+
+class TestAsyncFrameworkDetection:
+    """Test suite for detecting the async test plugin."""
+    
+    def test_pytest_asyncio_auto_mode(self, tmp_path):
+        """Test pytest-asyncio in auto mode is detected from pyproject.toml."""
+        (tmp_path / "pyproject.toml").write_text(
+            '[project.optional-dependencies]\ndev = ["pytest-asyncio>=0.23"]\n'
+            '[tool.pytest.ini_options]\nasyncio_mode = "auto"\n'
+        )
+        
+        config = detect_async_framework(str(tmp_path))
+        
+        assert (config.framework, config.auto_mode) == (ASYNCIO, True)
+        assert config.prompt_framework == "asyncio-auto"
+    
+    def test_anyio_and_trio(self, tmp_path):
+        """Test anyio (conftest backend fixture) and pytest-trio are detected."""
+        (tmp_path / "conftest.py").write_text(
+            "import pytest\n\n@pytest.fixture\ndef anyio_backend():\n    return 'asyncio'\n"
+        )
+        assert detect_async_framework(str(tmp_path)).framework == ANYIO
+        
+        (tmp_path / "requirements-dev.txt").write_text("pytest-trio==0.8.0\n")
+        assert detect_async_framework(str(tmp_path)).framework == TRIO
+    
+    def test_defaults_to_pytest_asyncio(self, tmp_path):
+        """Test projects without a plugin default to pytest-asyncio."""
+        config = detect_async_framework(str(tmp_path))
+        
+        assert (config.framework, config.detected) == (ASYNCIO, False)
+
+
+class TestAsyncPrompts:
+    """Test suite for async guidance in Python prompts."""
+    
+    def test_async_guidance_follows_plugin(self):
+        """Test coroutine code gets AsyncMock, timeout and plugin-specific guidance."""
+        prompt = PromptTemplates.get_prompt(Language.PYTHON, SERVICE, async_framework="anyio")
+        
+        assert "AsyncMock" in prompt
+        assert "@pytest.mark.anyio" in prompt
+        assert "anyio.fail_after()" in prompt
+        assert "cancellation" in prompt
+        assert prompt.endswith("Generate ONLY the test code, no explanations.")
+    
+    def test_sync_code_has_no_async_guidance(self):
+        """Test plain functions keep the plain prompt."""
+        prompt = PromptTemplates.get_prompt(Language.PYTHON, "def add(a, b):\n    return a + b")
+        
+        assert "AsyncMock" not in prompt
+
+
+class TestVacuousAsyncTests:
+    """Test suite for rejecting async tests that don't run anything."""
+    
+    def test_unawaited_coroutine_call_rejected(self):
+        """Test a sync test calling a coroutine without awaiting is invalid."""
+        validator = ResponseValidator(coroutine_names={"get_user"})
+        
+        result = validator.validate_response(
+            "```python\nimport pytest\n\n"
+            "def test_get_user(service):\n"
+            "    result = service.get_user(1)\n"
+            "    assert result is not None\n```"
+        )
+        
+        assert not result.is_valid
+        assert any("get_user() without awaiting" in issue for issue in result.issues)
+    
+    def test_awaited_and_marked_tests_accepted(self):
+        """Test marked async tests and asyncio.run() calls are valid."""
+        validator = ResponseValidator(coroutine_names={"get_user"})
+        
+        result = validator.validate_response(
+            "```python\nimport asyncio\nimport pytest\n\n"
+            "@pytest.mark.asyncio\n"
+            "async def test_get_user(service):\n"
+            "    assert await service.get_user(1) == {'id': 1}\n\n"
+            "def test_get_user_sync(service):\n"
+            "    coro = service.get_user(1)\n"
+            "    assert asyncio.run(coro) == {'id': 1}\n```"
+        )
+        
+        assert result.is_valid, result.issues
+        assert result.test_count == 2
+    
+    def test_unmarked_async_test_rejected_unless_auto_mode(self):
+        """Test async tests without the plugin marker only pass in auto mode."""
+        code = "```python\nimport pytest\n\nasync def test_session():\n    assert True\n```"
+        
+        assert not ResponseValidator().validate_response(code).is_valid
+        auto = AsyncTestConfig(framework=ASYNCIO, detected=True, auto_mode=True)
+        assert ResponseValidator(async_config=auto).validate_response(code).is_valid
@@ -0,0 +1,129 @@
+"""
+Unit tests for per-suite resource usage accounting.
+
+This test suite covers:
+- Merging usage of several runs
+- The Go exec wrapper (rusage of the wrapped process, package lookup)
+- Attaching usage to TestSuite
+- Resource hogs in PerformanceMonitor reports
+"""
+
+import shutil
+import sys
+
+import pytest
+from testgen.core.base_runner import TestResult, TestResults
+from testgen.core.performance_monitor import PerformanceMonitor
+from testgen.core.resource_usage import (
+    SUPPORTED,
+    ResourceUsage,
+    collect_usage,
+    go_package_path,
+    main,
+)
+from testgen.core.result_models import create_execution_summary_from_runner_results
+
+
+MB = 1024 * 1024
+
+
+class TestResourceUsage:
+    """Test suite for the ResourceUsage record."""
+    
+    def test_merge_adds_times_and_keeps_peak(self):
+        """Test merged usage sums CPU and writes but keeps the highest RSS."""
+        first = ResourceUsage(cpu_user=1.0, peak_rss=100 * MB, bytes_written=10, fs_writes=1)
+        second = ResourceUsage(cpu_user=2.0, cpu_system=0.5, peak_rss=50 * MB, fs_writes=2)
+        
+        merged = first.merge(second)
+        assert merged.cpu_time == 3.5
+        assert merged.peak_rss_mb == 100
+        assert merged.bytes_written == 10
+        assert merged.fs_writes == 3
+        assert merged.processes == 2
+    
+    def test_go_package_path_from_go_mod(self, tmp_path):
+        """Test the import path is derived from the enclosing go.mod."""
+        (tmp_path / "go.mod").write_text("module example.com/shop\n\ngo 1.21\n")
+        (tmp_path / "internal" / "cart").mkdir(parents=True)
+        
+        assert go_package_path(tmp_path) == "example.com/shop"
+        assert go_package_path(tmp_path / "internal" / "cart") == "example.com/shop/internal/cart"
+
+
+@pytest.mark.skipif(not SUPPORTED, reason="rusage not available on this platform")
+class TestExecWrapper:
+    """Test suite for the `go test -exec` wrapper."""
+    
+    def test_records_usage_of_wrapped_process(self, tmp_path, monkeypatch):
+        """Test the wrapper measures the child and keeps its exit code."""
+        (tmp_path / "go.mod").write_text("module example.com/heavy\n")
+        monkeypatch.chdir(tmp_path)
+        out_dir = tmp_path / "usage"
+        
+        allocate = "data = bytearray(200 * 1024 * 1024); import sys; sys.exit(3)"
+        with pytest.raises(SystemExit) as exit_info:
+            main(["--out", str(out_dir), sys.executable, "-c", allocate])
+        
+        assert exit_info.value.code == 3
+        usage = collect_usage(str(out_dir))
+        assert list(usage) == ["example.com/heavy"]
+        assert usage["example.com/heavy"].peak_rss_mb >= 200
+    
+    @pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+    def test_go_runner_reports_usage_per_package(self, tmp_path):
+        """Test every Go package's test binary gets its own usage."""
+        from testgen.core.go_runner import GoTestRunner
+        
+        (tmp_path / "go.mod").write_text("module example.com/pkgs\n\ngo 1.21\n")
+        for name in ("a", "b"):
+            (tmp_path / name).mkdir()
+            (tmp_path / name / f"{name}_test.go").write_text(
+                f'package {name}\n\nimport "testing"\n\nfunc TestOK(t *testing.T) {{}}\n'
+            )
+        
+        results = GoTestRunner().run_tests(str(tmp_path), extra_args=["-count=1"], measure_usage=True)
+        unmeasured = GoTestRunner().run_tests(str(tmp_path), extra_args=["-count=1"])
+        
+        assert results.passed == 2
+        assert set(results.resource_usage) == {"example.com/pkgs/a", "example.com/pkgs/b"}
+        assert unmeasured.passed == 2 and unmeasured.resource_usage == {}
+        assert all(u.peak_rss > 0 for u in results.resource_usage.values())
+
+
+class TestResourceReporting:
+    """Test suite for usage on suites and in performance reports."""
+    
+    def _summary(self):
+        results = TestResults(
+            tests=[
+                TestResult(name="TestBig", status="passed", duration=2.0, suite="example.com/big"),
+                TestResult(name="TestSmall", status="passed", duration=0.1, suite="example.com/small"),
+            ],
+            total=2, passed=2, language="go", framework="testing",
+            resource_usage={
+                "example.com/big": ResourceUsage(cpu_user=4.0, peak_rss=3000 * MB, wall_time=2.5),
+                "example.com/small": ResourceUsage(cpu_user=0.1, peak_rss=20 * MB, wall_time=0.2),
+            }
+        )
+        return create_execution_summary_from_runner_results(results)
+    
+    def test_usage_attached_to_suite(self):
+        """Test runner usage ends up on the matching TestSuite."""
+        suites = {s.name: s for s in self._summary().suites}
+        
+        assert suites["example.com/big"].resource_usage.peak_rss_mb == 3000
+        assert suites["example.com/small"].resource_usage.cpu_time == pytest.approx(0.1)
+    
+    def test_memory_hogs_in_report(self):
+        """Test suites above the RSS threshold are reported as hogs next to wall time."""
+        monitor = PerformanceMonitor()
+        monitor.add_summary(self._summary())
+        
+        hogs = monitor.get_resource_hogs()
+        assert [h.suite_name for h in hogs] == ["example.com/big"]
+        assert hogs[0].memory_hog and not hogs[0].cpu_hog
+        
+        report = monitor.generate_performance_report()
+        assert "RESOURCE HOGS" in report
+        assert "example.com/big [memory]: wall 2.50s, CPU 4.00s, peak RSS 3000 MB" in report
@@ -241,6 +241,155 @@ class TestGoRunner:
         with tempfile.TemporaryDirectory() as tmpdir:
             results = runner.run_tests(tmpdir)
             assert mock_run.called
+    
+    @patch('subprocess.run')
+    def test_json_event_parsing(self, mock_run):
+        """Test parsing `go test -json` events into per-test results."""
+        runner = GoTestRunner()
+        
+        mock_result = Mock()
+        mock_result.returncode = 1
+        mock_result.stdout = "\n".join([
+            '{"Action":"run","Package":"example/calc","Test":"TestAdd"}',
+            '{"Action":"pass","Package":"example/calc","Test":"TestAdd","Elapsed":0.01}',
+            '{"Action":"run","Package":"example/calc","Test":"TestSub"}',
+            '{"Action":"output","Package":"example/calc","Test":"TestSub","Output":"    calc_test.go:12: want 1, got 2\\n"}',
+            '{"Action":"fail","Package":"example/calc","Test":"TestSub","Elapsed":0.02}',
+            '{"Action":"fail","Package":"example/calc","Elapsed":0.05}',
+        ])
+        mock_result.stderr = ""
+        mock_run.return_value = mock_result
+        
+        with tempfile.TemporaryDirectory() as tmpdir:
+            results = runner.run_tests(tmpdir)
+        
+        assert results.total == 2
+        assert results.passed == 1
+        assert results.failed == 1
+        failed = [t for t in results.tests if t.status == "failed"][0]
+        assert failed.name == "TestSub"
+        assert failed.suite == "example/calc"
+        assert "want 1, got 2" in failed.message
+    
+    @patch('subprocess.run')
+    def test_rerun_uses_anchored_run_pattern(self, mock_run):
+        """Test failed Go tests are rerun per package with an anchored -run."""
+        from testgen.core.base_runner import TestResult
+        
+        runner = GoTestRunner()
+        
+        mock_result = Mock()
+        mock_result.returncode = 0
+        mock_result.stdout = ""
+        mock_result.stderr = ""
+        mock_run.return_value = mock_result
+        
+        failing = [
+            TestResult(name="TestA", status="failed", suite="example/pkg"),
+            TestResult(name="TestB/case_1", status="failed", suite="example/pkg"),
+        ]
+        
+        # A package build error has no test to select
+        failing.append(TestResult(name="example/broken", status="error", suite="example/broken"))
+        
+        with tempfile.TemporaryDirectory() as tmpdir:
+            runner.rerun_tests(tmpdir, failing)
+        
+        cmd = mock_run.call_args[0][0]
+        assert "example/pkg" in cmd
+        assert cmd[cmd.index("-run") + 1] == "^(TestA|TestB)$"
+        assert all("example/broken" not in call[0][0] for call in mock_run.call_args_list)
+    
+    @patch('subprocess.run')
+    def test_rerun_packages_in_one_go_test(self, mock_run):
+        """Test failed Go tests of several packages are rerun in one go test."""
+        from testgen.core.base_runner import TestResult
+        
+        runner = GoTestRunner()
+        
+        mock_result = Mock()
+        mock_result.returncode = 0
+        mock_result.stdout = ""
+        mock_result.stderr = ""
+        mock_run.return_value = mock_result
+        
+        failing = [
+            TestResult(name="TestA", status="failed", suite="example/pkg"),
+            TestResult(name="TestC", status="failed", suite="example/other"),
+        ]
+        
+        with tempfile.TemporaryDirectory() as tmpdir:
+            runner.rerun_tests(tmpdir, failing)
+        
+        [cmd] = [call[0][0] for call in mock_run.call_args_list if call[0][0][:2] == ["go", "test"]]
+        assert cmd[-2:] == ["example/pkg", "example/other"]
+        assert cmd[cmd.index("-run") + 1] == "^(TestA|TestC)$"
+
+
+class TestRetryExecution:
+    """Test suite for executor-level retries."""
+    
+    def test_flaky_test_marked_passed_on_retry(self):
+        """Test a test that fails then passes is reported as passed on retry."""
+        from testgen.core.base_runner import TestResult, TestResults
+        from testgen.core.test_executor import UniversalTestExecutor, TestExecutionConfig
+        
+        runner = Mock()
+        runner.run_tests.return_value = TestResults(
+            total=2, passed=1, failed=1,
+            tests=[
+                TestResult(name="test_ok", status="passed"),
+                TestResult(name="test_flaky", status="failed", message="boom"),
+            ]
+        )
+        runner.rerun_tests.return_value = TestResults(
+            total=1, passed=1,
+            tests=[TestResult(name="test_flaky", status="passed")]
+        )
+        
+        executor = UniversalTestExecutor(runner)
+        results = executor.run_with_retries(
+            ".", TestExecutionConfig(retry_failed=True, max_retries=2)
+        )
+        
+        flaky = [t for t in results.tests if t.name == "test_flaky"][0]
+        assert flaky.status == "passed_on_retry"
+        assert [a.status for a in flaky.attempts] == ["failed", "passed"]
+        assert results.failed == 0
+        assert results.passed_on_retry == 1
+        assert runner.rerun_tests.call_count == 1
+    
+    def test_retry_matches_suite_and_skips_package_errors(self):
+        """Test retries match tests by suite and name, and never retry or upgrade package errors."""
+        from testgen.core.base_runner import TestResult, TestResults
+        from testgen.core.test_executor import UniversalTestExecutor, TestExecutionConfig
+        
+        runner = Mock()
+        runner.run_tests.return_value = TestResults(
+            total=3, failed=2, errors=1,
+            tests=[
+                TestResult(name="TestGet", status="failed", suite="example.com/a"),
+                TestResult(name="TestGet", status="failed", suite="example.com/b"),
+                TestResult(name="example.com/c", status="error", message="undefined: X", suite="example.com/c"),
+            ]
+        )
+        # Only a's TestGet passes; b's isn't reported at all
+        runner.rerun_tests.return_value = TestResults(
+            total=1, passed=1,
+            tests=[TestResult(name="TestGet", status="passed", suite="example.com/a")]
+        )
+        
+        executor = UniversalTestExecutor(runner)
+        results = executor.run_with_retries(
+            ".", TestExecutionConfig(retry_failed=True, max_retries=1)
+        )
+        
+        retried = runner.rerun_tests.call_args[0][1]
+        assert [(t.suite, t.name) for t in retried] == [("example.com/a", "TestGet"), ("example.com/b", "TestGet")]
+        assert [(t.suite, t.status) for t in results.tests] == [
+            ("example.com/a", "passed_on_retry"), ("example.com/b", "failed"), ("example.com/c", "error")
+        ]
+        assert results.failed == 1 and results.errors == 1
 
 
 class TestRunnerFactory:
@@ -0,0 +1,192 @@
+"""
+Unit tests for security test generation.
+
+This test suite covers:
+- Finding input-handling code (handlers, SQL builders, path joins, deserialization)
+- Go `filepath.Join` with request input and `fmt.Sprintf` into SQL
+- Security guidance and payloads in prompts
+- Reporting failed marked security tests as findings, on top of ordinary failures
+"""
+
+from testgen.core.language_config import Language
+from testgen.core.prompt_templates import PromptTemplates
+from testgen.core.result_aggregator import MultiLanguageAggregator, ResultAggregator
+from testgen.core.result_models import ErrorInfo, TestResult, TestStatus, TestSuite
+from testgen.core.security_tests import (
+    OVERSIZED_INPUT, PATH_TRAVERSAL, SQL_INJECTION, describe_targets, find_input_handlers,
+    find_security_tests, security_category
+)
+
+
+GO_HANDLERS = """package files
+
+func Download(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	path := filepath.Join(baseDir, name)
+	http.ServeFile(w, r, path)
+}
+
+func FindUser(db *sql.DB, name string) (*User, error) {
+	query := fmt.Sprintf("SELECT id FROM users WHERE name = '%s'", name)
+	return scan(db.QueryRow(query))
+}
+
+func configPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".app")
+}
+"""
+
+PYTHON_HANDLERS = """
+@app.route("/files/<name>")
+def download(name):
+    return send_file(os.path.join(BASE, name))
+
+def build_query(user):
+    return f"SELECT * FROM users WHERE name = '{user}'"
+
+def find(cursor, user):
+    cursor.execute("SELECT * FROM users WHERE name = ?", (user,))
+
+def load(blob):
+    return pickle.loads(blob)
+"""
+
+
+class TestInputHandlerDetection:
+    """Test suite for finding input-handling code."""
+    
+    def test_go_join_and_sprintf_sql(self):
+        """Test filepath.Join with request input and Sprintf'd SQL are found, constant joins aren't."""
+        handlers = {h.name: h for h in find_input_handlers(GO_HANDLERS, "go")}
+        
+        assert set(handlers) == {"Download", "FindUser"}
+        assert handlers["Download"].kinds == ["http_handler", "path_join"]
+        assert "filepath.Join(baseDir, name)" in handlers["Download"].evidence[1]
+        assert handlers["FindUser"].categories == [SQL_INJECTION]
+        assert handlers["Download"].line == 3
+    
+    def test_python_routes_queries_and_deserialization(self):
+        """Test routes, f-string SQL and pickle are found; parameterized queries aren't."""
+        handlers = {h.name: h for h in find_input_handlers(PYTHON_HANDLERS, "python")}
+        
+        assert set(handlers) == {"download", "build_query", "load"}
+        assert PATH_TRAVERSAL in handlers["download"].categories
+        assert handlers["load"].kinds == ["deserialization"]
+    
+    def test_express_route(self):
+        """Test Express route handlers are named by method and path."""
+        code = "app.get('/files/:name', (req, res) => {\n  res.sendFile(path.join(BASE, req.params.name));\n});\n"
+        
+        handlers = find_input_handlers(code, "typescript")
+        
+        assert handlers[0].name == "GET /files/:name"
+        assert handlers[0].kinds == ["http_handler", "path_join"]
+
+
+class TestSecurityPrompt:
+    """Test suite for security guidance in prompts."""
+    
+    def test_targets_payloads_and_naming(self):
+        """Test the prompt lists targets with payloads, expected behavior and Go naming."""
+        targets = describe_targets(find_input_handlers(GO_HANDLERS, "go"))
+        
+        prompt = PromptTemplates.get_prompt(Language.GO, GO_HANDLERS, security_targets=targets)
+        
+        assert "- Download (line 3):" in prompt
+        assert "`../../etc/passwd`" in prompt
+        assert "`' OR '1'='1`" in prompt
+        assert "bound parameter" in prompt
+        assert "TestSecurity<Function><Risk>" in prompt
+        assert "`// testgen:security <risk>` comment, where <risk> is one of path_traversal, sql_injection," in prompt
+        assert prompt.endswith("Generate ONLY the test code, no explanations.")
+    
+    def test_prompt_unchanged_without_security_mode(self):
+        """Test prompts don't ask for security tests unless targets are given."""
+        assert "Security tests" not in PromptTemplates.get_prompt(Language.GO, GO_HANDLERS)
+
+
+class TestSecurityFindings:
+    """Test suite for reporting security findings separately."""
+    
+    def test_marked_tests(self, tmp_path):
+        """Test security tests are found by their marker comment, not their names."""
+        (tmp_path / "files_test.go").write_text(
+            "package files\n\n// testgen:security path_traversal\nfunc TestSecurityDownload(t *testing.T) {}\n\n"
+            "func TestSecurityHeaders(t *testing.T) {}\n"
+        )
+        (tmp_path / "test_query.py").write_text(
+            "# testgen:security\n@pytest.mark.parametrize('name', PAYLOADS)\ndef test_build_query_sql_injection(name):\n    pass\n"
+        )
+        (tmp_path / "upload.test.js").write_text(
+            "// testgen:security oversized_input\ndescribe('security: upload', () => {});\n"
+        )
+        
+        marked = find_security_tests(str(tmp_path))
+        
+        assert marked == {
+            "TestSecurityDownload": PATH_TRAVERSAL,
+            "test_build_query_sql_injection": SQL_INJECTION,
+            "security: upload": OVERSIZED_INPUT,
+        }
+        assert security_category("TestSecurityDownload/../../etc/passwd", marked) == PATH_TRAVERSAL
+        assert security_category("tests/test_query.py::test_build_query_sql_injection[' OR 1]", marked) == SQL_INJECTION
+        assert security_category("security: upload > rejects 10 MB", marked) == OVERSIZED_INPUT
+        assert security_category("TestSecurityHeaders", marked) is None
+        assert security_category("TestSecurityDownloadAll", marked) is None
+    
+    def test_findings_are_also_failures(self):
+        """Test failed marked security tests become findings and stay failures."""
+        aggregator = ResultAggregator(security_tests={"TestSecurityDownloadPathTraversal": PATH_TRAVERSAL})
+        aggregator.add_suite(TestSuite(name="files", file_path="files_test.go", tests=[
+            TestResult(name="TestDownload", status=TestStatus.PASSED),
+            TestResult(name="TestParse", status=TestStatus.FAILED, error=ErrorInfo(message="want 2, got 3")),
+            TestResult(
+                name="TestSecurityDownloadPathTraversal",
+                status=TestStatus.FAILED,
+                error=ErrorInfo(message="served /etc/passwd")
+            ),
+            TestResult(name="TestSecurityHeaders", status=TestStatus.FAILED),
+        ]))
+        
+        summary = aggregator.combine_results()
+        
+        assert summary.failed == 3
+        assert [(f.test_name, f.category, f.message) for f in summary.security_findings] == [
+            ("TestSecurityDownloadPathTraversal", PATH_TRAVERSAL, "served /etc/passwd")
+        ]
+        assert [t.name for t, _ in aggregator.get_failed_tests()] == [
+            "TestParse", "TestSecurityDownloadPathTraversal", "TestSecurityHeaders"
+        ]
+        assert "Security Findings:" in aggregator.get_summary_report()
+        assert "[path_traversal] TestSecurityDownloadPathTraversal (files)" in aggregator.get_summary_report()
+        assert not summary.success
+    
+    def test_multi_language_counts_findings_as_failures(self):
+        """Test the multi-language summary counts findings as failures, like the single-language one."""
+        marked = {"TestSecurityDownloadPathTraversal": PATH_TRAVERSAL}
+        suite = TestSuite(name="files", file_path="files_test.go", tests=[
+            TestResult(name="TestParse", status=TestStatus.FAILED),
+            TestResult(name="TestSecurityDownloadPathTraversal", status=TestStatus.FAILED),
+        ])
+        single = ResultAggregator(security_tests=marked)
+        single.add_suite(suite)
+        multi = MultiLanguageAggregator(security_tests=marked)
+        multi.add_suite(suite)
+        
+        overall = multi.get_overall_summary()
+        
+        assert len(overall.security_findings) == 1
+        assert overall.failed == single.combine_results().failed == 2
+        assert not overall.success
+    
+    def test_no_findings_without_markers(self):
+        """Test unmarked tests never become findings, whatever their names."""
+        aggregator = ResultAggregator()
+        aggregator.add_suite(TestSuite(name="files", file_path="files_test.go", tests=[
+            TestResult(name="TestSecurityDownloadPathTraversal", status=TestStatus.FAILED),
+        ]))
+        
+        summary = aggregator.combine_results()
+        
+        assert summary.security_findings == []
+        assert summary.failed == 1
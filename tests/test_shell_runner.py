@@ -0,0 +1,169 @@
+"""
+Unit tests for shell script support.
+
+This test suite covers:
+- Parsing bats TAP output (timing, skips, failure locations)
+- Finding a vendored bats and building bats commands
+- Shell functions, external commands and shebang detection in the scanner
+- Shell/bats detection and PATH-stubbing guidance in prompts
+"""
+
+from unittest.mock import Mock, patch
+
+import pytest
+from testgen.core.base_runner import TestResult
+from testgen.core.language_config import Language
+from testgen.core.language_detector import Language as DetectedLanguage, LanguageDetector, TestFramework
+from testgen.core.prompt_templates import PromptTemplates
+from testgen.core.runner_factory import TestRunnerFactory
+from testgen.core.scanner import CodeScanner, FileType
+from testgen.core.shell_runner import ShellTestRunner
+
+
+TAP_OUTPUT = """1..4
+ok 1 deploy uploads the artifact in 41ms
+not ok 2 deploy fails without a token in 12ms
+# (in test file test/deploy.bats, line 14)
+#   `[ "$status" -eq 1 ]' failed
+ok 3 rollback # skip not implemented yet
+"""
+
+SCRIPT = """#!/usr/bin/env bash
+set -euo pipefail
+SCRIPT_DIR=$(dirname "$0")
+source "$SCRIPT_DIR/lib.sh"
+
+upload() {
+    curl -fsS -T "$1" "$BUCKET_URL" | tee upload.log
+}
+
+function deploy {
+    TOKEN="$DEPLOY_TOKEN" kubectl apply -f manifest.yaml && upload build.tar
+}
+"""
+
+
+@pytest.fixture
+def bats_project(tmp_path):
+    """Project with bats-core vendored as a submodule under test/bats."""
+    (tmp_path / "test" / "bats" / "bin").mkdir(parents=True)
+    (tmp_path / "test" / "bats" / "bin" / "bats").write_text("#!/usr/bin/env bash\n")
+    (tmp_path / "test" / "deploy.bats").write_text('@test "deploy" {\n  run true\n}\n@test "rollback" {\n  skip\n}\n')
+    (tmp_path / "deploy.sh").write_text(SCRIPT)
+    return tmp_path
+
+
+class TestTapParsing:
+    """Test suite for parsing bats TAP output."""
+    
+    def test_results_timing_and_failure_location(self):
+        """Test statuses, --timing durations and failure diagnostics are parsed."""
+        results = ShellTestRunner()._parse_output(Mock(returncode=1, stdout=TAP_OUTPUT, stderr=""))
+        tests = {t.name: t for t in results.tests}
+        
+        assert (results.passed, results.failed, results.skipped) == (1, 1, 1)
+        assert tests["deploy uploads the artifact"].duration == 0.041
+        failed = tests["deploy fails without a token"]
+        assert (failed.file_path, failed.line_number, failed.suite) == ("test/deploy.bats", 14, "deploy")
+        assert failed.message == '`[ "$status" -eq 1 ]\' failed'
+        assert tests["rollback"].message == "not implemented yet"
+    
+    def test_planned_tests_that_never_ran_are_errors(self):
+        """Test a plan larger than the reported tests counts the rest as errors."""
+        results = ShellTestRunner()._parse_output(Mock(returncode=1, stdout=TAP_OUTPUT, stderr=""))
+        
+        assert (results.total, results.errors) == (4, 1)
+    
+    def test_bats_failure_without_output(self):
+        """Test a bats error before any TAP output becomes a single error entry."""
+        results = ShellTestRunner()._parse_output(
+            Mock(returncode=1, stdout="", stderr="bats: test/missing.bats does not exist")
+        )
+        
+        assert results.errors == 1
+        assert "does not exist" in results.tests[0].message
+
+
+class TestBatsCommand:
+    """Test suite for finding bats and building commands."""
+    
+    def test_vendored_bats_preferred(self, bats_project):
+        """Test a bats submodule near the tests is used with TAP and timing output."""
+        cmd = ShellTestRunner().build_command(str(bats_project / "test"))
+        
+        assert cmd[0] == str((bats_project / "test" / "bats" / "bin" / "bats").resolve())
+        assert cmd[1:3] == ["--tap", "--timing"]
+        assert cmd[-2:] == ["--recursive", str(bats_project / "test")]
+    
+    def test_discovery_skips_vendored_bats(self, bats_project):
+        """Test bats' own files aren't discovered or counted as project tests."""
+        (bats_project / "test" / "bats" / "test").mkdir()
+        (bats_project / "test" / "bats" / "test" / "bats.bats").write_text('@test "internal" {\n}\n')
+        runner = ShellTestRunner()
+        
+        assert runner.discover_tests(str(bats_project / "test")) == [bats_project / "test" / "deploy.bats"]
+        assert runner.count_tests(str(bats_project / "test")) == 2
+    
+    @patch('subprocess.run')
+    def test_rerun_uses_anchored_filter(self, mock_run, bats_project):
+        """Test reruns filter on the escaped test names."""
+        mock_run.return_value = Mock(returncode=0, stdout="1..1\nok 1 handles (empty) input\n", stderr="")
+        
+        ShellTestRunner().rerun_tests(
+            str(bats_project / "test"), [TestResult(name="handles (empty) input", status="failed")]
+        )
+        
+        cmd = mock_run.call_args[0][0]
+        assert cmd[cmd.index("--filter") + 1] == r"^(handles \(empty\) input)$"
+
+
+class TestShellScanning:
+    """Test suite for shell scripts in the scanner."""
+    
+    def test_functions_commands_and_sources(self):
+        """Test functions, external commands to stub and sourced files are extracted."""
+        functions, commands, sources = CodeScanner()._extract_shell_info(SCRIPT)
+        
+        assert functions == ["upload", "deploy"]
+        assert commands == ["dirname", "curl", "tee", "kubectl"]
+        assert sources == ["$SCRIPT_DIR/lib.sh"]
+    
+    def test_extensionless_script_with_shebang(self, tmp_path):
+        """Test scripts without an extension are recognized by their shebang."""
+        (tmp_path / "release").write_text(SCRIPT)
+        (tmp_path / "LICENSE").write_text("MIT License\n")
+        scanner = CodeScanner()
+        
+        assert scanner._get_file_type(tmp_path / "release") == FileType.SHELL
+        assert scanner._get_file_type(tmp_path / "LICENSE") == FileType.UNKNOWN
+
+
+class TestShellDetection:
+    """Test suite for shell detection and prompts."""
+    
+    def test_detector_and_factory(self, bats_project):
+        """Test a shell project is detected as bats and gets the shell runner."""
+        detector = LanguageDetector()
+        
+        assert detector.detect_language(str(bats_project)) == DetectedLanguage.SHELL
+        assert detector.detect_test_framework(str(bats_project), DetectedLanguage.SHELL) == TestFramework.BATS
+        assert isinstance(TestRunnerFactory().create_runner(str(bats_project)), ShellTestRunner)
+    
+    def test_bash_files_count_for_multi_language(self, tmp_path):
+        """Test .bash scripts count as shell when detecting multi-language projects."""
+        for i in range(3):
+            (tmp_path / f"deploy{i}.sh").write_text(SCRIPT)
+            (tmp_path / f"release{i}.bash").write_text(SCRIPT)
+            (tmp_path / f"app{i}.py").write_text("x = 1\n")
+            (tmp_path / f"lib{i}.py").write_text("y = 1\n")
+        
+        assert LanguageDetector().get_project_info(str(tmp_path))["is_multi_language"]
+    
+    def test_prompt_stubs_commands_on_path(self):
+        """Test the bats prompt isolates tests in a temp dir and stubs commands on PATH."""
+        prompt = PromptTemplates.get_prompt(Language.SHELL, SCRIPT)
+        
+        assert '"$BATS_TEST_TMPDIR/bin"' in prompt
+        assert "prepend it to PATH" in prompt
+        assert "${lines[@]}" in prompt
+        assert prompt.endswith("Generate ONLY the test code, no explanations.")
@@ -0,0 +1,134 @@
+"""
+Unit tests for local dependency stand-ins.
+
+This test suite covers:
+- Project config loading (testgen.toml)
+- SQLite seeding and rollback
+- HTTP replay of recorded responses
+- Object storage directories
+- Environment injection and teardown
+- Stand-in servers that never start
+"""
+
+import json
+import os
+import sqlite3
+import threading
+import urllib.request
+
+import pytest
+from testgen.core.project_config import load_project_config
+from testgen.core.standins import StandInError, StandInManager, create_replay_server
+
+
+@pytest.fixture
+def project(tmp_path):
+    """Project with a SQLite and an object storage stand-in."""
+    fixtures = tmp_path / "fixtures"
+    (fixtures / "seed" / "avatars").mkdir(parents=True)
+    (fixtures / "seed" / "avatars" / "a.png").write_text("png")
+    (fixtures / "schema.sql").write_text("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);")
+    (fixtures / "users.json").write_text(json.dumps({"users": [{"id": 1, "name": "ada"}]}))
+    (tmp_path / "testgen.toml").write_text(
+        '[[standins.sqlite]]\n'
+        'name = "app_db"\n'
+        'fixtures = ["fixtures/schema.sql", "fixtures/users.json"]\n'
+        '\n'
+        '[[standins.object_storage]]\n'
+        'name = "uploads"\n'
+        'seed = "fixtures/seed"\n'
+        'buckets = ["docs"]\n'
+        '\n'
+        '[standins.env]\n'
+        'APP_ENV = "test"\n'
+    )
+    (tmp_path / "tests").mkdir()
+    return tmp_path
+
+
+class TestProjectConfig:
+    """Test loading the stand-ins section."""
+    
+    def test_found_from_subdirectory(self, project):
+        """Test config is found by searching upward."""
+        config = load_project_config(str(project / "tests"))
+        
+        assert config.root == project
+        assert config.standins.sqlite[0].name == "app_db"
+        assert config.standins.object_storage[0].buckets == ["docs"]
+    
+    def test_defaults_without_config(self, tmp_path):
+        """Test missing config means no stand-ins."""
+        config = load_project_config(str(tmp_path))
+        
+        assert not config.standins.enabled
+
+
+class TestStandInManager:
+    """Test starting, resetting and stopping stand-ins."""
+    
+    def test_environment_injected_and_restored(self, project, monkeypatch):
+        """Test connection details are set while running and removed after."""
+        monkeypatch.delenv("DATABASE_URL", raising=False)
+        manager = StandInManager(load_project_config(str(project)))
+        
+        with manager:
+            assert os.environ["DATABASE_URL"].startswith("sqlite:///")
+            assert os.environ["APP_ENV"] == "test"
+            store = os.environ["UPLOADS_DIR"]
+            assert os.path.exists(os.path.join(store, "avatars", "a.png"))
+            assert os.path.isdir(os.path.join(store, "docs"))
+        
+        assert "DATABASE_URL" not in os.environ
+        assert not os.path.exists(store)
+    
+    def test_rollback_restores_seeded_state(self, project):
+        """Test reset() discards writes made by tests."""
+        with StandInManager(load_project_config(str(project))) as manager:
+            database = manager.env["DATABASE_URL"][len("sqlite:///"):]
+            
+            connection = sqlite3.connect(database)
+            connection.execute("INSERT INTO users (name) VALUES ('grace')")
+            connection.commit()
+            connection.close()
+            
+            manager.reset()
+            
+            connection = sqlite3.connect(database)
+            assert connection.execute("SELECT name FROM users").fetchall() == [("ada",)]
+            connection.close()
+    
+    def test_server_that_never_announces(self, project, monkeypatch, tmp_path):
+        """Test a server process that prints no port fails after the timeout instead of hanging."""
+        monkeypatch.setattr("testgen.core.standins.START_TIMEOUT", 1)
+        manager = StandInManager(load_project_config(str(project)))
+        manager.work_dir = tmp_path
+        
+        with pytest.raises(StandInError, match="no port announced within 1s"):
+            manager._start_server_process("silent", ["timeit", "-n1", "-r1", "import time; time.sleep(30)"])
+        assert manager._processes[0].poll() is not None
+
+
+class TestHTTPReplay:
+    """Test the fake HTTP service."""
+    
+    def test_replays_recorded_responses(self):
+        """Test matching requests get the recorded response, others 404."""
+        server = create_replay_server([
+            {"request": {"method": "GET", "path": "/charges/1"},
+             "response": {"status": 200, "body": {"id": 1, "amount": 5}}},
+        ])
+        thread = threading.Thread(target=server.serve_forever, daemon=True)
+        thread.start()
+        base_url = f"http://127.0.0.1:{server.server_address[1]}"
+        
+        try:
+            body = json.load(urllib.request.urlopen(f"{base_url}/charges/1"))
+            assert body == {"id": 1, "amount": 5}
+            
+            with pytest.raises(urllib.error.HTTPError) as exc_info:
+                urllib.request.urlopen(f"{base_url}/charges/2")
+            assert exc_info.value.code == 404
+        finally:
+            server.shutdown()
+            server.server_close()
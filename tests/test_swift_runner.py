@@ -0,0 +1,162 @@
+"""
+Unit tests for Swift support.
+
+This test suite covers:
+- Package.swift target discovery
+- `swift test --parallel --xunit-output` invocation
+- XCTest and swift-testing results (xUnit reports and console output)
+- swift-testing detection and prompts
+"""
+
+from pathlib import Path
+from unittest.mock import Mock, patch
+
+import pytest
+from testgen.core.base_runner import TestResult
+from testgen.core.language_config import Language
+from testgen.core.language_detector import Language as DetectedLanguage, LanguageDetector, TestFramework
+from testgen.core.prompt_templates import PromptTemplates
+from testgen.core.swift_runner import SwiftTestRunner, discover_package_targets
+
+
+MANIFEST = """// swift-tools-version:5.9
+import PackageDescription
+
+let package = Package(
+    name: "Shop",
+    targets: [
+        .target(name: "Cart", dependencies: ["Models"]),
+        .target(name: "Models", path: "Sources/Domain"),
+        .testTarget(
+            name: "CartTests",
+            dependencies: ["Cart"]
+        ),
+    ]
+)
+"""
+
+XCTEST_REPORT = """<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="TestResults" errors="0" tests="2" failures="1" time="0.01">
+    <testcase classname="CartTests.CartTests" name="testAdd" time="0.001"/>
+    <testcase classname="CartTests.CartTests" name="testRemove" time="0.002">
+      <failure message="XCTAssertEqual failed: (&quot;1&quot;) is not equal to (&quot;2&quot;)"></failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+"""
+
+SWIFT_TESTING_REPORT = """<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="TestResults" errors="0" tests="1" failures="0" skipped="0" time="0.003">
+    <testcase classname="CartTests.TotalTests" name="sumsPrices()" time="0.003"/>
+  </testsuite>
+</testsuites>
+"""
+
+
+@pytest.fixture
+def package(tmp_path):
+    """Swift package with two targets and a test target."""
+    (tmp_path / "Package.swift").write_text(MANIFEST)
+    (tmp_path / "Sources" / "Cart").mkdir(parents=True)
+    (tmp_path / "Sources" / "Cart" / "Cart.swift").write_text("public struct Cart {}\n")
+    (tmp_path / "Tests" / "CartTests").mkdir(parents=True)
+    (tmp_path / "Tests" / "CartTests" / "CartTests.swift").write_text(
+        "import Testing\n@testable import Cart\n\n@Test func sumsPrices() {\n    #expect(1 == 1)\n}\n"
+    )
+    return tmp_path
+
+
+class TestPackageTargets:
+    """Test suite for Package.swift target discovery."""
+    
+    def test_targets_with_paths_and_dependencies(self, package):
+        """Test targets, their kinds, paths and dependencies are read."""
+        targets = {t.name: t for t in discover_package_targets(str(package))}
+        
+        assert targets["Cart"].path == package / "Sources" / "Cart"
+        assert targets["Cart"].dependencies == ["Models"]
+        assert targets["Models"].path == package / "Sources" / "Domain"
+        assert targets["CartTests"].is_test
+        assert targets["CartTests"].path == package / "Tests" / "CartTests"
+    
+    def test_test_target_for_source_file(self, package):
+        """Test the test target depending on a source file's target is found."""
+        runner = SwiftTestRunner()
+        
+        assert runner.test_target_for(str(package / "Sources" / "Cart" / "Cart.swift")).name == "CartTests"
+        assert runner.count_tests(str(package)) == 1
+
+
+class TestSwiftRunner:
+    """Test suite for running swift test."""
+    
+    @patch('subprocess.run')
+    def test_parses_xctest_and_swift_testing_reports(self, mock_run, package):
+        """Test both xUnit reports are read from the package root run."""
+        def swift_test(cmd, **kwargs):
+            report = Path(cmd[cmd.index("--xunit-output") + 1])
+            report.write_text(XCTEST_REPORT)
+            report.with_name("results-swift-testing.xml").write_text(SWIFT_TESTING_REPORT)
+            return Mock(returncode=1, stdout="", stderr="")
+        
+        mock_run.side_effect = swift_test
+        
+        results = SwiftTestRunner().run_tests(str(package / "Tests"))
+        
+        cmd = mock_run.call_args[0][0]
+        assert cmd[:3] == ["swift", "test", "--parallel"]
+        assert mock_run.call_args.kwargs["cwd"] == package.resolve()
+        assert (results.total, results.passed, results.failed) == (3, 2, 1)
+        assert {t.name for t in results.tests} == {"testAdd", "testRemove", "sumsPrices()"}
+    
+    def test_console_fallback_parses_both_frameworks(self):
+        """Test XCTest and swift-testing console lines become per-test results."""
+        output = "\n".join([
+            "Test Case 'CartTests.testAdd' passed (0.001 seconds)",
+            "Test Case '-[CartTests.CartTests testRemove]' failed (0.002 seconds).",
+            "✔ Test sumsPrices() passed after 0.001 seconds.",
+            "✘ Test \"Applies discount\" recorded an issue at CartTests.swift:9:5: Expectation failed: 1 == 2",
+            "✘ Test \"Applies discount\" failed after 0.004 seconds with 1 issue.",
+            "✔ Test run with 2 tests passed after 0.005 seconds.",
+        ])
+        
+        results = SwiftTestRunner()._parse_output(Mock(stdout=output, stderr="", returncode=1))
+        
+        assert [(t.name, t.status) for t in results.tests] == [
+            ("testAdd", "passed"),
+            ("testRemove", "failed"),
+            ("sumsPrices()", "passed"),
+            ("Applies discount", "failed"),
+        ]
+    
+    @patch('subprocess.run')
+    def test_rerun_uses_anchored_filter(self, mock_run, package):
+        """Test failed tests are rerun with an anchored --filter."""
+        mock_run.return_value = Mock(returncode=0, stdout="", stderr="")
+        failing = [TestResult(name="testRemove", status="failed", suite="CartTests.CartTests")]
+        
+        SwiftTestRunner().rerun_tests(str(package), failing)
+        
+        cmd = mock_run.call_args[0][0]
+        assert cmd[cmd.index("--filter") + 1] == r"^(CartTests\.CartTests/testRemove)$"
+
+
+class TestSwiftTesting:
+    """Test suite for swift-testing detection and prompts."""
+    
+    def test_swift_testing_detected(self, package):
+        """Test packages whose tests import Testing use swift-testing."""
+        detector = LanguageDetector()
+        
+        assert detector.detect_language(str(package)) == DetectedLanguage.SWIFT
+        assert detector.detect_test_framework(str(package)) == TestFramework.SWIFT_TESTING
+    
+    def test_swift_testing_prompt(self):
+        """Test the swift-testing prompt asks for @Test and #expect."""
+        prompt = PromptTemplates.get_prompt(Language.SWIFT, "func add(_ a: Int, _ b: Int) -> Int", "swift-testing")
+        
+        assert "@Test" in prompt
+        assert "#expect" in prompt
+        assert "#expect(throws: ErrorType.self) { ... }" in prompt
@@ -0,0 +1,148 @@
+"""
+Unit tests for table-driven refactoring of Go tests.
+
+This test suite covers:
+- Tokenizing test bodies with constant expressions joined
+- Clustering structurally similar tests (samples/go/sample_test.go)
+- The table-driven rewrite and its patch
+- Verifying per-case outcomes before and after with go test -overlay
+"""
+
+import shutil
+import subprocess
+from pathlib import Path
+
+import pytest
+from testgen.core.table_tests import find_test_clusters, make_patch, refactor_file, rewrite, tokenize
+
+
+SAMPLE = Path(__file__).parent.parent / "samples" / "go" / "sample_test.go"
+
+MIXED = """package calc
+
+import (
+	"testing"
+	"time"
+)
+
+// Halving rounds down.
+func TestHalfEven(t *testing.T) {
+	if got := Half(4); got != 2 {
+		t.Fatalf("Half(4) = %d", got)
+	}
+}
+
+func TestHalfOdd(t *testing.T) {
+	if got := Half(5); got != 2 {
+		t.Fatalf("Half(5) = %d", got)
+	}
+}
+
+func TestHalfNegative(t *testing.T) {
+	if got := Half(-3); got != -1 {
+		t.Fatalf("Half(-3) = %d", got)
+	}
+}
+
+func TestWaitShort(t *testing.T) {
+	time.Sleep(1 * time.Millisecond)
+}
+
+func TestWaitLong(t *testing.T) {
+	time.Sleep(2 * time.Millisecond)
+}
+"""
+
+CALC = """package calc
+
+func Half(n int) int {
+	return n / 2
+}
+"""
+
+
+class TestTokenize:
+    """Test constant expressions become single tokens."""
+    
+    def test_constant_expressions(self):
+        """Test `2 + 2` and `-3` are lifted whole, but not a literal bound to `*`."""
+        consts = [t.text for t in tokenize("result := 2 + 2\nx := f(-3)\ntime.Sleep(1 * time.Second)\n") if t.kind == "const"]
+        
+        assert consts == ["2 + 2", "-3", "1"]
+
+
+class TestClusters:
+    """Test finding and rewriting clusters."""
+    
+    def test_sample_addition_and_subtraction(self):
+        """Test the sample's two passing arithmetic tests form the only cluster."""
+        clusters = find_test_clusters(SAMPLE.read_text())
+        
+        assert [c.original_names for c in clusters] == [["TestAdditionPass", "TestSubtractionPass"]]
+        cluster = clusters[0]
+        assert cluster.name == "TestPass"
+        assert cluster.case_names == ["Addition", "Subtraction"]
+        assert [(n, t) for n, t, _ in cluster.fields] == [("result", "int"), ("want", "int"), ("format", "string")]
+        assert '{name: "Subtraction", result: 10 - 5, want: 5, format: "Expected 5, got %d"},' in cluster.source
+        assert "\t\t\tif result != tc.want {\n\t\t\t\tt.Errorf(tc.format, result)" in cluster.source
+    
+    def test_rewrite_keeps_doc_comments_and_other_tests(self):
+        """Test docs move onto their case and untouched tests stay in place."""
+        clusters = find_test_clusters(MIXED)
+        half = next(c for c in clusters if c.name == "TestHalf")
+        
+        rewritten = rewrite(MIXED, [half])
+        
+        assert half.case_names == ["Even", "Odd", "Negative"]
+        assert "\t\t// Halving rounds down.\n\t\t{name: \"Even\", arg: 4, want: 2, format: \"Half(4) = %d\"}," in rewritten
+        assert '{name: "Negative", arg: -3, want: -1, format: "Half(-3) = %d"},' in rewritten
+        assert "func TestHalfOdd" not in rewritten
+        assert rewritten.count("func TestWait") == 2
+        assert "}\n\nfunc TestWaitShort" in rewritten
+    
+    def test_patch(self):
+        """Test the patch has git-style paths."""
+        patch = make_patch("a\nb\n", "a\nc\n", Path("pkg/x_test.go"))
+        
+        assert patch.startswith("--- a/pkg/x_test.go\n+++ b/pkg/x_test.go\n")
+        assert "-b\n+c\n" in patch
+
+
+@pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+class TestVerify:
+    """Test before/after verification with the Go toolchain."""
+    
+    def test_verified_and_rejected_clusters(self, tmp_path):
+        """Test outcomes match for the Half cases and a rewrite that doesn't build is left alone."""
+        (tmp_path / "go.mod").write_text("module example.com/calc\n\ngo 1.22\n")
+        (tmp_path / "calc.go").write_text(CALC)
+        test_file = tmp_path / "calc_test.go"
+        test_file.write_text(MIXED)
+        
+        result = refactor_file(str(test_file))
+        
+        assert [c.name for c in result.applied] == ["TestHalf"]
+        assert result.applied[0].outcomes == {
+            "TestHalfEven": ("passed", "passed"),
+            "TestHalfOdd": ("passed", "passed"),
+            "TestHalfNegative": ("passed", "passed"),
+        }
+        assert result.rejected[0].original_names == ["TestWaitShort", "TestWaitLong"]
+        assert result.rejected[0].problem.startswith("rewrite doesn't build")
+        assert test_file.read_text() == MIXED
+        
+        test_file.write_text(result.rewritten)
+        after = subprocess.run(["go", "test", "-v", "-run", "^TestHalf$", "."], cwd=tmp_path, capture_output=True, text=True)
+        assert "--- PASS: TestHalf/Negative" in after.stdout
+    
+    def test_failing_tests_stay_failing(self, tmp_path):
+        """Test a cluster with a failing case is still rewritten with the same outcomes."""
+        (tmp_path / "go.mod").write_text("module example.com/calc\n\ngo 1.22\n")
+        (tmp_path / "calc.go").write_text(CALC)
+        test_file = tmp_path / "calc_test.go"
+        test_file.write_text(MIXED.replace("got != -1", "got != -2"))
+        
+        result = refactor_file(str(test_file))
+        
+        assert result.applied[0].outcomes["TestHalfNegative"] == ("failed", "failed")
+        assert result.applied[0].outcomes["TestHalfEven"] == ("passed", "passed")
@@ -0,0 +1,204 @@
+"""
+Unit tests for failure-first test ordering.
+
+This test suite covers:
+- Run store history (last status, durations)
+- Ordering tiers: failing, impacted, rest by duration
+- Runner arguments for each step (node ids, -run/-skip groups, fail-fast)
+- Ordered execution with fail-fast and retries
+"""
+
+from unittest.mock import Mock
+
+import pytest
+from testgen.core.base_runner import TestResult, TestResults
+from testgen.core.python_runner import PythonTestRunner
+from testgen.core.run_store import RunStore
+from testgen.core.test_executor import UniversalTestExecutor, TestExecutionConfig
+from testgen.core.test_ordering import TestOrderer, ExecutionStep, GoPackage, go_name_pattern
+
+
+def _results(*tests, language="python", framework="pytest"):
+    results = TestResults(tests=list(tests), language=language, framework=framework)
+    for test in tests:
+        results.total += 1
+        if test.status == "passed":
+            results.passed += 1
+        elif test.status == "failed":
+            results.failed += 1
+    return results
+
+
+@pytest.fixture
+def test_files(tmp_path):
+    """Three test files, one of them exercising `parser`."""
+    tests_dir = tmp_path / "tests"
+    tests_dir.mkdir()
+    (tests_dir / "test_fast.py").write_text("def test_a(): pass\n")
+    (tests_dir / "test_slow.py").write_text("def test_b(): pass\n")
+    (tests_dir / "test_parser.py").write_text("from app import parser\n")
+    return tests_dir
+
+
+class TestRunStore:
+    """Test suite for the run store."""
+    
+    def test_history_tracks_last_status_and_durations(self, tmp_path):
+        """Test history aggregates outcomes over runs."""
+        store = RunStore(str(tmp_path))
+        store.record_run(_results(TestResult(name="t.py::test_x", status="failed", duration=1.0)))
+        store.record_run(_results(TestResult(name="t.py::test_x", status="passed", duration=3.0)))
+        
+        history = store.test_history("python")["t.py::test_x"]
+        assert history.runs == 2
+        assert history.failures == 1
+        assert history.last_failed is False
+        assert history.average_duration == 2.0
+    
+    def test_old_runs_are_pruned(self, tmp_path):
+        """Test only max_runs runs are kept."""
+        store = RunStore(str(tmp_path), max_runs=2)
+        for _ in range(3):
+            store.record_run(_results(TestResult(name="test_x", status="passed")))
+        
+        assert len(store.list_runs()) == 2
+        assert len(list((tmp_path / "runs").glob("*.json"))) == 3  # 2 runs + index
+
+
+class TestOrdering:
+    """Test suite for the ordering of steps."""
+    
+    def test_failing_then_impacted_then_by_duration(self, tmp_path, test_files):
+        """Test previously failing tests run first and the rest shortest first."""
+        store = RunStore(str(tmp_path / "cache"))
+        store.record_run(_results(
+            TestResult(name="tests/test_fast.py::test_a", status="passed", duration=0.1),
+            TestResult(name="tests/test_slow.py::test_b", status="failed", duration=5.0),
+            TestResult(name="tests/test_parser.py::test_c", status="passed", duration=9.0),
+        ))
+        
+        orderer = TestOrderer(store.test_history(), [tmp_path / "app" / "parser.py"])
+        steps = orderer.plan_files(sorted(test_files.glob("test_*.py")), node_ids=True)
+        
+        assert steps[0].tier == "failing"
+        assert steps[0].tests == ["tests/test_slow.py::test_b"]
+        assert [(s.tier, s.target.rsplit("/", 1)[1]) for s in steps[1:]] == [
+            ("impacted", "test_parser.py"),
+            ("rest", "test_fast.py"),
+            ("rest", "test_slow.py"),
+        ]
+        assert steps[3].skip_tests == ["tests/test_slow.py::test_b"]
+    
+    def test_go_tiers_run_as_one_go_test(self, tmp_path, monkeypatch):
+        """Test Go packages of a tier run together, build failures run whole and first."""
+        store = RunStore(str(tmp_path / "cache"))
+        store.record_run(_results(
+            TestResult(name="TestA", status="failed", suite="example.com/a", duration=1.0),
+            TestResult(name="TestB", status="passed", suite="example.com/a", duration=1.0),
+            TestResult(name="example.com/b", status="error", suite="example.com/b"),
+            TestResult(name="TestC", status="passed", suite="example.com/c", duration=2.0),
+            TestResult(name="TestD", status="passed", suite="example.com/d", duration=1.0),
+            language="go", framework="testing"
+        ))
+        packages = {
+            path: GoPackage(import_path=path, dir=tmp_path / path, imports=set(), has_tests=True)
+            for path in ("example.com/a", "example.com/b", "example.com/c", "example.com/d")
+        }
+        monkeypatch.setattr("testgen.core.test_ordering.list_go_packages", lambda module_dir: packages)
+        monkeypatch.setattr("testgen.core.test_ordering.go_supports_skip", lambda: True)
+        
+        steps = TestOrderer(store.test_history("go"), []).plan_go(str(tmp_path))
+        
+        assert [(s.tier, s.packages, s.tests, s.skip_tests) for s in steps] == [
+            ("failing", ["example.com/a"], ["TestA"], []),
+            ("failing", ["example.com/b"], [], []),
+            ("rest", ["example.com/d", "example.com/c"], [], []),
+            ("rest", ["example.com/a"], [], ["TestA"]),
+        ]
+        assert steps[2].runner_kwargs("testing", ".")["packages"] == ["example.com/d", "example.com/c"]
+    
+    def test_go_without_skip_runs_failing_packages_whole(self, tmp_path, monkeypatch):
+        """Test failing packages aren't split when go test has no -skip (before Go 1.20)."""
+        store = RunStore(str(tmp_path / "cache"))
+        store.record_run(_results(
+            TestResult(name="TestA", status="failed", suite="example.com/a"),
+            language="go", framework="testing"
+        ))
+        packages = {"example.com/a": GoPackage("example.com/a", tmp_path, set(), True)}
+        monkeypatch.setattr("testgen.core.test_ordering.list_go_packages", lambda module_dir: packages)
+        monkeypatch.setattr("testgen.core.test_ordering.go_supports_skip", lambda: False)
+        
+        steps = TestOrderer(store.test_history("go"), []).plan_go(str(tmp_path))
+        
+        assert [(s.tier, s.packages, s.tests, s.skip_tests) for s in steps] == [("failing", ["example.com/a"], [], [])]
+    
+    def test_go_step_arguments(self):
+        """Test Go steps use -run groups, -skip and -failfast."""
+        failing = ExecutionStep(tier="failing", target="example.com/a", tests=["TestB", "TestA/sub"])
+        rest = ExecutionStep(tier="rest", target="example.com/a", skip_tests=["TestB"])
+        
+        assert failing.runner_kwargs("testing", ".") == {
+            "test_dir": ".", "pattern": None, "packages": ["example.com/a"], "run": "^(TestA|TestB)$"
+        }
+        assert rest.runner_kwargs("testing", ".", remaining_failures=1)["extra_args"] == [
+            "-skip", "^(TestB)$", "-failfast"
+        ]
+    
+    def test_pytest_step_arguments(self):
+        """Test pytest steps deselect failing tests and pass --maxfail."""
+        step = ExecutionStep(tier="rest", target="tests/test_a.py", skip_tests=["tests/test_a.py::test_x"])
+        
+        kwargs = step.runner_kwargs("pytest", "tests", remaining_failures=3)
+        assert kwargs["test_dir"] == "tests/test_a.py"
+        assert kwargs["extra_args"] == ["--deselect", "tests/test_a.py::test_x", "--maxfail=3"]
+    
+    def test_go_name_pattern_escapes_names(self):
+        """Test -run patterns are anchored and escaped."""
+        assert go_name_pattern(["TestX/case.1", "TestY"]) == "^(TestX|TestY)$"
+
+
+class TestOrderedExecution:
+    """Test suite for UniversalTestExecutor.execute_ordered."""
+    
+    def test_stops_after_max_failures(self, tmp_path, test_files):
+        """Test fail-fast stops before the remaining steps run."""
+        runner = Mock()
+        runner.get_language.return_value = "javascript"
+        runner.get_framework.return_value = "jest"
+        runner.discover_tests.return_value = sorted(test_files.glob("test_*.py"))
+        runner.run_tests.return_value = _results(
+            TestResult(name="test_x", status="failed"),
+            language="javascript", framework="jest"
+        )
+        store = RunStore(str(tmp_path / "cache"))
+        
+        results = UniversalTestExecutor(runner).execute_ordered(
+            str(test_files), TestExecutionConfig(max_failures=1), run_store=store, changed_files=[]
+        )
+        
+        assert results.stopped_early
+        assert runner.run_tests.call_count == 1
+        assert runner.run_tests.call_args.kwargs["extra_args"] == ["--bail=1"]
+        assert store.list_runs()[0]["metadata"]["stopped_early"] is True
+    
+    def test_retries_failing_pytest_step(self, tmp_path, test_files, monkeypatch):
+        """Test a failing pytest step is retried with the rerun's own JSON report."""
+        runner = PythonTestRunner()
+        calls = []
+        
+        def run_tests(test_dir, pattern=None, **kwargs):
+            calls.append(kwargs)
+            status = "passed" if kwargs.get("node_ids") else "failed"
+            return _results(TestResult(name="tests/test_fast.py::test_a", status=status, suite="tests/test_fast.py"))
+        
+        monkeypatch.setattr(runner, "run_tests", run_tests)
+        store = RunStore(str(tmp_path / "cache"))
+        
+        results = UniversalTestExecutor(runner).execute_ordered(
+            str(test_files), TestExecutionConfig(retry_failed=True, max_retries=1), run_store=store, changed_files=[]
+        )
+        
+        rerun = calls[-1]
+        assert rerun["node_ids"] == ["tests/test_fast.py::test_a"]
+        assert rerun["json_report"] is True and rerun["json_report_file"].endswith("rerun.json")
+        assert results.failed == 0 and results.passed_on_retry == len(calls) // 2  # one rerun per step
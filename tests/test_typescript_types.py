@@ -0,0 +1,188 @@
+"""
+Unit tests for type-aware TypeScript generation.
+
+This test suite covers:
+- Compiler type information (helper output) and its rendering
+- Type information in the TypeScript Jest prompt
+- Rejecting generated tests that fail `tsc --noEmit`
+"""
+
+import json
+from pathlib import Path
+from unittest.mock import Mock, patch
+
+import pytest
+from testgen.core import typescript_types
+from testgen.core.file_writer import TestFileWriter
+from testgen.core.language_config import Language
+from testgen.core.prompt_templates import PromptTemplates
+from testgen.core.typescript_types import TypeInfo, extract_types, typecheck_test_file
+
+
+HELPER_OUTPUT = {
+    "functions": [{
+        "name": "area",
+        "parameters": [{"name": "shape", "type": "Shape", "optional": False, "rest": False}],
+        "return_type": "number",
+        "type_parameters": [],
+        "is_async": False,
+        "exported": True
+    }, {
+        "name": "load",
+        "parameters": [
+            {"name": "id", "type": "string", "optional": False, "rest": False},
+            {"name": "options", "type": "LoadOptions", "optional": True, "rest": False}
+        ],
+        "return_type": "Promise<Shape>",
+        "type_parameters": [],
+        "is_async": True,
+        "exported": True
+    }],
+    "interfaces": [{
+        "name": "LoadOptions",
+        "properties": [
+            {"name": "cache", "type": "boolean", "optional": True, "readonly": False},
+            {"name": "timeout", "type": "number", "optional": False, "readonly": True}
+        ],
+        "exported": True
+    }],
+    "unions": [{
+        "name": "Shape",
+        "type": "{ kind: \"circle\"; radius: number; } | { kind: \"square\"; size: number; }",
+        "discriminant": "kind",
+        "variants": [
+            {"tag": "\"circle\"", "type": "{ kind: \"circle\"; radius: number; }"},
+            {"tag": "\"square\"", "type": "{ kind: \"square\"; size: number; }"}
+        ],
+        "exported": True
+    }],
+    "aliases": [],
+    "enums": [],
+    "classes": []
+}
+
+
+@pytest.fixture
+def ts_project(tmp_path):
+    """TypeScript project with a tsconfig and an installed `typescript` package."""
+    (tmp_path / "tsconfig.json").write_text('{"compilerOptions": {"strict": true}}')
+    (tmp_path / "node_modules" / "typescript").mkdir(parents=True)
+    (tmp_path / "node_modules" / "typescript" / "package.json").write_text('{"name": "typescript"}')
+    (tmp_path / "src").mkdir()
+    (tmp_path / "src" / "shapes.ts").write_text("export type Shape = { kind: 'circle' };\n")
+    return tmp_path
+
+
+class TestTypeInfo:
+    """Test suite for compiler type information."""
+    
+    def test_declarations_render_types_and_unions(self):
+        """Test parameters, optional fields and union variants are rendered."""
+        declarations = TypeInfo.from_dict("shapes.ts", HELPER_OUTPUT).to_declarations()
+        
+        assert "export interface LoadOptions {" in declarations
+        assert "  cache?: boolean;" in declarations
+        assert "  readonly timeout: number;" in declarations
+        assert '// discriminated union on `kind`: "circle" | "square"' in declarations
+        assert "export async function load(id: string, options?: LoadOptions): Promise<Shape>;" in declarations
+    
+    @patch('subprocess.run')
+    def test_extract_types_runs_helper_with_project_tsconfig(self, mock_run, ts_project):
+        """Test the helper runs under node with the project's tsconfig."""
+        source = ts_project / "src" / "shapes.ts"
+        mock_run.return_value = Mock(
+            returncode=0,
+            stdout=json.dumps({"typescript": "5.4.5", "files": {str(source.resolve()): HELPER_OUTPUT}}),
+            stderr=""
+        )
+        
+        with patch.object(typescript_types, "find_node", return_value="/usr/bin/node"):
+            types = extract_types([str(source)])
+        
+        cmd = mock_run.call_args[0][0]
+        assert cmd[:2] == ["/usr/bin/node", str(typescript_types.HELPER_SCRIPT)]
+        assert cmd[cmd.index("--project") + 1] == str(ts_project / "tsconfig.json")
+        info = types[str(source.resolve())]
+        assert info.unions[0].tags == ['"circle"', '"square"']
+        assert info.function_signatures()[1] == "async load(id: string, options?: LoadOptions): Promise<Shape>"
+    
+    def test_no_types_without_typescript(self, tmp_path):
+        """Test extraction is skipped when the project has no typescript package."""
+        (tmp_path / "package.json").write_text("{}")
+        (tmp_path / "index.ts").write_text("export const x = 1;\n")
+        
+        assert extract_types([str(tmp_path / "index.ts")]) == {}
+
+
+class TestTypeScriptPrompt:
+    """Test suite for type information in TypeScript prompts."""
+    
+    def test_type_declarations_added_to_prompt(self):
+        """Test declarations and type guidance are inserted before the final instruction."""
+        declarations = TypeInfo.from_dict("shapes.ts", HELPER_OUTPUT).to_declarations()
+        
+        prompt = PromptTemplates.get_prompt(
+            Language.TYPESCRIPT, "export function area(shape: Shape) {}", type_declarations=declarations
+        )
+        
+        assert "Type information from the TypeScript compiler" in prompt
+        assert "export interface LoadOptions {" in prompt
+        assert "Cover every variant of each discriminated union" in prompt
+        assert prompt.endswith("Generate ONLY the test code, no explanations.")
+    
+    def test_prompt_unchanged_without_types(self):
+        """Test prompts without type information keep the plain template."""
+        prompt = PromptTemplates.get_prompt(Language.TYPESCRIPT, "export const x = 1;")
+        
+        assert "Type information" not in prompt
+
+
+class TestTypeCheck:
+    """Test suite for rejecting tests that fail tsc --noEmit."""
+    
+    @patch('subprocess.run')
+    def test_only_test_file_errors_count(self, mock_run, ts_project):
+        """Test tsc errors in the code under test don't reject the test."""
+        test_file = ts_project / "src" / "shapes.test.ts"
+        test_file.write_text("test('x', () => {});\n")
+        configs = []
+        
+        def tsc(cmd, **kwargs):
+            configs.append(json.loads(Path(cmd[cmd.index("--project") + 1]).read_text()))
+            return Mock(returncode=2, stderr="", stdout="\n".join([
+                "src/shapes.ts(3,1): error TS2322: Type 'number' is not assignable to type 'string'.",
+                "src/shapes.test.ts(4,20): error TS2345: Argument of type '\"triangle\"' is not assignable to parameter of type 'Shape'.",
+            ]))
+        
+        mock_run.side_effect = tsc
+        
+        with patch.object(typescript_types, "find_node", return_value="/usr/bin/node"):
+            result = typecheck_test_file(str(test_file))
+        
+        assert configs[0]["extends"] == "./tsconfig.json"
+        assert configs[0]["files"] == [str(test_file.resolve())]
+        assert result.checked and not result.ok
+        assert result.diagnostics == [
+            "shapes.test.ts:4:20 TS2345: Argument of type '\"triangle\"' is not assignable to parameter of type 'Shape'."
+        ]
+        assert not list(ts_project.glob("tsconfig.testgen-*.json"))
+    
+    @patch('subprocess.run')
+    def test_writer_rejects_tests_that_do_not_compile(self, mock_run, ts_project):
+        """Test a failing type check restores the previous test file."""
+        test_file = ts_project / "src" / "shapes.test.ts"
+        test_file.write_text("// previous tests\n")
+        mock_run.return_value = Mock(
+            returncode=2,
+            stderr="",
+            stdout="src/shapes.test.ts(1,1): error TS2304: Cannot find name 'areaa'."
+        )
+        writer = TestFileWriter(output_dir=str(ts_project), add_header=False)
+        
+        with patch.object(typescript_types, "find_node", return_value="/usr/bin/node"):
+            result = writer.save_test_file("areaa({ kind: 'circle' });\n", output_path=str(test_file))
+        
+        assert not result.success
+        assert result.diagnostics == ["shapes.test.ts:1:1 TS2304: Cannot find name 'areaa'."]
+        assert test_file.read_text() == "// previous tests\n"
+        assert sorted(p.name for p in test_file.parent.iterdir()) == ["shapes.test.ts", "shapes.ts"]